@@ -2,12 +2,17 @@ package main
 
 import (
 	"FMTS/initiator"
+	"os"
 	// "FMTS/kafka"
 )
 
 func main() {
 	// GenerateAdminToken()
 	// kafka.Kafka_demo()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		initiator.Migrate(os.Args[2:])
+		return
+	}
 	initiator.Initiator()
 
 }
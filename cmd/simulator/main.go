@@ -0,0 +1,10 @@
+package main
+
+import (
+	"FMTS/initiator"
+	"os"
+)
+
+func main() {
+	initiator.Simulate(os.Args[1:])
+}
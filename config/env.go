@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnvFloat reads a float environment variable, falling back to def when unset.
+func GetEnvFloat(key string, def float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("Warning: invalid value %q for %s, using default %v", raw, key, def)
+		return def
+	}
+	return v
+}
+
+// GetEnvDuration reads a Go duration (e.g. "5m") environment variable, falling back to def when unset.
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Warning: invalid value %q for %s, using default %v", raw, key, def)
+		return def
+	}
+	return v
+}
+
+// GetEnvString reads a string environment variable, falling back to def when unset.
+func GetEnvString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// GetEnvInt reads an integer environment variable, falling back to def when unset.
+func GetEnvInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("Warning: invalid value %q for %s, using default %v", raw, key, def)
+		return def
+	}
+	return v
+}
+
+// GetEnvBool reads a boolean environment variable, falling back to def when unset.
+func GetEnvBool(key string, def bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Warning: invalid value %q for %s, using default %v", raw, key, def)
+		return def
+	}
+	return v
+}
+
+// GetEnvList reads a comma separated environment variable, falling back to def when unset.
+func GetEnvList(key string, def []string) []string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
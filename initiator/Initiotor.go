@@ -11,6 +11,8 @@ import (
 	// "FMTS/internal/adapter/inbound/http/responseutil"
 	// "FMTS/pkg/utils"
 
+	config "FMTS/config"
+	"FMTS/internal/tracking/adapter/inbound/live"
 	"FMTS/utils"
 	util "FMTS/utils"
 
@@ -52,16 +54,42 @@ func Initiator() {
 	jwtManager := utils.NewJWTManager(jwtSecretKey, 15*time.Minute, 7*24*time.Hour, key, iv) // 15 min access, 7 days refresh
 	logger.Infof("JWT manager initialized")
 
+	liveHub := live.NewHub(logger)
+
 	logger.Infof("Initializing domain services...")
-	domain := InitDomain(persistence, logger, jwtManager)
+	trackerConfig := LoadTrackerConfig()
+	overspeedConfig := LoadOverspeedConfig()
+	domain := InitDomain(persistence, logger, jwtManager, trackerConfig, overspeedConfig, liveHub)
 	logger.Infof("Domain services initialized")
 
 	logger.Infof("Initializing application services...")
 	application := InitApplication(domain, logger)
 	logger.Infof("Application services initialized")
 
+	logger.Infof("Initializing location ingestion...")
+	ingestion := InitIngestion(LoadIngestionConfig(), application.TrackerApp, logger)
+	ingestion.Start(logger)
+	logger.Infof("Location ingestion initialized")
+
+	retention := InitRetention(trackerConfig.Retention, domain.TrackerDomain)
+	retention.Start(logger)
+
+	connectivity := InitConnectivity(trackerConfig.Connectivity, domain.TrackerDomain)
+	connectivity.Start(logger)
+
+	trips := InitTripSegmentation(trackerConfig.Trips, domain.TrackerDomain)
+	trips.Start(logger)
+
+	overspeedSweep := InitOverspeedSweep(overspeedConfig, domain.OverspeedDomain)
+	overspeedSweep.Start(logger)
+
+	logger.Infof("Initializing GT06 listener...")
+	gt06Server := InitGT06(LoadGT06Config(), domain, application.TrackerApp, ingestion.Producer, logger)
+
 	logger.Infof("Initializing adapter services...")
-	adapter := InitAdapter(application, logger)
+	// Browser origins allowed by CORS and on the live location stream.
+	allowedOrigins := config.GetEnvList("CORS_ALLOWED_ORIGINS", []string{"*"})
+	adapter := InitAdapter(application, ingestion.Producer, liveHub, allowedOrigins, logger)
 	logger.Infof("Adapter services initialized")
 
 	logger.Infof("Initializing Chi router...")
@@ -69,7 +97,7 @@ func Initiator() {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST"},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: true,
@@ -109,6 +137,16 @@ func Initiator() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatalf("Failed to shutdown gracefully: %v", err)
 	}
+	if gt06Server != nil {
+		if err := gt06Server.Shutdown(ctx); err != nil {
+			logger.Errorf("Failed to shutdown GT06 listener: %v", err)
+		}
+	}
+	retention.Stop()
+	connectivity.Stop()
+	trips.Stop()
+	overspeedSweep.Stop()
+	ingestion.Stop(logger)
 
 	logger.Infof("Server shutdown successfully")
 }
@@ -1,6 +1,13 @@
 package initiator
 
 import (
+	"errors"
+	"fmt"
+
+	device_application "FMTS/internal/device/application"
+	device_service "FMTS/internal/device/domain/service"
+	"FMTS/internal/middleware"
+	"FMTS/kafka"
 	"FMTS/pkg/utils"
 
 	user_adapter "FMTS/internal/user/adapter/inbound/http"
@@ -10,24 +17,63 @@ import (
 	vehicle_port "FMTS/internal/vehicle/port/inbound"
 
 	tracker_adapter "FMTS/internal/tracking/adapter/inbound/http"
+	"FMTS/internal/tracking/adapter/inbound/live"
 	tracker_port "FMTS/internal/tracking/port/inbound"
 
 	authUser_adapter "FMTS/internal/auth/adapter/inbound/http"
 	authUser_port "FMTS/internal/auth/port/inbound"
+
+	device_adapter "FMTS/internal/device/adapter/inbound/http"
+	device_port "FMTS/internal/device/port/inbound"
+
+	geofence_adapter "FMTS/internal/geofence/adapter/inbound/http"
+	geofence_port "FMTS/internal/geofence/port/inbound"
+
+	overspeed_adapter "FMTS/internal/overspeed/adapter/inbound/http"
+	overspeed_port "FMTS/internal/overspeed/port/inbound"
 )
 
 type Adapter struct {
-	UserAdapter     user_port.UserPortHandler
-	VihicleAdapter  vehicle_port.VehiclePortInterface
-	TrackerAdapter  tracker_port.TrackerPortHandler
-	AuthUserAdapter authUser_port.AuthHandler
+	UserAdapter      user_port.UserPortHandler
+	VihicleAdapter   vehicle_port.VehiclePortInterface
+	TrackerAdapter   tracker_port.TrackerPortHandler
+	AuthUserAdapter  authUser_port.AuthHandler
+	GeofenceAdapter  geofence_port.GeofencePortInterface
+	OverspeedAdapter overspeed_port.OverspeedPortInterface
+	DeviceAdapter    device_port.DevicePortInterface
+	// DeviceAuth verifies the device credentials trackers may upload with.
+	DeviceAuth middleware.DeviceAuthenticator
 }
 
-func InitAdapter(application Application, logger utils.Logger) Adapter {
+func InitAdapter(application Application, producer *kafka.KafkaProducer, liveHub *live.Hub, allowedOrigins []string, logger utils.Logger) Adapter {
 	return Adapter{
-		UserAdapter:     user_adapter.NewUserHandler(application.UserApp, logger),
-		VihicleAdapter:  vehicle_adapter.NewVehicleHandler(application.VehicleApp, logger),
-		TrackerAdapter:  tracker_adapter.NewTrackerHandler(nil, application.TrackerApp, logger),
-		AuthUserAdapter: authUser_adapter.NewAuthHandler(application.AuthUserApp, logger),
+		UserAdapter:      user_adapter.NewUserHandler(application.UserApp, logger),
+		VihicleAdapter:   vehicle_adapter.NewVehicleHandler(application.VehicleApp, logger),
+		TrackerAdapter:   tracker_adapter.NewTrackerHandler(producer, application.TrackerApp, liveHub, allowedOrigins, logger),
+		AuthUserAdapter:  authUser_adapter.NewAuthHandler(application.AuthUserApp, logger),
+		GeofenceAdapter:  geofence_adapter.NewGeofenceHandler(application.GeofenceApp, logger),
+		OverspeedAdapter: overspeed_adapter.NewOverspeedHandler(application.OverspeedApp, logger),
+		DeviceAdapter:    device_adapter.NewDeviceHandler(application.DeviceApp, logger),
+		DeviceAuth:       deviceAuthenticator{devices: application.DeviceApp},
+	}
+}
+
+// deviceAuthenticator verifies device credentials for the auth middleware.
+type deviceAuthenticator struct {
+	devices device_application.DeviceService
+}
+
+func (a deviceAuthenticator) AuthenticateDevice(token string) (middleware.DeviceIdentity, bool, error) {
+	identity, ok, err := a.devices.AuthenticateToken(token)
+	if errors.Is(err, device_service.ErrInvalidCredential) || errors.Is(err, device_service.ErrDeviceInactive) {
+		err = fmt.Errorf("%w: %v", middleware.ErrDeviceRejected, err)
+	}
+	if !ok || err != nil {
+		return middleware.DeviceIdentity{}, ok, err
 	}
+	return middleware.DeviceIdentity{
+		DeviceID:  identity.DeviceID,
+		OwnerID:   identity.OwnerID,
+		VehicleID: identity.VehicleID,
+	}, true, nil
 }
@@ -1,28 +1,128 @@
 package initiator
 
 import (
+	"context"
+	"time"
+
 	userApplication "FMTS/internal/user/application"
 	"FMTS/pkg/utils"
 
 	tracker_application "FMTS/internal/tracking/application"
 	// "FMTS/internal/tracking/domain/service"
 	userAuth_application "FMTS/internal/auth/application"
+	device_application "FMTS/internal/device/application"
+	device_service "FMTS/internal/device/domain/service"
+	geofence_application "FMTS/internal/geofence/application"
+	overspeed_application "FMTS/internal/overspeed/application"
 	vehicle_application "FMTS/internal/vehicle/application"
+	vehicle_entity "FMTS/internal/vehicle/domain/entity"
+	vehicle_service "FMTS/internal/vehicle/domain/service"
 )
 
 type Application struct {
-	UserApp     userApplication.UserService
-	VehicleApp  vehicle_application.VehicleService
-	TrackerApp  tracker_application.TrackerApplication
-	AuthUserApp userAuth_application.AuthService
+	UserApp      userApplication.UserService
+	VehicleApp   vehicle_application.VehicleService
+	TrackerApp   tracker_application.TrackerApplication
+	AuthUserApp  userAuth_application.AuthService
+	GeofenceApp  geofence_application.GeofenceService
+	OverspeedApp overspeed_application.OverspeedService
+	DeviceApp    device_application.DeviceService
 }
 
 func InitApplication(domain Domain, logger utils.Logger) Application {
+	trackerApp := tracker_application.NewTrackerApplicationService(domain.TrackerDomain, logger)
+
 	return Application{
-		UserApp:     userApplication.NewUserService(domain.UserDomain, logger),
-		VehicleApp:  vehicle_application.NewVehicleService(domain.VehicleDomain, logger),
-		TrackerApp:  tracker_application.NewTrackerApplicationService(domain.TrackerDomain, logger),
-		AuthUserApp: userAuth_application.NewAuthService(domain.AuthUserDomain, logger),
+		UserApp:      userApplication.NewUserService(domain.UserDomain, logger),
+		VehicleApp:   vehicle_application.NewVehicleService(domain.VehicleDomain, trackerOdometerReader{tracker: trackerApp}, trackerConnectivityReader{tracker: trackerApp}, deviceIMEILookup{devices: domain.DeviceDomain}, logger),
+		TrackerApp:   trackerApp,
+		AuthUserApp:  userAuth_application.NewAuthService(domain.AuthUserDomain, logger),
+		GeofenceApp:  geofence_application.NewGeofenceService(domain.GeofenceDomain, logger),
+		OverspeedApp: overspeed_application.NewOverspeedService(domain.OverspeedDomain, logger),
+		DeviceApp:    device_application.NewDeviceService(domain.DeviceDomain, vehicleOwnerLookup{vehicles: domain.VehicleDomain}, logger),
 	}
 
 }
+
+// trackerOdometerReader exposes the tracking odometer to the vehicle module.
+type trackerOdometerReader struct {
+	tracker tracker_application.TrackerApplication
+}
+
+func (t trackerOdometerReader) GetOdometer(vehicleID string) (vehicle_entity.Odometer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	odometer, err := t.tracker.GetVehicleOdometer(ctx, vehicleID)
+	if err != nil {
+		return vehicle_entity.Odometer{}, err
+	}
+	result := vehicle_entity.Odometer{VehicleID: vehicleID, TotalKm: odometer.TotalKm}
+	if !odometer.LastTimestamp.IsZero() {
+		result.LastFixAt = &odometer.LastTimestamp
+		result.UpdatedAt = &odometer.UpdatedAt
+	}
+	return result, nil
+}
+
+// trackerConnectivityReader exposes the connectivity status of vehicles to
+// the vehicle module.
+type trackerConnectivityReader struct {
+	tracker tracker_application.TrackerApplication
+}
+
+func (t trackerConnectivityReader) GetConnectivity(ownerID string) (map[string]vehicle_entity.Connectivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := t.tracker.GetVehicleConnectivity(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]vehicle_entity.Connectivity, len(statuses))
+	for _, status := range statuses {
+		lastFixAt := status.LastFixAt
+		result[status.VehicleID] = vehicle_entity.Connectivity{
+			Status:    string(status.Status),
+			Since:     status.Since,
+			LastFixAt: &lastFixAt,
+		}
+	}
+	return result, nil
+}
+
+// vehicleOwnerLookup lets the device module check who owns a vehicle before
+// binding a device to it.
+type vehicleOwnerLookup struct {
+	vehicles vehicle_service.VehicleService
+}
+
+func (l vehicleOwnerLookup) VehicleOwner(vehicleID string) (string, bool, error) {
+	vehicle, err := l.vehicles.LookupVehicle(vehicleID)
+	if err != nil || vehicle == nil || vehicle.IsDeleted {
+		return "", false, err
+	}
+	return vehicle.OwnerID, true, nil
+}
+
+func (l vehicleOwnerLookup) VehicleWithIMEI(imei string) (string, bool, error) {
+	vehicle, err := l.vehicles.FindByDeviceIMEI(imei)
+	if err != nil || vehicle == nil {
+		return "", false, err
+	}
+	return vehicle.ID, true, nil
+}
+
+// deviceIMEILookup lets the vehicle module check that an IMEI stored on a
+// vehicle does not belong to a registered device of another vehicle.
+type deviceIMEILookup struct {
+	devices device_service.DeviceService
+}
+
+func (l deviceIMEILookup) DeviceWithIMEI(imei string) (string, bool, error) {
+	device, err := l.devices.FindByIMEI(imei)
+	if err != nil || device == nil {
+		return "", false, err
+	}
+	return device.VehicleID, true, nil
+}
@@ -0,0 +1,78 @@
+package initiator
+
+import (
+	"context"
+	"time"
+
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	tracker_service "FMTS/internal/tracking/domain/service"
+	"FMTS/utils"
+)
+
+// Connectivity periodically re-classifies every vehicle as online, idle or
+// offline so that vehicles which stopped reporting get their transitions
+// recorded. Vehicles coming back online are caught by the location listener.
+type Connectivity struct {
+	cfg     tracker_entity.ConnectivityConfig
+	tracker tracker_service.DomainTracker
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func InitConnectivity(cfg tracker_entity.ConnectivityConfig, tracker tracker_service.DomainTracker) *Connectivity {
+	return &Connectivity{cfg: cfg, tracker: tracker}
+}
+
+// Start loads the recorded statuses and schedules the checks when the
+// monitor is enabled.
+func (c *Connectivity) Start(logger utils.Logger) {
+	if !c.cfg.Enabled {
+		logger.Infof("Connectivity monitor disabled")
+		return
+	}
+	interval := c.cfg.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		if err := c.tracker.LoadConnectivity(ctx); err != nil {
+			logger.Errorf("[Connectivity] failed to load recorded statuses: %v", err)
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			c.run(ctx, logger)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	logger.Infof("Connectivity monitor scheduled every %s (idle after %ds, offline after %ds)",
+		interval, c.cfg.Default.IdleAfterSeconds, c.cfg.Default.OfflineAfterSeconds)
+}
+
+func (c *Connectivity) run(ctx context.Context, logger utils.Logger) {
+	recorded, err := c.tracker.CheckConnectivity(ctx)
+	if err != nil {
+		logger.Errorf("[Connectivity] check failed: %v", err)
+		return
+	}
+	if recorded > 0 {
+		logger.Debugf("[Connectivity] %d transitions recorded", recorded)
+	}
+}
+
+// Stop cancels the schedule and waits for a running check to return.
+func (c *Connectivity) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+}
@@ -3,6 +3,12 @@ package initiator
 import (
 	// authToken_service "FMTS/internal/auth/domain/service"
 	authUser_service "FMTS/internal/auth/domain/service"
+	device_service "FMTS/internal/device/domain/service"
+	geofence_service "FMTS/internal/geofence/domain/service"
+	overspeed_entity "FMTS/internal/overspeed/domain/entity"
+	overspeed_service "FMTS/internal/overspeed/domain/service"
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	tracker_repo "FMTS/internal/tracking/domain/repository"
 	tracker_service "FMTS/internal/tracking/domain/service"
 	userService "FMTS/internal/user/domain/service"
 	vehicle_service "FMTS/internal/vehicle/domain/service"
@@ -11,18 +17,48 @@ import (
 )
 
 type Domain struct {
-	UserDomain     userService.UserService
-	VehicleDomain  vehicle_service.VehicleService
-	TrackerDomain  tracker_service.DomainTracker
-	AuthUserDomain authUser_service.AuthDomainService
-	JWTRelated     utils.JWTManager
+	UserDomain      userService.UserService
+	VehicleDomain   vehicle_service.VehicleService
+	TrackerDomain   tracker_service.DomainTracker
+	AuthUserDomain  authUser_service.AuthDomainService
+	GeofenceDomain  geofence_service.GeofenceService
+	OverspeedDomain overspeed_service.OverspeedService
+	DeviceDomain    device_service.DeviceService
+	JWTRelated      utils.JWTManager
 }
 
-func InitDomain(persistence Persistence, logger utils.Logger, JWT utils.JWTManager) Domain {
+func InitDomain(persistence Persistence, logger utils.Logger, JWT utils.JWTManager, trackerConfig tracker_entity.TrackerConfig, overspeedConfig overspeed_entity.Config, liveFeed tracker_repo.LocationListener) Domain {
+	geofenceDomain := geofence_service.NewGeofenceDomainService(persistence.GeofencePersistence, logger)
+	vehicleDomain := vehicle_service.NewVehicleDomainService(persistence.VehivlePersistence, logger)
+	overspeedDomain := overspeed_service.NewOverspeedDomainService(persistence.OverspeedPersistence, overspeedConfig, logger)
+
+	trackerDomain := tracker_service.InitDomaintrakerservice(logger, persistence.TrackingPersistence, trackerConfig)
+	initLatestPositions(trackerDomain, logger)
+	trackerDomain.AddLocationListener(trackerDomain.OdometerListener())
+	trackerDomain.AddLocationListener(geofenceListener(geofenceDomain, logger))
+	trackerDomain.AddLocationListener(overspeedListener(trackerDomain, overspeedDomain, logger))
+	trackerDomain.AddLocationListener(liveFeed)
+	if trackerConfig.Connectivity.Enabled {
+		trackerDomain.AddLocationListener(trackerDomain.ConnectivityListener())
+	}
+	if trackerConfig.Stops.Enabled {
+		trackerDomain.AddLocationListener(trackerDomain.StopListener())
+	}
+	if trackerConfig.Fuel.Enabled {
+		trackerDomain.AddLocationListener(trackerDomain.FuelListener())
+	}
+	trackerDomain.SetSpeedLimitProvider(vehicleSpeedLimits(vehicleDomain))
+	trackerDomain.SetVehicleDirectory(vehicleDirectory(vehicleDomain))
+	trackerDomain.SetVehicleRegistry(vehicleRegistry{vehicles: vehicleDomain})
+	trackerDomain.SetGeofenceLocator(geofenceLocator{geofences: geofenceDomain})
+
 	return Domain{
-		UserDomain:     userService.NewUserDomainService(persistence.UserPersistence, logger),
-		VehicleDomain:  vehicle_service.NewVehicleDomainService(persistence.VehivlePersistence, logger),
-		TrackerDomain:  tracker_service.InitDomaintrakerservice(logger, persistence.TrackingPersistence),
-		AuthUserDomain: authUser_service.NewAuthDomainService(persistence.AuthUserPersistance, persistence.AuthPersistance, logger, JWT),
+		UserDomain:      userService.NewUserDomainService(persistence.UserPersistence, logger),
+		VehicleDomain:   vehicleDomain,
+		TrackerDomain:   trackerDomain,
+		AuthUserDomain:  authUser_service.NewAuthDomainService(persistence.AuthUserPersistance, persistence.AuthPersistance, logger, JWT),
+		GeofenceDomain:  geofenceDomain,
+		OverspeedDomain: overspeedDomain,
+		DeviceDomain:    device_service.NewDeviceDomainService(persistence.DevicePersistence, logger),
 	}
 }
@@ -0,0 +1,122 @@
+package initiator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	config "FMTS/config"
+	device_entity "FMTS/internal/device/domain/entity"
+	device_service "FMTS/internal/device/domain/service"
+	"FMTS/internal/tracking/adapter/inbound/gt06"
+	tracker_application "FMTS/internal/tracking/application"
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	vehicle_service "FMTS/internal/vehicle/domain/service"
+	"FMTS/kafka"
+	"FMTS/utils"
+)
+
+// GT06Config configures the TCP listener for GT06/Concox terminals. The
+// listener is disabled when ListenAddr is empty.
+type GT06Config struct {
+	ListenAddr  string
+	IdleTimeout time.Duration
+}
+
+func LoadGT06Config() GT06Config {
+	return GT06Config{
+		ListenAddr:  config.GetEnvString("GT06_LISTEN_ADDR", ""),
+		IdleTimeout: config.GetEnvDuration("GT06_IDLE_TIMEOUT", gt06.DefaultIdleTimeout),
+	}
+}
+
+// deviceIMEIResolver looks terminals up in the device registry and follows
+// their binding to a vehicle. IMEIs that are not registered as devices are
+// looked up on the vehicles, where they used to be stored.
+type deviceIMEIResolver struct {
+	devices  device_service.DeviceService
+	vehicles vehicle_service.VehicleService
+}
+
+func (r deviceIMEIResolver) ResolveIMEI(ctx context.Context, imei string) (string, string, error) {
+	device, err := r.devices.FindByIMEI(imei)
+	if err != nil {
+		return "", "", err
+	}
+	if device == nil {
+		return vehicleIMEIResolver{vehicles: r.vehicles}.ResolveIMEI(ctx, imei)
+	}
+	if device.Status != device_entity.DeviceStatusActive {
+		return "", "", fmt.Errorf("device %s is %s", device.ID, device.Status)
+	}
+	if device.VehicleID == "" {
+		return "", "", fmt.Errorf("%w: device %s is not bound to a vehicle", gt06.ErrUnknownDevice, device.ID)
+	}
+
+	vehicle, err := r.vehicles.LookupVehicle(device.VehicleID)
+	if err != nil {
+		return "", "", err
+	}
+	if vehicle == nil || vehicle.IsDeleted {
+		return "", "", fmt.Errorf("%w: vehicle %s of device %s no longer exists", gt06.ErrUnknownDevice, device.VehicleID, device.ID)
+	}
+	if vehicle.IsDisabled {
+		return "", "", fmt.Errorf("vehicle %s is disabled", vehicle.ID)
+	}
+	return vehicle.ID, vehicle.OwnerID, nil
+}
+
+// vehicleIMEIResolver looks terminals up by the device IMEI stored on the vehicle.
+type vehicleIMEIResolver struct {
+	vehicles vehicle_service.VehicleService
+}
+
+func (r vehicleIMEIResolver) ResolveIMEI(ctx context.Context, imei string) (string, string, error) {
+	vehicle, err := r.vehicles.FindByDeviceIMEI(imei)
+	if err != nil {
+		return "", "", err
+	}
+	if vehicle == nil {
+		return "", "", fmt.Errorf("%w: %s", gt06.ErrUnknownDevice, imei)
+	}
+	if vehicle.IsDisabled {
+		return "", "", fmt.Errorf("vehicle %s is disabled", vehicle.ID)
+	}
+	return vehicle.ID, vehicle.OwnerID, nil
+}
+
+// producerSink routes terminal fixes through the queue when ingestion is
+// not direct, so they follow the same path as HTTP uploads.
+type producerSink struct {
+	producer *kafka.KafkaProducer
+}
+
+func (p producerSink) UpdateLocation(ctx context.Context, location tracker_entity.VehicleLocation) (tracker_entity.VehicleLocation, error) {
+	return location, p.producer.ProduceVehicleLocation(ctx, location)
+}
+
+// InitGT06 starts the terminal listener in the background. It returns nil
+// when the listener is disabled.
+func InitGT06(cfg GT06Config, domain Domain, trackerApp tracker_application.TrackerApplication, producer *kafka.KafkaProducer, logger utils.Logger) *gt06.Server {
+	if cfg.ListenAddr == "" {
+		logger.Infof("GT06 listener disabled (GT06_LISTEN_ADDR not set)")
+		return nil
+	}
+
+	var sink gt06.LocationSink = trackerApp
+	if producer != nil {
+		sink = producerSink{producer: producer}
+	}
+
+	resolver := deviceIMEIResolver{devices: domain.DeviceDomain, vehicles: domain.VehicleDomain}
+	server := gt06.NewServer(resolver, sink, logger)
+	server.IdleTimeout = cfg.IdleTimeout
+
+	go func() {
+		logger.Infof("GT06 listener started on %s", cfg.ListenAddr)
+		if err := server.ListenAndServe(cfg.ListenAddr); err != nil {
+			logger.Errorf("GT06 listener stopped with error: %v", err)
+		}
+	}()
+	return server
+}
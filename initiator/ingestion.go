@@ -0,0 +1,132 @@
+package initiator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	config "FMTS/config"
+	tracker_application "FMTS/internal/tracking/application"
+	"FMTS/kafka"
+	"FMTS/utils"
+)
+
+const (
+	IngestModeDirect = "direct" // handlers write straight to Postgres
+	IngestModeKafka  = "kafka"  // handlers publish to Kafka, a consumer group writes to Postgres
+	IngestModeMemory = "memory" // like kafka, but on an in-process broker (local runs and tests)
+)
+
+type IngestionConfig struct {
+	Mode          string
+	Brokers       []string
+	Topic         string
+	DeadLetter    string
+	ConsumerGroup string
+	Consumer      kafka.ConsumerConfig
+}
+
+func LoadIngestionConfig() IngestionConfig {
+	return IngestionConfig{
+		Mode:          strings.ToLower(config.GetEnvString("INGEST_MODE", IngestModeDirect)),
+		Brokers:       config.GetEnvList("KAFKA_BROKERS", []string{"localhost:9092"}),
+		Topic:         config.GetEnvString("KAFKA_LOCATION_TOPIC", "vehicle-locations"),
+		DeadLetter:    config.GetEnvString("KAFKA_LOCATION_DLQ_TOPIC", "vehicle-locations-dlq"),
+		ConsumerGroup: config.GetEnvString("KAFKA_CONSUMER_GROUP", "fmts-location-writer"),
+		Consumer: kafka.ConsumerConfig{
+			MaxRetries:   config.GetEnvInt("KAFKA_MAX_RETRIES", 5),
+			RetryBackoff: config.GetEnvDuration("KAFKA_RETRY_BACKOFF", 500*time.Millisecond),
+		},
+	}
+}
+
+// Ingestion holds the pieces of the queued ingestion pipeline. Producer is
+// nil in direct mode, which makes the tracker handler write synchronously.
+type Ingestion struct {
+	Producer *kafka.KafkaProducer
+	consumer *kafka.KafkaConsumer
+	dlq      kafka.MessageWriter
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func InitIngestion(cfg IngestionConfig, trackerApp tracker_application.TrackerApplication, logger utils.Logger) *Ingestion {
+	ingestion := &Ingestion{}
+
+	var (
+		writer kafka.MessageWriter
+		reader kafka.MessageReader
+	)
+	switch cfg.Mode {
+	case IngestModeDirect, "":
+		logger.Infof("Location ingestion mode: direct")
+		return ingestion
+	case IngestModeKafka:
+		logger.Infof("Location ingestion mode: kafka (brokers %v, topic %s)", cfg.Brokers, cfg.Topic)
+		writer = kafka.NewWriter(cfg.Brokers, cfg.Topic)
+		reader = kafka.NewReader(cfg.Brokers, cfg.Topic, cfg.ConsumerGroup)
+		ingestion.dlq = kafka.NewWriter(cfg.Brokers, cfg.DeadLetter)
+	case IngestModeMemory:
+		logger.Infof("Location ingestion mode: in-memory broker")
+		broker := kafka.NewMemoryBroker()
+		writer = broker.Writer(cfg.Topic)
+		reader = broker.Reader(cfg.Topic, cfg.ConsumerGroup)
+		ingestion.dlq = broker.Writer(cfg.DeadLetter)
+	default:
+		logger.Fatalf("unknown INGEST_MODE %q", cfg.Mode)
+	}
+
+	ingestion.Producer = kafka.NewKafkaProducerWithWriter(writer, logger)
+	ingestion.consumer = kafka.NewKafkaConsumer(reader, ingestion.dlq, trackerApp, cfg.Consumer, logger)
+	return ingestion
+}
+
+// Start runs the consumer in the background, restarting it after failures.
+func (i *Ingestion) Start(logger utils.Logger) {
+	if i.consumer == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+	i.done = make(chan struct{})
+
+	go func() {
+		defer close(i.done)
+		for {
+			err := i.consumer.StartConsuming(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Errorf("Location consumer stopped: %v; restarting", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	logger.Infof("Location consumer started")
+}
+
+// Stop shuts the consumer down and closes the Kafka clients.
+func (i *Ingestion) Stop(logger utils.Logger) {
+	if i.cancel != nil {
+		i.cancel()
+		<-i.done
+	}
+	if i.consumer != nil {
+		if err := i.consumer.Close(); err != nil {
+			logger.Errorf("Failed to close location consumer: %v", err)
+		}
+	}
+	if i.Producer != nil {
+		if err := i.Producer.Close(); err != nil {
+			logger.Errorf("Failed to close location producer: %v", err)
+		}
+	}
+	if i.dlq != nil {
+		if err := i.dlq.Close(); err != nil {
+			logger.Errorf("Failed to close dead-letter writer: %v", err)
+		}
+	}
+}
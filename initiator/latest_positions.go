@@ -0,0 +1,41 @@
+package initiator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	config "FMTS/config"
+	"FMTS/internal/tracking/adapter/outbound/cache"
+	tracker_repo "FMTS/internal/tracking/domain/repository"
+	tracker_service "FMTS/internal/tracking/domain/service"
+	"FMTS/utils"
+)
+
+// initLatestPositions attaches the latest-position store selected by
+// LATEST_POSITION_STORE ("memory", the default, or "none" to always read
+// Postgres) and warms it from Postgres.
+func initLatestPositions(trackerDomain *tracker_service.DomainTrackerService, logger utils.Logger) {
+	var store tracker_repo.LatestPositionStore
+	switch kind := strings.ToLower(config.GetEnvString("LATEST_POSITION_STORE", "memory")); kind {
+	case "none":
+		logger.Infof("Latest-position store disabled")
+		return
+	case "memory":
+		store = cache.NewMemoryLatestStore()
+	default:
+		logger.Warnf("Unknown LATEST_POSITION_STORE %q, using memory", kind)
+		store = cache.NewMemoryLatestStore()
+	}
+	trackerDomain.SetLatestPositionStore(store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetEnvDuration("LATEST_POSITION_WARM_TIMEOUT", 30*time.Second))
+	defer cancel()
+	count, err := trackerDomain.WarmLatestPositions(ctx)
+	if err != nil {
+		// Owner listings keep reading Postgres until a warm-up succeeds.
+		logger.Errorf("Failed to warm latest-position store: %v", err)
+		return
+	}
+	logger.Infof("Latest-position store warmed with %d vehicles", count)
+}
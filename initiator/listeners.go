@@ -0,0 +1,151 @@
+package initiator
+
+import (
+	"context"
+	"strings"
+
+	geofence_entity "FMTS/internal/geofence/domain/entity"
+	geofence_service "FMTS/internal/geofence/domain/service"
+	overspeed_entity "FMTS/internal/overspeed/domain/entity"
+	overspeed_service "FMTS/internal/overspeed/domain/service"
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	tracker_repo "FMTS/internal/tracking/domain/repository"
+	vehicle_service "FMTS/internal/vehicle/domain/service"
+	"FMTS/utils"
+)
+
+// geofenceListener evaluates every persisted location against the owner's
+// geofences. Failures are logged and never reject the location itself.
+func geofenceListener(geofences geofence_service.GeofenceService, logger utils.Logger) tracker_repo.LocationListener {
+	return tracker_repo.LocationListenerFunc(func(ctx context.Context, location tracker_entity.VehicleLocation) {
+		events, err := geofences.EvaluateLocation(geofence_entity.LocationFix{
+			OwnerID:   location.OwnerID,
+			VehicleID: location.VehicleID,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+			Timestamp: location.Timestamp,
+		})
+		if err != nil {
+			logger.Errorf("[geofenceListener] vehicle %s: %v", location.VehicleID, err)
+			return
+		}
+		for _, event := range events {
+			logger.Infof("[geofenceListener] vehicle %s %s geofence %s", event.VehicleID, event.EventType, event.GeofenceName)
+		}
+	})
+}
+
+// overspeedListener checks every persisted location against the speed limit
+// of its vehicle. Locations of vehicles without a known limit are skipped.
+func overspeedListener(speedLimits tracker_repo.SpeedLimitProvider, overspeed overspeed_service.OverspeedService, logger utils.Logger) tracker_repo.LocationListener {
+	return tracker_repo.LocationListenerFunc(func(ctx context.Context, location tracker_entity.VehicleLocation) {
+		limit, ok := speedLimits.SpeedLimit(ctx, location.VehicleID)
+		if !ok {
+			logger.Debugf("[overspeedListener] no speed limit for vehicle %s", location.VehicleID)
+			return
+		}
+
+		event, err := overspeed.EvaluateSpeed(overspeed_entity.SpeedFix{
+			OwnerID:    location.OwnerID,
+			VehicleID:  location.VehicleID,
+			Latitude:   location.Latitude,
+			Longitude:  location.Longitude,
+			Speed:      location.Speed,
+			SpeedLimit: limit,
+			Timestamp:  location.Timestamp,
+		})
+		if err != nil {
+			logger.Errorf("[overspeedListener] vehicle %s: %v", location.VehicleID, err)
+			return
+		}
+		if event != nil {
+			logger.Infof("[overspeedListener] vehicle %s overspeed episode %s (limit %.0f km/h, peak %.0f km/h)", event.VehicleID, event.Status, event.SpeedLimit, event.PeakSpeed)
+		}
+	})
+}
+
+// vehicleSpeedLimits exposes vehicle speed limits to the tracking domain.
+func vehicleSpeedLimits(vehicles vehicle_service.VehicleService) tracker_repo.SpeedLimitProvider {
+	return tracker_repo.SpeedLimitProviderFunc(func(ctx context.Context, vehicleID string) (float64, bool) {
+		vehicle, err := vehicles.FindByID(vehicleID)
+		if err != nil || vehicle == nil {
+			return 0, false
+		}
+		return vehicle.EffectiveSpeedLimit(), true
+	})
+}
+
+// vehicleDirectory exposes registered vehicles to the tracking domain.
+func vehicleDirectory(vehicles vehicle_service.VehicleService) tracker_repo.VehicleDirectory {
+	return vehicleDirectoryFunc(func(ctx context.Context, ownerID, vehicleType string) ([]tracker_entity.VehicleInfo, error) {
+		all, err := vehicles.FindAll(ownerID)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]tracker_entity.VehicleInfo, 0, len(all))
+		for _, v := range all {
+			if vehicleType != "" && !strings.EqualFold(string(v.VehicleType), vehicleType) {
+				continue
+			}
+			infos = append(infos, tracker_entity.VehicleInfo{
+				ID:          v.ID,
+				OwnerID:     v.OwnerID,
+				PlateNumber: v.PlateNumber,
+				VehicleType: string(v.VehicleType),
+			})
+		}
+		return infos, nil
+	})
+}
+
+type vehicleDirectoryFunc func(ctx context.Context, ownerID, vehicleType string) ([]tracker_entity.VehicleInfo, error)
+
+func (f vehicleDirectoryFunc) ListVehicles(ctx context.Context, ownerID, vehicleType string) ([]tracker_entity.VehicleInfo, error) {
+	return f(ctx, ownerID, vehicleType)
+}
+
+// vehicleRegistry lets the tracking domain authorize locations against the
+// registered vehicles.
+type vehicleRegistry struct {
+	vehicles vehicle_service.VehicleService
+}
+
+func (r vehicleRegistry) LookupVehicle(ctx context.Context, vehicleID string) (tracker_entity.VehicleInfo, bool, error) {
+	vehicle, err := r.vehicles.LookupVehicle(vehicleID)
+	if err != nil || vehicle == nil {
+		return tracker_entity.VehicleInfo{}, false, err
+	}
+	return tracker_entity.VehicleInfo{
+		ID:          vehicle.ID,
+		OwnerID:     vehicle.OwnerID,
+		PlateNumber: vehicle.PlateNumber,
+		VehicleType: string(vehicle.VehicleType),
+		Deleted:     vehicle.IsDeleted,
+		Disabled:    vehicle.IsDisabled,
+		Tracked:     vehicle.CurrentlyTracked,
+	}, true, nil
+}
+
+func (r vehicleRegistry) MarkTracked(ctx context.Context, vehicleID string) error {
+	return r.vehicles.MarkTracked(vehicleID)
+}
+
+// geofenceLocator lets the tracking domain match stops to the owner's
+// geofences.
+type geofenceLocator struct {
+	geofences geofence_service.GeofenceService
+}
+
+func (l geofenceLocator) GeofencesAt(ctx context.Context, ownerID string, latitude, longitude float64) ([]tracker_entity.GeofenceRef, error) {
+	geofences, err := l.geofences.FindByOwner(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	var refs []tracker_entity.GeofenceRef
+	for _, g := range geofences {
+		if geofence_service.Contains(*g, latitude, longitude) {
+			refs = append(refs, tracker_entity.GeofenceRef{ID: g.ID, Name: g.Name})
+		}
+	}
+	return refs, nil
+}
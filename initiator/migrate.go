@@ -0,0 +1,102 @@
+package initiator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	config "FMTS/config"
+	"FMTS/migrations"
+	"FMTS/pkg/utils"
+	util "FMTS/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// checkSchema refuses to start on a database with pending migrations. With
+// DB_AUTO_MIGRATE=true it applies them instead.
+func checkSchema(pool *pgxpool.Pool, logger utils.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetEnvDuration("DB_MIGRATE_TIMEOUT", 5*time.Minute))
+	defer cancel()
+
+	runner, err := migrations.NewRunner(pool)
+	if err != nil {
+		logger.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	if config.GetEnvBool("DB_AUTO_MIGRATE", false) {
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			logger.Fatalf("Failed to migrate database: %v", err)
+		}
+		for _, m := range applied {
+			logger.Infof("Applied migration %d_%s", m.Version, m.Name)
+		}
+	}
+
+	if err := runner.Check(ctx); err != nil {
+		logger.Fatalf("%v; run `migrate up` or set DB_AUTO_MIGRATE=true", err)
+	}
+	logger.Infof("Database schema is up to date")
+}
+
+// Migrate runs the migrate subcommand: up, down [steps] or status.
+func Migrate(args []string) {
+	logger := util.NewLogger()
+
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	runner, err := migrations.NewRunner(config.ConnectSupabasePool(DB_URL))
+	if err != nil {
+		logger.Fatalf("Failed to load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := runner.Up(ctx)
+		for _, m := range applied {
+			logger.Infof("Applied migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			logger.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			logger.Infof("Database schema is already up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				logger.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := runner.Down(ctx, steps)
+		for _, m := range reverted {
+			logger.Infof("Reverted migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			logger.Fatalf("Migration failed: %v", err)
+		}
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			logger.Fatalf("Failed to read schema status: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		logger.Fatalf("unknown migrate command %q (want up, down [steps] or status)", command)
+	}
+}
@@ -0,0 +1,72 @@
+package initiator
+
+import (
+	"context"
+	"time"
+
+	overspeed_entity "FMTS/internal/overspeed/domain/entity"
+	overspeed_service "FMTS/internal/overspeed/domain/service"
+	"FMTS/utils"
+)
+
+// OverspeedSweep periodically closes the overspeed episodes of vehicles that
+// stopped reporting while speeding, which no later fix would close.
+type OverspeedSweep struct {
+	cfg       overspeed_entity.Config
+	overspeed overspeed_service.OverspeedService
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func InitOverspeedSweep(cfg overspeed_entity.Config, overspeed overspeed_service.OverspeedService) *OverspeedSweep {
+	return &OverspeedSweep{cfg: cfg, overspeed: overspeed}
+}
+
+// Start schedules the sweeps when episodes have a max gap.
+func (o *OverspeedSweep) Start(logger utils.Logger) {
+	if o.cfg.MaxGap <= 0 {
+		logger.Infof("Overspeed sweep disabled")
+		return
+	}
+	interval := o.cfg.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.done = make(chan struct{})
+
+	go func() {
+		defer close(o.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			o.run(logger)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	logger.Infof("Overspeed sweep scheduled every %s (max gap %s)", interval, o.cfg.MaxGap)
+}
+
+func (o *OverspeedSweep) run(logger utils.Logger) {
+	closed, err := o.overspeed.CloseStaleEpisodes(time.Now())
+	if err != nil {
+		logger.Errorf("[OverspeedSweep] failed: %v", err)
+		return
+	}
+	if closed > 0 {
+		logger.Debugf("[OverspeedSweep] %d stale episodes closed", closed)
+	}
+}
+
+// Stop cancels the schedule and waits for a running sweep to return.
+func (o *OverspeedSweep) Stop() {
+	if o.cancel != nil {
+		o.cancel()
+		<-o.done
+	}
+}
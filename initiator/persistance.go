@@ -1,6 +1,9 @@
 package initiator
 
 import (
+	device_persistance "FMTS/internal/device/adapter/outbound/persistance"
+	geofence_persistance "FMTS/internal/geofence/adapter/outbound/persistance"
+	overspeed_persistance "FMTS/internal/overspeed/adapter/outbound/persistance"
 	tracking_persistance "FMTS/internal/tracking/adapter/outbound/mongo"
 	constructor "FMTS/internal/user/adapter/outbound/persistance"
 	vihicle_persistance "FMTS/internal/vehicle/adapter/outbound/persistance"
@@ -13,6 +16,9 @@ import (
 	token "FMTS/internal/auth/port/outbound/auth"
 	auth "FMTS/internal/auth/port/outbound/user"
 
+	device_port "FMTS/internal/device/port/outbound"
+	geofence_port "FMTS/internal/geofence/port/outbound"
+	overspeed_port "FMTS/internal/overspeed/port/outbound"
 	vihicle_port "FMTS/internal/vehicle/port/outbound"
 
 	"FMTS/pkg/utils"
@@ -22,11 +28,14 @@ import (
 )
 
 type Persistence struct {
-	UserPersistence     outbound.UserRepoOutboundPort
-	VehivlePersistence  vihicle_port.VehicleRepo
-	TrackingPersistence tracking_port.TimescaleTrackerRepo
-	AuthPersistance     token.TokenRepo
-	AuthUserPersistance auth.UserRepo
+	UserPersistence      outbound.UserRepoOutboundPort
+	VehivlePersistence   vihicle_port.VehicleRepo
+	TrackingPersistence  tracking_port.TimescaleTrackerRepo
+	AuthPersistance      token.TokenRepo
+	AuthUserPersistance  auth.UserRepo
+	GeofencePersistence  geofence_port.GeofenceRepo
+	OverspeedPersistence overspeed_port.OverspeedRepo
+	DevicePersistence    device_port.DeviceRepo
 }
 
 var DB_URL = config.LoadConfig()
@@ -37,13 +46,26 @@ func InitPersistence(client *mongo.Client, DB_name string, logger utils.Logger)
 		"vehicle",
 		"tracking",
 		"tokens",
+		"geofences",
+		"geofence_events",
+		"geofence_states",
+		"overspeed_events",
+		"devices",
+		"device_bindings",
+		"device_credentials",
 	}
 
+	pool := config.ConnectSupabasePool(DB_URL)
+	checkSchema(pool, logger)
+
 	return Persistence{
-		UserPersistence:     constructor.InitUserRepo(client, DB_name, collectionNames[0], logger),
-		VehivlePersistence:  vihicle_persistance.InitVehicleRepo(client, DB_name, collectionNames[1], logger),
-		TrackingPersistence: tracking_persistance.NewTimescaleTrackerRepo(config.ConnectSupabasePool(DB_URL)),
-		AuthPersistance:     token_repo.InitTokenRepo(client, DB_name, collectionNames[3], logger),
-		AuthUserPersistance: auth_persistance.NewUserAuthRepo(client, DB_name, collectionNames[0], logger),
+		UserPersistence:      constructor.InitUserRepo(client, DB_name, collectionNames[0], logger),
+		VehivlePersistence:   vihicle_persistance.InitVehicleRepo(client, DB_name, collectionNames[1], logger),
+		TrackingPersistence:  tracking_persistance.NewTimescaleTrackerRepo(pool),
+		AuthPersistance:      token_repo.InitTokenRepo(client, DB_name, collectionNames[3], logger),
+		AuthUserPersistance:  auth_persistance.NewUserAuthRepo(client, DB_name, collectionNames[0], logger),
+		GeofencePersistence:  geofence_persistance.InitGeofenceRepo(client, DB_name, collectionNames[4], collectionNames[5], collectionNames[6], logger),
+		OverspeedPersistence: overspeed_persistance.InitOverspeedRepo(client, DB_name, collectionNames[7], logger),
+		DevicePersistence:    device_persistance.InitDeviceRepo(client, DB_name, collectionNames[8], collectionNames[9], collectionNames[10], logger),
 	}
 }
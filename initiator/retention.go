@@ -0,0 +1,78 @@
+package initiator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	tracker_service "FMTS/internal/tracking/domain/service"
+	"FMTS/utils"
+)
+
+// Retention runs the location retention job on a fixed interval in the
+// background. Several instances may run it; the repository lock lets only
+// one of them work at a time.
+type Retention struct {
+	cfg     tracker_entity.RetentionConfig
+	tracker tracker_service.DomainTracker
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func InitRetention(cfg tracker_entity.RetentionConfig, tracker tracker_service.DomainTracker) *Retention {
+	return &Retention{cfg: cfg, tracker: tracker}
+}
+
+// Start schedules the job when retention is enabled.
+func (r *Retention) Start(logger utils.Logger) {
+	if !r.cfg.Enabled {
+		logger.Infof("Location retention disabled")
+		return
+	}
+	interval := r.cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			r.run(ctx, logger)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	logger.Infof("Location retention scheduled every %s (downsample after %s, delete after %s, dry run %t)",
+		interval, r.cfg.DownsampleAfter, r.cfg.DeleteAfter, r.cfg.DryRun)
+}
+
+func (r *Retention) run(ctx context.Context, logger utils.Logger) {
+	report, err := r.tracker.RunRetention(ctx, r.cfg.DryRun)
+	switch {
+	case errors.Is(err, tracker_service.ErrRetentionRunning):
+		logger.Infof("[Retention] skipped: another run is in progress")
+	case err != nil:
+		logger.Errorf("[Retention] failed: %v", err)
+	default:
+		logger.Infof("[Retention] run %d (dry run %t): %d summaries from %d points, %d points deleted in %s",
+			report.ID, report.DryRun, report.SummariesWritten, report.PointsSummarised, report.PointsDeleted,
+			report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond))
+	}
+}
+
+// Stop cancels the schedule and waits for a running pass to return.
+func (r *Retention) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+}
@@ -2,7 +2,10 @@ package initiator
 
 import (
 	authUser_handler "FMTS/internal/auth/adapter/inbound/http"
+	device_handler "FMTS/internal/device/adapter/inbound/http"
+	geofence_handler "FMTS/internal/geofence/adapter/inbound/http"
 	"FMTS/internal/middleware"
+	overspeed_handler "FMTS/internal/overspeed/adapter/inbound/http"
 	Tracker_handler "FMTS/internal/tracking/adapter/inbound/http"
 	user_handler "FMTS/internal/user/adapter/inbound/http"
 	vehicle_handler "FMTS/internal/vehicle/adapter/inbound/http"
@@ -14,12 +17,16 @@ import (
 
 func InitRoutes(r chi.Router, adapter Adapter, secretKey, key, iv string, logger utils.Logger) {
 	authMiddleware := middleware.InitAuthMiddleware(secretKey, key, iv, logger)
+	deviceAuth := middleware.AuthenticateDeviceOrToken(adapter.DeviceAuth, authMiddleware, logger)
 
 	r.Route("/api/v1/FMTS/", func(r chi.Router) {
 		user_handler.InitUserRoutes(r, adapter.UserAdapter, authMiddleware)
 		vehicle_handler.InitVehicleRoutes(r, adapter.VihicleAdapter, authMiddleware)
 		authUser_handler.InitUserRoutes(r, adapter.AuthUserAdapter, authMiddleware)
-		Tracker_handler.InitTrackerRoutes(r, adapter.TrackerAdapter, authMiddleware)
+		Tracker_handler.InitTrackerRoutes(r, adapter.TrackerAdapter, authMiddleware, deviceAuth)
+		geofence_handler.InitGeofenceRoutes(r, adapter.GeofenceAdapter, authMiddleware)
+		overspeed_handler.InitOverspeedRoutes(r, adapter.OverspeedAdapter, authMiddleware)
+		device_handler.InitDeviceRoutes(r, adapter.DeviceAdapter, authMiddleware)
 
 	})
 }
@@ -0,0 +1,177 @@
+package initiator
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"FMTS/internal/simulator"
+	"FMTS/internal/tracking/adapter/inbound/live"
+	tracker_application "FMTS/internal/tracking/application"
+	"FMTS/kafka"
+	"FMTS/pkg/geo"
+	util "FMTS/utils"
+)
+
+// Simulate runs the simulator command: it generates a seeded fleet trace and
+// drives it through the HTTP API, Kafka, the tracker application in process,
+// or stdout.
+func Simulate(args []string) {
+	logger := util.NewLogger()
+	ingestion := LoadIngestionConfig()
+
+	flags := flag.NewFlagSet("simulator", flag.ExitOnError)
+	seed := flags.Int64("seed", 1, "random seed; the same seed and flags produce the same trace")
+	vehicles := flags.Int("vehicles", 5, "number of vehicles to simulate")
+	vehicleIDs := flags.String("vehicle-ids", "", "comma-separated vehicle IDs to use instead of -id-prefix with -vehicles")
+	idPrefix := flags.String("id-prefix", "SIM", "prefix of generated vehicle IDs")
+	owner := flags.String("owner", "", "owner ID reported with every location")
+	mode := flags.String("mode", "http", "where to send reports: http, kafka, direct or stdout")
+	baseURL := flags.String("url", "http://localhost:8082/api/v1/FMTS", "API root for http mode")
+	token := flags.String("token", os.Getenv("SIMULATOR_TOKEN"), "bearer token for http mode, a user JWT or a device credential (default $SIMULATOR_TOKEN)")
+	brokers := flags.String("brokers", strings.Join(ingestion.Brokers, ","), "Kafka brokers for kafka mode")
+	topic := flags.String("topic", ingestion.Topic, "Kafka topic for kafka mode")
+	routeFile := flags.String("route", "", "JSON file with a [[lat, lon], ...] polyline; random walk without it")
+	center := flags.String("center", "9.0108,38.7613", "random walk centre as lat,lon")
+	radius := flags.Float64("radius", 5000, "random walk radius in metres")
+	start := flags.String("start", "", "RFC3339 timestamp of the first report (default now)")
+	duration := flags.Duration("duration", time.Hour, "simulated time span")
+	interval := flags.Duration("interval", 10*time.Second, "time between reports of a vehicle")
+	speedup := flags.Float64("speedup", 0, "play at this multiple of real time; 0 sends as fast as possible")
+	cruise := flags.Float64("cruise", 40, "typical speed in km/h")
+	maxSpeed := flags.Float64("max-speed", 90, "speed cap in km/h")
+	stopEvery := flags.Duration("stop-every", 20*time.Minute, "mean time between stops; 0 disables stops")
+	stopMin := flags.Duration("stop-min", 2*time.Minute, "shortest stop")
+	stopMax := flags.Duration("stop-max", 15*time.Minute, "longest stop")
+	offlineEvery := flags.Duration("offline-every", 0, "mean time between offline gaps; 0 disables them")
+	offlineMin := flags.Duration("offline-min", time.Minute, "shortest offline gap")
+	offlineMax := flags.Duration("offline-max", 10*time.Minute, "longest offline gap")
+	noise := flags.Float64("noise", 5, "GPS noise standard deviation in metres")
+	batch := flags.Int("batch", simulator.MaxHTTPBatch, "maximum reports per send")
+	flags.Parse(args)
+
+	scenario := simulator.Scenario{
+		Seed:         *seed,
+		Duration:     *duration,
+		Interval:     *interval,
+		RadiusMeters: *radius,
+		Speed:        simulator.SpeedProfile{CruiseKmh: *cruise, MaxKmh: *maxSpeed},
+		Stops:        simulator.Episodes{Every: *stopEvery, Min: *stopMin, Max: *stopMax},
+		Offline:      simulator.Episodes{Every: *offlineEvery, Min: *offlineMin, Max: *offlineMax},
+		NoiseMeters:  *noise,
+	}
+
+	if *vehicleIDs != "" {
+		for _, id := range strings.Split(*vehicleIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				scenario.Vehicles = append(scenario.Vehicles, simulator.Vehicle{ID: id, OwnerID: *owner})
+			}
+		}
+	} else {
+		for i := 1; i <= *vehicles; i++ {
+			scenario.Vehicles = append(scenario.Vehicles, simulator.Vehicle{ID: fmt.Sprintf("%s%03d", *idPrefix, i), OwnerID: *owner})
+		}
+	}
+
+	scenario.Start = time.Now().UTC().Truncate(time.Second)
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			logger.Fatalf("invalid -start: %v", err)
+		}
+		scenario.Start = t.UTC()
+	}
+
+	if *routeFile != "" {
+		route, err := loadRoute(*routeFile)
+		if err != nil {
+			logger.Fatalf("invalid -route: %v", err)
+		}
+		scenario.Route = route
+	} else {
+		c, err := parsePoint(*center)
+		if err != nil {
+			logger.Fatalf("invalid -center: %v", err)
+		}
+		scenario.Center = c
+	}
+
+	sim, err := simulator.New(scenario)
+	if err != nil {
+		logger.Fatalf("invalid scenario: %v", err)
+	}
+
+	var sink simulator.Sink
+	switch *mode {
+	case "http":
+		sink = simulator.NewHTTPSink(*baseURL, *token)
+	case "kafka":
+		producer := kafka.NewKafkaProducer(strings.Split(*brokers, ","), *topic, logger)
+		defer producer.Close()
+		sink = simulator.KafkaSink{Producer: producer}
+	case "direct":
+		sink = simulator.ApplicationSink{App: initTrackerApplication(logger)}
+	case "stdout":
+		sink = simulator.WriterSink{W: os.Stdout}
+	default:
+		logger.Fatalf("unknown -mode %q (want http, kafka, direct or stdout)", *mode)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Infof("[Simulator] seed %d: %d vehicles, %d rounds from %s, mode %s",
+		scenario.Seed, len(scenario.Vehicles), sim.Ticks(), scenario.Start.Format(time.RFC3339), *mode)
+	stats, err := simulator.Run(ctx, sim, sink, simulator.RunOptions{Speedup: *speedup, BatchSize: *batch, Logger: logger})
+	if err != nil {
+		logger.Warnf("[Simulator] stopped early: %v", err)
+	}
+	logger.Infof("[Simulator] %d rounds: %d reports sent, %d accepted, %d failed", stats.Ticks, stats.Sent, stats.Accepted, stats.Failed)
+}
+
+// initTrackerApplication builds the tracker application the way the server
+// does, so direct mode runs the same authorization, filtering and listeners.
+func initTrackerApplication(logger util.Logger) tracker_application.TrackerApplication {
+	persistence := InitPersistence(InitMongo(logger), "FMTS", logger)
+	domain := InitDomain(persistence, logger, nil, LoadTrackerConfig(), LoadOverspeedConfig(), live.NewHub(logger))
+	return InitApplication(domain, logger).TrackerApp
+}
+
+func loadRoute(path string) ([]geo.Point, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pairs [][2]float64
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		return nil, err
+	}
+	route := make([]geo.Point, 0, len(pairs))
+	for _, p := range pairs {
+		route = append(route, geo.Point{Latitude: p[0], Longitude: p[1]})
+	}
+	return route, nil
+}
+
+func parsePoint(s string) (geo.Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return geo.Point{}, fmt.Errorf("want lat,lon, got %q", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return geo.Point{}, err
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return geo.Point{}, err
+	}
+	return geo.Point{Latitude: lat, Longitude: lon}, nil
+}
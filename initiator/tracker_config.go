@@ -0,0 +1,81 @@
+package initiator
+
+import (
+	"time"
+
+	config "FMTS/config"
+	overspeed_entity "FMTS/internal/overspeed/domain/entity"
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+)
+
+func LoadTrackerConfig() tracker_entity.TrackerConfig {
+	return tracker_entity.TrackerConfig{
+		Trips: tracker_entity.TripDetectionConfig{
+			StopSpeed:       config.GetEnvFloat("TRIP_STOP_SPEED_KMH", 5),
+			MinDwell:        config.GetEnvDuration("TRIP_MIN_DWELL", 5*time.Minute),
+			MinDistanceKm:   config.GetEnvFloat("TRIP_MIN_DISTANCE_KM", 0.2),
+			SegmentInterval: config.GetEnvDuration("TRIP_SEGMENT_INTERVAL", time.Minute),
+			Backfill:        config.GetEnvDuration("TRIP_BACKFILL", 7*24*time.Hour),
+		},
+		Odometer: tracker_entity.OdometerConfig{
+			MinMoveMeters: config.GetEnvFloat("ODOMETER_MIN_MOVE_METERS", 15),
+			MaxSpeedKmh:   config.GetEnvFloat("ODOMETER_MAX_SPEED_KMH", 250),
+		},
+		Ingest: tracker_entity.IngestFilterConfig{
+			MaxSpeedKmh:        config.GetEnvFloat("INGEST_MAX_SPEED_KMH", 300),
+			TeleportMinMeters:  config.GetEnvFloat("INGEST_TELEPORT_MIN_METERS", 100),
+			TeleportResetAfter: config.GetEnvInt("INGEST_TELEPORT_RESET_AFTER", 3),
+			Kalman: tracker_entity.KalmanConfig{
+				Enabled:          config.GetEnvBool("INGEST_KALMAN_ENABLED", false),
+				ProcessNoise:     config.GetEnvFloat("INGEST_KALMAN_PROCESS_NOISE", 3),
+				MeasurementNoise: config.GetEnvFloat("INGEST_KALMAN_MEASUREMENT_NOISE", 10),
+				ResetGap:         config.GetEnvDuration("INGEST_KALMAN_RESET_GAP", 5*time.Minute),
+			},
+			VehicleCacheTTL: config.GetEnvDuration("INGEST_VEHICLE_CACHE_TTL", 30*time.Second),
+		},
+		Retention: tracker_entity.RetentionConfig{
+			Enabled:         config.GetEnvBool("RETENTION_ENABLED", false),
+			Interval:        config.GetEnvDuration("RETENTION_INTERVAL", time.Hour),
+			DryRun:          config.GetEnvBool("RETENTION_DRY_RUN", false),
+			DownsampleAfter: config.GetEnvDuration("RETENTION_DOWNSAMPLE_AFTER", 30*24*time.Hour),
+			DeleteAfter:     config.GetEnvDuration("RETENTION_DELETE_AFTER", 90*24*time.Hour),
+			Chunk:           config.GetEnvDuration("RETENTION_CHUNK", 24*time.Hour),
+		},
+		Connectivity: tracker_entity.ConnectivityConfig{
+			Enabled:  config.GetEnvBool("CONNECTIVITY_ENABLED", true),
+			Interval: config.GetEnvDuration("CONNECTIVITY_CHECK_INTERVAL", 30*time.Second),
+			Default: tracker_entity.ConnectivityThresholds{
+				IdleAfterSeconds:    int64(config.GetEnvDuration("CONNECTIVITY_IDLE_AFTER", 5*time.Minute) / time.Second),
+				OfflineAfterSeconds: int64(config.GetEnvDuration("CONNECTIVITY_OFFLINE_AFTER", 30*time.Minute) / time.Second),
+			},
+		},
+		Stops: tracker_entity.StopDetectionConfig{
+			Enabled:      config.GetEnvBool("STOP_DETECTION_ENABLED", true),
+			RadiusMeters: config.GetEnvFloat("STOP_RADIUS_METERS", 50),
+			MinDuration:  config.GetEnvDuration("STOP_MIN_DURATION", 5*time.Minute),
+		},
+		Shares: tracker_entity.ShareConfig{
+			DefaultTTL:  config.GetEnvDuration("SHARE_DEFAULT_TTL", 24*time.Hour),
+			MaxTTL:      config.GetEnvDuration("SHARE_MAX_TTL", 7*24*time.Hour),
+			TrackPoints: config.GetEnvInt("SHARE_TRACK_POINTS", 500),
+		},
+		Fuel: tracker_entity.FuelDetectionConfig{
+			Enabled:            config.GetEnvBool("FUEL_DETECTION_ENABLED", true),
+			SmoothingSamples:   config.GetEnvInt("FUEL_SMOOTHING_SAMPLES", 5),
+			RefuelMinLiters:    config.GetEnvFloat("FUEL_REFUEL_MIN_LITERS", 10),
+			DrainMinLiters:     config.GetEnvFloat("FUEL_DRAIN_MIN_LITERS", 8),
+			EventWindow:        config.GetEnvDuration("FUEL_EVENT_WINDOW", 15*time.Minute),
+			SettleTime:         config.GetEnvDuration("FUEL_SETTLE_TIME", 5*time.Minute),
+			StationarySpeedKmh: config.GetEnvFloat("FUEL_STATIONARY_SPEED_KMH", 5),
+		},
+	}
+}
+
+func LoadOverspeedConfig() overspeed_entity.Config {
+	return overspeed_entity.Config{
+		Tolerance:     config.GetEnvFloat("OVERSPEED_TOLERANCE_KMH", 3),
+		MaxGap:        config.GetEnvDuration("OVERSPEED_MAX_GAP", 2*time.Minute),
+		SaveInterval:  config.GetEnvDuration("OVERSPEED_SAVE_INTERVAL", time.Minute),
+		SweepInterval: config.GetEnvDuration("OVERSPEED_SWEEP_INTERVAL", time.Minute),
+	}
+}
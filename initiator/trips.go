@@ -0,0 +1,70 @@
+package initiator
+
+import (
+	"context"
+	"time"
+
+	tracker_entity "FMTS/internal/tracking/domain/entity"
+	tracker_service "FMTS/internal/tracking/domain/service"
+	"FMTS/utils"
+)
+
+// TripSegmentation periodically segments the newly reported points of every
+// vehicle into trips, so reading trips never has to write.
+type TripSegmentation struct {
+	cfg     tracker_entity.TripDetectionConfig
+	tracker tracker_service.DomainTracker
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func InitTripSegmentation(cfg tracker_entity.TripDetectionConfig, tracker tracker_service.DomainTracker) *TripSegmentation {
+	return &TripSegmentation{cfg: cfg, tracker: tracker}
+}
+
+// Start schedules the segmentation runs.
+func (t *TripSegmentation) Start(logger utils.Logger) {
+	interval := t.cfg.SegmentInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			t.run(ctx, logger)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	logger.Infof("Trip segmentation scheduled every %s (backfill %s)", interval, t.cfg.Backfill)
+}
+
+func (t *TripSegmentation) run(ctx context.Context, logger utils.Logger) {
+	saved, err := t.tracker.SegmentTrips(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Errorf("[TripSegmentation] failed: %v", err)
+		}
+		return
+	}
+	if saved > 0 {
+		logger.Debugf("[TripSegmentation] %d trips saved", saved)
+	}
+}
+
+// Stop cancels the schedule and waits for a running pass to return.
+func (t *TripSegmentation) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+		<-t.done
+	}
+}
@@ -0,0 +1,344 @@
+package device_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	app "FMTS/internal/device/application"
+	domain "FMTS/internal/device/domain/service"
+	port "FMTS/internal/device/port/inbound"
+	contexts "FMTS/pkg/context"
+	"FMTS/pkg/utils"
+	utility "FMTS/utils"
+)
+
+type DeviceHandler struct {
+	deviceService app.DeviceService
+	logger        utils.Logger
+}
+
+func NewDeviceHandler(service app.DeviceService, logger utils.Logger) port.DevicePortInterface {
+	return &DeviceHandler{
+		deviceService: service,
+		logger:        logger,
+	}
+}
+
+func (h *DeviceHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	var req app.CreateDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[RegisterDevice] decode error: %v", err)
+		utility.SendErrorResponse(w, "invalid request", http.StatusBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[RegisterDevice] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx := contexts.ExtractUserContext(r)
+	if ctx.UserID == "" {
+		h.logger.Warnf("[RegisterDevice] user context missing")
+		utility.SendErrorResponse(w, "unauthorized", http.StatusUnauthorized, nil)
+		return
+	}
+	ownerID := ctx.UserID
+	if ctx.IsAdmin() && req.OwnerID != "" {
+		ownerID = req.OwnerID
+	}
+
+	created, err := h.deviceService.RegisterDevice(req, ownerID)
+	if err != nil {
+		h.logger.Errorf("[RegisterDevice] service error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+
+	utility.WriteSuccessResponse(w, created, "Device registered successfully")
+}
+
+func (h *DeviceHandler) GetDevice(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetDevice] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	device, err := h.deviceService.GetDevice(id, ownerID)
+	if err != nil {
+		h.logger.Errorf("[GetDevice] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, device, "Device fetched successfully")
+}
+
+func (h *DeviceHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListDevices] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	devices, err := h.deviceService.ListDevices(ownerID)
+	if err != nil {
+		h.logger.Errorf("[ListDevices] error: %v", err)
+		utility.SendErrorResponse(w, "failed to list devices", http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, devices, "Devices retrieved")
+}
+
+func (h *DeviceHandler) UpdateDevice(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[UpdateDevice] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var req app.UpdateDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[UpdateDevice] decode error: %v", err)
+		utility.SendErrorResponse(w, "invalid input", http.StatusBadRequest, nil)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[UpdateDevice] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	updated, err := h.deviceService.UpdateDevice(id, ownerID, req)
+	if err != nil {
+		h.logger.Errorf("[UpdateDevice] update error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, updated, "Device updated successfully")
+}
+
+func (h *DeviceHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[DeleteDevice] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.deviceService.DeleteDevice(id, ownerID); err != nil {
+		h.logger.Errorf("[DeleteDevice] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, id, "Device deleted successfully")
+}
+
+func (h *DeviceHandler) BindVehicle(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[BindVehicle] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var req app.BindDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[BindVehicle] decode error: %v", err)
+		utility.SendErrorResponse(w, "invalid input", http.StatusBadRequest, nil)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[BindVehicle] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	device, err := h.deviceService.BindVehicle(id, ownerID, req)
+	if err != nil {
+		h.logger.Errorf("[BindVehicle] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, device, "Device bound to vehicle successfully")
+}
+
+func (h *DeviceHandler) UnbindVehicle(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[UnbindVehicle] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	device, err := h.deviceService.UnbindVehicle(id, ownerID)
+	if err != nil {
+		h.logger.Errorf("[UnbindVehicle] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, device, "Device unbound from vehicle successfully")
+}
+
+func (h *DeviceHandler) ListBindings(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListBindings] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	bindings, err := h.deviceService.ListBindings(id, ownerID, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListBindings] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, bindings, "Device bindings fetched successfully")
+}
+
+// ListVehicleBindings returns the devices installed in a vehicle over time.
+// With the RFC3339 `at` query param it returns the device that was
+// installed at that instant.
+func (h *DeviceHandler) ListVehicleBindings(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListVehicleBindings] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+	var at *time.Time
+	if raw := r.URL.Query().Get("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			utility.SendErrorResponse(w, "at must be an RFC3339 timestamp", http.StatusBadRequest, nil)
+			return
+		}
+		at = &parsed
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	bindings, err := h.deviceService.ListVehicleBindings(vehicleID, ownerID, at, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListVehicleBindings] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, bindings, "Vehicle device bindings fetched successfully")
+}
+
+func (h *DeviceHandler) CreateCredential(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[CreateCredential] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var req app.CreateCredentialRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.Errorf("[CreateCredential] decode error: %v", err)
+			utility.SendErrorResponse(w, "invalid input", http.StatusBadRequest, nil)
+			return
+		}
+	}
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[CreateCredential] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	credential, err := h.deviceService.CreateCredential(id, ownerID, req)
+	if err != nil {
+		h.logger.Errorf("[CreateCredential] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, credential, "Device credential created; store the token now, it is not shown again")
+}
+
+func (h *DeviceHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListCredentials] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	credentials, err := h.deviceService.ListCredentials(id, ownerID)
+	if err != nil {
+		h.logger.Errorf("[ListCredentials] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, credentials, "Device credentials fetched successfully")
+}
+
+func (h *DeviceHandler) RevokeCredential(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[RevokeCredential] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	credentialID := chi.URLParam(r, "credential_id")
+	credential, err := h.deviceService.RevokeCredential(id, ownerID, credentialID)
+	if err != nil {
+		h.logger.Errorf("[RevokeCredential] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, credential, "Device credential revoked successfully")
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDeviceNotFound),
+		errors.Is(err, domain.ErrCredentialNotFound),
+		errors.Is(err, app.ErrVehicleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrDeviceForbidden),
+		errors.Is(err, app.ErrVehicleForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, app.ErrIMEIInUse),
+		errors.Is(err, app.ErrIMEIOnVehicle),
+		errors.Is(err, app.ErrDeviceRetired):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
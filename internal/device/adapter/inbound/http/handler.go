@@ -0,0 +1,97 @@
+package device_handler
+
+import (
+	"net/http"
+
+	inbound "FMTS/internal/device/port/inbound"
+	route "FMTS/internal/user/adapter"
+	"FMTS/internal/user/application/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func InitDeviceRoutes(router chi.Router, deviceHandler inbound.DevicePortInterface, authMiddleware middleware.AuthMiddleware) {
+	router.Route("/devices", func(r chi.Router) {
+		authenticated := []func(http.Handler) http.Handler{
+			authMiddleware.AuthenticateToken,
+			authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+		}
+
+		routes := []route.Route{
+			{
+				Method:      http.MethodPost,
+				Path:        "/",
+				Handler:     deviceHandler.RegisterDevice,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/",
+				Handler:     deviceHandler.ListDevices,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/vehicles/{vehicle_id}/bindings",
+				Handler:     deviceHandler.ListVehicleBindings,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/{id}",
+				Handler:     deviceHandler.GetDevice,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodPatch,
+				Path:        "/{id}",
+				Handler:     deviceHandler.UpdateDevice,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodDelete,
+				Path:        "/{id}",
+				Handler:     deviceHandler.DeleteDevice,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodPut,
+				Path:        "/{id}/vehicle",
+				Handler:     deviceHandler.BindVehicle,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodDelete,
+				Path:        "/{id}/vehicle",
+				Handler:     deviceHandler.UnbindVehicle,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/{id}/bindings",
+				Handler:     deviceHandler.ListBindings,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodPost,
+				Path:        "/{id}/credentials",
+				Handler:     deviceHandler.CreateCredential,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/{id}/credentials",
+				Handler:     deviceHandler.ListCredentials,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodDelete,
+				Path:        "/{id}/credentials/{credential_id}",
+				Handler:     deviceHandler.RevokeCredential,
+				Middlewares: authenticated,
+			},
+		}
+
+		route.RegisterRoutes(r, routes)
+	})
+}
@@ -0,0 +1,279 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "FMTS/internal/device/domain/entity"
+	deviceOutboundPort "FMTS/internal/device/port/outbound"
+	dal "FMTS/internal/user/adapter/outbound/infra"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type DevicePersistence struct {
+	deviceDal     dal.MongoDal[model.Device, model.Device]
+	bindingDal    dal.MongoDal[model.DeviceBinding, model.DeviceBinding]
+	credentialDal dal.MongoDal[model.DeviceCredential, model.DeviceCredential]
+	logger        utils.Logger
+}
+
+var _ deviceOutboundPort.DeviceRepo = (*DevicePersistence)(nil)
+
+func InitDeviceRepo(client *mongo.Client, dbName string, deviceCollection, bindingCollection, credentialCollection string, logger utils.Logger) deviceOutboundPort.DeviceRepo {
+	return &DevicePersistence{
+		deviceDal:     dal.NewMongoDal[model.Device, model.Device](client, dbName, deviceCollection),
+		bindingDal:    dal.NewMongoDal[model.DeviceBinding, model.DeviceBinding](client, dbName, bindingCollection),
+		credentialDal: dal.NewMongoDal[model.DeviceCredential, model.DeviceCredential](client, dbName, credentialCollection),
+		logger:        logger,
+	}
+}
+
+func (d *DevicePersistence) CreateDevice(device model.Device) (*model.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := d.deviceDal.InsertOne(ctx, device)
+	if err != nil {
+		d.logger.Errorf("[CreateDevice] insert error: %v", err)
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (d *DevicePersistence) FindByID(id string) (*model.Device, error) {
+	return d.findOne("FindByID", bson.M{"_id": id, "is_deleted": false})
+}
+
+func (d *DevicePersistence) FindByIMEI(imei string) (*model.Device, error) {
+	return d.findOne("FindByIMEI", bson.M{"imei": imei, "is_deleted": false})
+}
+
+func (d *DevicePersistence) FindByVehicle(vehicleID string) (*model.Device, error) {
+	return d.findOne("FindByVehicle", bson.M{"vehicle_id": vehicleID, "is_deleted": false})
+}
+
+// findOne returns nil when no device matches the filter.
+func (d *DevicePersistence) findOne(op string, filter bson.M) (*model.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	device, err := d.deviceDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		d.logger.Errorf("[%s] DB error: %v", op, err)
+		return nil, err
+	}
+	return device, nil
+}
+
+func (d *DevicePersistence) FindByOwner(ownerID string) ([]*model.Device, error) {
+	filter := bson.M{"is_deleted": false}
+	if ownerID != "" {
+		filter["owner_id"] = ownerID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return d.deviceDal.FindAll(ctx, filter, bson.M{})
+}
+
+func (d *DevicePersistence) UpdateDevice(device model.Device) (model.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": device.ID, "is_deleted": false}
+	update := bson.M{
+		"imei":       device.IMEI,
+		"serial":     device.Serial,
+		"model":      device.Model,
+		"sim_number": device.SIMNumber,
+		"firmware":   device.Firmware,
+		"status":     device.Status,
+		"updated_at": device.UpdatedAt,
+	}
+
+	updated, err := d.deviceDal.UpdateOne(ctx, filter, update)
+	if err != nil {
+		d.logger.Errorf("[UpdateDevice] update error: %v", err)
+		return model.Device{}, err
+	}
+	return updated, nil
+}
+
+// SetVehicle records the vehicle a device is bound to; an empty vehicleID
+// unbinds it.
+func (d *DevicePersistence) SetVehicle(id, vehicleID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"vehicle_id": vehicleID, "updated_at": time.Now()}
+	if _, err := d.deviceDal.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		d.logger.Errorf("[SetVehicle] update error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (d *DevicePersistence) UpdateSoftDelete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"is_deleted": true, "vehicle_id": "", "updated_at": time.Now()}
+
+	if _, err := d.deviceDal.UpdateOne(ctx, filter, update); err != nil {
+		d.logger.Errorf("[UpdateSoftDelete] error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (d *DevicePersistence) CreateBinding(binding model.DeviceBinding) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := d.bindingDal.InsertOne(ctx, binding); err != nil {
+		d.logger.Errorf("[CreateBinding] insert error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CloseBindings ends the open bindings of the device and of the vehicle.
+// Either ID may be empty.
+func (d *DevicePersistence) CloseBindings(deviceID, vehicleID string, at time.Time) error {
+	var either []bson.M
+	if deviceID != "" {
+		either = append(either, bson.M{"device_id": deviceID})
+	}
+	if vehicleID != "" {
+		either = append(either, bson.M{"vehicle_id": vehicleID})
+	}
+	if len(either) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"unbound_at": bson.M{"$exists": false}, "$or": either}
+	if _, err := d.bindingDal.Collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"unbound_at": at}}); err != nil {
+		d.logger.Errorf("[CloseBindings] update error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (d *DevicePersistence) FindBindings(query model.BindingQuery) ([]*model.DeviceBinding, error) {
+	filter := bson.M{}
+	if query.DeviceID != "" {
+		filter["device_id"] = query.DeviceID
+	}
+	if query.VehicleID != "" {
+		filter["vehicle_id"] = query.VehicleID
+	}
+	if query.OwnerID != "" {
+		filter["owner_id"] = query.OwnerID
+	}
+	if query.At != nil {
+		filter["bound_at"] = bson.M{"$lte": *query.At}
+		filter["$or"] = []bson.M{
+			{"unbound_at": bson.M{"$exists": false}},
+			{"unbound_at": bson.M{"$gt": *query.At}},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "bound_at", Value: -1}}).SetLimit(query.Limit)
+	cursor, err := d.bindingDal.Collection().Find(ctx, filter, opts)
+	if err != nil {
+		d.logger.Errorf("[FindBindings] DB error: %v", err)
+		return nil, err
+	}
+
+	var bindings []*model.DeviceBinding
+	if err := cursor.All(ctx, &bindings); err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+func (d *DevicePersistence) CreateCredential(credential model.DeviceCredential) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := d.credentialDal.InsertOne(ctx, credential); err != nil {
+		d.logger.Errorf("[CreateCredential] insert error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (d *DevicePersistence) FindCredentials(deviceID string) ([]*model.DeviceCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return d.credentialDal.FindAll(ctx, bson.M{"device_id": deviceID}, bson.M{})
+}
+
+// FindCredentialByHash returns nil when no credential has the hash.
+func (d *DevicePersistence) FindCredentialByHash(tokenHash string) (*model.DeviceCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	credential, err := d.credentialDal.FindOne(ctx, bson.M{"token_hash": tokenHash}, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		d.logger.Errorf("[FindCredentialByHash] DB error: %v", err)
+		return nil, err
+	}
+	return credential, nil
+}
+
+// RevokeCredential returns nil when the device has no such credential.
+// Revoking twice keeps the first revocation time.
+func (d *DevicePersistence) RevokeCredential(deviceID, id string, at time.Time) (*model.DeviceCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "device_id": deviceID}
+	if _, err := d.credentialDal.Collection().UpdateOne(ctx,
+		bson.M{"_id": id, "device_id": deviceID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": at}},
+	); err != nil {
+		d.logger.Errorf("[RevokeCredential] update error: %v", err)
+		return nil, err
+	}
+
+	credential, err := d.credentialDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		d.logger.Errorf("[RevokeCredential] DB error: %v", err)
+		return nil, err
+	}
+	return credential, nil
+}
+
+func (d *DevicePersistence) TouchCredential(id string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := d.credentialDal.Collection().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": at}}); err != nil {
+		d.logger.Errorf("[TouchCredential] update error: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package device
+
+import (
+	"errors"
+	"time"
+
+	model "FMTS/internal/device/domain/entity"
+	domain "FMTS/internal/device/domain/service"
+	outbound "FMTS/internal/device/port/outbound"
+	"FMTS/pkg/utils"
+)
+
+const (
+	DefaultBindingLimit = 100
+	MaxBindingLimit     = 1000
+)
+
+var (
+	ErrDeviceForbidden  = errors.New("device belongs to another owner")
+	ErrIMEIInUse        = errors.New("another device is already registered with this IMEI")
+	ErrIMEIOnVehicle    = errors.New("this IMEI is already installed in another vehicle")
+	ErrVehicleNotFound  = errors.New("vehicle not found")
+	ErrVehicleForbidden = errors.New("vehicle belongs to another owner")
+	ErrDeviceRetired    = errors.New("retired devices cannot be bound to a vehicle")
+)
+
+// DeviceService defines business use cases for Device. ownerID is the
+// caller's user ID, or empty for admins who may act on every device.
+type DeviceService interface {
+	RegisterDevice(req CreateDeviceRequest, ownerID string) (*model.Device, error)
+	GetDevice(id, ownerID string) (*model.Device, error)
+	ListDevices(ownerID string) ([]*model.Device, error)
+	UpdateDevice(id, ownerID string, req UpdateDeviceRequest) (*model.Device, error)
+	DeleteDevice(id, ownerID string) error
+	BindVehicle(id, ownerID string, req BindDeviceRequest) (*model.Device, error)
+	UnbindVehicle(id, ownerID string) (*model.Device, error)
+	ListBindings(id, ownerID string, limit int64) ([]*model.DeviceBinding, error)
+	ListVehicleBindings(vehicleID, ownerID string, at *time.Time, limit int64) ([]*model.DeviceBinding, error)
+	CreateCredential(id, ownerID string, req CreateCredentialRequest) (*model.DeviceCredential, error)
+	ListCredentials(id, ownerID string) ([]*model.DeviceCredential, error)
+	RevokeCredential(id, ownerID, credentialID string) (*model.DeviceCredential, error)
+	AuthenticateToken(token string) (model.DeviceIdentity, bool, error)
+	ResolveIMEI(imei string) (*model.Device, error)
+}
+
+type deviceServiceImpl struct {
+	domain   domain.DeviceService
+	vehicles outbound.VehicleLookup
+	logger   utils.Logger
+}
+
+// Constructor
+func NewDeviceService(domain domain.DeviceService, vehicles outbound.VehicleLookup, logger utils.Logger) DeviceService {
+	return &deviceServiceImpl{
+		domain:   domain,
+		vehicles: vehicles,
+		logger:   logger,
+	}
+}
+
+// RegisterDevice validates the request, checks the IMEI is free and
+// persists the device, bound to req.VehicleID when given
+func (s *deviceServiceImpl) RegisterDevice(req CreateDeviceRequest, ownerID string) (*model.Device, error) {
+	if err := req.Validate(); err != nil {
+		s.logger.Warnf("[RegisterDevice] validation failed: %v", err)
+		return nil, err
+	}
+	if err := s.checkIMEIFree(req.IMEI, "", req.VehicleID); err != nil {
+		return nil, err
+	}
+	if req.VehicleID != "" {
+		if err := s.checkVehicle(req.VehicleID, ownerID); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.domain.CreateDevice(model.Device{
+		OwnerID:   ownerID,
+		IMEI:      req.IMEI,
+		Serial:    req.Serial,
+		Model:     req.Model,
+		SIMNumber: req.SIMNumber,
+		Firmware:  req.Firmware,
+	})
+	if err != nil {
+		s.logger.Errorf("[RegisterDevice] failed to save device: %v", err)
+		return nil, err
+	}
+	if req.VehicleID == "" {
+		return created, nil
+	}
+	return s.domain.BindVehicle(*created, req.VehicleID)
+}
+
+// GetDevice fetches a device the caller is allowed to see
+func (s *deviceServiceImpl) GetDevice(id, ownerID string) (*model.Device, error) {
+	device, err := s.domain.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && device.OwnerID != ownerID {
+		return nil, ErrDeviceForbidden
+	}
+	return device, nil
+}
+
+// ListDevices returns the caller's devices (every device for admins)
+func (s *deviceServiceImpl) ListDevices(ownerID string) ([]*model.Device, error) {
+	devices, err := s.domain.FindByOwner(ownerID)
+	if err != nil {
+		s.logger.Errorf("[ListDevices] error: %v", err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// UpdateDevice updates the details and status of a device. Retiring a
+// device removes it from its vehicle.
+func (s *deviceServiceImpl) UpdateDevice(id, ownerID string, req UpdateDeviceRequest) (*model.Device, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	device, err := s.GetDevice(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IMEI != nil && *req.IMEI != device.IMEI {
+		if err := s.checkIMEIFree(*req.IMEI, device.ID, device.VehicleID); err != nil {
+			return nil, err
+		}
+		device.IMEI = *req.IMEI
+	}
+	if req.Serial != nil {
+		device.Serial = *req.Serial
+	}
+	if req.Model != nil {
+		device.Model = *req.Model
+	}
+	if req.SIMNumber != nil {
+		device.SIMNumber = *req.SIMNumber
+	}
+	if req.Firmware != nil {
+		device.Firmware = *req.Firmware
+	}
+	if req.Status != nil {
+		device.Status = model.DeviceStatus(*req.Status)
+	}
+
+	if device.Status == model.DeviceStatusRetired {
+		if device, err = s.domain.UnbindVehicle(*device); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := s.domain.UpdateDevice(*device)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteDevice marks a device as deleted (soft delete) and ends its binding
+func (s *deviceServiceImpl) DeleteDevice(id, ownerID string) error {
+	if _, err := s.GetDevice(id, ownerID); err != nil {
+		return err
+	}
+	return s.domain.UpdateSoftDelete(id)
+}
+
+// BindVehicle installs a device in a vehicle of the same owner
+func (s *deviceServiceImpl) BindVehicle(id, ownerID string, req BindDeviceRequest) (*model.Device, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	device, err := s.GetDevice(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if device.Status == model.DeviceStatusRetired {
+		return nil, ErrDeviceRetired
+	}
+	if err := s.checkVehicle(req.VehicleID, device.OwnerID); err != nil {
+		return nil, err
+	}
+	return s.domain.BindVehicle(*device, req.VehicleID)
+}
+
+// UnbindVehicle removes a device from its vehicle
+func (s *deviceServiceImpl) UnbindVehicle(id, ownerID string) (*model.Device, error) {
+	device, err := s.GetDevice(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.domain.UnbindVehicle(*device)
+}
+
+// ListBindings returns the vehicles a device was installed in, newest first
+func (s *deviceServiceImpl) ListBindings(id, ownerID string, limit int64) ([]*model.DeviceBinding, error) {
+	if _, err := s.GetDevice(id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.domain.FindBindings(model.BindingQuery{DeviceID: id, Limit: clampBindingLimit(limit)})
+}
+
+// ListVehicleBindings returns the devices installed in a vehicle, newest
+// first; with at set, only the device that was installed at that time
+func (s *deviceServiceImpl) ListVehicleBindings(vehicleID, ownerID string, at *time.Time, limit int64) ([]*model.DeviceBinding, error) {
+	return s.domain.FindBindings(model.BindingQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		At:        at,
+		Limit:     clampBindingLimit(limit),
+	})
+}
+
+// CreateCredential issues an API key for a device
+func (s *deviceServiceImpl) CreateCredential(id, ownerID string, req CreateCredentialRequest) (*model.DeviceCredential, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	device, err := s.GetDevice(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.domain.CreateCredential(*device, req.Name)
+}
+
+// ListCredentials returns the API keys of a device, without their tokens
+func (s *deviceServiceImpl) ListCredentials(id, ownerID string) ([]*model.DeviceCredential, error) {
+	if _, err := s.GetDevice(id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.domain.FindCredentials(id)
+}
+
+// RevokeCredential stops an API key of a device from authenticating
+func (s *deviceServiceImpl) RevokeCredential(id, ownerID, credentialID string) (*model.DeviceCredential, error) {
+	if _, err := s.GetDevice(id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.domain.RevokeCredential(id, credentialID)
+}
+
+// AuthenticateToken resolves a device credential; see the domain service
+func (s *deviceServiceImpl) AuthenticateToken(token string) (model.DeviceIdentity, bool, error) {
+	return s.domain.AuthenticateToken(token)
+}
+
+// ResolveIMEI returns the device registered with the IMEI, or nil when
+// there is none
+func (s *deviceServiceImpl) ResolveIMEI(imei string) (*model.Device, error) {
+	return s.domain.FindByIMEI(imei)
+}
+
+// checkIMEIFree makes sure no other device is registered with the IMEI and
+// no vehicle other than vehicleID, the one the device is or will be bound
+// to, still stores it as its device IMEI.
+func (s *deviceServiceImpl) checkIMEIFree(imei, deviceID, vehicleID string) error {
+	existing, err := s.domain.FindByIMEI(imei)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != deviceID {
+		return ErrIMEIInUse
+	}
+
+	installedIn, found, err := s.vehicles.VehicleWithIMEI(imei)
+	if err != nil {
+		return err
+	}
+	if found && installedIn != vehicleID {
+		return ErrIMEIOnVehicle
+	}
+	return nil
+}
+
+// checkVehicle makes sure the vehicle exists and belongs to ownerID.
+func (s *deviceServiceImpl) checkVehicle(vehicleID, ownerID string) error {
+	vehicleOwner, found, err := s.vehicles.VehicleOwner(vehicleID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrVehicleNotFound
+	}
+	if vehicleOwner != ownerID {
+		return ErrVehicleForbidden
+	}
+	return nil
+}
+
+func clampBindingLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultBindingLimit
+	}
+	if limit > MaxBindingLimit {
+		return MaxBindingLimit
+	}
+	return limit
+}
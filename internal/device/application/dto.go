@@ -0,0 +1,68 @@
+package device
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type CreateDeviceRequest struct {
+	// OwnerID is only honoured for admins, who register devices on behalf
+	// of an owner; everyone else registers their own devices.
+	OwnerID   string `json:"owner_id,omitempty"`
+	IMEI      string `json:"imei"`
+	Serial    string `json:"serial,omitempty"`
+	Model     string `json:"model,omitempty"`
+	SIMNumber string `json:"sim_number,omitempty"`
+	Firmware  string `json:"firmware,omitempty"`
+	VehicleID string `json:"vehicle_id,omitempty"`
+}
+
+func (r CreateDeviceRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.IMEI, validation.Required, is.Digit, validation.Length(15, 15)),
+		validation.Field(&r.Serial, validation.Length(0, 64)),
+		validation.Field(&r.Model, validation.Length(0, 64)),
+		validation.Field(&r.SIMNumber, validation.Length(0, 32)),
+		validation.Field(&r.Firmware, validation.Length(0, 64)),
+	)
+}
+
+type UpdateDeviceRequest struct {
+	IMEI      *string `json:"imei,omitempty"`
+	Serial    *string `json:"serial,omitempty"`
+	Model     *string `json:"model,omitempty"`
+	SIMNumber *string `json:"sim_number,omitempty"`
+	Firmware  *string `json:"firmware,omitempty"`
+	Status    *string `json:"status,omitempty"`
+}
+
+func (r UpdateDeviceRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.IMEI, validation.When(r.IMEI != nil, validation.Required, is.Digit, validation.Length(15, 15))),
+		validation.Field(&r.Serial, validation.When(r.Serial != nil, validation.Length(0, 64))),
+		validation.Field(&r.Model, validation.When(r.Model != nil, validation.Length(0, 64))),
+		validation.Field(&r.SIMNumber, validation.When(r.SIMNumber != nil, validation.Length(0, 32))),
+		validation.Field(&r.Firmware, validation.When(r.Firmware != nil, validation.Length(0, 64))),
+		validation.Field(&r.Status, validation.When(r.Status != nil, validation.In("active", "suspended", "retired"))),
+	)
+}
+
+type BindDeviceRequest struct {
+	VehicleID string `json:"vehicle_id"`
+}
+
+func (r BindDeviceRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.VehicleID, validation.Required),
+	)
+}
+
+type CreateCredentialRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+func (r CreateCredentialRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Length(0, 100)),
+	)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// DeviceStatus custom string type with predefined values
+type DeviceStatus string
+
+const (
+	DeviceStatusActive    DeviceStatus = "active"
+	DeviceStatusSuspended DeviceStatus = "suspended"
+	DeviceStatusRetired   DeviceStatus = "retired"
+)
+
+// Device is a tracker unit. VehicleID is the vehicle it is currently bound
+// to; the binding history is kept as DeviceBinding records.
+type Device struct {
+	ID        string       `bson:"_id,omitempty" json:"id"`
+	OwnerID   string       `bson:"owner_id" json:"owner_id"`
+	IMEI      string       `bson:"imei" json:"imei"`
+	Serial    string       `bson:"serial,omitempty" json:"serial,omitempty"`
+	Model     string       `bson:"model,omitempty" json:"model,omitempty"`
+	SIMNumber string       `bson:"sim_number,omitempty" json:"sim_number,omitempty"`
+	Firmware  string       `bson:"firmware,omitempty" json:"firmware,omitempty"`
+	VehicleID string       `bson:"vehicle_id,omitempty" json:"vehicle_id,omitempty"`
+	Status    DeviceStatus `bson:"status" json:"status"`
+	IsDeleted bool         `bson:"is_deleted" json:"is_deleted"`
+	CreatedAt time.Time    `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time    `bson:"updated_at" json:"updated_at"`
+}
+
+// DeviceBinding records that a device was installed in a vehicle from
+// BoundAt until UnboundAt. The open binding of a device has no UnboundAt.
+type DeviceBinding struct {
+	ID        string     `bson:"_id,omitempty" json:"id"`
+	DeviceID  string     `bson:"device_id" json:"device_id"`
+	IMEI      string     `bson:"imei" json:"imei"`
+	VehicleID string     `bson:"vehicle_id" json:"vehicle_id"`
+	OwnerID   string     `bson:"owner_id" json:"owner_id"`
+	BoundAt   time.Time  `bson:"bound_at" json:"bound_at"`
+	UnboundAt *time.Time `bson:"unbound_at,omitempty" json:"unbound_at,omitempty"`
+}
+
+// BindingQuery filters binding history. With At set only the bindings that
+// were open at that instant are returned.
+type BindingQuery struct {
+	DeviceID  string
+	VehicleID string
+	OwnerID   string
+	At        *time.Time
+	Limit     int64
+}
+
+// DeviceCredential is an API key a device reports with instead of a user's
+// token. Only the hash of the secret is stored; Token is set once, in the
+// response that creates the credential.
+type DeviceCredential struct {
+	ID         string     `bson:"_id,omitempty" json:"id"`
+	DeviceID   string     `bson:"device_id" json:"device_id"`
+	OwnerID    string     `bson:"owner_id" json:"owner_id"`
+	Name       string     `bson:"name,omitempty" json:"name,omitempty"`
+	TokenHash  string     `bson:"token_hash" json:"-"`
+	Token      string     `bson:"-" json:"token,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// DeviceIdentity is what an authenticated device credential resolves to.
+type DeviceIdentity struct {
+	DeviceID  string
+	OwnerID   string
+	VehicleID string
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	model "FMTS/internal/device/domain/entity"
+	"FMTS/internal/device/domain/repository"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenPrefix marks device credentials so they can be told apart from user
+// JWTs in the same Authorization header.
+const TokenPrefix = "fmtsd_"
+
+// credentialTouchInterval limits how often the last use of a credential is
+// written back.
+const credentialTouchInterval = time.Minute
+
+var (
+	ErrDeviceNotFound     = errors.New("device not found or deleted")
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrInvalidCredential  = errors.New("invalid or revoked device credential")
+	ErrDeviceInactive     = errors.New("device is not active")
+)
+
+type DeviceDomain struct {
+	deviceRepo repository.DeviceRepo
+	logger     utils.Logger
+}
+
+func NewDeviceDomainService(repo repository.DeviceRepo, logger utils.Logger) DeviceService {
+	return &DeviceDomain{
+		deviceRepo: repo,
+		logger:     logger,
+	}
+}
+
+type DeviceService interface {
+	CreateDevice(device model.Device) (*model.Device, error)
+	FindByID(id string) (*model.Device, error)
+	FindByIMEI(imei string) (*model.Device, error)
+	FindByOwner(ownerID string) ([]*model.Device, error)
+	UpdateDevice(device model.Device) (model.Device, error)
+	UpdateSoftDelete(id string) error
+	BindVehicle(device model.Device, vehicleID string) (*model.Device, error)
+	UnbindVehicle(device model.Device) (*model.Device, error)
+	FindBindings(query model.BindingQuery) ([]*model.DeviceBinding, error)
+	CreateCredential(device model.Device, name string) (*model.DeviceCredential, error)
+	FindCredentials(deviceID string) ([]*model.DeviceCredential, error)
+	RevokeCredential(deviceID, id string) (*model.DeviceCredential, error)
+	AuthenticateToken(token string) (model.DeviceIdentity, bool, error)
+}
+
+// Create a new device
+func (d *DeviceDomain) CreateDevice(device model.Device) (*model.Device, error) {
+	device.ID = primitive.NewObjectID().Hex()
+	device.CreatedAt = time.Now()
+	device.UpdatedAt = time.Now()
+	device.IsDeleted = false
+	device.VehicleID = ""
+	if device.Status == "" {
+		device.Status = model.DeviceStatusActive
+	}
+
+	created, err := d.deviceRepo.CreateDevice(device)
+	if err != nil {
+		d.logger.Errorf("[CreateDevice] failed to create device: %v", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+// Find device by ID
+func (d *DeviceDomain) FindByID(id string) (*model.Device, error) {
+	device, err := d.deviceRepo.FindByID(id)
+	if err != nil {
+		d.logger.Errorf("[FindByID] error: %v", err)
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+	return device, nil
+}
+
+// FindByIMEI returns nil when no device has the IMEI
+func (d *DeviceDomain) FindByIMEI(imei string) (*model.Device, error) {
+	device, err := d.deviceRepo.FindByIMEI(imei)
+	if err != nil {
+		d.logger.Errorf("[FindByIMEI] error: %v", err)
+		return nil, err
+	}
+	return device, nil
+}
+
+// List the devices of an owner
+func (d *DeviceDomain) FindByOwner(ownerID string) ([]*model.Device, error) {
+	devices, err := d.deviceRepo.FindByOwner(ownerID)
+	if err != nil {
+		d.logger.Errorf("[FindByOwner] error: %v", err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Update the details and status of a device
+func (d *DeviceDomain) UpdateDevice(device model.Device) (model.Device, error) {
+	device.UpdatedAt = time.Now()
+
+	updated, err := d.deviceRepo.UpdateDevice(device)
+	if err != nil {
+		d.logger.Errorf("[UpdateDevice] error updating device: %v", err)
+		return model.Device{}, err
+	}
+	return updated, nil
+}
+
+// Soft delete a device, ending its binding
+func (d *DeviceDomain) UpdateSoftDelete(id string) error {
+	if _, err := d.FindByID(id); err != nil {
+		return err
+	}
+	if err := d.deviceRepo.CloseBindings(id, "", time.Now()); err != nil {
+		return fmt.Errorf("failed to close device bindings: %w", err)
+	}
+	if err := d.deviceRepo.UpdateSoftDelete(id); err != nil {
+		d.logger.Errorf("[UpdateSoftDelete] update error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// BindVehicle installs the device in a vehicle. The device's previous
+// binding ends, and so does the binding of any other device that was in
+// the vehicle: a vehicle reports through one device at a time.
+func (d *DeviceDomain) BindVehicle(device model.Device, vehicleID string) (*model.Device, error) {
+	if device.VehicleID == vehicleID {
+		return &device, nil
+	}
+
+	now := time.Now()
+	previous, err := d.deviceRepo.FindByVehicle(vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.deviceRepo.CloseBindings(device.ID, vehicleID, now); err != nil {
+		return nil, fmt.Errorf("failed to close device bindings: %w", err)
+	}
+	if previous != nil && previous.ID != device.ID {
+		if err := d.deviceRepo.SetVehicle(previous.ID, ""); err != nil {
+			return nil, err
+		}
+		d.logger.Infof("[BindVehicle] device %s replaced device %s in vehicle %s", device.ID, previous.ID, vehicleID)
+	}
+
+	if err := d.deviceRepo.CreateBinding(model.DeviceBinding{
+		ID:        primitive.NewObjectID().Hex(),
+		DeviceID:  device.ID,
+		IMEI:      device.IMEI,
+		VehicleID: vehicleID,
+		OwnerID:   device.OwnerID,
+		BoundAt:   now,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record device binding: %w", err)
+	}
+	if err := d.deviceRepo.SetVehicle(device.ID, vehicleID); err != nil {
+		return nil, err
+	}
+
+	device.VehicleID = vehicleID
+	device.UpdatedAt = now
+	return &device, nil
+}
+
+// UnbindVehicle removes the device from its vehicle
+func (d *DeviceDomain) UnbindVehicle(device model.Device) (*model.Device, error) {
+	if device.VehicleID == "" {
+		return &device, nil
+	}
+
+	now := time.Now()
+	if err := d.deviceRepo.CloseBindings(device.ID, "", now); err != nil {
+		return nil, fmt.Errorf("failed to close device bindings: %w", err)
+	}
+	if err := d.deviceRepo.SetVehicle(device.ID, ""); err != nil {
+		return nil, err
+	}
+
+	device.VehicleID = ""
+	device.UpdatedAt = now
+	return &device, nil
+}
+
+// List binding history, newest first
+func (d *DeviceDomain) FindBindings(query model.BindingQuery) ([]*model.DeviceBinding, error) {
+	bindings, err := d.deviceRepo.FindBindings(query)
+	if err != nil {
+		d.logger.Errorf("[FindBindings] error: %v", err)
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// CreateCredential issues a new API key for the device. The returned
+// credential carries the token; it cannot be read back later.
+func (d *DeviceDomain) CreateCredential(device model.Device, name string) (*model.DeviceCredential, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate device token: %w", err)
+	}
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	credential := model.DeviceCredential{
+		ID:        primitive.NewObjectID().Hex(),
+		DeviceID:  device.ID,
+		OwnerID:   device.OwnerID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now(),
+	}
+	if err := d.deviceRepo.CreateCredential(credential); err != nil {
+		d.logger.Errorf("[CreateCredential] error: %v", err)
+		return nil, err
+	}
+
+	credential.Token = token
+	return &credential, nil
+}
+
+// List the credentials of a device
+func (d *DeviceDomain) FindCredentials(deviceID string) ([]*model.DeviceCredential, error) {
+	credentials, err := d.deviceRepo.FindCredentials(deviceID)
+	if err != nil {
+		d.logger.Errorf("[FindCredentials] error: %v", err)
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// Revoke a credential of a device
+func (d *DeviceDomain) RevokeCredential(deviceID, id string) (*model.DeviceCredential, error) {
+	credential, err := d.deviceRepo.RevokeCredential(deviceID, id, time.Now())
+	if err != nil {
+		d.logger.Errorf("[RevokeCredential] error: %v", err)
+		return nil, err
+	}
+	if credential == nil {
+		return nil, ErrCredentialNotFound
+	}
+	return credential, nil
+}
+
+// AuthenticateToken resolves a device credential to the device and the
+// vehicle it is bound to. ok is false when token is not a device
+// credential at all, so callers can fall back to user authentication.
+func (d *DeviceDomain) AuthenticateToken(token string) (model.DeviceIdentity, bool, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return model.DeviceIdentity{}, false, nil
+	}
+
+	credential, err := d.deviceRepo.FindCredentialByHash(hashToken(token))
+	if err != nil {
+		return model.DeviceIdentity{}, true, err
+	}
+	if credential == nil || credential.RevokedAt != nil {
+		return model.DeviceIdentity{}, true, ErrInvalidCredential
+	}
+
+	device, err := d.deviceRepo.FindByID(credential.DeviceID)
+	if err != nil {
+		return model.DeviceIdentity{}, true, err
+	}
+	if device == nil {
+		return model.DeviceIdentity{}, true, ErrInvalidCredential
+	}
+	if device.Status != model.DeviceStatusActive {
+		return model.DeviceIdentity{}, true, ErrDeviceInactive
+	}
+
+	now := time.Now()
+	if credential.LastUsedAt == nil || now.Sub(*credential.LastUsedAt) >= credentialTouchInterval {
+		// Failing to record the use must not refuse the device.
+		_ = d.deviceRepo.TouchCredential(credential.ID, now)
+	}
+	return model.DeviceIdentity{DeviceID: device.ID, OwnerID: device.OwnerID, VehicleID: device.VehicleID}, true, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	model "FMTS/internal/device/domain/entity"
+	"FMTS/pkg/utils"
+)
+
+// memoryDeviceRepo keeps devices, bindings and credentials in memory.
+type memoryDeviceRepo struct {
+	devices     map[string]model.Device
+	bindings    []*model.DeviceBinding
+	credentials map[string]model.DeviceCredential
+	lookups     int
+	touches     int
+}
+
+func newMemoryDeviceRepo() *memoryDeviceRepo {
+	return &memoryDeviceRepo{
+		devices:     make(map[string]model.Device),
+		credentials: make(map[string]model.DeviceCredential),
+	}
+}
+
+func (r *memoryDeviceRepo) CreateDevice(device model.Device) (*model.Device, error) {
+	r.devices[device.ID] = device
+	return &device, nil
+}
+
+func (r *memoryDeviceRepo) FindByID(id string) (*model.Device, error) {
+	device, ok := r.devices[id]
+	if !ok || device.IsDeleted {
+		return nil, nil
+	}
+	return &device, nil
+}
+
+func (r *memoryDeviceRepo) FindByIMEI(imei string) (*model.Device, error) {
+	for _, device := range r.devices {
+		if device.IMEI == imei && !device.IsDeleted {
+			return &device, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryDeviceRepo) FindByVehicle(vehicleID string) (*model.Device, error) {
+	for _, device := range r.devices {
+		if device.VehicleID == vehicleID && !device.IsDeleted {
+			return &device, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryDeviceRepo) FindByOwner(ownerID string) ([]*model.Device, error) {
+	var devices []*model.Device
+	for _, device := range r.devices {
+		if device.OwnerID == ownerID && !device.IsDeleted {
+			devices = append(devices, &device)
+		}
+	}
+	return devices, nil
+}
+
+func (r *memoryDeviceRepo) UpdateDevice(device model.Device) (model.Device, error) {
+	r.devices[device.ID] = device
+	return device, nil
+}
+
+func (r *memoryDeviceRepo) SetVehicle(id, vehicleID string) error {
+	device := r.devices[id]
+	device.VehicleID = vehicleID
+	r.devices[id] = device
+	return nil
+}
+
+func (r *memoryDeviceRepo) UpdateSoftDelete(id string) error {
+	device := r.devices[id]
+	device.IsDeleted = true
+	r.devices[id] = device
+	return nil
+}
+
+func (r *memoryDeviceRepo) CreateBinding(binding model.DeviceBinding) error {
+	r.bindings = append(r.bindings, &binding)
+	return nil
+}
+
+func (r *memoryDeviceRepo) CloseBindings(deviceID, vehicleID string, at time.Time) error {
+	for _, b := range r.bindings {
+		if b.UnboundAt != nil {
+			continue
+		}
+		if (deviceID != "" && b.DeviceID == deviceID) || (vehicleID != "" && b.VehicleID == vehicleID) {
+			unboundAt := at
+			b.UnboundAt = &unboundAt
+		}
+	}
+	return nil
+}
+
+func (r *memoryDeviceRepo) FindBindings(query model.BindingQuery) ([]*model.DeviceBinding, error) {
+	var bindings []*model.DeviceBinding
+	for _, b := range r.bindings {
+		if (query.DeviceID == "" || b.DeviceID == query.DeviceID) && (query.VehicleID == "" || b.VehicleID == query.VehicleID) {
+			bindings = append(bindings, b)
+		}
+	}
+	return bindings, nil
+}
+
+func (r *memoryDeviceRepo) CreateCredential(credential model.DeviceCredential) error {
+	r.credentials[credential.ID] = credential
+	return nil
+}
+
+func (r *memoryDeviceRepo) FindCredentials(deviceID string) ([]*model.DeviceCredential, error) {
+	var credentials []*model.DeviceCredential
+	for _, c := range r.credentials {
+		if c.DeviceID == deviceID {
+			credentials = append(credentials, &c)
+		}
+	}
+	return credentials, nil
+}
+
+func (r *memoryDeviceRepo) FindCredentialByHash(tokenHash string) (*model.DeviceCredential, error) {
+	r.lookups++
+	for _, c := range r.credentials {
+		if c.TokenHash == tokenHash {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryDeviceRepo) RevokeCredential(deviceID, id string, at time.Time) (*model.DeviceCredential, error) {
+	c, ok := r.credentials[id]
+	if !ok || c.DeviceID != deviceID {
+		return nil, nil
+	}
+	c.RevokedAt = &at
+	r.credentials[id] = c
+	return &c, nil
+}
+
+func (r *memoryDeviceRepo) TouchCredential(id string, at time.Time) error {
+	r.touches++
+	c := r.credentials[id]
+	c.LastUsedAt = &at
+	r.credentials[id] = c
+	return nil
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	cases := []struct {
+		name      string
+		token     func(issued string) string
+		revoke    bool
+		status    model.DeviceStatus
+		deleted   bool
+		wantOK    bool
+		wantErr   error
+		wantTouch bool
+	}{
+		{name: "active device", wantOK: true, wantTouch: true},
+		{name: "user token falls through", token: func(string) string { return "eyJhbGciOiJIUzI1NiJ9.e30.sig" }},
+		{name: "empty token falls through", token: func(string) string { return "" }},
+		{name: "unknown device token", token: func(string) string { return TokenPrefix + "unknown" }, wantOK: true, wantErr: ErrInvalidCredential},
+		{name: "token without its prefix", token: func(issued string) string { return issued[len(TokenPrefix):] }},
+		{name: "revoked credential", revoke: true, wantOK: true, wantErr: ErrInvalidCredential},
+		{name: "suspended device", status: model.DeviceStatusSuspended, wantOK: true, wantErr: ErrDeviceInactive},
+		{name: "retired device", status: model.DeviceStatusRetired, wantOK: true, wantErr: ErrDeviceInactive},
+		{name: "deleted device", deleted: true, wantOK: true, wantErr: ErrInvalidCredential},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemoryDeviceRepo()
+			d := NewDeviceDomainService(store, utils.NewStandardLogger())
+
+			device, err := d.CreateDevice(model.Device{OwnerID: "owner1", IMEI: "356938035643809"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.BindVehicle(*device, "v1"); err != nil {
+				t.Fatal(err)
+			}
+			credential, err := d.CreateCredential(*device, "primary")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.revoke {
+				if _, err := d.RevokeCredential(device.ID, credential.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			stored := store.devices[device.ID]
+			if tc.status != "" {
+				stored.Status = tc.status
+			}
+			stored.IsDeleted = tc.deleted
+			store.devices[device.ID] = stored
+
+			token := credential.Token
+			if tc.token != nil {
+				token = tc.token(credential.Token)
+			}
+			identity, ok, err := d.AuthenticateToken(token)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if !tc.wantOK && store.lookups != 0 {
+				t.Errorf("looked up %d credentials for a token that is not a device token", store.lookups)
+			}
+			if (store.touches != 0) != tc.wantTouch {
+				t.Errorf("credential touched %d times, want touched = %v", store.touches, tc.wantTouch)
+			}
+
+			want := model.DeviceIdentity{}
+			if tc.wantOK && tc.wantErr == nil {
+				want = model.DeviceIdentity{DeviceID: device.ID, OwnerID: "owner1", VehicleID: "v1"}
+			}
+			if identity != want {
+				t.Errorf("identity = %+v, want %+v", identity, want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateTokenTouchesOncePerInterval(t *testing.T) {
+	store := newMemoryDeviceRepo()
+	d := NewDeviceDomainService(store, utils.NewStandardLogger())
+	device, _ := d.CreateDevice(model.Device{OwnerID: "owner1", IMEI: "356938035643809"})
+	credential, _ := d.CreateCredential(*device, "primary")
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := d.AuthenticateToken(credential.Token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.touches != 1 {
+		t.Errorf("credential touched %d times, want 1", store.touches)
+	}
+}
+
+func TestBindVehicle(t *testing.T) {
+	store := newMemoryDeviceRepo()
+	d := NewDeviceDomainService(store, utils.NewStandardLogger())
+
+	first, _ := d.CreateDevice(model.Device{OwnerID: "owner1", IMEI: "356938035643809"})
+	second, _ := d.CreateDevice(model.Device{OwnerID: "owner1", IMEI: "490154203237518"})
+
+	first, err := d.BindVehicle(*first, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Binding again to the same vehicle keeps the open binding.
+	if _, err := d.BindVehicle(*first, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.bindings) != 1 {
+		t.Fatalf("%d bindings after rebinding to the same vehicle, want 1", len(store.bindings))
+	}
+
+	// The second device replaces the first in the vehicle.
+	second, err = d.BindVehicle(*second, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.VehicleID != "v1" {
+		t.Errorf("second device in vehicle %q, want v1", second.VehicleID)
+	}
+	if got := store.devices[first.ID].VehicleID; got != "" {
+		t.Errorf("replaced device still in vehicle %q", got)
+	}
+
+	open := func(deviceID string) []*model.DeviceBinding {
+		var bindings []*model.DeviceBinding
+		for _, b := range store.bindings {
+			if b.DeviceID == deviceID && b.UnboundAt == nil {
+				bindings = append(bindings, b)
+			}
+		}
+		return bindings
+	}
+	if bindings := open(first.ID); len(bindings) != 0 {
+		t.Errorf("replaced device has %d open bindings, want 0", len(bindings))
+	}
+	if bindings := open(second.ID); len(bindings) != 1 || bindings[0].VehicleID != "v1" {
+		t.Errorf("second device open bindings = %+v, want one to v1", bindings)
+	}
+
+	// Moving the second device on closes its binding to v1.
+	if _, err := d.BindVehicle(*second, "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if bindings := open(second.ID); len(bindings) != 1 || bindings[0].VehicleID != "v2" {
+		t.Errorf("moved device open bindings = %+v, want one to v2", bindings)
+	}
+	vehicle, _ := d.FindBindings(model.BindingQuery{VehicleID: "v1"})
+	for _, b := range vehicle {
+		if b.UnboundAt == nil {
+			t.Errorf("vehicle v1 still has an open binding of device %s", b.DeviceID)
+		}
+	}
+}
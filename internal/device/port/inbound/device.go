@@ -0,0 +1,18 @@
+package inbound
+
+import "net/http"
+
+type DevicePortInterface interface {
+	RegisterDevice(w http.ResponseWriter, r *http.Request)
+	GetDevice(w http.ResponseWriter, r *http.Request)
+	ListDevices(w http.ResponseWriter, r *http.Request)
+	UpdateDevice(w http.ResponseWriter, r *http.Request)
+	DeleteDevice(w http.ResponseWriter, r *http.Request)
+	BindVehicle(w http.ResponseWriter, r *http.Request)
+	UnbindVehicle(w http.ResponseWriter, r *http.Request)
+	ListBindings(w http.ResponseWriter, r *http.Request)
+	ListVehicleBindings(w http.ResponseWriter, r *http.Request)
+	CreateCredential(w http.ResponseWriter, r *http.Request)
+	ListCredentials(w http.ResponseWriter, r *http.Request)
+	RevokeCredential(w http.ResponseWriter, r *http.Request)
+}
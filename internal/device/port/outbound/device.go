@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"time"
+
+	model "FMTS/internal/device/domain/entity"
+)
+
+// DeviceRepo abstracts database operations for devices, their vehicle
+// bindings and their API credentials
+type DeviceRepo interface {
+	CreateDevice(device model.Device) (*model.Device, error)
+	FindByID(id string) (*model.Device, error)
+	FindByIMEI(imei string) (*model.Device, error)
+	FindByVehicle(vehicleID string) (*model.Device, error)
+	FindByOwner(ownerID string) ([]*model.Device, error)
+	UpdateDevice(device model.Device) (model.Device, error)
+	SetVehicle(id, vehicleID string) error
+	UpdateSoftDelete(id string) error
+	CreateBinding(binding model.DeviceBinding) error
+	CloseBindings(deviceID, vehicleID string, at time.Time) error
+	FindBindings(query model.BindingQuery) ([]*model.DeviceBinding, error)
+	CreateCredential(credential model.DeviceCredential) error
+	FindCredentials(deviceID string) ([]*model.DeviceCredential, error)
+	FindCredentialByHash(tokenHash string) (*model.DeviceCredential, error)
+	RevokeCredential(deviceID, id string, at time.Time) (*model.DeviceCredential, error)
+	TouchCredential(id string, at time.Time) error
+}
@@ -0,0 +1,9 @@
+package repository
+
+// VehicleLookup reads vehicles from the vehicle module, so devices are only
+// bound to vehicles of the same owner and never share an IMEI with the
+// device IMEI still stored on another vehicle.
+type VehicleLookup interface {
+	VehicleOwner(vehicleID string) (ownerID string, found bool, err error)
+	VehicleWithIMEI(imei string) (vehicleID string, found bool, err error)
+}
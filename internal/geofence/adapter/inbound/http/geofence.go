@@ -0,0 +1,215 @@
+package geofence_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	app "FMTS/internal/geofence/application"
+	domain "FMTS/internal/geofence/domain/service"
+	port "FMTS/internal/geofence/port/inbound"
+	contexts "FMTS/pkg/context"
+	"FMTS/pkg/utils"
+	utility "FMTS/utils"
+)
+
+type GeofenceHandler struct {
+	geofenceService app.GeofenceService
+	logger          utils.Logger
+}
+
+func NewGeofenceHandler(service app.GeofenceService, logger utils.Logger) port.GeofencePortInterface {
+	return &GeofenceHandler{
+		geofenceService: service,
+		logger:          logger,
+	}
+}
+
+func (h *GeofenceHandler) CreateGeofence(w http.ResponseWriter, r *http.Request) {
+	var req app.CreateGeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[CreateGeofence] decode error: %v", err)
+		utility.SendErrorResponse(w, "invalid request", http.StatusBadRequest, nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[CreateGeofence] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx := contexts.ExtractUserContext(r)
+	if ctx.UserID == "" {
+		h.logger.Warnf("[CreateGeofence] user context missing")
+		utility.SendErrorResponse(w, "unauthorized", http.StatusUnauthorized, nil)
+		return
+	}
+
+	created, err := h.geofenceService.CreateGeofence(req, ctx.UserID)
+	if err != nil {
+		h.logger.Errorf("[CreateGeofence] service error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+
+	utility.WriteSuccessResponse(w, created, "Geofence created successfully")
+}
+
+func (h *GeofenceHandler) GetGeofence(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetGeofence] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	geofence, err := h.geofenceService.GetGeofence(id, ownerID)
+	if err != nil {
+		h.logger.Errorf("[GetGeofence] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, geofence, "Geofence fetched successfully")
+}
+
+func (h *GeofenceHandler) ListGeofences(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListGeofences] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	geofences, err := h.geofenceService.ListGeofences(ownerID)
+	if err != nil {
+		h.logger.Errorf("[ListGeofences] error: %v", err)
+		utility.SendErrorResponse(w, "failed to list geofences", http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, geofences, "Geofences retrieved")
+}
+
+func (h *GeofenceHandler) UpdateGeofence(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[UpdateGeofence] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var req app.UpdateGeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[UpdateGeofence] decode error: %v", err)
+		utility.SendErrorResponse(w, "invalid input", http.StatusBadRequest, nil)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.logger.Warnf("[UpdateGeofence] validation error: %v", err)
+		utility.SendErrorResponse(w, err, http.StatusBadRequest, nil)
+		return
+	}
+
+	updated, err := h.geofenceService.UpdateGeofence(id, ownerID, req)
+	if err != nil {
+		h.logger.Errorf("[UpdateGeofence] update error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, updated, "Geofence updated successfully")
+}
+
+func (h *GeofenceHandler) DeleteGeofence(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[DeleteGeofence] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.geofenceService.DeleteGeofence(id, ownerID); err != nil {
+		h.logger.Errorf("[DeleteGeofence] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, id, "Geofence deleted successfully")
+}
+
+func (h *GeofenceHandler) ListGeofenceEvents(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListGeofenceEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[ListGeofenceEvents] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	events, err := h.geofenceService.ListGeofenceEvents(id, ownerID, from, to, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListGeofenceEvents] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), statusFor(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, events, "Geofence events fetched successfully")
+}
+
+func (h *GeofenceHandler) ListVehicleEvents(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListVehicleEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[ListVehicleEvents] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	events, err := h.geofenceService.ListVehicleEvents(vehicleID, ownerID, from, to, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListVehicleEvents] error: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, events, "Vehicle geofence events fetched successfully")
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrGeofenceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrGeofenceForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
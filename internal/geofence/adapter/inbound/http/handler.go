@@ -0,0 +1,67 @@
+package geofence_handler
+
+import (
+	"net/http"
+
+	inbound "FMTS/internal/geofence/port/inbound"
+	route "FMTS/internal/user/adapter"
+	"FMTS/internal/user/application/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func InitGeofenceRoutes(router chi.Router, geofenceHandler inbound.GeofencePortInterface, authMiddleware middleware.AuthMiddleware) {
+	router.Route("/geofences", func(r chi.Router) {
+		authenticated := []func(http.Handler) http.Handler{
+			authMiddleware.AuthenticateToken,
+			authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+		}
+
+		routes := []route.Route{
+			{
+				Method:      http.MethodPost,
+				Path:        "/",
+				Handler:     geofenceHandler.CreateGeofence,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/",
+				Handler:     geofenceHandler.ListGeofences,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/vehicles/{vehicle_id}/events",
+				Handler:     geofenceHandler.ListVehicleEvents,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/{id}",
+				Handler:     geofenceHandler.GetGeofence,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodPatch,
+				Path:        "/{id}",
+				Handler:     geofenceHandler.UpdateGeofence,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodDelete,
+				Path:        "/{id}",
+				Handler:     geofenceHandler.DeleteGeofence,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/{id}/events",
+				Handler:     geofenceHandler.ListGeofenceEvents,
+				Middlewares: authenticated,
+			},
+		}
+
+		route.RegisterRoutes(r, routes)
+	})
+}
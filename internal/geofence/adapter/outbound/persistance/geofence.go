@@ -0,0 +1,171 @@
+package geofence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "FMTS/internal/geofence/domain/entity"
+	geofenceOutboundPort "FMTS/internal/geofence/port/outbound"
+	dal "FMTS/internal/user/adapter/outbound/infra"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type GeofencePersistence struct {
+	geofenceDal dal.MongoDal[model.Geofence, model.Geofence]
+	eventDal    dal.MongoDal[model.GeofenceEvent, model.GeofenceEvent]
+	stateDal    dal.MongoDal[model.GeofenceState, model.GeofenceState]
+	logger      utils.Logger
+}
+
+var _ geofenceOutboundPort.GeofenceRepo = (*GeofencePersistence)(nil)
+
+func InitGeofenceRepo(client *mongo.Client, dbName string, geofenceCollection, eventCollection, stateCollection string, logger utils.Logger) geofenceOutboundPort.GeofenceRepo {
+	return &GeofencePersistence{
+		geofenceDal: dal.NewMongoDal[model.Geofence, model.Geofence](client, dbName, geofenceCollection),
+		eventDal:    dal.NewMongoDal[model.GeofenceEvent, model.GeofenceEvent](client, dbName, eventCollection),
+		stateDal:    dal.NewMongoDal[model.GeofenceState, model.GeofenceState](client, dbName, stateCollection),
+		logger:      logger,
+	}
+}
+
+func (g *GeofencePersistence) CreateGeofence(geofence model.Geofence) (*model.Geofence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := g.geofenceDal.InsertOne(ctx, geofence)
+	if err != nil {
+		g.logger.Errorf("[CreateGeofence] insert error: %v", err)
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (g *GeofencePersistence) FindByID(id string) (*model.Geofence, error) {
+	filter := bson.M{"_id": id, "is_deleted": false}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	geofence, err := g.geofenceDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		g.logger.Errorf("[FindByID] DB error: %v", err)
+		return nil, err
+	}
+	return geofence, nil
+}
+
+func (g *GeofencePersistence) FindByOwner(ownerID string) ([]*model.Geofence, error) {
+	filter := bson.M{"is_deleted": false}
+	if ownerID != "" {
+		filter["owner_id"] = ownerID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return g.geofenceDal.FindAll(ctx, filter, bson.M{})
+}
+
+func (g *GeofencePersistence) UpdateGeofence(geofence model.Geofence) (model.Geofence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": geofence.ID, "is_deleted": false}
+	update := bson.M{
+		"name":          geofence.Name,
+		"description":   geofence.Description,
+		"type":          geofence.Type,
+		"center":        geofence.Center,
+		"radius_meters": geofence.RadiusMeters,
+		"polygon":       geofence.Polygon,
+		"updated_at":    geofence.UpdatedAt,
+	}
+
+	updated, err := g.geofenceDal.UpdateOne(ctx, filter, update)
+	if err != nil {
+		g.logger.Errorf("[UpdateGeofence] update error: %v", err)
+		return model.Geofence{}, err
+	}
+	return updated, nil
+}
+
+func (g *GeofencePersistence) UpdateSoftDelete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	update := bson.M{"is_deleted": true, "updated_at": time.Now()}
+
+	if _, err := g.geofenceDal.UpdateOne(ctx, filter, update); err != nil {
+		g.logger.Errorf("[UpdateSoftDelete] error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GeofencePersistence) FindStates(vehicleID string) ([]*model.GeofenceState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return g.stateDal.FindAll(ctx, bson.M{"vehicle_id": vehicleID}, bson.M{})
+}
+
+func (g *GeofencePersistence) SaveState(state model.GeofenceState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := g.stateDal.Collection().ReplaceOne(ctx, bson.M{"_id": state.ID}, state, options.Replace().SetUpsert(true))
+	if err != nil {
+		g.logger.Errorf("[SaveState] upsert error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GeofencePersistence) CreateEvent(event model.GeofenceEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := g.eventDal.InsertOne(ctx, event); err != nil {
+		g.logger.Errorf("[CreateEvent] insert error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GeofencePersistence) FindEvents(query model.EventQuery) ([]*model.GeofenceEvent, error) {
+	filter := bson.M{
+		"timestamp": bson.M{"$gte": query.From, "$lt": query.To},
+	}
+	if query.OwnerID != "" {
+		filter["owner_id"] = query.OwnerID
+	}
+	if query.GeofenceID != "" {
+		filter["geofence_id"] = query.GeofenceID
+	}
+	if query.VehicleID != "" {
+		filter["vehicle_id"] = query.VehicleID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}).SetLimit(query.Limit)
+	cursor, err := g.eventDal.Collection().Find(ctx, filter, opts)
+	if err != nil {
+		g.logger.Errorf("[FindEvents] DB error: %v", err)
+		return nil, err
+	}
+
+	var events []*model.GeofenceEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,67 @@
+package geofence
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type CoordinateRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (c CoordinateRequest) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Latitude, validation.Min(-90.0), validation.Max(90.0)),
+		validation.Field(&c.Longitude, validation.Min(-180.0), validation.Max(180.0)),
+	)
+}
+
+type CreateGeofenceRequest struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description,omitempty"`
+	Type         string              `json:"type"`
+	Center       *CoordinateRequest  `json:"center,omitempty"`
+	RadiusMeters float64             `json:"radius_meters,omitempty"`
+	Polygon      []CoordinateRequest `json:"polygon,omitempty"`
+}
+
+func (r CreateGeofenceRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.Description, validation.Length(0, 500)),
+		validation.Field(&r.Type, validation.Required, validation.In("circle", "polygon")),
+		validation.Field(&r.Center, validation.When(r.Type == "circle", validation.Required)),
+		validation.Field(&r.RadiusMeters, validation.When(r.Type == "circle", validation.Required, validation.Min(1.0), validation.Max(100000.0))),
+		validation.Field(&r.Polygon, validation.When(r.Type == "polygon", validation.Required, validation.By(validatePolygon))),
+	)
+}
+
+type UpdateGeofenceRequest struct {
+	Name         *string             `json:"name,omitempty"`
+	Description  *string             `json:"description,omitempty"`
+	Center       *CoordinateRequest  `json:"center,omitempty"`
+	RadiusMeters *float64            `json:"radius_meters,omitempty"`
+	Polygon      []CoordinateRequest `json:"polygon,omitempty"`
+}
+
+func (r UpdateGeofenceRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.When(r.Name != nil, validation.Length(1, 100))),
+		validation.Field(&r.Description, validation.When(r.Description != nil, validation.Length(0, 500))),
+		validation.Field(&r.RadiusMeters, validation.When(r.RadiusMeters != nil, validation.Min(1.0), validation.Max(100000.0))),
+		validation.Field(&r.Polygon, validation.When(r.Polygon != nil, validation.By(validatePolygon))),
+	)
+}
+
+func validatePolygon(value interface{}) error {
+	ring, _ := value.([]CoordinateRequest)
+	if len(ring) < 3 {
+		return errors.New("polygon must have at least 3 points")
+	}
+	if len(ring) > 500 {
+		return errors.New("polygon must have at most 500 points")
+	}
+	return nil
+}
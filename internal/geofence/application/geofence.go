@@ -0,0 +1,187 @@
+package geofence
+
+import (
+	"errors"
+	"time"
+
+	model "FMTS/internal/geofence/domain/entity"
+	domain "FMTS/internal/geofence/domain/service"
+	"FMTS/pkg/utils"
+)
+
+const (
+	DefaultEventLimit = 500
+	MaxEventLimit     = 5000
+)
+
+var ErrGeofenceForbidden = errors.New("geofence belongs to another owner")
+
+// GeofenceService defines business use cases for Geofence. ownerID is the
+// caller's user ID, or empty for admins who may act on every geofence.
+type GeofenceService interface {
+	CreateGeofence(req CreateGeofenceRequest, ownerID string) (*model.Geofence, error)
+	GetGeofence(id, ownerID string) (*model.Geofence, error)
+	ListGeofences(ownerID string) ([]*model.Geofence, error)
+	UpdateGeofence(id, ownerID string, req UpdateGeofenceRequest) (*model.Geofence, error)
+	DeleteGeofence(id, ownerID string) error
+	ListGeofenceEvents(id, ownerID string, from, to time.Time, limit int64) ([]*model.GeofenceEvent, error)
+	ListVehicleEvents(vehicleID, ownerID string, from, to time.Time, limit int64) ([]*model.GeofenceEvent, error)
+}
+
+type geofenceServiceImpl struct {
+	domain domain.GeofenceService
+	logger utils.Logger
+}
+
+// Constructor
+func NewGeofenceService(domain domain.GeofenceService, logger utils.Logger) GeofenceService {
+	return &geofenceServiceImpl{
+		domain: domain,
+		logger: logger,
+	}
+}
+
+// CreateGeofence validates the request and persists a new zone for the owner
+func (s *geofenceServiceImpl) CreateGeofence(req CreateGeofenceRequest, ownerID string) (*model.Geofence, error) {
+	if err := req.Validate(); err != nil {
+		s.logger.Warnf("[CreateGeofence] validation failed: %v", err)
+		return nil, err
+	}
+
+	geofence := model.Geofence{
+		OwnerID:      ownerID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Type:         model.GeofenceType(req.Type),
+		RadiusMeters: req.RadiusMeters,
+	}
+	if geofence.Type == model.GeofenceTypeCircle {
+		geofence.Center = toCoordinate(req.Center)
+	} else {
+		geofence.Polygon = toCoordinates(req.Polygon)
+		geofence.RadiusMeters = 0
+	}
+
+	created, err := s.domain.CreateGeofence(geofence)
+	if err != nil {
+		s.logger.Errorf("[CreateGeofence] failed to save geofence: %v", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetGeofence fetches a geofence the caller is allowed to see
+func (s *geofenceServiceImpl) GetGeofence(id, ownerID string) (*model.Geofence, error) {
+	geofence, err := s.domain.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && geofence.OwnerID != ownerID {
+		return nil, ErrGeofenceForbidden
+	}
+	return geofence, nil
+}
+
+// ListGeofences returns the caller's geofences (every geofence for admins)
+func (s *geofenceServiceImpl) ListGeofences(ownerID string) ([]*model.Geofence, error) {
+	geofences, err := s.domain.FindByOwner(ownerID)
+	if err != nil {
+		s.logger.Errorf("[ListGeofences] error: %v", err)
+		return nil, err
+	}
+	return geofences, nil
+}
+
+// UpdateGeofence updates the name, description and shape of a geofence
+func (s *geofenceServiceImpl) UpdateGeofence(id, ownerID string, req UpdateGeofenceRequest) (*model.Geofence, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	geofence, err := s.GetGeofence(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		geofence.Name = *req.Name
+	}
+	if req.Description != nil {
+		geofence.Description = *req.Description
+	}
+	if geofence.Type == model.GeofenceTypeCircle {
+		if req.Center != nil {
+			geofence.Center = toCoordinate(req.Center)
+		}
+		if req.RadiusMeters != nil {
+			geofence.RadiusMeters = *req.RadiusMeters
+		}
+	}
+	if geofence.Type == model.GeofenceTypePolygon && req.Polygon != nil {
+		geofence.Polygon = toCoordinates(req.Polygon)
+	}
+
+	updated, err := s.domain.UpdateGeofence(*geofence)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteGeofence marks a geofence as deleted (soft delete)
+func (s *geofenceServiceImpl) DeleteGeofence(id, ownerID string) error {
+	if _, err := s.GetGeofence(id, ownerID); err != nil {
+		return err
+	}
+	return s.domain.UpdateSoftDelete(id)
+}
+
+// ListGeofenceEvents returns the enter/exit events recorded for one geofence
+func (s *geofenceServiceImpl) ListGeofenceEvents(id, ownerID string, from, to time.Time, limit int64) ([]*model.GeofenceEvent, error) {
+	if _, err := s.GetGeofence(id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.domain.FindEvents(model.EventQuery{
+		OwnerID:    ownerID,
+		GeofenceID: id,
+		From:       from,
+		To:         to,
+		Limit:      clampEventLimit(limit),
+	})
+}
+
+// ListVehicleEvents returns the enter/exit events recorded for one vehicle
+func (s *geofenceServiceImpl) ListVehicleEvents(vehicleID, ownerID string, from, to time.Time, limit int64) ([]*model.GeofenceEvent, error) {
+	return s.domain.FindEvents(model.EventQuery{
+		OwnerID:   ownerID,
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		Limit:     clampEventLimit(limit),
+	})
+}
+
+func clampEventLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultEventLimit
+	}
+	if limit > MaxEventLimit {
+		return MaxEventLimit
+	}
+	return limit
+}
+
+func toCoordinate(c *CoordinateRequest) *model.Coordinate {
+	if c == nil {
+		return nil
+	}
+	return &model.Coordinate{Latitude: c.Latitude, Longitude: c.Longitude}
+}
+
+func toCoordinates(ring []CoordinateRequest) []model.Coordinate {
+	coords := make([]model.Coordinate, len(ring))
+	for i, c := range ring {
+		coords[i] = model.Coordinate{Latitude: c.Latitude, Longitude: c.Longitude}
+	}
+	return coords
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// GeofenceType custom string type with predefined values
+type GeofenceType string
+
+const (
+	GeofenceTypeCircle  GeofenceType = "circle"
+	GeofenceTypePolygon GeofenceType = "polygon"
+)
+
+type Coordinate struct {
+	Latitude  float64 `bson:"latitude" json:"latitude"`
+	Longitude float64 `bson:"longitude" json:"longitude"`
+}
+
+// Geofence is a named zone owned by a user or fleet manager. Circles use
+// Center and RadiusMeters, polygons use Polygon.
+type Geofence struct {
+	ID           string       `bson:"_id,omitempty" json:"id"`
+	OwnerID      string       `bson:"owner_id" json:"owner_id"`
+	Name         string       `bson:"name" json:"name"`
+	Description  string       `bson:"description,omitempty" json:"description,omitempty"`
+	Type         GeofenceType `bson:"type" json:"type"`
+	Center       *Coordinate  `bson:"center,omitempty" json:"center,omitempty"`
+	RadiusMeters float64      `bson:"radius_meters,omitempty" json:"radius_meters,omitempty"`
+	Polygon      []Coordinate `bson:"polygon,omitempty" json:"polygon,omitempty"`
+	IsDeleted    bool         `bson:"is_deleted" json:"is_deleted"`
+	CreatedAt    time.Time    `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time    `bson:"updated_at" json:"updated_at"`
+}
+
+// GeofenceEventType custom string type with predefined values
+type GeofenceEventType string
+
+const (
+	GeofenceEventEnter GeofenceEventType = "enter"
+	GeofenceEventExit  GeofenceEventType = "exit"
+)
+
+// GeofenceEvent records a vehicle crossing a geofence boundary.
+type GeofenceEvent struct {
+	ID           string            `bson:"_id,omitempty" json:"id"`
+	GeofenceID   string            `bson:"geofence_id" json:"geofence_id"`
+	GeofenceName string            `bson:"geofence_name" json:"geofence_name"`
+	OwnerID      string            `bson:"owner_id" json:"owner_id"`
+	VehicleID    string            `bson:"vehicle_id" json:"vehicle_id"`
+	EventType    GeofenceEventType `bson:"event_type" json:"event_type"`
+	Latitude     float64           `bson:"latitude" json:"latitude"`
+	Longitude    float64           `bson:"longitude" json:"longitude"`
+	Timestamp    time.Time         `bson:"timestamp" json:"timestamp"`
+	CreatedAt    time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// GeofenceState is the last known inside/outside state of a vehicle for a
+// geofence, used to turn point checks into enter/exit transitions.
+type GeofenceState struct {
+	ID         string    `bson:"_id" json:"id"`
+	GeofenceID string    `bson:"geofence_id" json:"geofence_id"`
+	VehicleID  string    `bson:"vehicle_id" json:"vehicle_id"`
+	Inside     bool      `bson:"inside" json:"inside"`
+	Timestamp  time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+// LocationFix is the subset of a tracked location geofences are evaluated on.
+type LocationFix struct {
+	OwnerID   string
+	VehicleID string
+	Latitude  float64
+	Longitude float64
+	Timestamp time.Time
+}
+
+// EventQuery filters geofence events. Empty fields are not filtered on.
+type EventQuery struct {
+	OwnerID    string
+	GeofenceID string
+	VehicleID  string
+	From       time.Time
+	To         time.Time
+	Limit      int64
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	model "FMTS/internal/geofence/domain/entity"
+	"FMTS/internal/geofence/domain/repository"
+	"FMTS/pkg/geo"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrGeofenceNotFound = errors.New("geofence not found or deleted")
+
+type GeofenceDomain struct {
+	geofenceRepo repository.GeofenceRepo
+	logger       utils.Logger
+}
+
+func NewGeofenceDomainService(repo repository.GeofenceRepo, logger utils.Logger) GeofenceService {
+	return &GeofenceDomain{
+		geofenceRepo: repo,
+		logger:       logger,
+	}
+}
+
+type GeofenceService interface {
+	CreateGeofence(geofence model.Geofence) (*model.Geofence, error)
+	FindByID(id string) (*model.Geofence, error)
+	FindByOwner(ownerID string) ([]*model.Geofence, error)
+	UpdateGeofence(geofence model.Geofence) (model.Geofence, error)
+	UpdateSoftDelete(id string) error
+	EvaluateLocation(fix model.LocationFix) ([]*model.GeofenceEvent, error)
+	FindEvents(query model.EventQuery) ([]*model.GeofenceEvent, error)
+}
+
+// Create a new geofence
+func (g *GeofenceDomain) CreateGeofence(geofence model.Geofence) (*model.Geofence, error) {
+	geofence.ID = primitive.NewObjectID().Hex()
+	geofence.CreatedAt = time.Now()
+	geofence.UpdatedAt = time.Now()
+	geofence.IsDeleted = false
+
+	created, err := g.geofenceRepo.CreateGeofence(geofence)
+	if err != nil {
+		g.logger.Errorf("[CreateGeofence] failed to create geofence: %v", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+// Find geofence by ID
+func (g *GeofenceDomain) FindByID(id string) (*model.Geofence, error) {
+	geofence, err := g.geofenceRepo.FindByID(id)
+	if err != nil {
+		g.logger.Errorf("[FindByID] error: %v", err)
+		return nil, ErrGeofenceNotFound
+	}
+	if geofence == nil || geofence.IsDeleted {
+		return nil, ErrGeofenceNotFound
+	}
+	return geofence, nil
+}
+
+// List the geofences of an owner
+func (g *GeofenceDomain) FindByOwner(ownerID string) ([]*model.Geofence, error) {
+	geofences, err := g.geofenceRepo.FindByOwner(ownerID)
+	if err != nil {
+		g.logger.Errorf("[FindByOwner] error: %v", err)
+		return nil, err
+	}
+	return geofences, nil
+}
+
+// Update existing geofence
+func (g *GeofenceDomain) UpdateGeofence(geofence model.Geofence) (model.Geofence, error) {
+	geofence.UpdatedAt = time.Now()
+
+	updated, err := g.geofenceRepo.UpdateGeofence(geofence)
+	if err != nil {
+		g.logger.Errorf("[UpdateGeofence] error updating geofence: %v", err)
+		return model.Geofence{}, err
+	}
+	return updated, nil
+}
+
+// Soft delete geofence
+func (g *GeofenceDomain) UpdateSoftDelete(id string) error {
+	if _, err := g.FindByID(id); err != nil {
+		return err
+	}
+	if err := g.geofenceRepo.UpdateSoftDelete(id); err != nil {
+		g.logger.Errorf("[UpdateSoftDelete] update error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// EvaluateLocation checks a fix against every geofence of its owner and
+// records an enter/exit event for each zone whose inside/outside state
+// changed since the vehicle's previous fix. A vehicle seen inside a zone for
+// the first time produces an enter event.
+func (g *GeofenceDomain) EvaluateLocation(fix model.LocationFix) ([]*model.GeofenceEvent, error) {
+	geofences, err := g.geofenceRepo.FindByOwner(fix.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load geofences: %w", err)
+	}
+	if len(geofences) == 0 {
+		return nil, nil
+	}
+
+	states, err := g.geofenceRepo.FindStates(fix.VehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load geofence states: %w", err)
+	}
+	previous := make(map[string]*model.GeofenceState, len(states))
+	for _, state := range states {
+		previous[state.GeofenceID] = state
+	}
+
+	var events []*model.GeofenceEvent
+	for _, geofence := range geofences {
+		inside := Contains(*geofence, fix.Latitude, fix.Longitude)
+		prev, seen := previous[geofence.ID]
+
+		if seen && fix.Timestamp.Before(prev.Timestamp) {
+			// Late fixes must not flip the state back in time.
+			continue
+		}
+		if seen && prev.Inside == inside {
+			continue
+		}
+
+		if err := g.geofenceRepo.SaveState(model.GeofenceState{
+			ID:         stateID(fix.VehicleID, geofence.ID),
+			GeofenceID: geofence.ID,
+			VehicleID:  fix.VehicleID,
+			Inside:     inside,
+			Timestamp:  fix.Timestamp,
+		}); err != nil {
+			return events, fmt.Errorf("failed to save geofence state: %w", err)
+		}
+
+		if !seen && !inside {
+			continue
+		}
+
+		eventType := model.GeofenceEventExit
+		if inside {
+			eventType = model.GeofenceEventEnter
+		}
+		event := model.GeofenceEvent{
+			ID:           primitive.NewObjectID().Hex(),
+			GeofenceID:   geofence.ID,
+			GeofenceName: geofence.Name,
+			OwnerID:      fix.OwnerID,
+			VehicleID:    fix.VehicleID,
+			EventType:    eventType,
+			Latitude:     fix.Latitude,
+			Longitude:    fix.Longitude,
+			Timestamp:    fix.Timestamp,
+			CreatedAt:    time.Now(),
+		}
+		if err := g.geofenceRepo.CreateEvent(event); err != nil {
+			return events, fmt.Errorf("failed to save geofence event: %w", err)
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+// List geofence events
+func (g *GeofenceDomain) FindEvents(query model.EventQuery) ([]*model.GeofenceEvent, error) {
+	events, err := g.geofenceRepo.FindEvents(query)
+	if err != nil {
+		g.logger.Errorf("[FindEvents] error: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// Contains reports whether the coordinate lies inside the geofence.
+func Contains(geofence model.Geofence, latitude, longitude float64) bool {
+	switch geofence.Type {
+	case model.GeofenceTypeCircle:
+		if geofence.Center == nil {
+			return false
+		}
+		return geo.HaversineMeters(geofence.Center.Latitude, geofence.Center.Longitude, latitude, longitude) <= geofence.RadiusMeters
+	case model.GeofenceTypePolygon:
+		ring := make([]geo.Point, len(geofence.Polygon))
+		for i, c := range geofence.Polygon {
+			ring[i] = geo.Point{Latitude: c.Latitude, Longitude: c.Longitude}
+		}
+		return geo.PointInPolygon(geo.Point{Latitude: latitude, Longitude: longitude}, ring)
+	}
+	return false
+}
+
+func stateID(vehicleID, geofenceID string) string {
+	return vehicleID + ":" + geofenceID
+}
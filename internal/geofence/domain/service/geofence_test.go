@@ -0,0 +1,256 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	model "FMTS/internal/geofence/domain/entity"
+	"FMTS/pkg/geo"
+	"FMTS/utils"
+)
+
+var (
+	depotCentre = geo.Point{Latitude: 9.0108, Longitude: 38.7613}
+	testStart   = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+)
+
+// near returns the coordinate the given metres north and east of the depot.
+func near(north, east float64) geo.Point {
+	return geo.Offset(depotCentre, north, east)
+}
+
+func coordinate(p geo.Point) model.Coordinate {
+	return model.Coordinate{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+func circleGeofence(id string, radius float64) *model.Geofence {
+	centre := coordinate(depotCentre)
+	return &model.Geofence{ID: id, OwnerID: "owner1", Name: id, Type: model.GeofenceTypeCircle, Center: &centre, RadiusMeters: radius}
+}
+
+// squareGeofence is a square of 200 m sides centred on the depot.
+func squareGeofence(id string) *model.Geofence {
+	return &model.Geofence{ID: id, OwnerID: "owner1", Name: id, Type: model.GeofenceTypePolygon, Polygon: []model.Coordinate{
+		coordinate(near(-100, -100)),
+		coordinate(near(-100, 100)),
+		coordinate(near(100, 100)),
+		coordinate(near(100, -100)),
+	}}
+}
+
+func TestContains(t *testing.T) {
+	square := squareGeofence("square")
+	// A strip across the antimeridian, from 179.5 E to 179.5 W.
+	strip := &model.Geofence{Type: model.GeofenceTypePolygon, Polygon: []model.Coordinate{
+		{Latitude: -17, Longitude: 179.5},
+		{Latitude: -17, Longitude: -179.5},
+		{Latitude: -16, Longitude: -179.5},
+		{Latitude: -16, Longitude: 179.5},
+	}}
+
+	cases := []struct {
+		name     string
+		geofence *model.Geofence
+		point    geo.Point
+		want     bool
+	}{
+		{"circle centre", circleGeofence("c", 100), depotCentre, true},
+		{"circle just inside", circleGeofence("c", 100), near(99.5, 0), true},
+		{"circle just outside", circleGeofence("c", 100), near(100.5, 0), false},
+		{"circle without centre", &model.Geofence{Type: model.GeofenceTypeCircle, RadiusMeters: 100}, depotCentre, false},
+		{"polygon centre", square, depotCentre, true},
+		{"polygon outside", square, near(150, 0), false},
+		{"polygon on an edge", square, geo.Point{Latitude: square.Polygon[0].Latitude, Longitude: depotCentre.Longitude}, true},
+		{"polygon on a vertex", square, geo.Point{Latitude: square.Polygon[2].Latitude, Longitude: square.Polygon[2].Longitude}, true},
+		{"polygon closed ring", &model.Geofence{Type: model.GeofenceTypePolygon, Polygon: append(append([]model.Coordinate(nil), square.Polygon...), square.Polygon[0])}, depotCentre, true},
+		{"antimeridian east side", strip, geo.Point{Latitude: -16.5, Longitude: 179.9}, true},
+		{"antimeridian west side", strip, geo.Point{Latitude: -16.5, Longitude: -179.9}, true},
+		{"antimeridian outside", strip, geo.Point{Latitude: -16.5, Longitude: 179}, false},
+		{"antimeridian far side of the globe", strip, geo.Point{Latitude: -16.5, Longitude: 0}, false},
+		{"unknown type", &model.Geofence{Type: "square"}, depotCentre, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Contains(*tc.geofence, tc.point.Latitude, tc.point.Longitude); got != tc.want {
+				t.Errorf("Contains = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// memoryGeofenceRepo keeps geofences, states and events in memory.
+type memoryGeofenceRepo struct {
+	geofences []*model.Geofence
+	states    map[string]model.GeofenceState
+	events    []model.GeofenceEvent
+}
+
+func newMemoryGeofenceRepo(geofences ...*model.Geofence) *memoryGeofenceRepo {
+	return &memoryGeofenceRepo{geofences: geofences, states: make(map[string]model.GeofenceState)}
+}
+
+func (r *memoryGeofenceRepo) CreateGeofence(geofence model.Geofence) (*model.Geofence, error) {
+	r.geofences = append(r.geofences, &geofence)
+	return &geofence, nil
+}
+
+func (r *memoryGeofenceRepo) FindByID(id string) (*model.Geofence, error) {
+	for _, g := range r.geofences {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return nil, ErrGeofenceNotFound
+}
+
+func (r *memoryGeofenceRepo) FindByOwner(ownerID string) ([]*model.Geofence, error) {
+	var owned []*model.Geofence
+	for _, g := range r.geofences {
+		if g.OwnerID == ownerID && !g.IsDeleted {
+			owned = append(owned, g)
+		}
+	}
+	return owned, nil
+}
+
+func (r *memoryGeofenceRepo) UpdateGeofence(geofence model.Geofence) (model.Geofence, error) {
+	return geofence, nil
+}
+
+func (r *memoryGeofenceRepo) UpdateSoftDelete(id string) error {
+	return nil
+}
+
+func (r *memoryGeofenceRepo) FindStates(vehicleID string) ([]*model.GeofenceState, error) {
+	var states []*model.GeofenceState
+	for _, s := range r.states {
+		if s.VehicleID == vehicleID {
+			states = append(states, &s)
+		}
+	}
+	return states, nil
+}
+
+func (r *memoryGeofenceRepo) SaveState(state model.GeofenceState) error {
+	r.states[state.ID] = state
+	return nil
+}
+
+func (r *memoryGeofenceRepo) CreateEvent(event model.GeofenceEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryGeofenceRepo) FindEvents(query model.EventQuery) ([]*model.GeofenceEvent, error) {
+	return nil, nil
+}
+
+func TestEvaluateLocation(t *testing.T) {
+	type fix struct {
+		seconds     int
+		north, east float64
+	}
+	type event struct {
+		geofence string
+		kind     model.GeofenceEventType
+		seconds  int
+	}
+
+	cases := []struct {
+		name  string
+		fixes []fix
+		want  []event
+	}{
+		{
+			name:  "arrive and leave",
+			fixes: []fix{{0, 500, 0}, {60, 300, 0}, {120, 50, 0}, {180, 0, 0}, {240, 300, 0}},
+			want: []event{
+				{"depot", model.GeofenceEventEnter, 120},
+				{"square", model.GeofenceEventEnter, 120},
+				{"depot", model.GeofenceEventExit, 240},
+				{"square", model.GeofenceEventExit, 240},
+			},
+		},
+		{
+			name:  "first fix inside",
+			fixes: []fix{{0, 0, 0}, {60, 10, 0}},
+			want: []event{
+				{"depot", model.GeofenceEventEnter, 0},
+				{"square", model.GeofenceEventEnter, 0},
+			},
+		},
+		{
+			name:  "first fix outside",
+			fixes: []fix{{0, 500, 0}, {60, 400, 0}},
+		},
+		{
+			name:  "inside the circle only",
+			fixes: []fix{{0, 500, 0}, {60, 0, 140}, {120, 500, 0}},
+			want: []event{
+				{"depot", model.GeofenceEventEnter, 60},
+				{"depot", model.GeofenceEventExit, 120},
+			},
+		},
+		{
+			name:  "late fix does not flip the state back",
+			fixes: []fix{{0, 500, 0}, {120, 0, 0}, {60, 500, 0}, {180, 10, 0}},
+			want: []event{
+				{"depot", model.GeofenceEventEnter, 120},
+				{"square", model.GeofenceEventEnter, 120},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newMemoryGeofenceRepo(circleGeofence("depot", 150), squareGeofence("square"))
+			g := NewGeofenceDomainService(repo, utils.NewLogger())
+
+			for _, f := range tc.fixes {
+				p := near(f.north, f.east)
+				if _, err := g.EvaluateLocation(model.LocationFix{
+					OwnerID:   "owner1",
+					VehicleID: "v1",
+					Latitude:  p.Latitude,
+					Longitude: p.Longitude,
+					Timestamp: testStart.Add(time.Duration(f.seconds) * time.Second),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got []event
+			for _, e := range repo.events {
+				got = append(got, event{e.GeofenceID, e.EventType, int(e.Timestamp.Sub(testStart).Seconds())})
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("events %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("event %d = %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateLocationIgnoresOtherOwners(t *testing.T) {
+	repo := newMemoryGeofenceRepo(circleGeofence("depot", 150))
+	g := NewGeofenceDomainService(repo, utils.NewLogger())
+
+	events, err := g.EvaluateLocation(model.LocationFix{
+		OwnerID:   "owner2",
+		VehicleID: "v2",
+		Latitude:  depotCentre.Latitude,
+		Longitude: depotCentre.Longitude,
+		Timestamp: testStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 || len(repo.states) != 0 {
+		t.Fatalf("events %d, states %d; want none for another owner's geofence", len(events), len(repo.states))
+	}
+}
@@ -0,0 +1,13 @@
+package inbound
+
+import "net/http"
+
+type GeofencePortInterface interface {
+	CreateGeofence(w http.ResponseWriter, r *http.Request)
+	GetGeofence(w http.ResponseWriter, r *http.Request)
+	ListGeofences(w http.ResponseWriter, r *http.Request)
+	UpdateGeofence(w http.ResponseWriter, r *http.Request)
+	DeleteGeofence(w http.ResponseWriter, r *http.Request)
+	ListGeofenceEvents(w http.ResponseWriter, r *http.Request)
+	ListVehicleEvents(w http.ResponseWriter, r *http.Request)
+}
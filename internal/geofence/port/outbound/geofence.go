@@ -0,0 +1,18 @@
+package repository
+
+import (
+	model "FMTS/internal/geofence/domain/entity"
+)
+
+// GeofenceRepo abstracts database operations for geofences and their events
+type GeofenceRepo interface {
+	CreateGeofence(geofence model.Geofence) (*model.Geofence, error)
+	FindByID(id string) (*model.Geofence, error)
+	FindByOwner(ownerID string) ([]*model.Geofence, error)
+	UpdateGeofence(geofence model.Geofence) (model.Geofence, error)
+	UpdateSoftDelete(id string) error
+	FindStates(vehicleID string) ([]*model.GeofenceState, error)
+	SaveState(state model.GeofenceState) error
+	CreateEvent(event model.GeofenceEvent) error
+	FindEvents(query model.EventQuery) ([]*model.GeofenceEvent, error)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"FMTS/pkg/utils"
+	common "FMTS/utils"
+	constant "FMTS/utils"
+)
+
+// RoleDevice is the role of requests authenticated with a device credential.
+const RoleDevice = "DEVICE"
+
+// ErrDeviceRejected wraps the reasons a DeviceAuthenticator refuses a
+// credential, as opposed to failing to check it.
+var ErrDeviceRejected = errors.New("device credential rejected")
+
+// DeviceIdentity is what a device credential authenticates as.
+type DeviceIdentity struct {
+	DeviceID  string
+	OwnerID   string
+	VehicleID string
+}
+
+// DeviceAuthenticator verifies device API credentials. ok is false when
+// the token is not a device credential.
+type DeviceAuthenticator interface {
+	AuthenticateDevice(token string) (identity DeviceIdentity, ok bool, err error)
+}
+
+// AuthenticateDeviceOrToken accepts a device credential in place of a user
+// JWT. A device acts as its owner with the DEVICE role, and its bound
+// vehicle is put in the context next to the device ID. Any other bearer
+// token goes through AuthenticateToken.
+func AuthenticateDeviceOrToken(devices DeviceAuthenticator, auth AuthMiddleware, logger utils.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fallback := auth.AuthenticateToken(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			identity, ok, err := devices.AuthenticateDevice(tokenString)
+			if !ok {
+				fallback.ServeHTTP(w, r)
+				return
+			}
+			if errors.Is(err, ErrDeviceRejected) {
+				logger.Warnf("device token refused: %v", err)
+				common.SendErrorResponse(w, "invalid, revoked or inactive device credential", http.StatusUnauthorized, nil)
+				return
+			}
+			if err != nil {
+				logger.Errorf("device token check failed: %v", err)
+				common.SendErrorResponse(w, "failed to verify device credential", http.StatusInternalServerError, nil)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), constant.ContextKey("user_id"), identity.OwnerID)
+			ctx = context.WithValue(ctx, constant.ContextKey("user_role"), RoleDevice)
+			ctx = context.WithValue(ctx, constant.ContextKey("device_id"), identity.DeviceID)
+			ctx = context.WithValue(ctx, constant.ContextKey("vehicle_id"), identity.VehicleID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
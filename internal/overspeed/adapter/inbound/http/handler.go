@@ -0,0 +1,37 @@
+package overspeed_handler
+
+import (
+	"net/http"
+
+	inbound "FMTS/internal/overspeed/port/inbound"
+	route "FMTS/internal/user/adapter"
+	"FMTS/internal/user/application/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func InitOverspeedRoutes(router chi.Router, overspeedHandler inbound.OverspeedPortInterface, authMiddleware middleware.AuthMiddleware) {
+	router.Route("/overspeed", func(r chi.Router) {
+		authenticated := []func(http.Handler) http.Handler{
+			authMiddleware.AuthenticateToken,
+			authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+		}
+
+		routes := []route.Route{
+			{
+				Method:      http.MethodGet,
+				Path:        "/events",
+				Handler:     overspeedHandler.ListOwnerEvents,
+				Middlewares: authenticated,
+			},
+			{
+				Method:      http.MethodGet,
+				Path:        "/vehicles/{vehicle_id}/events",
+				Handler:     overspeedHandler.ListVehicleEvents,
+				Middlewares: authenticated,
+			},
+		}
+
+		route.RegisterRoutes(r, routes)
+	})
+}
@@ -0,0 +1,94 @@
+package overspeed_handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	app "FMTS/internal/overspeed/application"
+	port "FMTS/internal/overspeed/port/inbound"
+	contexts "FMTS/pkg/context"
+	"FMTS/pkg/utils"
+	utility "FMTS/utils"
+)
+
+type OverspeedHandler struct {
+	overspeedService app.OverspeedService
+	logger           utils.Logger
+}
+
+func NewOverspeedHandler(service app.OverspeedService, logger utils.Logger) port.OverspeedPortInterface {
+	return &OverspeedHandler{
+		overspeedService: service,
+		logger:           logger,
+	}
+}
+
+func (h *OverspeedHandler) ListVehicleEvents(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListVehicleEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[ListVehicleEvents] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	events, err := h.overspeedService.ListVehicleEvents(vehicleID, ownerID, from, to, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListVehicleEvents] error: %v", err)
+		utility.SendErrorResponse(w, "failed to fetch overspeed events", http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, events, "Vehicle overspeed events fetched successfully")
+}
+
+// ListOwnerEvents lists the caller's overspeed events. Admins see every
+// owner's events, or one owner's when owner_id is given.
+func (h *OverspeedHandler) ListOwnerEvents(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListOwnerEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[ListOwnerEvents] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	events, err := h.overspeedService.ListOwnerEvents(ownerID, from, to, int64(limit))
+	if err != nil {
+		h.logger.Errorf("[ListOwnerEvents] error: %v", err)
+		utility.SendErrorResponse(w, "failed to fetch overspeed events", http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, events, "Overspeed events fetched successfully")
+}
@@ -0,0 +1,110 @@
+package overspeed
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "FMTS/internal/overspeed/domain/entity"
+	overspeedOutboundPort "FMTS/internal/overspeed/port/outbound"
+	dal "FMTS/internal/user/adapter/outbound/infra"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OverspeedPersistence struct {
+	eventDal dal.MongoDal[model.OverspeedEvent, model.OverspeedEvent]
+	logger   utils.Logger
+}
+
+var _ overspeedOutboundPort.OverspeedRepo = (*OverspeedPersistence)(nil)
+
+func InitOverspeedRepo(client *mongo.Client, dbName string, collection string, logger utils.Logger) overspeedOutboundPort.OverspeedRepo {
+	return &OverspeedPersistence{
+		eventDal: dal.NewMongoDal[model.OverspeedEvent, model.OverspeedEvent](client, dbName, collection),
+		logger:   logger,
+	}
+}
+
+func (o *OverspeedPersistence) FindOngoing(vehicleID string) (*model.OverspeedEvent, error) {
+	filter := bson.M{"vehicle_id": vehicleID, "status": model.EpisodeOngoing}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event, err := o.eventDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		o.logger.Errorf("[FindOngoing] DB error: %v", err)
+		return nil, err
+	}
+	return event, nil
+}
+
+// FindStale returns the ongoing episodes whose last fix is older than
+// lastSeenBefore.
+func (o *OverspeedPersistence) FindStale(lastSeenBefore time.Time) ([]*model.OverspeedEvent, error) {
+	filter := bson.M{"status": model.EpisodeOngoing, "last_seen_at": bson.M{"$lt": lastSeenBefore}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := o.eventDal.Collection().Find(ctx, filter)
+	if err != nil {
+		o.logger.Errorf("[FindStale] DB error: %v", err)
+		return nil, err
+	}
+
+	var events []*model.OverspeedEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (o *OverspeedPersistence) SaveEvent(event model.OverspeedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := o.eventDal.Collection().ReplaceOne(ctx, bson.M{"_id": event.ID}, event, options.Replace().SetUpsert(true))
+	if err != nil {
+		o.logger.Errorf("[SaveEvent] upsert error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (o *OverspeedPersistence) FindEvents(query model.EventQuery) ([]*model.OverspeedEvent, error) {
+	filter := bson.M{
+		"start_time": bson.M{"$lt": query.To},
+		"$or": []bson.M{
+			{"status": model.EpisodeOngoing},
+			{"end_time": bson.M{"$gte": query.From}},
+		},
+	}
+	if query.OwnerID != "" {
+		filter["owner_id"] = query.OwnerID
+	}
+	if query.VehicleID != "" {
+		filter["vehicle_id"] = query.VehicleID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}}).SetLimit(query.Limit)
+	cursor, err := o.eventDal.Collection().Find(ctx, filter, opts)
+	if err != nil {
+		o.logger.Errorf("[FindEvents] DB error: %v", err)
+		return nil, err
+	}
+
+	var events []*model.OverspeedEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
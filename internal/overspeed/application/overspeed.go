@@ -0,0 +1,75 @@
+package overspeed
+
+import (
+	"time"
+
+	model "FMTS/internal/overspeed/domain/entity"
+	domain "FMTS/internal/overspeed/domain/service"
+	"FMTS/pkg/utils"
+)
+
+const (
+	DefaultEventLimit = 500
+	MaxEventLimit     = 5000
+)
+
+// OverspeedService defines the read use cases for overspeed events. ownerID
+// is the caller's user ID, or empty for admins who may see every owner.
+type OverspeedService interface {
+	ListVehicleEvents(vehicleID, ownerID string, from, to time.Time, limit int64) ([]*model.OverspeedEvent, error)
+	ListOwnerEvents(ownerID string, from, to time.Time, limit int64) ([]*model.OverspeedEvent, error)
+}
+
+type overspeedServiceImpl struct {
+	domain domain.OverspeedService
+	logger utils.Logger
+}
+
+// Constructor
+func NewOverspeedService(domain domain.OverspeedService, logger utils.Logger) OverspeedService {
+	return &overspeedServiceImpl{
+		domain: domain,
+		logger: logger,
+	}
+}
+
+// ListVehicleEvents returns the overspeed episodes of one vehicle
+func (s *overspeedServiceImpl) ListVehicleEvents(vehicleID, ownerID string, from, to time.Time, limit int64) ([]*model.OverspeedEvent, error) {
+	events, err := s.domain.FindEvents(model.EventQuery{
+		OwnerID:   ownerID,
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		Limit:     clampEventLimit(limit),
+	})
+	if err != nil {
+		s.logger.Errorf("[ListVehicleEvents] failed: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListOwnerEvents returns the overspeed episodes of every vehicle of an owner
+func (s *overspeedServiceImpl) ListOwnerEvents(ownerID string, from, to time.Time, limit int64) ([]*model.OverspeedEvent, error) {
+	events, err := s.domain.FindEvents(model.EventQuery{
+		OwnerID: ownerID,
+		From:    from,
+		To:      to,
+		Limit:   clampEventLimit(limit),
+	})
+	if err != nil {
+		s.logger.Errorf("[ListOwnerEvents] failed: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+func clampEventLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultEventLimit
+	}
+	if limit > MaxEventLimit {
+		return MaxEventLimit
+	}
+	return limit
+}
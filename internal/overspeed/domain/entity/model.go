@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// EpisodeStatus custom string type with predefined values
+type EpisodeStatus string
+
+const (
+	EpisodeOngoing EpisodeStatus = "ongoing"
+	EpisodeClosed  EpisodeStatus = "closed"
+)
+
+type Coordinate struct {
+	Latitude  float64 `bson:"latitude" json:"latitude"`
+	Longitude float64 `bson:"longitude" json:"longitude"`
+}
+
+// OverspeedEvent is one continuous episode of a vehicle driving above its
+// speed limit. The episode stays ongoing until a fix at or below the limit
+// arrives, or the vehicle stops reporting for longer than the max gap.
+type OverspeedEvent struct {
+	ID              string        `bson:"_id,omitempty" json:"id"`
+	OwnerID         string        `bson:"owner_id" json:"owner_id"`
+	VehicleID       string        `bson:"vehicle_id" json:"vehicle_id"`
+	Status          EpisodeStatus `bson:"status" json:"status"`
+	SpeedLimit      float64       `bson:"speed_limit" json:"speed_limit"`
+	PeakSpeed       float64       `bson:"peak_speed" json:"peak_speed"`
+	StartTime       time.Time     `bson:"start_time" json:"start_time"`
+	StartLocation   Coordinate    `bson:"start_location" json:"start_location"`
+	PeakTime        time.Time     `bson:"peak_time" json:"peak_time"`
+	PeakLocation    Coordinate    `bson:"peak_location" json:"peak_location"`
+	EndTime         *time.Time    `bson:"end_time,omitempty" json:"end_time,omitempty"`
+	EndLocation     *Coordinate   `bson:"end_location,omitempty" json:"end_location,omitempty"`
+	DurationSeconds int64         `bson:"duration_seconds" json:"duration_seconds"`
+	LastSeenAt      time.Time     `bson:"last_seen_at" json:"last_seen_at"`
+	LastLocation    Coordinate    `bson:"last_location" json:"last_location"`
+	CreatedAt       time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// SpeedFix is a tracked location together with the speed limit that applied
+// to the vehicle when it was recorded.
+type SpeedFix struct {
+	OwnerID    string
+	VehicleID  string
+	Latitude   float64
+	Longitude  float64
+	Speed      float64
+	SpeedLimit float64
+	Timestamp  time.Time
+}
+
+// Config tunes episode detection.
+type Config struct {
+	// Tolerance is added to the limit before a fix counts as overspeeding,
+	// to absorb GPS speed noise (km/h).
+	Tolerance float64
+	// MaxGap closes an ongoing episode when the vehicle has not reported
+	// for this long.
+	MaxGap time.Duration
+	// SaveInterval writes an ongoing episode at least this often, so its
+	// last fix survives a restart. Zero only writes it when the peak rises.
+	SaveInterval time.Duration
+	// SweepInterval is how often ongoing episodes of vehicles that went
+	// silent are looked for and closed.
+	SweepInterval time.Duration
+}
+
+// EventQuery filters overspeed events. Episodes overlapping [From, To) are
+// returned; empty fields are not filtered on.
+type EventQuery struct {
+	OwnerID   string
+	VehicleID string
+	From      time.Time
+	To        time.Time
+	Limit     int64
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	model "FMTS/internal/overspeed/domain/entity"
+	"FMTS/internal/overspeed/domain/repository"
+	"FMTS/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OverspeedDomain struct {
+	overspeedRepo repository.OverspeedRepo
+	config        model.Config
+	logger        utils.Logger
+
+	mu       sync.Mutex
+	episodes map[string]*vehicleEpisode
+}
+
+// vehicleEpisode is the ongoing episode of one vehicle, nil when it is not
+// overspeeding. It is loaded from the store on the vehicle's first fix and
+// kept in memory after that; savedAt is when it was last written.
+type vehicleEpisode struct {
+	mu      sync.Mutex
+	loaded  bool
+	ongoing *model.OverspeedEvent
+	savedAt time.Time
+}
+
+func NewOverspeedDomainService(repo repository.OverspeedRepo, config model.Config, logger utils.Logger) OverspeedService {
+	return &OverspeedDomain{
+		overspeedRepo: repo,
+		config:        config,
+		logger:        logger,
+		episodes:      make(map[string]*vehicleEpisode),
+	}
+}
+
+type OverspeedService interface {
+	EvaluateSpeed(fix model.SpeedFix) (*model.OverspeedEvent, error)
+	CloseStaleEpisodes(now time.Time) (int, error)
+	FindEvents(query model.EventQuery) ([]*model.OverspeedEvent, error)
+}
+
+// EvaluateSpeed advances the vehicle's overspeed episode with a new fix. It
+// opens an episode on the first fix above the limit, tracks the peak while
+// the vehicle stays above it and closes the episode on the first fix at or
+// below the limit. The returned event is non-nil when an episode was opened
+// or closed.
+//
+// Episodes are written when they open or close, when the peak rises and at
+// least every SaveInterval in between, so a restart resumes from the stored
+// peak. The last fix between writes is only kept in memory.
+func (o *OverspeedDomain) EvaluateSpeed(fix model.SpeedFix) (*model.OverspeedEvent, error) {
+	episode := o.episode(fix.VehicleID)
+	episode.mu.Lock()
+	defer episode.mu.Unlock()
+
+	if !episode.loaded {
+		ongoing, err := o.overspeedRepo.FindOngoing(fix.VehicleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ongoing episode: %w", err)
+		}
+		episode.ongoing = ongoing
+		episode.loaded = true
+	}
+
+	if ongoing := episode.ongoing; ongoing != nil {
+		if fix.Timestamp.Before(ongoing.LastSeenAt) {
+			// Late fixes cannot reopen or extend an episode back in time.
+			return nil, nil
+		}
+		if o.config.MaxGap > 0 && fix.Timestamp.Sub(ongoing.LastSeenAt) > o.config.MaxGap {
+			stale := *ongoing
+			closeEpisode(&stale, ongoing.LastSeenAt, ongoing.LastLocation)
+			if err := o.overspeedRepo.SaveEvent(stale); err != nil {
+				return nil, fmt.Errorf("failed to close stale episode: %w", err)
+			}
+			episode.ongoing = nil
+		}
+	}
+
+	ongoing := episode.ongoing
+	over := fix.SpeedLimit > 0 && fix.Speed > fix.SpeedLimit+o.config.Tolerance
+	location := model.Coordinate{Latitude: fix.Latitude, Longitude: fix.Longitude}
+
+	switch {
+	case ongoing == nil && over:
+		event := model.OverspeedEvent{
+			ID:            primitive.NewObjectID().Hex(),
+			OwnerID:       fix.OwnerID,
+			VehicleID:     fix.VehicleID,
+			Status:        model.EpisodeOngoing,
+			SpeedLimit:    fix.SpeedLimit,
+			PeakSpeed:     fix.Speed,
+			StartTime:     fix.Timestamp,
+			StartLocation: location,
+			PeakTime:      fix.Timestamp,
+			PeakLocation:  location,
+			LastSeenAt:    fix.Timestamp,
+			LastLocation:  location,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := o.overspeedRepo.SaveEvent(event); err != nil {
+			return nil, fmt.Errorf("failed to save overspeed episode: %w", err)
+		}
+		episode.ongoing = &event
+		episode.savedAt = time.Now()
+		opened := event
+		return &opened, nil
+
+	case ongoing != nil && over:
+		peaked := fix.Speed > ongoing.PeakSpeed
+		if peaked {
+			ongoing.PeakSpeed = fix.Speed
+			ongoing.PeakTime = fix.Timestamp
+			ongoing.PeakLocation = location
+		}
+		ongoing.LastSeenAt = fix.Timestamp
+		ongoing.LastLocation = location
+		ongoing.DurationSeconds = int64(fix.Timestamp.Sub(ongoing.StartTime).Seconds())
+		ongoing.UpdatedAt = time.Now()
+
+		due := o.config.SaveInterval > 0 && time.Since(episode.savedAt) >= o.config.SaveInterval
+		if peaked || due {
+			if err := o.overspeedRepo.SaveEvent(*ongoing); err != nil {
+				return nil, fmt.Errorf("failed to save overspeed episode: %w", err)
+			}
+			episode.savedAt = time.Now()
+		}
+		return nil, nil
+
+	case ongoing != nil:
+		closed := *ongoing
+		closeEpisode(&closed, fix.Timestamp, location)
+		if err := o.overspeedRepo.SaveEvent(closed); err != nil {
+			return nil, fmt.Errorf("failed to close overspeed episode: %w", err)
+		}
+		episode.ongoing = nil
+		return &closed, nil
+	}
+	return nil, nil
+}
+
+// CloseStaleEpisodes closes the ongoing episodes of vehicles that have not
+// reported for longer than MaxGap before now, ending them at their last fix.
+// It returns the number of episodes closed.
+func (o *OverspeedDomain) CloseStaleEpisodes(now time.Time) (int, error) {
+	if o.config.MaxGap <= 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-o.config.MaxGap)
+	stale, err := o.overspeedRepo.FindStale(cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stale episodes: %w", err)
+	}
+
+	closed := 0
+	for _, stored := range stale {
+		ok, err := o.closeStale(stored, cutoff)
+		if err != nil {
+			return closed, err
+		}
+		if ok {
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// closeStale closes a stored ongoing episode unless the vehicle has reported
+// since it was last written.
+func (o *OverspeedDomain) closeStale(stored *model.OverspeedEvent, cutoff time.Time) (bool, error) {
+	episode := o.episode(stored.VehicleID)
+	episode.mu.Lock()
+	defer episode.mu.Unlock()
+
+	event := *stored
+	current := episode.ongoing != nil && episode.ongoing.ID == stored.ID
+	if current {
+		if !episode.ongoing.LastSeenAt.Before(cutoff) {
+			return false, nil
+		}
+		event = *episode.ongoing
+	}
+
+	closeEpisode(&event, event.LastSeenAt, event.LastLocation)
+	if err := o.overspeedRepo.SaveEvent(event); err != nil {
+		return false, fmt.Errorf("failed to close stale episode: %w", err)
+	}
+	if current {
+		episode.ongoing = nil
+	}
+	return true, nil
+}
+
+// List overspeed events. Ongoing episodes show their peak and last fix as
+// tracked in memory, which is newer than what is stored.
+func (o *OverspeedDomain) FindEvents(query model.EventQuery) ([]*model.OverspeedEvent, error) {
+	events, err := o.overspeedRepo.FindEvents(query)
+	if err != nil {
+		o.logger.Errorf("[FindEvents] error: %v", err)
+		return nil, err
+	}
+	for i, event := range events {
+		if event.Status != model.EpisodeOngoing {
+			continue
+		}
+		if current := o.currentEpisode(event.VehicleID); current != nil && current.ID == event.ID {
+			events[i] = current
+		}
+	}
+	return events, nil
+}
+
+// episode returns the in-memory episode state of a vehicle.
+func (o *OverspeedDomain) episode(vehicleID string) *vehicleEpisode {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	episode, ok := o.episodes[vehicleID]
+	if !ok {
+		episode = &vehicleEpisode{}
+		o.episodes[vehicleID] = episode
+	}
+	return episode
+}
+
+// currentEpisode returns a copy of the vehicle's ongoing episode as tracked
+// in memory, or nil.
+func (o *OverspeedDomain) currentEpisode(vehicleID string) *model.OverspeedEvent {
+	o.mu.Lock()
+	episode, ok := o.episodes[vehicleID]
+	o.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	episode.mu.Lock()
+	defer episode.mu.Unlock()
+	if episode.ongoing == nil {
+		return nil
+	}
+	current := *episode.ongoing
+	return &current
+}
+
+func closeEpisode(event *model.OverspeedEvent, end time.Time, location model.Coordinate) {
+	event.Status = model.EpisodeClosed
+	event.EndTime = &end
+	event.EndLocation = &location
+	event.LastSeenAt = end
+	event.LastLocation = location
+	event.DurationSeconds = int64(end.Sub(event.StartTime).Seconds())
+	event.UpdatedAt = time.Now()
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	model "FMTS/internal/overspeed/domain/entity"
+	"FMTS/pkg/utils"
+)
+
+// memoryOverspeedRepo keeps episodes in memory and counts the calls.
+type memoryOverspeedRepo struct {
+	events map[string]model.OverspeedEvent
+	loads  int
+	saves  int
+}
+
+func (r *memoryOverspeedRepo) FindOngoing(vehicleID string) (*model.OverspeedEvent, error) {
+	r.loads++
+	for _, e := range r.events {
+		if e.VehicleID == vehicleID && e.Status == model.EpisodeOngoing {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOverspeedRepo) FindStale(lastSeenBefore time.Time) ([]*model.OverspeedEvent, error) {
+	var events []*model.OverspeedEvent
+	for _, e := range r.events {
+		if e.Status == model.EpisodeOngoing && e.LastSeenAt.Before(lastSeenBefore) {
+			events = append(events, &e)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryOverspeedRepo) SaveEvent(event model.OverspeedEvent) error {
+	r.saves++
+	r.events[event.ID] = event
+	return nil
+}
+
+func (r *memoryOverspeedRepo) FindEvents(query model.EventQuery) ([]*model.OverspeedEvent, error) {
+	var events []*model.OverspeedEvent
+	for _, e := range r.events {
+		events = append(events, &e)
+	}
+	return events, nil
+}
+
+func TestEvaluateSpeedWritesTransitionsAndPeaks(t *testing.T) {
+	store := &memoryOverspeedRepo{events: make(map[string]model.OverspeedEvent)}
+	o := NewOverspeedDomainService(store, model.Config{Tolerance: 5, MaxGap: time.Minute}, utils.NewStandardLogger())
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	fix := func(seconds int, speed float64) model.SpeedFix {
+		return model.SpeedFix{OwnerID: "owner1", VehicleID: "v1", Speed: speed, SpeedLimit: 80, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	speeds := []float64{60, 84, 90, 110, 100, 95, 70, 60}
+	var opened, closed *model.OverspeedEvent
+	for i, speed := range speeds {
+		event, err := o.EvaluateSpeed(fix(i*10, speed))
+		if err != nil {
+			t.Fatalf("fix %d: %v", i, err)
+		}
+		if event == nil {
+			continue
+		}
+		if event.Status == model.EpisodeOngoing {
+			opened = event
+		} else {
+			closed = event
+		}
+
+		if i == 4 {
+			// Listing shows the in-memory peak of the ongoing episode.
+			events, _ := o.FindEvents(model.EventQuery{})
+			if len(events) != 1 || events[0].PeakSpeed != 110 {
+				t.Fatalf("listed %v, want the ongoing episode with peak 110", events)
+			}
+		}
+	}
+
+	if store.loads != 1 {
+		t.Fatalf("loaded the ongoing episode %d times, want 1", store.loads)
+	}
+	if store.saves != 3 {
+		t.Fatalf("saved %d times, want 3 (open, new peak and close)", store.saves)
+	}
+	if opened == nil || !opened.StartTime.Equal(start.Add(20*time.Second)) {
+		t.Fatalf("opened = %+v, want an episode starting at the 90 km/h fix", opened)
+	}
+	if closed == nil || closed.PeakSpeed != 110 || closed.DurationSeconds != 40 {
+		t.Fatalf("closed = %+v, want peak 110 over 40 s", closed)
+	}
+	if stored := store.events[closed.ID]; stored.Status != model.EpisodeClosed || stored.PeakSpeed != 110 {
+		t.Fatalf("stored %+v, want the closed episode with its peak", stored)
+	}
+}
+
+func TestEvaluateSpeedClosesStaleEpisodeAtLastFix(t *testing.T) {
+	store := &memoryOverspeedRepo{events: make(map[string]model.OverspeedEvent)}
+	o := NewOverspeedDomainService(store, model.Config{MaxGap: time.Minute}, utils.NewStandardLogger())
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	fix := func(seconds int, speed float64) model.SpeedFix {
+		return model.SpeedFix{VehicleID: "v1", Speed: speed, SpeedLimit: 80, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	o.EvaluateSpeed(fix(0, 100))
+	o.EvaluateSpeed(fix(30, 120))
+	// Silent for ten minutes, then overspeeding again: a new episode.
+	event, err := o.EvaluateSpeed(fix(630, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event == nil || event.Status != model.EpisodeOngoing || !event.StartTime.Equal(start.Add(630*time.Second)) {
+		t.Fatalf("event = %+v, want a new episode", event)
+	}
+
+	var stale *model.OverspeedEvent
+	for _, e := range store.events {
+		if e.Status == model.EpisodeClosed {
+			stale = &e
+		}
+	}
+	if stale == nil || stale.PeakSpeed != 120 || !stale.EndTime.Equal(start.Add(30*time.Second)) {
+		t.Fatalf("stale episode = %+v, want closed at its last fix with peak 120", stale)
+	}
+}
+
+func TestEvaluateSpeedResumesStoredPeakAfterRestart(t *testing.T) {
+	store := &memoryOverspeedRepo{events: make(map[string]model.OverspeedEvent)}
+	config := model.Config{MaxGap: time.Minute}
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	fix := func(seconds int, speed float64) model.SpeedFix {
+		return model.SpeedFix{VehicleID: "v1", Speed: speed, SpeedLimit: 80, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	before := NewOverspeedDomainService(store, config, utils.NewStandardLogger())
+	before.EvaluateSpeed(fix(0, 90))
+	before.EvaluateSpeed(fix(10, 130))
+	before.EvaluateSpeed(fix(20, 100))
+
+	after := NewOverspeedDomainService(store, config, utils.NewStandardLogger())
+	closed, err := after.EvaluateSpeed(fix(30, 60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed == nil || closed.Status != model.EpisodeClosed || closed.PeakSpeed != 130 || !closed.PeakTime.Equal(start.Add(10*time.Second)) {
+		t.Fatalf("closed = %+v, want the episode with the peak seen before the restart", closed)
+	}
+}
+
+func TestCloseStaleEpisodes(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	fix := func(vehicleID string, seconds int, speed float64) model.SpeedFix {
+		return model.SpeedFix{VehicleID: vehicleID, Speed: speed, SpeedLimit: 80, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	cases := []struct {
+		name       string
+		restart    bool // sweep from a new service, as after a restart
+		sweepAt    int
+		wantClosed int
+	}{
+		{name: "vehicle reported since the last write", sweepAt: 90, wantClosed: 0},
+		{name: "vehicle went silent", sweepAt: 300, wantClosed: 1},
+		{name: "vehicle went silent before a restart", restart: true, sweepAt: 300, wantClosed: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memoryOverspeedRepo{events: make(map[string]model.OverspeedEvent)}
+			config := model.Config{MaxGap: time.Minute}
+			o := NewOverspeedDomainService(store, config, utils.NewStandardLogger())
+			// The last fix at 40 s is only kept in memory, the store has 20 s.
+			o.EvaluateSpeed(fix("v1", 0, 90))
+			o.EvaluateSpeed(fix("v1", 20, 110))
+			o.EvaluateSpeed(fix("v1", 40, 100))
+			if tc.restart {
+				o = NewOverspeedDomainService(store, config, utils.NewStandardLogger())
+			}
+
+			closed, err := o.CloseStaleEpisodes(start.Add(time.Duration(tc.sweepAt) * time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if closed != tc.wantClosed {
+				t.Fatalf("closed %d episodes, want %d", closed, tc.wantClosed)
+			}
+			if tc.wantClosed == 0 {
+				return
+			}
+
+			var episode model.OverspeedEvent
+			for _, e := range store.events {
+				episode = e
+			}
+			wantEnd := start.Add(40 * time.Second)
+			if tc.restart {
+				wantEnd = start.Add(20 * time.Second)
+			}
+			if episode.Status != model.EpisodeClosed || !episode.EndTime.Equal(wantEnd) || episode.PeakSpeed != 110 {
+				t.Fatalf("stored %+v, want closed at %v with peak 110", episode, wantEnd)
+			}
+
+			// The next fix opens a new episode instead of extending the closed one.
+			opened, err := o.EvaluateSpeed(fix("v1", 400, 100))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opened == nil || opened.ID == episode.ID {
+				t.Fatalf("opened = %+v, want a new episode", opened)
+			}
+		})
+	}
+}
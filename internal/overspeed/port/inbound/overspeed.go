@@ -0,0 +1,8 @@
+package inbound
+
+import "net/http"
+
+type OverspeedPortInterface interface {
+	ListVehicleEvents(w http.ResponseWriter, r *http.Request)
+	ListOwnerEvents(w http.ResponseWriter, r *http.Request)
+}
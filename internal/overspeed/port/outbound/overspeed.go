@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"time"
+
+	model "FMTS/internal/overspeed/domain/entity"
+)
+
+// OverspeedRepo abstracts database operations for overspeed episodes
+type OverspeedRepo interface {
+	FindOngoing(vehicleID string) (*model.OverspeedEvent, error)
+	FindStale(lastSeenBefore time.Time) ([]*model.OverspeedEvent, error)
+	SaveEvent(event model.OverspeedEvent) error
+	FindEvents(query model.EventQuery) ([]*model.OverspeedEvent, error)
+}
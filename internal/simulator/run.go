@@ -0,0 +1,90 @@
+package simulator
+
+import (
+	"context"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/utils"
+)
+
+// RunOptions controls how a simulation is played into a sink.
+type RunOptions struct {
+	// Speedup plays the scenario at that multiple of real time, e.g. 60
+	// replays an hour in a minute. Zero sends everything as fast as the
+	// sink accepts it.
+	Speedup float64
+	// BatchSize caps the reports per Send. Without pacing, reports of
+	// several rounds are sent together up to this size.
+	BatchSize int
+	Logger    utils.Logger
+}
+
+// Stats summarises a run.
+type Stats struct {
+	Ticks    int
+	Sent     int
+	Accepted int
+	Failed   int
+}
+
+// Run plays sim into sink until the scenario ends or ctx is cancelled.
+// Failed sends are logged and counted, and the run carries on.
+func Run(ctx context.Context, sim *Simulation, sink Sink, opts RunOptions) (Stats, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = MaxHTTPBatch
+	}
+
+	var stats Stats
+	var pending []entity.VehicleLocation
+	// flush sends full batches, and the remainder too when all is set.
+	flush := func(all bool) {
+		for len(pending) >= opts.BatchSize || (all && len(pending) > 0) {
+			n := min(len(pending), opts.BatchSize)
+			accepted, err := sink.Send(ctx, pending[:n])
+			stats.Sent += n
+			stats.Accepted += accepted
+			if err != nil {
+				stats.Failed += n - accepted
+				opts.Logger.Errorf("[Simulator] failed to send %d reports: %v", n, err)
+			}
+			pending = pending[n:]
+		}
+	}
+
+	began := time.Now()
+	var first time.Time
+	for {
+		at, reports, ok := sim.Next()
+		if !ok {
+			break
+		}
+		if stats.Ticks == 0 {
+			first = at
+		}
+		stats.Ticks++
+
+		if opts.Speedup > 0 {
+			due := began.Add(time.Duration(float64(at.Sub(first)) / opts.Speedup))
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(time.Until(due)):
+			}
+			pending = reports
+			flush(true)
+		} else {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			pending = append(pending, reports...)
+			flush(false)
+		}
+
+		if stats.Ticks%100 == 0 {
+			opts.Logger.Infof("[Simulator] %d/%d rounds, %d reports accepted", stats.Ticks, sim.Ticks(), stats.Accepted)
+		}
+	}
+	flush(true)
+	return stats, nil
+}
@@ -0,0 +1,106 @@
+// Package simulator generates synthetic GPS traces for a fleet. A scenario
+// with the same seed always produces the same trace, so trip, stop and
+// geofence bugs can be reproduced from a command line.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"FMTS/pkg/geo"
+)
+
+// Scenario describes the fleet to simulate.
+type Scenario struct {
+	Seed     int64
+	Vehicles []Vehicle
+	// Start is the timestamp of the first report; the trace covers
+	// [Start, Start+Duration) with a report every Interval.
+	Start    time.Time
+	Duration time.Duration
+	Interval time.Duration
+	// Route is the polyline vehicles drive back and forth on. Without a
+	// route they random-walk within RadiusMeters of Center.
+	Route        []geo.Point
+	Center       geo.Point
+	RadiusMeters float64
+	Speed        SpeedProfile
+	Stops        Episodes
+	Offline      Episodes
+	// NoiseMeters is the standard deviation of the GPS error added to each
+	// reported position.
+	NoiseMeters float64
+}
+
+// Vehicle is one simulated vehicle.
+type Vehicle struct {
+	ID      string
+	OwnerID string
+}
+
+// SpeedProfile shapes how vehicles drive. Every few minutes a vehicle picks
+// a new target speed around CruiseKmh, never above MaxKmh, and reaches it at
+// AccelMps2.
+type SpeedProfile struct {
+	CruiseKmh float64
+	MaxKmh    float64
+	AccelMps2 float64
+}
+
+// Episodes are recurring stops or offline gaps. They start on average Every
+// apart and last between Min and Max. A zero Every disables them.
+type Episodes struct {
+	Every time.Duration
+	Min   time.Duration
+	Max   time.Duration
+}
+
+var (
+	ErrNoVehicles      = errors.New("scenario needs at least one vehicle")
+	ErrInvalidInterval = errors.New("interval and duration must be positive")
+	ErrInvalidRoute    = errors.New("route needs at least two distinct points")
+	ErrInvalidArea     = errors.New("random walk needs a positive radius")
+	ErrInvalidSpeed    = errors.New("cruise speed must be positive and at most the max speed")
+)
+
+// Validate checks that a scenario can be simulated.
+func (s Scenario) Validate() error {
+	if len(s.Vehicles) == 0 {
+		return ErrNoVehicles
+	}
+	if s.Interval <= 0 || s.Duration <= 0 {
+		return ErrInvalidInterval
+	}
+	if len(s.Route) > 0 && routeLength(s.Route) == 0 {
+		return ErrInvalidRoute
+	}
+	if len(s.Route) == 0 && s.RadiusMeters <= 0 {
+		return ErrInvalidArea
+	}
+	if s.Speed.CruiseKmh <= 0 || (s.Speed.MaxKmh > 0 && s.Speed.CruiseKmh > s.Speed.MaxKmh) {
+		return ErrInvalidSpeed
+	}
+	if err := s.Stops.validate("stops"); err != nil {
+		return err
+	}
+	if err := s.Offline.validate("offline gaps"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e Episodes) validate(name string) error {
+	if e.Every < 0 || e.Min < 0 || e.Max < e.Min {
+		return fmt.Errorf("invalid %s: every must not be negative and min must not exceed max", name)
+	}
+	return nil
+}
+
+func routeLength(route []geo.Point) float64 {
+	var meters float64
+	for i := 1; i < len(route); i++ {
+		meters += geo.HaversineMeters(route[i-1].Latitude, route[i-1].Longitude, route[i].Latitude, route[i].Longitude)
+	}
+	return meters
+}
@@ -0,0 +1,300 @@
+package simulator
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+)
+
+const (
+	defaultAccelMps2 = 1.5
+	// Vehicles pick a new target speed every minRetarget to maxRetarget.
+	minRetarget = time.Minute
+	maxRetarget = 4 * time.Minute
+	// walkTurnRadians is the standard deviation of a random walk's turn per
+	// report.
+	walkTurnRadians = 0.25
+)
+
+// Simulation steps every vehicle of a scenario through time. Each vehicle
+// draws from its own random source seeded by the scenario seed and its
+// position in the fleet, so adding vehicles does not change the others.
+type Simulation struct {
+	scenario Scenario
+	vehicles []*vehicleSim
+	tick     int
+	ticks    int
+}
+
+// New prepares a simulation of the scenario.
+func New(s Scenario) (*Simulation, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Speed.MaxKmh <= 0 {
+		s.Speed.MaxKmh = s.Speed.CruiseKmh * 1.5
+	}
+	if s.Speed.AccelMps2 <= 0 {
+		s.Speed.AccelMps2 = defaultAccelMps2
+	}
+
+	var route *polyline
+	if len(s.Route) > 0 {
+		route = newPolyline(s.Route)
+	}
+	sim := &Simulation{scenario: s, ticks: int(s.Duration / s.Interval)}
+	for i, v := range s.Vehicles {
+		rng := rand.New(rand.NewPCG(uint64(s.Seed), uint64(i)))
+		sim.vehicles = append(sim.vehicles, newVehicleSim(v, rng, &sim.scenario, route))
+	}
+	return sim, nil
+}
+
+// Ticks is the number of report rounds in the scenario.
+func (sim *Simulation) Ticks() int {
+	return sim.ticks
+}
+
+// Next advances every vehicle by one interval and returns the time of the
+// round and the reports of the vehicles that are online, in fleet order.
+// ok is false once the scenario is over.
+func (sim *Simulation) Next() (at time.Time, reports []entity.VehicleLocation, ok bool) {
+	if sim.tick >= sim.ticks {
+		return time.Time{}, nil, false
+	}
+	at = sim.scenario.Start.Add(time.Duration(sim.tick) * sim.scenario.Interval)
+	dt := sim.scenario.Interval.Seconds()
+	if sim.tick == 0 {
+		dt = 0
+	}
+	sim.tick++
+
+	for _, v := range sim.vehicles {
+		if report, online := v.step(at, dt); online {
+			reports = append(reports, report)
+		}
+	}
+	return at, reports, true
+}
+
+type drivingState int
+
+const (
+	driving drivingState = iota
+	braking
+	parked
+)
+
+type vehicleSim struct {
+	vehicle  Vehicle
+	rng      *rand.Rand
+	scenario *Scenario
+	route    *polyline
+
+	position  geo.Point
+	along     float64 // metres along the route
+	direction float64 // +1 or -1 along the route
+	heading   float64 // radians clockwise from north, for random walks
+
+	state       drivingState
+	speed       float64 // m/s
+	target      float64 // m/s
+	retargetAt  time.Time
+	stopAt      time.Time
+	parkedUntil time.Time
+
+	offlineAt    time.Time
+	offlineUntil time.Time
+}
+
+func newVehicleSim(vehicle Vehicle, rng *rand.Rand, s *Scenario, route *polyline) *vehicleSim {
+	v := &vehicleSim{vehicle: vehicle, rng: rng, scenario: s, route: route, direction: 1}
+	if route != nil {
+		v.along = rng.Float64() * route.length
+		if rng.IntN(2) == 0 {
+			v.direction = -1
+		}
+		v.position = route.at(v.along)
+	} else {
+		r := s.RadiusMeters * math.Sqrt(rng.Float64())
+		theta := 2 * math.Pi * rng.Float64()
+		v.position = geo.Offset(s.Center, r*math.Cos(theta), r*math.Sin(theta))
+		v.heading = 2 * math.Pi * rng.Float64()
+	}
+
+	v.retarget(s.Start)
+	v.speed = v.target
+	v.stopAt = s.Start.Add(v.gap(s.Stops))
+	v.offlineAt = s.Start.Add(v.gap(s.Offline))
+	return v
+}
+
+// step advances the vehicle by dt seconds to t and returns its report;
+// online is false while the vehicle is in an offline gap.
+func (v *vehicleSim) step(t time.Time, dt float64) (report entity.VehicleLocation, online bool) {
+	stops := v.scenario.Stops
+	switch v.state {
+	case driving:
+		if stops.Every > 0 && !t.Before(v.stopAt) {
+			v.state = braking
+			v.target = 0
+		} else if !t.Before(v.retargetAt) {
+			v.retarget(t)
+		}
+	case braking:
+		if v.speed == 0 {
+			v.state = parked
+			v.parkedUntil = t.Add(v.length(stops))
+		}
+	case parked:
+		if !t.Before(v.parkedUntil) {
+			v.state = driving
+			v.stopAt = t.Add(v.gap(stops))
+			v.retarget(t)
+		}
+	}
+
+	previous := v.speed
+	delta := v.scenario.Speed.AccelMps2 * dt
+	if v.speed < v.target {
+		v.speed = math.Min(v.target, v.speed+delta)
+	} else {
+		v.speed = math.Max(v.target, v.speed-delta)
+	}
+	if v.state == parked {
+		v.speed = 0
+	}
+	v.move((previous + v.speed) / 2 * dt)
+
+	// Draw the noise even while offline so gaps do not shift the rest of
+	// the trace.
+	noisy := geo.Offset(v.position, v.rng.NormFloat64()*v.scenario.NoiseMeters, v.rng.NormFloat64()*v.scenario.NoiseMeters)
+	if v.offline(t) {
+		return entity.VehicleLocation{}, false
+	}
+	return entity.VehicleLocation{
+		OwnerID:   v.vehicle.OwnerID,
+		VehicleID: v.vehicle.ID,
+		Latitude:  noisy.Latitude,
+		Longitude: noisy.Longitude,
+		Speed:     math.Round(v.speed*3.6*10) / 10,
+		Timestamp: t,
+	}, true
+}
+
+func (v *vehicleSim) offline(t time.Time) bool {
+	gaps := v.scenario.Offline
+	if gaps.Every <= 0 {
+		return false
+	}
+	if v.offlineUntil.IsZero() && !t.Before(v.offlineAt) {
+		v.offlineUntil = t.Add(v.length(gaps))
+	}
+	if v.offlineUntil.IsZero() {
+		return false
+	}
+	if t.Before(v.offlineUntil) {
+		return true
+	}
+	v.offlineUntil = time.Time{}
+	v.offlineAt = t.Add(v.gap(gaps))
+	return false
+}
+
+// retarget picks the next cruising speed.
+func (v *vehicleSim) retarget(t time.Time) {
+	speed := v.scenario.Speed
+	kmh := math.Min(speed.CruiseKmh*(0.6+0.55*v.rng.Float64()), speed.MaxKmh)
+	v.target = kmh / 3.6
+	v.retargetAt = t.Add(minRetarget + time.Duration(v.rng.Float64()*float64(maxRetarget-minRetarget)))
+}
+
+func (v *vehicleSim) move(meters float64) {
+	if meters <= 0 {
+		return
+	}
+	if v.route != nil {
+		v.along += v.direction * meters
+		// Turn around at the ends of the route.
+		for v.along < 0 || v.along > v.route.length {
+			if v.along > v.route.length {
+				v.along = 2*v.route.length - v.along
+				v.direction = -1
+			} else {
+				v.along = -v.along
+				v.direction = 1
+			}
+		}
+		v.position = v.route.at(v.along)
+		return
+	}
+
+	center := v.scenario.Center
+	if geo.HaversineMeters(v.position.Latitude, v.position.Longitude, center.Latitude, center.Longitude) > v.scenario.RadiusMeters {
+		v.heading = bearing(v.position, center)
+	}
+	v.heading += v.rng.NormFloat64() * walkTurnRadians
+	v.position = geo.Offset(v.position, meters*math.Cos(v.heading), meters*math.Sin(v.heading))
+}
+
+// gap returns the time until the next episode, exponentially distributed
+// around e.Every.
+func (v *vehicleSim) gap(e Episodes) time.Duration {
+	if e.Every <= 0 {
+		return 0
+	}
+	return max(time.Duration(v.rng.ExpFloat64()*float64(e.Every)), v.scenario.Interval)
+}
+
+// length returns how long an episode lasts.
+func (v *vehicleSim) length(e Episodes) time.Duration {
+	return e.Min + time.Duration(v.rng.Float64()*float64(e.Max-e.Min))
+}
+
+// bearing returns the initial heading from a to b on a local flat
+// approximation, in radians clockwise from north.
+func bearing(a, b geo.Point) float64 {
+	north := b.Latitude - a.Latitude
+	east := (b.Longitude - a.Longitude) * math.Cos(a.Latitude*math.Pi/180)
+	return math.Atan2(east, north)
+}
+
+// polyline interpolates positions along a route by distance.
+type polyline struct {
+	points     []geo.Point
+	cumulative []float64
+	length     float64
+}
+
+func newPolyline(points []geo.Point) *polyline {
+	p := &polyline{points: points, cumulative: make([]float64, len(points))}
+	for i := 1; i < len(points); i++ {
+		p.cumulative[i] = p.cumulative[i-1] + geo.HaversineMeters(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+	p.length = p.cumulative[len(points)-1]
+	return p
+}
+
+func (p *polyline) at(meters float64) geo.Point {
+	i := sort.SearchFloat64s(p.cumulative, meters)
+	if i == 0 {
+		return p.points[0]
+	}
+	if i >= len(p.points) {
+		return p.points[len(p.points)-1]
+	}
+	a, b := p.points[i-1], p.points[i]
+	segment := p.cumulative[i] - p.cumulative[i-1]
+	if segment == 0 {
+		return b
+	}
+	f := (meters - p.cumulative[i-1]) / segment
+	return geo.Point{
+		Latitude:  a.Latitude + f*(b.Latitude-a.Latitude),
+		Longitude: a.Longitude + f*(b.Longitude-a.Longitude),
+	}
+}
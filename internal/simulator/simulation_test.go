@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+)
+
+func testScenario() Scenario {
+	return Scenario{
+		Seed:         42,
+		Vehicles:     []Vehicle{{ID: "SIM001", OwnerID: "owner1"}, {ID: "SIM002", OwnerID: "owner1"}},
+		Start:        time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC),
+		Duration:     3 * time.Hour,
+		Interval:     10 * time.Second,
+		Center:       geo.Point{Latitude: 9.0108, Longitude: 38.7613},
+		RadiusMeters: 3000,
+		Speed:        SpeedProfile{CruiseKmh: 40, MaxKmh: 80},
+		Stops:        Episodes{Every: 20 * time.Minute, Min: 5 * time.Minute, Max: 10 * time.Minute},
+		Offline:      Episodes{Every: 30 * time.Minute, Min: 2 * time.Minute, Max: 5 * time.Minute},
+		NoiseMeters:  5,
+	}
+}
+
+func trace(t *testing.T, s Scenario) []entity.VehicleLocation {
+	t.Helper()
+	sim, err := New(s)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var all []entity.VehicleLocation
+	for {
+		_, reports, ok := sim.Next()
+		if !ok {
+			return all
+		}
+		all = append(all, reports...)
+	}
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	a, b := trace(t, testScenario()), trace(t, testScenario())
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same seed produced different traces")
+	}
+
+	other := testScenario()
+	other.Seed++
+	if reflect.DeepEqual(a, trace(t, other)) {
+		t.Fatal("different seeds produced the same trace")
+	}
+}
+
+func TestSimulationStopsAndGaps(t *testing.T) {
+	s := testScenario()
+	var last time.Time
+	var gaps, stopped int
+	for _, r := range trace(t, s) {
+		if r.VehicleID != "SIM001" {
+			continue
+		}
+		if !last.IsZero() && r.Timestamp.Sub(last) > s.Interval {
+			gaps++
+		}
+		if r.Speed == 0 {
+			stopped++
+		}
+		if d := geo.HaversineMeters(r.Latitude, r.Longitude, s.Center.Latitude, s.Center.Longitude); d > 2*s.RadiusMeters {
+			t.Fatalf("vehicle wandered %.0f m from the centre", d)
+		}
+		last = r.Timestamp
+	}
+	if gaps == 0 {
+		t.Error("expected offline gaps in the trace")
+	}
+	if stopped < int(s.Stops.Min/s.Interval) {
+		t.Errorf("expected at least one full stop, got %d stationary reports", stopped)
+	}
+}
+
+func TestSimulationFollowsRoute(t *testing.T) {
+	s := testScenario()
+	s.Route = []geo.Point{
+		{Latitude: 9.0108, Longitude: 38.7613},
+		{Latitude: 9.0208, Longitude: 38.7613},
+		{Latitude: 9.0208, Longitude: 38.7713},
+	}
+	s.NoiseMeters = 0
+	for _, r := range trace(t, s) {
+		onFirst := r.Longitude > 38.7612 && r.Longitude < 38.7614 && r.Latitude > 9.0107 && r.Latitude < 9.0209
+		onSecond := r.Latitude > 9.0207 && r.Latitude < 9.0209 && r.Longitude > 38.7612 && r.Longitude < 38.7714
+		if !onFirst && !onSecond {
+			t.Fatalf("report %+v is off the route", r)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tracker_application "FMTS/internal/tracking/application"
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/kafka"
+)
+
+// MaxHTTPBatch is the largest batch the tracker's batch endpoint accepts.
+const MaxHTTPBatch = 1000
+
+// Sink delivers simulated reports. It returns how many were accepted.
+type Sink interface {
+	Send(ctx context.Context, locations []entity.VehicleLocation) (int, error)
+}
+
+// HTTPSink posts reports to the tracker's batch endpoint.
+type HTTPSink struct {
+	// BaseURL is the API root, e.g. http://localhost:8082/api/v1/FMTS.
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+func NewHTTPSink(baseURL, token string) *HTTPSink {
+	return &HTTPSink{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *HTTPSink) Send(ctx context.Context, locations []entity.VehicleLocation) (int, error) {
+	accepted := 0
+	for start := 0; start < len(locations); start += MaxHTTPBatch {
+		end := min(start+MaxHTTPBatch, len(locations))
+		n, err := s.post(ctx, locations[start:end])
+		accepted += n
+		if err != nil {
+			return accepted, err
+		}
+	}
+	return accepted, nil
+}
+
+func (s *HTTPSink) post(ctx context.Context, locations []entity.VehicleLocation) (int, error) {
+	body, err := json.Marshal(locations)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/tracker/batch", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		Message string `json:"message"`
+		Data    struct {
+			Accepted int `json:"accepted"`
+		} `json:"data"`
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil && resp.StatusCode < 300 {
+		return 0, fmt.Errorf("invalid batch response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		message := decoded.Message
+		if message == "" {
+			message = strings.TrimSpace(string(raw))
+		}
+		return 0, fmt.Errorf("batch endpoint returned %d: %s", resp.StatusCode, message)
+	}
+	return decoded.Data.Accepted, nil
+}
+
+// KafkaSink publishes reports to the location topic, as the HTTP handlers
+// do in kafka ingest mode. Every published report counts as accepted.
+type KafkaSink struct {
+	Producer *kafka.KafkaProducer
+}
+
+func (s KafkaSink) Send(ctx context.Context, locations []entity.VehicleLocation) (int, error) {
+	if err := s.Producer.ProduceVehicleLocations(ctx, locations); err != nil {
+		return 0, err
+	}
+	return len(locations), nil
+}
+
+// ApplicationSink ingests reports in process, skipping HTTP and Kafka.
+type ApplicationSink struct {
+	App tracker_application.TrackerApplication
+}
+
+func (s ApplicationSink) Send(ctx context.Context, locations []entity.VehicleLocation) (int, error) {
+	result, err := s.App.UpdateLocations(ctx, locations)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.Inserted), nil
+}
+
+// WriterSink writes reports as NDJSON, e.g. to save a trace or pipe it into
+// the batch endpoint later.
+type WriterSink struct {
+	W io.Writer
+}
+
+func (s WriterSink) Send(ctx context.Context, locations []entity.VehicleLocation) (int, error) {
+	encoder := json.NewEncoder(s.W)
+	for i, location := range locations {
+		if err := encoder.Encode(location); err != nil {
+			return i, err
+		}
+	}
+	return len(locations), nil
+}
@@ -0,0 +1,216 @@
+// Package gt06 implements the server side of the binary GT06/Concox GPS
+// tracker protocol and feeds decoded fixes into the tracker.
+package gt06
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Protocol numbers handled by the server.
+const (
+	ProtocolLogin     byte = 0x01
+	ProtocolLocation  byte = 0x12 // GPS + LBS, GT06
+	ProtocolHeartbeat byte = 0x13 // status information
+	ProtocolAlarm     byte = 0x16 // GPS + LBS + status
+	ProtocolLocation2 byte = 0x22 // GPS + LBS, GT06N / Concox
+)
+
+var (
+	ErrIncomplete   = errors.New("gt06: incomplete packet")
+	ErrBadStart     = errors.New("gt06: invalid start bits")
+	ErrBadStop      = errors.New("gt06: invalid stop bits")
+	ErrBadChecksum  = errors.New("gt06: checksum mismatch")
+	ErrShortContent = errors.New("gt06: packet content too short")
+)
+
+// Packet is one decoded GT06 frame.
+type Packet struct {
+	Protocol byte
+	Content  []byte
+	Serial   uint16
+}
+
+// Login is the content of a login packet.
+type Login struct {
+	IMEI string
+}
+
+// Heartbeat is the content of a status information packet.
+type Heartbeat struct {
+	TerminalInfo byte
+	Voltage      byte
+	GSMSignal    byte
+}
+
+// Location is a GPS fix decoded from a location or alarm packet.
+type Location struct {
+	Timestamp  time.Time
+	Satellites int
+	Latitude   float64
+	Longitude  float64
+	Speed      float64 // km/h
+	Course     int     // degrees
+	Positioned bool
+}
+
+// crcTable is the CRC-ITU (CRC-16/X-25) lookup table used by GT06.
+var crcTable = func() [256]uint16 {
+	var table [256]uint16
+	for i := range table {
+		crc := uint16(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 != 0 {
+				crc = (crc >> 1) ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// CRC computes the CRC-ITU checksum GT06 uses, from the packet length byte
+// up to and including the serial number.
+func CRC(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc = (crc >> 8) ^ crcTable[(crc^uint16(b))&0xFF]
+	}
+	return ^crc
+}
+
+// Decode parses the first frame in buf. It returns the packet and the number
+// of bytes consumed, or ErrIncomplete when more data is needed.
+func Decode(buf []byte) (Packet, int, error) {
+	if len(buf) < 2 {
+		return Packet{}, 0, ErrIncomplete
+	}
+
+	var lengthSize int
+	switch {
+	case buf[0] == 0x78 && buf[1] == 0x78:
+		lengthSize = 1
+	case buf[0] == 0x79 && buf[1] == 0x79:
+		lengthSize = 2
+	default:
+		return Packet{}, 0, ErrBadStart
+	}
+
+	if len(buf) < 2+lengthSize {
+		return Packet{}, 0, ErrIncomplete
+	}
+	var length int
+	if lengthSize == 1 {
+		length = int(buf[2])
+	} else {
+		length = int(binary.BigEndian.Uint16(buf[2:4]))
+	}
+	// length covers protocol number, content, serial and CRC.
+	if length < 5 {
+		return Packet{}, 0, ErrShortContent
+	}
+
+	total := 2 + lengthSize + length + 2
+	if len(buf) < total {
+		return Packet{}, 0, ErrIncomplete
+	}
+	if buf[total-2] != 0x0D || buf[total-1] != 0x0A {
+		return Packet{}, total, ErrBadStop
+	}
+
+	crcEnd := total - 4
+	want := binary.BigEndian.Uint16(buf[crcEnd : crcEnd+2])
+	if got := CRC(buf[2:crcEnd]); got != want {
+		return Packet{}, total, fmt.Errorf("%w: got %04X, want %04X", ErrBadChecksum, got, want)
+	}
+
+	body := buf[2+lengthSize : crcEnd]
+	return Packet{
+		Protocol: body[0],
+		Content:  append([]byte(nil), body[1:len(body)-2]...),
+		Serial:   binary.BigEndian.Uint16(body[len(body)-2:]),
+	}, total, nil
+}
+
+// EncodeResponse builds the short acknowledgement frame the terminal expects
+// for login, heartbeat and alarm packets.
+func EncodeResponse(protocol byte, serial uint16) []byte {
+	frame := []byte{0x78, 0x78, 0x05, protocol, byte(serial >> 8), byte(serial)}
+	crc := CRC(frame[2:])
+	return append(frame, byte(crc>>8), byte(crc), 0x0D, 0x0A)
+}
+
+// ParseLogin decodes the BCD encoded terminal ID of a login packet.
+func ParseLogin(content []byte) (Login, error) {
+	if len(content) < 8 {
+		return Login{}, ErrShortContent
+	}
+	digits := make([]byte, 0, 16)
+	for _, b := range content[:8] {
+		hi, lo := b>>4, b&0x0F
+		if hi > 9 || lo > 9 {
+			return Login{}, fmt.Errorf("gt06: invalid BCD terminal id %X", content[:8])
+		}
+		digits = append(digits, '0'+hi, '0'+lo)
+	}
+	// The 15 digit IMEI is left padded with a zero to fill 8 bytes.
+	imei := string(digits)
+	if imei[0] == '0' {
+		imei = imei[1:]
+	}
+	return Login{IMEI: imei}, nil
+}
+
+// ParseHeartbeat decodes a status information packet.
+func ParseHeartbeat(content []byte) (Heartbeat, error) {
+	if len(content) < 3 {
+		return Heartbeat{}, ErrShortContent
+	}
+	return Heartbeat{
+		TerminalInfo: content[0],
+		Voltage:      content[1],
+		GSMSignal:    content[2],
+	}, nil
+}
+
+// ParseLocation decodes the GPS block shared by location and alarm packets.
+func ParseLocation(content []byte) (Location, error) {
+	if len(content) < 18 {
+		return Location{}, ErrShortContent
+	}
+
+	ts := time.Date(
+		2000+int(content[0]),
+		time.Month(content[1]),
+		int(content[2]),
+		int(content[3]),
+		int(content[4]),
+		int(content[5]),
+		0, time.UTC,
+	)
+
+	lat := float64(binary.BigEndian.Uint32(content[7:11])) / 1800000
+	lng := float64(binary.BigEndian.Uint32(content[11:15])) / 1800000
+	flags := binary.BigEndian.Uint16(content[16:18])
+
+	if flags&(1<<10) == 0 {
+		lat = -lat
+	}
+	if flags&(1<<11) != 0 {
+		lng = -lng
+	}
+
+	return Location{
+		Timestamp:  ts,
+		Satellites: int(content[6] & 0x0F),
+		Latitude:   lat,
+		Longitude:  lng,
+		Speed:      float64(content[15]),
+		Course:     int(flags & 0x03FF),
+		Positioned: flags&(1<<12) != 0,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package gt06
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// Frames recorded from the GT06 protocol manual and a GT06N terminal.
+var (
+	loginFrame     = mustHex("78780D01012345678901234500018CDD0D0A")
+	loginAck       = mustHex("787805010001D9DC0D0A")
+	heartbeatFrame = mustHex("78780A134004040001000FDCEE0D0A")
+	locationFrame  = mustHex("78781F120B081D112E10CF027AC7EB0C46584900148F01CC00287D001FB8000380810D0A")
+	locationFrame2 = mustHex("78781F1210020E14061DCC0476FCD00E6B4BD00015D401940B03FC0000F3000D56230D0A")
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestCRC(t *testing.T) {
+	// CRC-16/X-25 check value.
+	if got := CRC([]byte("123456789")); got != 0x906E {
+		t.Fatalf("CRC(check) = %04X, want 906E", got)
+	}
+
+	for name, frame := range map[string][]byte{
+		"login":     loginFrame,
+		"ack":       loginAck,
+		"heartbeat": heartbeatFrame,
+		"location":  locationFrame,
+		"location2": locationFrame2,
+	} {
+		n := len(frame)
+		want := uint16(frame[n-4])<<8 | uint16(frame[n-3])
+		if got := CRC(frame[2 : n-4]); got != want {
+			t.Errorf("%s: CRC = %04X, want %04X", name, got, want)
+		}
+	}
+}
+
+func TestDecodeLogin(t *testing.T) {
+	packet, n, err := Decode(loginFrame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if n != len(loginFrame) || packet.Protocol != ProtocolLogin || packet.Serial != 1 {
+		t.Fatalf("unexpected packet %+v (consumed %d)", packet, n)
+	}
+
+	login, err := ParseLogin(packet.Content)
+	if err != nil {
+		t.Fatalf("ParseLogin: %v", err)
+	}
+	if login.IMEI != "123456789012345" {
+		t.Errorf("IMEI = %q, want 123456789012345", login.IMEI)
+	}
+
+	if ack := EncodeResponse(packet.Protocol, packet.Serial); !bytes.Equal(ack, loginAck) {
+		t.Errorf("ack = %X, want %X", ack, loginAck)
+	}
+}
+
+func TestDecodeHeartbeat(t *testing.T) {
+	packet, _, err := Decode(heartbeatFrame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if packet.Protocol != ProtocolHeartbeat || packet.Serial != 0x000F {
+		t.Fatalf("unexpected packet %+v", packet)
+	}
+	hb, err := ParseHeartbeat(packet.Content)
+	if err != nil {
+		t.Fatalf("ParseHeartbeat: %v", err)
+	}
+	if hb.TerminalInfo != 0x40 || hb.Voltage != 4 || hb.GSMSignal != 4 {
+		t.Errorf("unexpected heartbeat %+v", hb)
+	}
+}
+
+func TestDecodeLocation(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+		want  Location
+	}{
+		{
+			name:  "manual",
+			frame: locationFrame,
+			want: Location{
+				Timestamp:  time.Date(2011, 8, 29, 17, 46, 16, 0, time.UTC),
+				Satellites: 15,
+				Latitude:   23.111668,
+				Longitude:  114.409285,
+				Speed:      0,
+				Course:     143,
+				Positioned: true,
+			},
+		},
+		{
+			name:  "gt06n",
+			frame: locationFrame2,
+			want: Location{
+				Timestamp:  time.Date(2016, 2, 14, 20, 6, 29, 0, time.UTC),
+				Satellites: 12,
+				Latitude:   41.614907,
+				Longitude:  134.395991,
+				Speed:      0,
+				Course:     468,
+				Positioned: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, _, err := Decode(tt.frame)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			got, err := ParseLocation(packet.Content)
+			if err != nil {
+				t.Fatalf("ParseLocation: %v", err)
+			}
+			if !got.Timestamp.Equal(tt.want.Timestamp) ||
+				got.Satellites != tt.want.Satellites ||
+				math.Abs(got.Latitude-tt.want.Latitude) > 1e-6 ||
+				math.Abs(got.Longitude-tt.want.Longitude) > 1e-6 ||
+				got.Speed != tt.want.Speed ||
+				got.Course != tt.want.Course ||
+				got.Positioned != tt.want.Positioned {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHemisphereFlags(t *testing.T) {
+	content := append([]byte(nil), mustDecode(t, locationFrame).Content...)
+	// Clear the north bit and set the west bit.
+	content[16] = (content[16] &^ 0x04) | 0x08
+
+	got, err := ParseLocation(content)
+	if err != nil {
+		t.Fatalf("ParseLocation: %v", err)
+	}
+	if got.Latitude >= 0 || got.Longitude >= 0 {
+		t.Errorf("expected south/west coordinates, got %v,%v", got.Latitude, got.Longitude)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	corrupt := append([]byte(nil), locationFrame...)
+	corrupt[10] ^= 0xFF
+
+	badStop := append([]byte(nil), loginFrame...)
+	badStop[len(badStop)-1] = 0x00
+
+	tests := []struct {
+		name string
+		buf  []byte
+		want error
+	}{
+		{"empty", nil, ErrIncomplete},
+		{"truncated", loginFrame[:len(loginFrame)-3], ErrIncomplete},
+		{"bad start", mustHex("7A7A0D01"), ErrBadStart},
+		{"bad checksum", corrupt, ErrBadChecksum},
+		{"bad stop", badStop, ErrBadStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.buf); !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeConsecutiveFrames(t *testing.T) {
+	stream := append(append([]byte(nil), loginFrame...), heartbeatFrame...)
+
+	first, n, err := Decode(stream)
+	if err != nil || first.Protocol != ProtocolLogin {
+		t.Fatalf("first frame: %+v, %v", first, err)
+	}
+	second, m, err := Decode(stream[n:])
+	if err != nil || second.Protocol != ProtocolHeartbeat {
+		t.Fatalf("second frame: %+v, %v", second, err)
+	}
+	if n+m != len(stream) {
+		t.Errorf("consumed %d bytes, want %d", n+m, len(stream))
+	}
+}
+
+func mustDecode(t *testing.T, frame []byte) Packet {
+	t.Helper()
+	packet, _, err := Decode(frame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return packet
+}
@@ -0,0 +1,290 @@
+package gt06
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	model "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/utils"
+)
+
+const (
+	// DefaultIdleTimeout drops connections that stay silent for longer than
+	// a few heartbeat intervals (terminals default to 3 minutes).
+	DefaultIdleTimeout = 10 * time.Minute
+	maxFrameSize       = 1024
+)
+
+// ErrUnknownDevice is returned by a VehicleResolver for an IMEI that is not
+// installed in any vehicle.
+var ErrUnknownDevice = errors.New("gt06: unknown device")
+
+// VehicleResolver maps a terminal IMEI to the vehicle it is installed in.
+type VehicleResolver interface {
+	ResolveIMEI(ctx context.Context, imei string) (vehicleID, ownerID string, err error)
+}
+
+// LocationSink receives decoded fixes, normally the tracker application.
+type LocationSink interface {
+	UpdateLocation(ctx context.Context, location model.VehicleLocation) (model.VehicleLocation, error)
+}
+
+type Server struct {
+	resolver    VehicleResolver
+	sink        LocationSink
+	logger      utils.Logger
+	IdleTimeout time.Duration
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+func NewServer(resolver VehicleResolver, sink LocationSink, logger utils.Logger) *Server {
+	return &Server{
+		resolver:    resolver,
+		sink:        sink,
+		logger:      logger,
+		IdleTimeout: DefaultIdleTimeout,
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe accepts terminal connections on addr until Shutdown is called.
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return net.ErrClosed
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Shutdown stops accepting connections, closes open ones and waits for
+// their handlers to return.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// session is the per-connection state; fixes are only accepted after a
+// successful login.
+type session struct {
+	conn      net.Conn
+	imei      string
+	vehicleID string
+	ownerID   string
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	sess := &session{conn: conn}
+	// Frames are decoded from the front of buf; the leftover partial frame
+	// is moved back to the start of the backing array after every read.
+	backing := make([]byte, 2*maxFrameSize)
+	buf := backing[:0]
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		n, err := conn.Read(backing[len(buf):])
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.logger.Debugf("[GT06] connection %s (%s) closed: %v", conn.RemoteAddr(), sess.imei, err)
+			}
+			return
+		}
+		buf = backing[:len(buf)+n]
+
+		for len(buf) > 0 {
+			packet, consumed, err := Decode(buf)
+			if errors.Is(err, ErrIncomplete) {
+				break
+			}
+			if err != nil {
+				s.logger.Warnf("[GT06] dropping bad frame from %s (%s): %v", conn.RemoteAddr(), sess.imei, err)
+				buf = resync(buf, consumed)
+				continue
+			}
+			buf = buf[consumed:]
+
+			if err := s.handlePacket(sess, packet); err != nil {
+				s.logger.Warnf("[GT06] closing connection %s (%s): %v", conn.RemoteAddr(), sess.imei, err)
+				return
+			}
+		}
+
+		if len(buf) >= maxFrameSize {
+			s.logger.Warnf("[GT06] frame from %s exceeds %d bytes, closing", conn.RemoteAddr(), maxFrameSize)
+			return
+		}
+		buf = backing[:copy(backing, buf)]
+	}
+}
+
+// resync skips past a bad frame, or to the next start marker when the frame
+// boundary is unknown.
+func resync(buf []byte, consumed int) []byte {
+	if consumed > 0 {
+		return buf[consumed:]
+	}
+	for i := 1; i+1 < len(buf); i++ {
+		if (buf[i] == 0x78 && buf[i+1] == 0x78) || (buf[i] == 0x79 && buf[i+1] == 0x79) {
+			return buf[i:]
+		}
+	}
+	return buf[:0]
+}
+
+func (s *Server) handlePacket(sess *session, packet Packet) error {
+	switch packet.Protocol {
+	case ProtocolLogin:
+		login, err := ParseLogin(packet.Content)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		vehicleID, ownerID, err := s.resolver.ResolveIMEI(ctx, login.IMEI)
+		cancel()
+		if err != nil {
+			// Not acknowledging makes the terminal retry the login later.
+			return err
+		}
+		sess.imei, sess.vehicleID, sess.ownerID = login.IMEI, vehicleID, ownerID
+		s.logger.Infof("[GT06] device %s logged in from %s as vehicle %s", login.IMEI, sess.conn.RemoteAddr(), vehicleID)
+		return s.respond(sess, packet)
+
+	case ProtocolHeartbeat:
+		if sess.imei == "" {
+			return errors.New("heartbeat before login")
+		}
+		return s.respond(sess, packet)
+
+	case ProtocolLocation, ProtocolLocation2, ProtocolAlarm:
+		if sess.imei == "" {
+			return errors.New("location before login")
+		}
+		fix, err := ParseLocation(packet.Content)
+		if err != nil {
+			s.logger.Warnf("[GT06] device %s sent malformed location: %v", sess.imei, err)
+		} else {
+			s.storeFix(sess, fix)
+		}
+		// Only alarms are acknowledged; plain location packets are not.
+		if packet.Protocol == ProtocolAlarm {
+			return s.respond(sess, packet)
+		}
+		return nil
+
+	default:
+		s.logger.Debugf("[GT06] device %s sent unsupported protocol 0x%02X", sess.imei, packet.Protocol)
+		return nil
+	}
+}
+
+func (s *Server) storeFix(sess *session, fix Location) {
+	if !fix.Positioned {
+		return
+	}
+	location := model.VehicleLocation{
+		OwnerID:   sess.ownerID,
+		VehicleID: sess.vehicleID,
+		Latitude:  fix.Latitude,
+		Longitude: fix.Longitude,
+		Speed:     fix.Speed,
+		Timestamp: fix.Timestamp,
+	}
+	heading, satellites := float64(fix.Course), fix.Satellites
+	location.Heading, location.Satellites = &heading, &satellites
+	if err := location.Validate(); err != nil {
+		s.logger.Warnf("[GT06] device %s sent invalid fix: %v", sess.imei, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := s.sink.UpdateLocation(ctx, location)
+	switch {
+	case errors.Is(err, model.ErrQuarantined), errors.Is(err, model.ErrIngestDenied):
+		s.logger.Warnf("[GT06] device %s: %v", sess.imei, err)
+	case err != nil:
+		s.logger.Errorf("[GT06] failed to store fix for vehicle %s: %v", sess.vehicleID, err)
+	}
+}
+
+func (s *Server) respond(sess *session, packet Packet) error {
+	sess.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := sess.conn.Write(EncodeResponse(packet.Protocol, packet.Serial))
+	return err
+}
@@ -0,0 +1,92 @@
+package gt06
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	model "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/utils"
+)
+
+type stubResolver map[string][2]string
+
+func (r stubResolver) ResolveIMEI(_ context.Context, imei string) (string, string, error) {
+	v, ok := r[imei]
+	if !ok {
+		return "", "", ErrUnknownDevice
+	}
+	return v[0], v[1], nil
+}
+
+type recordingSink struct {
+	mu        sync.Mutex
+	locations []model.VehicleLocation
+	stored    chan struct{}
+}
+
+func (s *recordingSink) UpdateLocation(_ context.Context, loc model.VehicleLocation) (model.VehicleLocation, error) {
+	s.mu.Lock()
+	s.locations = append(s.locations, loc)
+	s.mu.Unlock()
+	s.stored <- struct{}{}
+	return loc, nil
+}
+
+func newTestServer(resolver VehicleResolver, sink LocationSink) (*Server, net.Conn) {
+	server := NewServer(resolver, sink, utils.NewStandardLogger())
+	client, conn := net.Pipe()
+	server.wg.Add(1)
+	server.conns[conn] = struct{}{}
+	go server.serveConn(conn)
+	return server, client
+}
+
+func TestServerLoginAndLocation(t *testing.T) {
+	sink := &recordingSink{stored: make(chan struct{}, 1)}
+	server, client := newTestServer(stubResolver{"123456789012345": {"veh1", "owner1"}}, sink)
+	defer server.Shutdown(context.Background())
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := client.Write(loginFrame); err != nil {
+		t.Fatalf("write login: %v", err)
+	}
+	ack := make([]byte, len(loginAck))
+	if _, err := io.ReadFull(client, ack); err != nil {
+		t.Fatalf("read ack: %v", err)
+	}
+	if !bytes.Equal(ack, loginAck) {
+		t.Fatalf("ack = %X, want %X", ack, loginAck)
+	}
+
+	// Split the location frame to exercise reassembly.
+	client.Write(locationFrame[:7])
+	client.Write(locationFrame[7:])
+
+	select {
+	case <-sink.stored:
+	case <-time.After(5 * time.Second):
+		t.Fatal("location was not stored")
+	}
+	sink.mu.Lock()
+	got := sink.locations[0]
+	sink.mu.Unlock()
+	if got.VehicleID != "veh1" || got.OwnerID != "owner1" {
+		t.Errorf("location attributed to %s/%s, want veh1/owner1", got.VehicleID, got.OwnerID)
+	}
+}
+
+func TestServerRejectsUnknownDevice(t *testing.T) {
+	server, client := newTestServer(stubResolver{}, &recordingSink{stored: make(chan struct{}, 1)})
+	defer server.Shutdown(context.Background())
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	client.Write(loginFrame)
+	if _, err := client.Read(make([]byte, 16)); err != io.EOF {
+		t.Errorf("expected connection to be closed without ack, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package tracker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	model "FMTS/internal/tracking/domain/entity"
+	utility "FMTS/utils"
+)
+
+const (
+	MaxBatchLocations = 1000
+	maxBatchBodyBytes = 5 << 20 // 5MB
+)
+
+// BatchItemResult reports what happened to one element of a batch upload.
+type BatchItemResult struct {
+	Index     int    `json:"index"`
+	VehicleID string `json:"vehicle_id,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchResponse summarises a batch upload.
+type BatchResponse struct {
+	Received int               `json:"received"`
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Results  []BatchItemResult `json:"results"`
+}
+
+// UpdateLocationsBatch accepts a JSON array of locations, or NDJSON (one
+// location per line) when sent as application/x-ndjson. Every item is
+// checked on its own; the valid ones are stored with one bulk write and the
+// response reports the outcome per item. The valid and the quarantined
+// items are written in one transaction: when the write fails nothing is
+// stored and the request fails as a whole.
+func (h *TrackerHandler) UpdateLocationsBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+
+	var (
+		items []json.RawMessage
+		err   error
+	)
+	if isNDJSON(r) {
+		items, err = readNDJSON(r.Body)
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&items)
+	}
+	if err != nil {
+		h.logger.Errorf("[UpdateLocationsBatch] failed to decode request: %v", err)
+		utility.SendErrorResponse(w, "invalid request format", http.StatusBadRequest, nil)
+		return
+	}
+	if len(items) == 0 {
+		utility.SendErrorResponse(w, "batch must contain at least one location", http.StatusBadRequest, nil)
+		return
+	}
+	if len(items) > MaxBatchLocations {
+		utility.SendErrorResponse(w, fmt.Sprintf("batch must contain at most %d locations", MaxBatchLocations), http.StatusRequestEntityTooLarge, nil)
+		return
+	}
+
+	resp := BatchResponse{Received: len(items), Results: make([]BatchItemResult, len(items))}
+	valid := make([]model.VehicleLocation, 0, len(items))
+	validIdx := make([]int, 0, len(items))
+	for i, raw := range items {
+		location, result := h.checkBatchItem(r, raw)
+		result.Index = i
+		if result.Status == "accepted" {
+			valid = append(valid, location)
+			validIdx = append(validIdx, i)
+		}
+		resp.Results[i] = result
+	}
+
+	accepted := len(valid)
+	if len(valid) > 0 {
+		result, err := h.storeBatch(r, valid)
+		if err != nil {
+			h.logger.Errorf("[UpdateLocationsBatch] failed to store %d locations: %v", len(valid), err)
+			utility.SendErrorResponse(w, "failed to store locations", http.StatusInternalServerError, nil)
+			return
+		}
+		for i, reason := range result.Invalid {
+			resp.Results[validIdx[i]].Status = "rejected"
+			resp.Results[validIdx[i]].Error = reason
+			accepted--
+		}
+		for i, reason := range result.Quarantined {
+			resp.Results[validIdx[i]].Status = "quarantined"
+			resp.Results[validIdx[i]].Error = string(reason)
+			accepted--
+		}
+		for i, reason := range result.Denied {
+			resp.Results[validIdx[i]].Status = "rejected"
+			resp.Results[validIdx[i]].Error = string(reason)
+			accepted--
+		}
+	}
+
+	resp.Accepted = accepted
+	resp.Rejected = resp.Received - resp.Accepted
+	utility.WriteSuccessResponse(w, resp, "Location batch processed")
+}
+
+// checkBatchItem decodes one element of a batch and ties it to the caller.
+// Batches stored directly are authorized and validated by the tracker
+// domain; queued ones are checked here, before they reach the queue. The
+// result's Status is "accepted" when the location may be stored.
+func (h *TrackerHandler) checkBatchItem(r *http.Request, raw json.RawMessage) (model.VehicleLocation, BatchItemResult) {
+	var location model.VehicleLocation
+	if err := json.Unmarshal(raw, &location); err != nil {
+		return location, BatchItemResult{Status: "rejected", Error: "invalid location format"}
+	}
+
+	result := BatchItemResult{VehicleID: location.VehicleID}
+	bind := bindLocation
+	if h.kafkaProducer != nil {
+		bind = h.authorizeLocation
+	}
+	authorized, status, err := bind(r, location)
+	if err != nil {
+		result.Status = "rejected"
+		if status == http.StatusInternalServerError {
+			result.Status = "failed"
+		}
+		result.Error = err.Error()
+		return location, result
+	}
+	if h.kafkaProducer == nil {
+		result.Status = "accepted"
+		return authorized, result
+	}
+	if err := authorized.Validate(); err != nil {
+		result.Status = "rejected"
+		result.Error = err.Error()
+		return location, result
+	}
+	result.Status = "accepted"
+	return authorized, result
+}
+
+// storeBatch reports the locations that were invalid, rejected by the
+// ingest filter or refused for their vehicle, keyed by their index in
+// locations. Queued batches are filtered later by the consumer, so nothing
+// is reported for them.
+func (h *TrackerHandler) storeBatch(r *http.Request, locations []model.VehicleLocation) (model.BatchIngestResult, error) {
+	if h.kafkaProducer != nil {
+		return model.BatchIngestResult{}, h.kafkaProducer.ProduceVehicleLocations(r.Context(), locations)
+	}
+	return h.AppTracker.UpdateLocations(r.Context(), locations)
+}
+
+func isNDJSON(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/x-ndjson" || mediaType == "application/ndjson" || mediaType == "application/jsonl"
+}
+
+// readNDJSON splits a newline delimited body into raw items, skipping blank lines.
+func readNDJSON(body io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(append([]byte(nil), line...)))
+		if len(items) > MaxBatchLocations {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("failed to read ndjson body")
+	}
+	return items, nil
+}
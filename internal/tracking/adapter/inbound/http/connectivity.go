@@ -0,0 +1,131 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errOwnerRequired = errors.New("owner_id is required")
+
+// GetVehicleConnectivityTransitions returns the online/idle/offline changes
+// of a vehicle between from and to, newest first.
+func (h *TrackerHandler) GetVehicleConnectivityTransitions(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetVehicleConnectivityTransitions] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleConnectivityTransitions] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleConnectivityTransitions] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[GetVehicleConnectivityTransitions] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	transitions, err := h.AppTracker.GetConnectivityTransitions(r.Context(), model.ConnectivityQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetVehicleConnectivityTransitions] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, transitions, "Connectivity transitions fetched successfully")
+}
+
+// GetConnectivityThresholds returns the idle and offline thresholds in
+// effect for the caller's fleet. Admins name the fleet with owner_id.
+func (h *TrackerHandler) GetConnectivityThresholds(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := thresholdsOwner(r)
+	if err != nil {
+		h.logger.Warnf("[GetConnectivityThresholds] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	thresholds, err := h.AppTracker.GetConnectivityThresholds(r.Context(), ownerID)
+	if err != nil {
+		h.logger.Errorf("[GetConnectivityThresholds] failed: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, thresholds, "Connectivity thresholds fetched successfully")
+}
+
+// SetConnectivityThresholds replaces the idle and offline thresholds of the
+// caller's fleet. Admins name the fleet with owner_id.
+func (h *TrackerHandler) SetConnectivityThresholds(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := thresholdsOwner(r)
+	if err != nil {
+		h.logger.Warnf("[SetConnectivityThresholds] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	var req model.ConnectivityThresholds
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[SetConnectivityThresholds] failed to decode request: %v", err)
+		utility.SendErrorResponse(w, "invalid request format", http.StatusBadRequest, nil)
+		return
+	}
+	req.OwnerID = ownerID
+
+	thresholds, err := h.AppTracker.SetConnectivityThresholds(r.Context(), req)
+	if err != nil {
+		h.logger.Errorf("[SetConnectivityThresholds] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidThresholds) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, thresholds, "Connectivity thresholds updated successfully")
+}
+
+// thresholdsOwner returns the fleet whose thresholds are addressed: the
+// caller's own, or the owner_id query parameter for admins.
+func thresholdsOwner(r *http.Request) (string, error) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		return "", err
+	}
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+	if ownerID == "" {
+		return "", errOwnerRequired
+	}
+	return ownerID, nil
+}
@@ -0,0 +1,316 @@
+package tracker
+
+import (
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	ExportFormatGPX     = "gpx"
+	ExportFormatKML     = "kml"
+	ExportFormatGeoJSON = "geojson"
+
+	// exportFlushEvery pushes buffered output to the client every this many
+	// points, so long exports start downloading immediately.
+	exportFlushEvery = 1000
+)
+
+var errUnsupportedExportFormat = errors.New("unsupported export format: use gpx, kml or geojson")
+
+// exportMediaTypes maps Accept header media types to export formats.
+var exportMediaTypes = map[string]string{
+	"application/gpx+xml":                  ExportFormatGPX,
+	"application/vnd.google-earth.kml+xml": ExportFormatKML,
+	"application/geo+json":                 ExportFormatGeoJSON,
+	"application/json":                     ExportFormatGeoJSON,
+}
+
+// ExportVehicleTrack streams the points of a vehicle in the requested range
+// as GPX, KML or GeoJSON. Points are written while they are read from the
+// database; an error after the first point truncates the document.
+func (h *TrackerHandler) ExportVehicleTrack(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[ExportVehicleTrack] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ExportVehicleTrack] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[ExportVehicleTrack] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if r.URL.Query().Get("format") == "" {
+			status = http.StatusNotAcceptable
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	encoder := newTrackEncoder(format, vehicleID)
+
+	out := bufio.NewWriterSize(w, 32*1024)
+	started := false
+	begin := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		filename := fmt.Sprintf("%s_%s_%s.%s", vehicleID, from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z"), encoder.extension())
+		w.Header().Set("Content-Type", encoder.contentType())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		w.WriteHeader(http.StatusOK)
+		return encoder.begin(out)
+	}
+
+	points := 0
+	err = h.AppTracker.StreamVehicleTrack(r.Context(), model.LocationRangeQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+	}, func(loc model.VehicleLocation) error {
+		if err := begin(); err != nil {
+			return err
+		}
+		if err := encoder.point(out, loc); err != nil {
+			return err
+		}
+		points++
+		if points%exportFlushEvery == 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			h.logger.Errorf("[ExportVehicleTrack] failed: %v", err)
+			status := http.StatusInternalServerError
+			if errors.Is(err, domain.ErrInvalidTimeRange) {
+				status = http.StatusBadRequest
+			}
+			utility.SendErrorResponse(w, err.Error(), status, nil)
+			return
+		}
+		h.logger.Errorf("[ExportVehicleTrack] vehicle %s: export aborted after %d points: %v", vehicleID, points, err)
+		return
+	}
+
+	if err := begin(); err == nil {
+		err = encoder.end(out)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		h.logger.Errorf("[ExportVehicleTrack] vehicle %s: failed to finish export: %v", vehicleID, err)
+	}
+}
+
+// exportFormat picks the format from the format query parameter, falling
+// back to the Accept header and then to GeoJSON.
+func exportFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		switch format {
+		case ExportFormatGPX, ExportFormatKML, ExportFormatGeoJSON:
+			return format, nil
+		case "json":
+			return ExportFormatGeoJSON, nil
+		}
+		return "", errUnsupportedExportFormat
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return ExportFormatGeoJSON, nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		if format, ok := exportMediaTypes[mediaType]; ok {
+			return format, nil
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return ExportFormatGeoJSON, nil
+		}
+	}
+	return "", errUnsupportedExportFormat
+}
+
+// trackEncoder writes one track document point by point.
+type trackEncoder interface {
+	contentType() string
+	extension() string
+	begin(w io.Writer) error
+	point(w io.Writer, loc model.VehicleLocation) error
+	end(w io.Writer) error
+}
+
+func newTrackEncoder(format, vehicleID string) trackEncoder {
+	switch format {
+	case ExportFormatGPX:
+		return &gpxEncoder{vehicleID: vehicleID}
+	case ExportFormatKML:
+		return &kmlEncoder{vehicleID: vehicleID}
+	}
+	return &geoJSONEncoder{vehicleID: vehicleID}
+}
+
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 7, 64)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// gpxEncoder writes a GPX 1.1 document with a single track segment.
+type gpxEncoder struct {
+	vehicleID string
+}
+
+func (e *gpxEncoder) contentType() string { return "application/gpx+xml" }
+func (e *gpxEncoder) extension() string   { return "gpx" }
+
+func (e *gpxEncoder) begin(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="FMTS" xmlns="http://www.topografix.com/GPX/1/1">
+<trk><name>%s</name><trkseg>
+`, escapeXML(e.vehicleID))
+	return err
+}
+
+// point writes a track point with the telemetry GPX has elements for, in
+// the order the schema requires.
+func (e *gpxEncoder) point(w io.Writer, loc model.VehicleLocation) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<trkpt lat="%s" lon="%s">`, formatCoordinate(loc.Latitude), formatCoordinate(loc.Longitude))
+	if loc.Altitude != nil {
+		fmt.Fprintf(&b, "<ele>%s</ele>", strconv.FormatFloat(*loc.Altitude, 'f', -1, 64))
+	}
+	fmt.Fprintf(&b, "<time>%s</time>", loc.Timestamp.UTC().Format(time.RFC3339))
+	if loc.Satellites != nil {
+		fmt.Fprintf(&b, "<sat>%d</sat>", *loc.Satellites)
+	}
+	if loc.HDOP != nil {
+		fmt.Fprintf(&b, "<hdop>%s</hdop>", strconv.FormatFloat(*loc.HDOP, 'f', -1, 64))
+	}
+	b.WriteString("</trkpt>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (e *gpxEncoder) end(w io.Writer) error {
+	_, err := io.WriteString(w, "</trkseg></trk>\n</gpx>\n")
+	return err
+}
+
+// kmlEncoder writes a KML document with the track as a LineString
+// placemark. gx:Track would keep timestamps, but its schema lists every
+// <when> before the first <gx:coord>, which cannot be streamed in one pass.
+type kmlEncoder struct {
+	vehicleID string
+}
+
+func (e *kmlEncoder) contentType() string { return "application/vnd.google-earth.kml+xml" }
+func (e *kmlEncoder) extension() string   { return "kml" }
+
+func (e *kmlEncoder) begin(w io.Writer) error {
+	name := escapeXML(e.vehicleID)
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document><name>%s</name>
+<Placemark><name>%s</name><LineString><tessellate>1</tessellate><coordinates>
+`, name, name)
+	return err
+}
+
+func (e *kmlEncoder) point(w io.Writer, loc model.VehicleLocation) error {
+	_, err := fmt.Fprintf(w, "%s,%s,0\n", formatCoordinate(loc.Longitude), formatCoordinate(loc.Latitude))
+	return err
+}
+
+func (e *kmlEncoder) end(w io.Writer) error {
+	_, err := io.WriteString(w, "</coordinates></LineString></Placemark>\n</Document>\n</kml>\n")
+	return err
+}
+
+// geoJSONEncoder writes a FeatureCollection with one LineString feature.
+// The properties follow the geometry so the summary can be filled in once
+// every point has been written.
+type geoJSONEncoder struct {
+	vehicleID string
+	count     int
+	first     time.Time
+	last      time.Time
+}
+
+func (e *geoJSONEncoder) contentType() string { return "application/geo+json" }
+func (e *geoJSONEncoder) extension() string   { return "geojson" }
+
+func (e *geoJSONEncoder) begin(w io.Writer) error {
+	_, err := io.WriteString(w, `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"LineString","coordinates":[`)
+	return err
+}
+
+func (e *geoJSONEncoder) point(w io.Writer, loc model.VehicleLocation) error {
+	sep := ","
+	if e.count == 0 {
+		sep = ""
+		e.first = loc.Timestamp
+	}
+	e.count++
+	e.last = loc.Timestamp
+	_, err := fmt.Fprintf(w, "%s\n[%s,%s]", sep, formatCoordinate(loc.Longitude), formatCoordinate(loc.Latitude))
+	return err
+}
+
+func (e *geoJSONEncoder) end(w io.Writer) error {
+	properties := map[string]any{
+		"vehicle_id":  e.vehicleID,
+		"point_count": e.count,
+	}
+	if e.count > 0 {
+		properties["start_time"] = e.first.UTC().Format(time.RFC3339)
+		properties["end_time"] = e.last.UTC().Format(time.RFC3339)
+	}
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n]},\"properties\":%s}]}\n", encoded)
+	return err
+}
@@ -0,0 +1,102 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetFuelReport returns the daily fuel consumption of a vehicle between
+// from and to, with liters per 100 km and the refuels and drains detected.
+func (h *TrackerHandler) GetFuelReport(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetFuelReport] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetFuelReport] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetFuelReport] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	report, err := h.AppTracker.GetFuelReport(r.Context(), model.FuelReportQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetFuelReport] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, report, "Fuel report fetched successfully")
+}
+
+// GetFuelEvents lists the refuels and drains of the caller's fleet between
+// from and to, optionally for one vehicle_id and one type. Admins see every
+// owner's fleet, or one owner's when owner_id is given.
+func (h *TrackerHandler) GetFuelEvents(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetFuelEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetFuelEvents] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[GetFuelEvents] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	events, err := h.AppTracker.GetFuelEvents(r.Context(), model.FuelEventQuery{
+		OwnerID:   ownerID,
+		VehicleID: r.URL.Query().Get("vehicle_id"),
+		Type:      r.URL.Query().Get("type"),
+		From:      from,
+		To:        to,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetFuelEvents] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) || errors.Is(err, domain.ErrInvalidFuelEventType) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, events, "Fuel events fetched successfully")
+}
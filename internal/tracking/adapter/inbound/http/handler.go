@@ -11,7 +11,10 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func InitTrackerRoutes(router chi.Router, userHandler inbound.TrackerPortHandler, authMiddleware middleware.AuthMiddleware) {
+// InitTrackerRoutes registers the tracker routes. deviceAuth authenticates
+// the location uploads, which trackers may make with a device credential
+// instead of a user token.
+func InitTrackerRoutes(router chi.Router, userHandler inbound.TrackerPortHandler, authMiddleware middleware.AuthMiddleware, deviceAuth func(http.Handler) http.Handler) {
 	router.Route("/tracker", func(r chi.Router) {
 		routes := []route.Route{
 			{
@@ -19,8 +22,105 @@ func InitTrackerRoutes(router chi.Router, userHandler inbound.TrackerPortHandler
 				Path:    "/",
 				Handler: userHandler.UpdateLocation,
 				Middlewares: []func(http.Handler) http.Handler{
+					deviceAuth,
+					authMiddleware.AccessControl([]string{"ADMIN", "USER", "DEVICE"}),
+				},
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/batch",
+				Handler: userHandler.UpdateLocationsBatch,
+				Middlewares: []func(http.Handler) http.Handler{
+					deviceAuth,
+					authMiddleware.AccessControl([]string{"ADMIN", "USER", "DEVICE"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/live",
+				Handler: userHandler.StreamLocations,
+				Middlewares: []func(http.Handler) http.Handler{
+					tokenFromProtocol,
 					authMiddleware.AuthenticateToken,
-					authMiddleware.AccessControl([]string{"ADMIN", "USER"}),
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/nearby",
+				Handler: userHandler.FindNearbyVehicles,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/heatmap",
+				Handler: userHandler.GetHeatmap,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/retention/run",
+				Handler: userHandler.RunRetention,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/retention/runs",
+				Handler: userHandler.ListRetentionReports,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/connectivity/thresholds",
+				Handler: userHandler.GetConnectivityThresholds,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/connectivity/thresholds",
+				Handler: userHandler.SetConnectivityThresholds,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				// Public: the share token is the credential.
+				Method:  http.MethodGet,
+				Path:    "/shared/{token}",
+				Handler: userHandler.ViewSharedLocation,
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/stops/geofences",
+				Handler: userHandler.GetGeofenceVisits,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/fuel/events",
+				Handler: userHandler.GetFuelEvents,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
 				},
 			},
 			{
@@ -31,6 +131,114 @@ func InitTrackerRoutes(router chi.Router, userHandler inbound.TrackerPortHandler
 				// 	authMiddleware.AuthenticateToken,
 				// },
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/history",
+				Handler: userHandler.GetVehicleLocationHistory,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/trips",
+				Handler: userHandler.GetVehicleTrips,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/distance",
+				Handler: userHandler.GetVehicleDistance,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/export",
+				Handler: userHandler.ExportVehicleTrack,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/stops",
+				Handler: userHandler.GetVehicleStops,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/fuel",
+				Handler: userHandler.GetFuelReport,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/{vehicle_id}/shares",
+				Handler: userHandler.CreateShareLink,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/shares",
+				Handler: userHandler.ListShareLinks,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/{vehicle_id}/shares/{share_id}",
+				Handler: userHandler.RevokeShareLink,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/shares/{share_id}/accesses",
+				Handler: userHandler.GetShareAccesses,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/connectivity",
+				Handler: userHandler.GetVehicleConnectivityTransitions,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{vehicle_id}/quarantine",
+				Handler: userHandler.GetQuarantinedLocations,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/",
@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+)
+
+// GetHeatmap aggregates the caller's fleet points between from and to into
+// geohash cells. The precision comes from precision (1-9) or from the map
+// zoom level; limit caps the number of cells. Admins see every owner's
+// fleet, or one owner's when owner_id is given.
+func (h *TrackerHandler) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetHeatmap] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetHeatmap] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	zoom, err := utility.ParseOptionalInt(r, "zoom")
+	if err != nil {
+		h.logger.Warnf("[GetHeatmap] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	precision, err := utility.ParseOptionalInt(r, "precision")
+	if err != nil {
+		h.logger.Warnf("[GetHeatmap] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[GetHeatmap] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	heatmap, err := h.AppTracker.GetHeatmap(r.Context(), model.HeatmapQuery{
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+		Zoom:      zoom,
+		Precision: precision,
+		MaxCells:  limit,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetHeatmap] failed: %v", err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange), errors.Is(err, domain.ErrHeatmapRangeTooLarge),
+			errors.Is(err, domain.ErrInvalidPrecision), errors.Is(err, domain.ErrInvalidZoom):
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, heatmap, "Heatmap fetched successfully")
+}
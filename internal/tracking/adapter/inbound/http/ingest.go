@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"FMTS/internal/middleware"
+	model "FMTS/internal/tracking/domain/entity"
+	contexts "FMTS/pkg/context"
+)
+
+var (
+	errOwnerMismatch         = errors.New("owner_id does not match the authenticated user")
+	errDeviceUnbound         = errors.New("device is not bound to a vehicle")
+	errDeviceVehicleMismatch = errors.New("vehicle_id does not match the vehicle the device is bound to")
+)
+
+// authorizeLocation ties a submitted location to the caller and checks it
+// against the vehicle registry. The returned status is meant for the
+// response when err is set.
+func (h *TrackerHandler) authorizeLocation(r *http.Request, location model.VehicleLocation) (model.VehicleLocation, int, error) {
+	location, status, err := bindLocation(r, location)
+	if err != nil {
+		return model.VehicleLocation{}, status, err
+	}
+	authorized, err := h.AppTracker.AuthorizeLocation(r.Context(), location)
+	if err != nil {
+		return model.VehicleLocation{}, ingestErrorStatus(err), err
+	}
+	return authorized, http.StatusOK, nil
+}
+
+// bindLocation ties a submitted location to the caller. Users may only
+// report for themselves, so owner_id defaults to the caller and any other
+// value is refused. Admins may report for any owner and may leave owner_id
+// empty to take it from the vehicle. Devices report as their owner and only
+// for the vehicle they are bound to, which vehicle_id defaults to.
+func bindLocation(r *http.Request, location model.VehicleLocation) (model.VehicleLocation, int, error) {
+	u := contexts.ExtractUserContext(r)
+	if strings.EqualFold(u.UserRole, middleware.RoleDevice) {
+		switch {
+		case u.VehicleID == "":
+			return model.VehicleLocation{}, http.StatusForbidden, errDeviceUnbound
+		case location.VehicleID == "":
+			location.VehicleID = u.VehicleID
+		case location.VehicleID != u.VehicleID:
+			return model.VehicleLocation{}, http.StatusForbidden, errDeviceVehicleMismatch
+		}
+	}
+	if !u.IsAdmin() {
+		if u.UserID == "" {
+			return model.VehicleLocation{}, http.StatusBadRequest, contexts.ErrMissingUserID
+		}
+		if location.OwnerID != "" && location.OwnerID != u.UserID {
+			return model.VehicleLocation{}, http.StatusForbidden, errOwnerMismatch
+		}
+		location.OwnerID = u.UserID
+	}
+	return location, http.StatusOK, nil
+}
+
+// ingestErrorStatus maps an authorization error to a response status.
+func ingestErrorStatus(err error) int {
+	var denied *model.IngestDeniedError
+	switch {
+	case errors.As(err, &denied) && denied.Reason == model.DenialUnknownVehicle:
+		return http.StatusNotFound
+	case errors.As(err, &denied):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
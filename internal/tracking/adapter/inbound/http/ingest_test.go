@@ -0,0 +1,145 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"FMTS/internal/middleware"
+	model "FMTS/internal/tracking/domain/entity"
+	contexts "FMTS/pkg/context"
+	constant "FMTS/utils"
+)
+
+// requestAs returns a request carrying the given user context values.
+func requestAs(values map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/tracker/locations", nil)
+	ctx := r.Context()
+	for key, value := range values {
+		ctx = context.WithValue(ctx, constant.ContextKey(key), value)
+	}
+	return r.WithContext(ctx)
+}
+
+func TestBindLocation(t *testing.T) {
+	user := map[string]string{"user_id": "owner1", "user_role": "USER"}
+	admin := map[string]string{"user_id": "admin1", "user_role": "ADMIN"}
+	device := map[string]string{"user_id": "owner1", "user_role": middleware.RoleDevice, "device_id": "d1", "vehicle_id": "v1"}
+	unbound := map[string]string{"user_id": "owner1", "user_role": middleware.RoleDevice, "device_id": "d1"}
+
+	cases := []struct {
+		name        string
+		caller      map[string]string
+		location    model.VehicleLocation
+		wantStatus  int
+		wantErr     error
+		wantOwner   string
+		wantVehicle string
+	}{
+		{
+			name:        "user reports for itself",
+			caller:      user,
+			location:    model.VehicleLocation{VehicleID: "v1"},
+			wantStatus:  http.StatusOK,
+			wantOwner:   "owner1",
+			wantVehicle: "v1",
+		},
+		{
+			name:       "user reports for another owner",
+			caller:     user,
+			location:   model.VehicleLocation{VehicleID: "v1", OwnerID: "owner2"},
+			wantStatus: http.StatusForbidden,
+			wantErr:    errOwnerMismatch,
+		},
+		{
+			name:       "user without user ID",
+			caller:     map[string]string{"user_role": "USER"},
+			location:   model.VehicleLocation{VehicleID: "v1"},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    contexts.ErrMissingUserID,
+		},
+		{
+			name:        "admin reports for any owner",
+			caller:      admin,
+			location:    model.VehicleLocation{VehicleID: "v1", OwnerID: "owner2"},
+			wantStatus:  http.StatusOK,
+			wantOwner:   "owner2",
+			wantVehicle: "v1",
+		},
+		{
+			name:        "admin leaves the owner to the vehicle",
+			caller:      admin,
+			location:    model.VehicleLocation{VehicleID: "v1"},
+			wantStatus:  http.StatusOK,
+			wantOwner:   "",
+			wantVehicle: "v1",
+		},
+		{
+			name:        "device defaults to its vehicle",
+			caller:      device,
+			location:    model.VehicleLocation{},
+			wantStatus:  http.StatusOK,
+			wantOwner:   "owner1",
+			wantVehicle: "v1",
+		},
+		{
+			name:       "device reports for another vehicle",
+			caller:     device,
+			location:   model.VehicleLocation{VehicleID: "v2"},
+			wantStatus: http.StatusForbidden,
+			wantErr:    errDeviceVehicleMismatch,
+		},
+		{
+			name:       "device reports for another owner",
+			caller:     device,
+			location:   model.VehicleLocation{VehicleID: "v1", OwnerID: "owner2"},
+			wantStatus: http.StatusForbidden,
+			wantErr:    errOwnerMismatch,
+		},
+		{
+			name:       "unbound device",
+			caller:     unbound,
+			location:   model.VehicleLocation{VehicleID: "v1"},
+			wantStatus: http.StatusForbidden,
+			wantErr:    errDeviceUnbound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, status, err := bindLocation(requestAs(tc.caller), tc.location)
+			if status != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tc.wantStatus)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.OwnerID != tc.wantOwner || got.VehicleID != tc.wantVehicle {
+				t.Errorf("bound to owner %q vehicle %q, want owner %q vehicle %q", got.OwnerID, got.VehicleID, tc.wantOwner, tc.wantVehicle)
+			}
+		})
+	}
+}
+
+func TestIngestErrorStatus(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{&model.IngestDeniedError{VehicleID: "v1", Reason: model.DenialUnknownVehicle}, http.StatusNotFound},
+		{&model.IngestDeniedError{VehicleID: "v1", Reason: model.DenialOwnerMismatch}, http.StatusForbidden},
+		{&model.IngestDeniedError{VehicleID: "v1", Reason: model.DenialVehicleDeleted}, http.StatusForbidden},
+		{&model.IngestDeniedError{VehicleID: "v1", Reason: model.DenialVehicleDisabled}, http.StatusForbidden},
+		{errors.New("registry down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		if got := ingestErrorStatus(tc.err); got != tc.want {
+			t.Errorf("ingestErrorStatus(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+)
+
+// FindNearbyVehicles lists the caller's vehicles whose latest position is
+// within radius meters (default 5 km) of lat/lng, nearest first. Admins
+// search every owner's vehicles, or one owner's when owner_id is given.
+func (h *TrackerHandler) FindNearbyVehicles(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[FindNearbyVehicles] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	lat, err := parseCoordinate(r, "lat")
+	if err != nil {
+		h.logger.Warnf("[FindNearbyVehicles] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	lng, err := parseCoordinate(r, "lng")
+	if err != nil {
+		h.logger.Warnf("[FindNearbyVehicles] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	radius, err := utility.ParseOptionalFloat(r, "radius")
+	if err != nil {
+		h.logger.Warnf("[FindNearbyVehicles] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[FindNearbyVehicles] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	result, err := h.AppTracker.FindNearbyVehicles(r.Context(), model.NearbyQuery{
+		OwnerID:      ownerID,
+		Latitude:     lat,
+		Longitude:    lng,
+		RadiusMeters: radius,
+		VehicleType:  r.URL.Query().Get("type"),
+		Limit:        limit,
+	})
+	if err != nil {
+		h.logger.Errorf("[FindNearbyVehicles] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidCoordinates) || errors.Is(err, domain.ErrInvalidRadius) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, result, "Nearby vehicles fetched successfully")
+}
+
+// parseCoordinate reads a required decimal degree query param.
+func parseCoordinate(r *http.Request, name string) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, errors.New(name + " is required")
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, errors.New(name + " must be a number")
+	}
+	return v, nil
+}
@@ -0,0 +1,59 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "FMTS/internal/tracking/domain/service"
+	utility "FMTS/utils"
+)
+
+// RunRetention starts a retention run immediately. With dry_run=true it only
+// reports what a real run would summarise and delete.
+func (h *TrackerHandler) RunRetention(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			h.logger.Warnf("[RunRetention] invalid dry_run %q", raw)
+			utility.SendErrorResponse(w, "invalid dry_run parameter", http.StatusBadRequest, nil)
+			return
+		}
+		dryRun = v
+	}
+
+	report, err := h.AppTracker.RunRetention(r.Context(), dryRun)
+	if err != nil {
+		h.logger.Errorf("[RunRetention] failed: %v", err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrRetentionRunning):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrRetentionDisabled), errors.Is(err, domain.ErrInvalidRetention):
+			status = http.StatusUnprocessableEntity
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, report, "Retention run completed")
+}
+
+// ListRetentionReports returns the reports of the most recent retention
+// runs, newest first.
+func (h *TrackerHandler) ListRetentionReports(w http.ResponseWriter, r *http.Request) {
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[ListRetentionReports] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	reports, err := h.AppTracker.ListRetentionReports(r.Context(), limit)
+	if err != nil {
+		h.logger.Errorf("[ListRetentionReports] failed: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, reports, "Retention runs fetched successfully")
+}
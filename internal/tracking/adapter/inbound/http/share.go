@@ -0,0 +1,190 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxUserAgentLength bounds the user agent kept in the share access audit.
+const maxUserAgentLength = 512
+
+// shareLinkRequest creates a share link. expires_in_seconds and expires_at
+// are alternatives; without either the link gets the default lifetime.
+type shareLinkRequest struct {
+	Label            string     `json:"label"`
+	ExpiresInSeconds int64      `json:"expires_in_seconds"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	WindowFrom       *time.Time `json:"window_from"`
+	WindowTo         *time.Time `json:"window_to"`
+}
+
+// CreateShareLink creates a public link to a vehicle's position. The token
+// in the response is shown only once.
+func (h *TrackerHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[CreateShareLink] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[CreateShareLink] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	var req shareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Errorf("[CreateShareLink] failed to decode request: %v", err)
+		utility.SendErrorResponse(w, "invalid request format", http.StatusBadRequest, nil)
+		return
+	}
+	if req.ExpiresInSeconds < 0 || (req.ExpiresInSeconds > 0 && req.ExpiresAt != nil) {
+		h.logger.Warnf("[CreateShareLink] invalid expiry")
+		utility.SendErrorResponse(w, "give either a positive expires_in_seconds or expires_at", http.StatusBadRequest, nil)
+		return
+	}
+
+	link := model.ShareLink{
+		VehicleID:  vehicleID,
+		OwnerID:    ownerID,
+		CreatedBy:  contexts.ExtractUserContext(r).UserID,
+		Label:      req.Label,
+		WindowFrom: req.WindowFrom,
+		WindowTo:   req.WindowTo,
+	}
+	if req.ExpiresAt != nil {
+		link.ExpiresAt = req.ExpiresAt.UTC()
+	}
+	if req.ExpiresInSeconds > 0 {
+		link.ExpiresAt = time.Now().UTC().Add(time.Duration(req.ExpiresInSeconds) * time.Second)
+	}
+
+	created, err := h.AppTracker.CreateShareLink(r.Context(), link)
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), shareErrorStatus(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, created, "Share link created successfully")
+}
+
+// ListShareLinks returns the share links of a vehicle with their access
+// counts.
+func (h *TrackerHandler) ListShareLinks(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[ListShareLinks] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	links, err := h.AppTracker.ListShareLinks(r.Context(), vehicleID, ownerID)
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, links, "Share links fetched successfully")
+}
+
+// RevokeShareLink makes a share link unusable immediately.
+func (h *TrackerHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[RevokeShareLink] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	shareID, err := strconv.ParseInt(chi.URLParam(r, "share_id"), 10, 64)
+	if err != nil {
+		h.logger.Warnf("[RevokeShareLink] invalid share_id: %v", err)
+		utility.SendErrorResponse(w, "share_id must be an integer", http.StatusBadRequest, nil)
+		return
+	}
+
+	link, err := h.AppTracker.RevokeShareLink(r.Context(), vehicleID, ownerID, shareID)
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), shareErrorStatus(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, link, "Share link revoked successfully")
+}
+
+// GetShareAccesses returns the audit of a share link, newest first.
+func (h *TrackerHandler) GetShareAccesses(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetShareAccesses] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	shareID, err := strconv.ParseInt(chi.URLParam(r, "share_id"), 10, 64)
+	if err != nil {
+		h.logger.Warnf("[GetShareAccesses] invalid share_id: %v", err)
+		utility.SendErrorResponse(w, "share_id must be an integer", http.StatusBadRequest, nil)
+		return
+	}
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[GetShareAccesses] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	accesses, err := h.AppTracker.GetShareAccesses(r.Context(), vehicleID, ownerID, shareID, limit)
+	if err != nil {
+		utility.SendErrorResponse(w, err.Error(), shareErrorStatus(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, accesses, "Share link accesses fetched successfully")
+}
+
+// ViewSharedLocation is the unauthenticated endpoint behind a share link. It
+// shows the vehicle's latest position and track while the link is valid.
+func (h *TrackerHandler) ViewSharedLocation(w http.ResponseWriter, r *http.Request) {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	view, err := h.AppTracker.ViewShareLink(r.Context(), chi.URLParam(r, "token"), model.ShareAccess{
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  userAgent,
+	})
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		h.logger.Warnf("[ViewSharedLocation] %v", err)
+		utility.SendErrorResponse(w, err.Error(), shareErrorStatus(err), nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, view, "Shared location fetched successfully")
+}
+
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidShare):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrShareForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrShareNotFound), errors.Is(err, domain.ErrShareVehicleNotFound),
+		errors.Is(err, domain.ErrShareNotStarted):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrShareExpired), errors.Is(err, domain.ErrShareRevoked):
+		return http.StatusGone
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,94 @@
+package tracker
+
+import (
+	"errors"
+	"net/http"
+
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetVehicleStops returns the stops of a vehicle that overlap from and to,
+// with their arrival and departure times and the geofences they lay in.
+func (h *TrackerHandler) GetVehicleStops(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetVehicleStops] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleStops] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleStops] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	stops, err := h.AppTracker.GetVehicleStops(r.Context(), model.StopQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetVehicleStops] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, stops, "Stops fetched successfully")
+}
+
+// GetGeofenceVisits summarises per geofence the stops the caller's fleet
+// made between from and to, optionally for one vehicle_id. Admins see every
+// owner's fleet, or one owner's when owner_id is given.
+func (h *TrackerHandler) GetGeofenceVisits(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetGeofenceVisits] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if ownerID == "" {
+		ownerID = r.URL.Query().Get("owner_id")
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetGeofenceVisits] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	report, err := h.AppTracker.GetGeofenceVisits(r.Context(), model.GeofenceVisitQuery{
+		OwnerID:   ownerID,
+		VehicleID: r.URL.Query().Get("vehicle_id"),
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetGeofenceVisits] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, report, "Geofence visits fetched successfully")
+}
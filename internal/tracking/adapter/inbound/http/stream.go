@@ -0,0 +1,281 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"FMTS/internal/tracking/adapter/inbound/live"
+	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+	contexts "FMTS/pkg/context"
+	utility "FMTS/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	streamWriteWait     = 10 * time.Second
+	streamPongWait      = 60 * time.Second
+	streamPingPeriod    = 25 * time.Second
+	streamMaxMessageLen = 4096
+	streamAllVehicles   = "*"
+)
+
+func newStreamUpgrader(allowedOrigins []string) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+		// Echoed back to clients that authenticate through the subprotocol.
+		Subprotocols: []string{streamTokenProtocol},
+		CheckOrigin: func(r *http.Request) bool {
+			return originAllowed(r, allowedOrigins)
+		},
+	}
+}
+
+// originAllowed accepts requests without an Origin header (non-browser
+// clients), from the API's own host and from the allowed origins, where "*"
+// allows every origin.
+func originAllowed(r *http.Request, allowedOrigins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamRequest is a client message on the live location socket.
+type streamRequest struct {
+	Action     string   `json:"action"`
+	VehicleIDs []string `json:"vehicle_ids,omitempty"`
+}
+
+// streamMessage is a server message on the live location socket.
+type streamMessage struct {
+	Type       string                 `json:"type"`
+	Code       int                    `json:"code,omitempty"`
+	Data       *model.VehicleLocation `json:"data,omitempty"`
+	VehicleIDs []string               `json:"vehicle_ids,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	Time       time.Time              `json:"time,omitempty"`
+}
+
+// StreamLocations upgrades the request to a WebSocket and pushes every new
+// location of the vehicles the client subscribed to. Clients send
+// {"action":"subscribe","vehicle_ids":[...]} / "unsubscribe" ("*" means all
+// of the caller's vehicles) and "ping"; the server sends "location",
+// "subscribed", "unsubscribed", "heartbeat", "pong" and "error" messages.
+// Vehicles are visible to the same callers as on the REST API; subscribing
+// to an unknown vehicle is an error with code 404, to another owner's
+// vehicle one with code 403.
+func (h *TrackerHandler) StreamLocations(w http.ResponseWriter, r *http.Request) {
+	if h.liveHub == nil {
+		utility.SendErrorResponse(w, "live stream is not enabled", http.StatusServiceUnavailable, nil)
+		return
+	}
+
+	u := contexts.ExtractUserContext(r)
+	if u.UserID == "" {
+		h.logger.Warnf("[StreamLocations] user context missing")
+		utility.SendErrorResponse(w, "unauthorized", http.StatusUnauthorized, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[StreamLocations] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Warnf("[StreamLocations] upgrade failed: %v", err)
+		return
+	}
+
+	sub := h.liveHub.Register(u.UserID, ownerID)
+	defer h.liveHub.Unregister(sub)
+	h.logger.Infof("[StreamLocations] user %s connected", u.UserID)
+
+	replies := make(chan streamMessage, 16)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go h.readStream(ctx, cancel, conn, sub, replies)
+	h.writeStream(ctx, conn, sub, replies)
+	h.logger.Infof("[StreamLocations] user %s disconnected", u.UserID)
+}
+
+// readStream handles client messages until the connection fails.
+func (h *TrackerHandler) readStream(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, sub *live.Subscriber, replies chan<- streamMessage) {
+	defer cancel()
+
+	conn.SetReadLimit(streamMaxMessageLen)
+	_ = conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+
+	for {
+		var req streamRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				h.logger.Warnf("[StreamLocations] read failed: %v", err)
+			}
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(streamPongWait))
+
+		for _, reply := range h.handleStreamRequest(ctx, sub, req) {
+			select {
+			case replies <- reply:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (h *TrackerHandler) handleStreamRequest(ctx context.Context, sub *live.Subscriber, req streamRequest) []streamMessage {
+	switch strings.ToLower(req.Action) {
+	case "ping":
+		return []streamMessage{{Type: "pong", Time: time.Now().UTC()}}
+	case "subscribe":
+		if len(req.VehicleIDs) == 0 {
+			return []streamMessage{{Type: "error", Code: http.StatusBadRequest, Message: "vehicle_ids is required"}}
+		}
+		var granted []string
+		denied := make(map[int][]string)
+		for _, id := range req.VehicleIDs {
+			if id == streamAllVehicles {
+				sub.SubscribeAll(true)
+				granted = append(granted, id)
+				continue
+			}
+			if status := h.vehicleAccessStatus(ctx, sub, id); status != http.StatusOK {
+				denied[status] = append(denied[status], id)
+				continue
+			}
+			sub.Subscribe(id)
+			granted = append(granted, id)
+		}
+
+		var replies []streamMessage
+		if len(granted) > 0 {
+			replies = append(replies, streamMessage{Type: "subscribed", VehicleIDs: granted})
+		}
+		for _, status := range []int{http.StatusNotFound, http.StatusForbidden, http.StatusInternalServerError} {
+			if ids := denied[status]; len(ids) > 0 {
+				replies = append(replies, streamMessage{Type: "error", Code: status, Message: streamDenialMessages[status], VehicleIDs: ids})
+			}
+		}
+		return replies
+	case "unsubscribe":
+		for _, id := range req.VehicleIDs {
+			if id == streamAllVehicles {
+				sub.SubscribeAll(false)
+			}
+		}
+		sub.Unsubscribe(req.VehicleIDs...)
+		return []streamMessage{{Type: "unsubscribed", VehicleIDs: req.VehicleIDs}}
+	}
+	return []streamMessage{{Type: "error", Code: http.StatusBadRequest, Message: "unknown action"}}
+}
+
+var streamDenialMessages = map[int]string{
+	http.StatusNotFound:            "vehicle not found",
+	http.StatusForbidden:           "access denied",
+	http.StatusInternalServerError: "failed to check vehicle access",
+}
+
+// vehicleAccessStatus checks a vehicle against the registry like the REST
+// API does and returns the status of the outcome.
+func (h *TrackerHandler) vehicleAccessStatus(ctx context.Context, sub *live.Subscriber, vehicleID string) int {
+	err := h.AppTracker.CheckVehicleAccess(ctx, vehicleID, sub.OwnerID())
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, domain.ErrVehicleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrVehicleForbidden):
+		return http.StatusForbidden
+	default:
+		h.logger.Errorf("[StreamLocations] vehicle %s: %v", vehicleID, err)
+		return http.StatusInternalServerError
+	}
+}
+
+// writeStream is the only writer of the connection: it forwards locations,
+// replies and heartbeats until the client leaves or is evicted as too slow.
+func (h *TrackerHandler) writeStream(ctx context.Context, conn *websocket.Conn, sub *live.Subscriber, replies <-chan streamMessage) {
+	ticker := time.NewTicker(streamPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	write := func(msg streamMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+		return conn.WriteJSON(msg) == nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Done():
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "client too slow"))
+			return
+		case location := <-sub.Messages():
+			if !write(streamMessage{Type: "location", Data: &location}) {
+				return
+			}
+		case reply := <-replies:
+			if !write(reply) {
+				return
+			}
+		case now := <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			if !write(streamMessage{Type: "heartbeat", Time: now.UTC()}) {
+				return
+			}
+		}
+	}
+}
+
+// streamTokenProtocol is the WebSocket subprotocol browser clients, which
+// cannot set headers, pass the access token with:
+// new WebSocket(url, ["bearer", token]). The token is never taken from the
+// URL, which ends up in request logs.
+const streamTokenProtocol = "bearer"
+
+// tokenFromProtocol turns a "bearer, <token>" Sec-WebSocket-Protocol header
+// into an Authorization header when the request has none.
+func tokenFromProtocol(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			protocols := websocket.Subprotocols(r)
+			if len(protocols) == 2 && strings.EqualFold(protocols[0], streamTokenProtocol) && protocols[1] != "" {
+				r.Header.Set("Authorization", "Bearer "+protocols[1])
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,29 +2,39 @@
 package tracker
 
 import (
+	"FMTS/internal/tracking/adapter/inbound/live"
 	app "FMTS/internal/tracking/application"
 	model "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
 	"FMTS/kafka"
 	"FMTS/pkg/utils"
 	utility "FMTS/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	contexts "FMTS/pkg/context"
 	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
 )
 
 type TrackerHandler struct {
 	kafkaProducer *kafka.KafkaProducer
 	AppTracker    app.TrackerApplication
+	liveHub       *live.Hub
+	upgrader      websocket.Upgrader
 	logger        utils.Logger
 }
 
-func NewTrackerHandler(producer *kafka.KafkaProducer, appTracker app.TrackerApplication, logger utility.Logger) *TrackerHandler {
+// NewTrackerHandler builds the tracker handler. allowedOrigins are the
+// browser origins the live stream accepts, as configured for CORS.
+func NewTrackerHandler(producer *kafka.KafkaProducer, appTracker app.TrackerApplication, liveHub *live.Hub, allowedOrigins []string, logger utility.Logger) *TrackerHandler {
 	return &TrackerHandler{
 		kafkaProducer: producer,
 		AppTracker:    appTracker,
+		liveHub:       liveHub,
+		upgrader:      newStreamUpgrader(allowedOrigins),
 		logger:        logger,
 	}
 }
@@ -36,13 +46,41 @@ func (h *TrackerHandler) UpdateLocation(w http.ResponseWriter, r *http.Request)
 		utility.SendErrorResponse(w, "invalid request format", http.StatusBadRequest, nil)
 		return
 	}
+	authorized, status, err := h.authorizeLocation(r, req)
+	if err != nil {
+		h.logger.Warnf("[UpdateLocation] vehicle %s: %v", req.VehicleID, err)
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	req = authorized
 	if err := req.Validate(); err != nil {
 		h.logger.Warnf("[UpdateLocation] validation failed: %v", err)
 		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
 		return
 	}
 
+	if h.kafkaProducer != nil {
+		// Queued ingestion: the location consumer persists the point.
+		if err := h.kafkaProducer.ProduceVehicleLocation(r.Context(), req); err != nil {
+			h.logger.Errorf("[UpdateLocation] failed to publish location: %v", err)
+			utility.SendErrorResponse(w, "failed to queue location", http.StatusServiceUnavailable, nil)
+			return
+		}
+		utility.WriteSuccessResponse(w, req, "Location accepted for processing")
+		return
+	}
+
 	locationUpdated, err := h.AppTracker.UpdateLocation(r.Context(), req)
+	if errors.Is(err, model.ErrIngestDenied) {
+		h.logger.Warnf("[UpdateLocation] %v", err)
+		utility.SendErrorResponse(w, err.Error(), ingestErrorStatus(err), nil)
+		return
+	}
+	if errors.Is(err, model.ErrQuarantined) {
+		h.logger.Warnf("[UpdateLocation] vehicle %s: %v", req.VehicleID, err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusUnprocessableEntity, nil)
+		return
+	}
 	if err != nil {
 		h.logger.Errorf("[UpdateLocation] service error: %v", err)
 		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
@@ -95,3 +133,200 @@ func (h *TrackerHandler) GetLetestLocationsOfViecleByUserID(w http.ResponseWrite
 	}
 	utility.WriteSuccessResponse(w, locations, "Latest vehicle locations fetched successfully")
 }
+
+func (h *TrackerHandler) GetVehicleLocationHistory(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetVehicleLocationHistory] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleLocationHistory] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleLocationHistory] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	maxPoints, err := utility.ParseOptionalInt(r, "max_points")
+	if err != nil {
+		h.logger.Warnf("[GetVehicleLocationHistory] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	tolerance, err := utility.ParseOptionalFloat(r, "tolerance")
+	if err != nil {
+		h.logger.Warnf("[GetVehicleLocationHistory] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	targetPoints, err := utility.ParseOptionalInt(r, "target_points")
+	if err != nil {
+		h.logger.Warnf("[GetVehicleLocationHistory] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	query := model.LocationHistoryQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+		Cursor:    r.URL.Query().Get("cursor"),
+		MaxPoints: maxPoints,
+	}
+	if tolerance > 0 || targetPoints > 0 {
+		query.Simplify = &model.SimplifyOptions{ToleranceMeters: tolerance, TargetPoints: targetPoints}
+	}
+
+	page, err := h.AppTracker.GetVehicleLocationHistory(r.Context(), query)
+	if err != nil {
+		h.logger.Errorf("[GetVehicleLocationHistory] failed: %v", err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrSimplifyWithCursor), errors.Is(err, domain.ErrInvalidSimplify),
+			errors.Is(err, domain.ErrTooManyPointsToSimplify):
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, page, "Vehicle location history fetched successfully")
+}
+
+func (h *TrackerHandler) GetVehicleTrips(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetVehicleTrips] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleTrips] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleTrips] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	trips, err := h.AppTracker.GetVehicleTrips(r.Context(), model.TripQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetVehicleTrips] failed: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusInternalServerError, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, trips, "Vehicle trips fetched successfully")
+}
+
+func (h *TrackerHandler) GetVehicleDistance(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetVehicleDistance] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleDistance] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetVehicleDistance] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	report, err := h.AppTracker.GetVehicleDistance(r.Context(), model.DistanceQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+		Bucket:    r.URL.Query().Get("bucket"),
+	})
+	if err != nil {
+		h.logger.Errorf("[GetVehicleDistance] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidBucket) || errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, report, "Vehicle distance fetched successfully")
+}
+
+// GetQuarantinedLocations lists the fixes of a vehicle that the ingest filter
+// rejected, with counts per reason, to audit device quality.
+func (h *TrackerHandler) GetQuarantinedLocations(w http.ResponseWriter, r *http.Request) {
+	vehicleID := chi.URLParam(r, "vehicle_id")
+	if vehicleID == "" {
+		h.logger.Warnf("[GetQuarantinedLocations] vehicle_id is empty or missing")
+		utility.SendErrorResponse(w, "vehicle_id is required and cannot be empty", http.StatusBadRequest, nil)
+		return
+	}
+
+	ownerID, err := contexts.OwnerScope(r)
+	if err != nil {
+		h.logger.Warnf("[GetQuarantinedLocations] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	from, to, err := utility.ParseTimeRange(r)
+	if err != nil {
+		h.logger.Warnf("[GetQuarantinedLocations] invalid time range: %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	limit, err := utility.ParseOptionalInt(r, "limit")
+	if err != nil {
+		h.logger.Warnf("[GetQuarantinedLocations] %v", err)
+		utility.SendErrorResponse(w, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	report, err := h.AppTracker.GetQuarantinedLocations(r.Context(), model.QuarantineQuery{
+		VehicleID: vehicleID,
+		OwnerID:   ownerID,
+		From:      from,
+		To:        to,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.logger.Errorf("[GetQuarantinedLocations] failed: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			status = http.StatusBadRequest
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, report, "Quarantined locations fetched successfully")
+}
@@ -0,0 +1,193 @@
+// Package live fans persisted vehicle locations out to connected live-map
+// clients.
+package live
+
+import (
+	"context"
+	"sync"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/utils"
+)
+
+const (
+	DefaultBufferSize = 256
+	// DefaultMaxDropped is how many consecutive locations a subscriber may
+	// miss because its buffer is full before the hub disconnects it.
+	DefaultMaxDropped = 64
+)
+
+// Hub is an in-process publish/subscribe hub for vehicle locations. It is
+// registered as a tracker LocationListener so every persisted point is
+// offered to the subscribers allowed to see it.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	bufferSize  int
+	maxDropped  int
+	logger      utils.Logger
+}
+
+func NewHub(logger utils.Logger) *Hub {
+	return &Hub{
+		subscribers: make(map[*Subscriber]struct{}),
+		bufferSize:  DefaultBufferSize,
+		maxDropped:  DefaultMaxDropped,
+		logger:      logger,
+	}
+}
+
+// Register adds a subscriber for a user that may receive the locations of
+// ownerID's vehicles, or of every vehicle when ownerID is empty (admins).
+// Fleet managers and users see the vehicles they own, as on the REST API.
+func (h *Hub) Register(userID, ownerID string) *Subscriber {
+	s := &Subscriber{
+		userID:   userID,
+		ownerID:  ownerID,
+		vehicles: make(map[string]struct{}),
+		send:     make(chan entity.VehicleLocation, h.bufferSize),
+		done:     make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unregister removes a subscriber; it is safe to call more than once.
+func (h *Hub) Unregister(s *Subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, s)
+	h.mu.Unlock()
+	s.close()
+}
+
+// OnLocation implements the tracker LocationListener interface.
+func (h *Hub) OnLocation(_ context.Context, location entity.VehicleLocation) {
+	h.Publish(location)
+}
+
+// Publish offers a location to every interested subscriber without blocking.
+// Subscribers that keep falling behind are evicted.
+func (h *Hub) Publish(location entity.VehicleLocation) {
+	var slow []*Subscriber
+
+	h.mu.RLock()
+	for s := range h.subscribers {
+		if !s.allowed(location) || !s.wants(location.VehicleID) {
+			continue
+		}
+		if !s.offer(location, h.maxDropped) {
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.logger.Warnf("[live.Hub] disconnecting slow subscriber %s", s.userID)
+		h.Unregister(s)
+	}
+}
+
+// Subscriber is one live connection's view of the hub.
+type Subscriber struct {
+	userID  string
+	ownerID string
+
+	mu       sync.RWMutex
+	vehicles map[string]struct{}
+	all      bool
+	dropped  int
+
+	send      chan entity.VehicleLocation
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *Subscriber) UserID() string { return s.userID }
+
+// OwnerID is the owner whose vehicles the subscriber may see, empty for
+// every owner.
+func (s *Subscriber) OwnerID() string { return s.ownerID }
+
+// Messages delivers the locations the subscriber asked for.
+func (s *Subscriber) Messages() <-chan entity.VehicleLocation { return s.send }
+
+// Done is closed once the subscriber is unregistered or evicted.
+func (s *Subscriber) Done() <-chan struct{} { return s.done }
+
+// Subscribe starts delivering the given vehicles.
+func (s *Subscriber) Subscribe(vehicleIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range vehicleIDs {
+		s.vehicles[id] = struct{}{}
+	}
+}
+
+// Unsubscribe stops delivering the given vehicles.
+func (s *Subscriber) Unsubscribe(vehicleIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range vehicleIDs {
+		delete(s.vehicles, id)
+	}
+}
+
+// SubscribeAll toggles delivery of every vehicle the subscriber may see.
+func (s *Subscriber) SubscribeAll(all bool) {
+	s.mu.Lock()
+	s.all = all
+	s.mu.Unlock()
+}
+
+// Subscriptions returns the explicitly subscribed vehicle IDs.
+func (s *Subscriber) Subscriptions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.vehicles))
+	for id := range s.vehicles {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (s *Subscriber) allowed(location entity.VehicleLocation) bool {
+	return s.ownerID == "" || location.OwnerID == s.ownerID
+}
+
+func (s *Subscriber) wants(vehicleID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.all {
+		return true
+	}
+	_, ok := s.vehicles[vehicleID]
+	return ok
+}
+
+// offer queues a location, returning false once the subscriber has missed
+// more than maxDropped locations in a row.
+func (s *Subscriber) offer(location entity.VehicleLocation, maxDropped int) bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case s.send <- location:
+		s.dropped = 0
+		return true
+	default:
+		s.dropped++
+		return s.dropped <= maxDropped
+	}
+}
+
+func (s *Subscriber) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
@@ -0,0 +1,79 @@
+// Package cache holds in-process implementations of the tracking stores.
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+// MemoryLatestStore is an in-process LatestPositionStore indexed by vehicle
+// and by owner.
+type MemoryLatestStore struct {
+	mu        sync.RWMutex
+	byVehicle map[string]entity.VehicleLocation
+	byOwner   map[string]map[string]struct{}
+}
+
+func NewMemoryLatestStore() *MemoryLatestStore {
+	return &MemoryLatestStore{
+		byVehicle: make(map[string]entity.VehicleLocation),
+		byOwner:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryLatestStore) Get(ctx context.Context, vehicleID string) (entity.VehicleLocation, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	location, ok := s.byVehicle[vehicleID]
+	return location, ok, nil
+}
+
+func (s *MemoryLatestStore) ListByOwner(ctx context.Context, ownerID string) ([]*entity.VehicleLocation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var locations []*entity.VehicleLocation
+	if ownerID == "" {
+		locations = make([]*entity.VehicleLocation, 0, len(s.byVehicle))
+		for _, location := range s.byVehicle {
+			locations = append(locations, &location)
+		}
+	} else {
+		vehicles := s.byOwner[ownerID]
+		locations = make([]*entity.VehicleLocation, 0, len(vehicles))
+		for vehicleID := range vehicles {
+			location := s.byVehicle[vehicleID]
+			locations = append(locations, &location)
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].VehicleID < locations[j].VehicleID
+	})
+	return locations, nil
+}
+
+func (s *MemoryLatestStore) Put(ctx context.Context, location entity.VehicleLocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.byVehicle[location.VehicleID]
+	if ok && !location.Timestamp.After(current.Timestamp) {
+		return nil
+	}
+	if ok && current.OwnerID != location.OwnerID {
+		delete(s.byOwner[current.OwnerID], location.VehicleID)
+		if len(s.byOwner[current.OwnerID]) == 0 {
+			delete(s.byOwner, current.OwnerID)
+		}
+	}
+
+	s.byVehicle[location.VehicleID] = location
+	vehicles, ok := s.byOwner[location.OwnerID]
+	if !ok {
+		vehicles = make(map[string]struct{})
+		s.byOwner[location.OwnerID] = vehicles
+	}
+	vehicles[location.VehicleID] = struct{}{}
+	return nil
+}
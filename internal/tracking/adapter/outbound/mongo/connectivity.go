@@ -0,0 +1,162 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetConnectivityStates returns the recorded status of every vehicle.
+func (r *TimescaleTrackerRepo) GetConnectivityStates(ctx context.Context) ([]entity.VehicleConnectivity, error) {
+	const query = `
+		SELECT vehicle_id, owner_id, status, since, last_fix_at
+		FROM vehicle_connectivity;
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connectivity states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []entity.VehicleConnectivity
+	for rows.Next() {
+		var state entity.VehicleConnectivity
+		var since time.Time
+		if err := rows.Scan(&state.VehicleID, &state.OwnerID, &state.Status, &since, &state.LastFixAt); err != nil {
+			return nil, fmt.Errorf("failed to scan connectivity state: %w", err)
+		}
+		state.Since = &since
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return states, nil
+}
+
+// SaveConnectivityTransition stores the new status of a vehicle and records
+// the transition in one transaction. It returns false without recording
+// anything when the stored status already equals transition.To, which
+// happens when another instance saw the change first.
+func (r *TimescaleTrackerRepo) SaveConnectivityTransition(ctx context.Context, t entity.ConnectivityTransition) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin connectivity transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const upsert = `
+		INSERT INTO vehicle_connectivity (vehicle_id, owner_id, status, since, last_fix_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vehicle_id) DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			status = EXCLUDED.status,
+			since = EXCLUDED.since,
+			last_fix_at = EXCLUDED.last_fix_at
+		WHERE vehicle_connectivity.status <> EXCLUDED.status;
+	`
+	tag, err := tx.Exec(ctx, upsert, t.VehicleID, t.OwnerID, string(t.To), t.At, t.LastFixAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to save connectivity state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	const event = `
+		INSERT INTO vehicle_connectivity_events (vehicle_id, owner_id, from_status, to_status, at, last_fix_at)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`
+	if _, err := tx.Exec(ctx, event, t.VehicleID, t.OwnerID, string(t.From), string(t.To), t.At, t.LastFixAt); err != nil {
+		return false, fmt.Errorf("failed to record connectivity transition: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit connectivity transition: %w", err)
+	}
+	return true, nil
+}
+
+// GetConnectivityTransitions returns the newest transitions first.
+func (r *TimescaleTrackerRepo) GetConnectivityTransitions(ctx context.Context, q entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error) {
+	query := `
+		SELECT id, vehicle_id, owner_id, from_status, to_status, at, last_fix_at
+		FROM vehicle_connectivity_events
+		WHERE vehicle_id = $1
+		  AND at >= $2
+		  AND at < $3
+	`
+	args := []any{q.VehicleID, q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+	query += fmt.Sprintf(" ORDER BY at DESC, id DESC LIMIT $%d;", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connectivity transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []*entity.ConnectivityTransition
+	for rows.Next() {
+		var t entity.ConnectivityTransition
+		if err := rows.Scan(&t.ID, &t.VehicleID, &t.OwnerID, &t.From, &t.To, &t.At, &t.LastFixAt); err != nil {
+			return nil, fmt.Errorf("failed to scan connectivity transition: %w", err)
+		}
+		transitions = append(transitions, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return transitions, nil
+}
+
+// GetConnectivityThresholds returns every fleet's own thresholds.
+func (r *TimescaleTrackerRepo) GetConnectivityThresholds(ctx context.Context) ([]entity.ConnectivityThresholds, error) {
+	const query = `
+		SELECT owner_id, idle_after_seconds, offline_after_seconds, updated_at
+		FROM fleet_connectivity_thresholds;
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connectivity thresholds: %w", err)
+	}
+	defer rows.Close()
+
+	var thresholds []entity.ConnectivityThresholds
+	for rows.Next() {
+		var t entity.ConnectivityThresholds
+		if err := rows.Scan(&t.OwnerID, &t.IdleAfterSeconds, &t.OfflineAfterSeconds, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan connectivity thresholds: %w", err)
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return thresholds, nil
+}
+
+func (r *TimescaleTrackerRepo) SaveConnectivityThresholds(ctx context.Context, t entity.ConnectivityThresholds) error {
+	const query = `
+		INSERT INTO fleet_connectivity_thresholds (owner_id, idle_after_seconds, offline_after_seconds, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (owner_id) DO UPDATE SET
+			idle_after_seconds = EXCLUDED.idle_after_seconds,
+			offline_after_seconds = EXCLUDED.offline_after_seconds,
+			updated_at = EXCLUDED.updated_at;
+	`
+	if _, err := r.db.Exec(ctx, query, t.OwnerID, t.IdleAfterSeconds, t.OfflineAfterSeconds, t.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save connectivity thresholds: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *TimescaleTrackerRepo) GetFuelState(ctx context.Context, vehicleID string) (entity.FuelState, bool, error) {
+	const query = `
+		SELECT vehicle_id, owner_id, recent, level, last_seen, low_level, low_at, high_level, high_at, open_event
+		FROM vehicle_fuel_state
+		WHERE vehicle_id = $1;
+	`
+
+	var s entity.FuelState
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(
+		&s.VehicleID,
+		&s.OwnerID,
+		&s.Recent,
+		&s.Level,
+		&s.LastSeen,
+		&s.LowLevel,
+		&s.LowAt,
+		&s.HighLevel,
+		&s.HighAt,
+		&s.Open,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.FuelState{}, false, nil
+	}
+	if err != nil {
+		return entity.FuelState{}, false, fmt.Errorf("failed to get fuel state: %w", err)
+	}
+	return s, true, nil
+}
+
+// AdvanceFuel stores the next fuel state, the day's levels and, when event
+// is set, the event in one transaction. Like AdvanceStop the write only
+// applies while the stored state was last seen at previous (nil when no
+// state exists yet) and returns false when another writer got there first.
+func (r *TimescaleTrackerRepo) AdvanceFuel(ctx context.Context, next entity.FuelState, previous *time.Time, day entity.FuelDay, event *entity.FuelEvent) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin fuel transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var query string
+	args := []any{
+		next.VehicleID, next.OwnerID, next.Recent, next.Level, next.LastSeen,
+		next.LowLevel, next.LowAt, next.HighLevel, next.HighAt, next.Open,
+	}
+	if previous == nil {
+		query = `
+			INSERT INTO vehicle_fuel_state (vehicle_id, owner_id, recent, level, last_seen,
+				low_level, low_at, high_level, high_at, open_event)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (vehicle_id) DO NOTHING;
+		`
+	} else {
+		query = `
+			UPDATE vehicle_fuel_state
+			SET owner_id = $2, recent = $3, level = $4, last_seen = $5, low_level = $6, low_at = $7,
+				high_level = $8, high_at = $9, open_event = $10
+			WHERE vehicle_id = $1 AND last_seen = $11;
+		`
+		args = append(args, *previous)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to save fuel state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	const daily = `
+		INSERT INTO vehicle_fuel_daily (vehicle_id, day, owner_id, start_level, end_level)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vehicle_id, day) DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			end_level = EXCLUDED.end_level;
+	`
+	if _, err := tx.Exec(ctx, daily, day.VehicleID, day.Day.Format(dateLayout), day.OwnerID, day.StartLevel, day.EndLevel); err != nil {
+		return false, fmt.Errorf("failed to save daily fuel levels: %w", err)
+	}
+
+	if event != nil {
+		const save = `
+			INSERT INTO vehicle_fuel_events (
+				owner_id, vehicle_id, type, start_time, end_time, level_before, level_after,
+				volume_liters, latitude, longitude, stationary, ongoing
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (vehicle_id, start_time) DO UPDATE SET
+				end_time = EXCLUDED.end_time,
+				level_after = EXCLUDED.level_after,
+				volume_liters = EXCLUDED.volume_liters,
+				stationary = EXCLUDED.stationary,
+				ongoing = EXCLUDED.ongoing;
+		`
+		if _, err := tx.Exec(ctx, save,
+			event.OwnerID,
+			event.VehicleID,
+			event.Type,
+			event.StartTime,
+			event.EndTime,
+			event.LevelBefore,
+			event.LevelAfter,
+			event.VolumeLiters,
+			event.Latitude,
+			event.Longitude,
+			event.Stationary,
+			event.Ongoing,
+		); err != nil {
+			return false, fmt.Errorf("failed to save fuel event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit fuel state: %w", err)
+	}
+	return true, nil
+}
+
+// GetFuelDays returns the daily fuel levels of a vehicle in day order.
+func (r *TimescaleTrackerRepo) GetFuelDays(ctx context.Context, q entity.FuelReportQuery) ([]entity.FuelDay, error) {
+	query := `
+		SELECT vehicle_id, owner_id, day::timestamp, start_level, end_level
+		FROM vehicle_fuel_daily
+		WHERE vehicle_id = $1
+		  AND day >= $2::date
+		  AND day < $3::date
+	`
+	args := []any{q.VehicleID, q.From.Format(dateLayout), q.To.Format(dateLayout)}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	query += " ORDER BY day ASC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily fuel levels: %w", err)
+	}
+	defer rows.Close()
+
+	var days []entity.FuelDay
+	for rows.Next() {
+		var d entity.FuelDay
+		if err := rows.Scan(&d.VehicleID, &d.OwnerID, &d.Day, &d.StartLevel, &d.EndLevel); err != nil {
+			return nil, fmt.Errorf("failed to scan daily fuel levels: %w", err)
+		}
+		d.Day = d.Day.UTC()
+		days = append(days, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return days, nil
+}
+
+// GetFuelEvents returns the fuel events overlapping the range in start
+// order.
+func (r *TimescaleTrackerRepo) GetFuelEvents(ctx context.Context, q entity.FuelEventQuery) ([]*entity.FuelEvent, error) {
+	query := `
+		SELECT id, owner_id, vehicle_id, type, start_time, end_time, level_before, level_after,
+			volume_liters, latitude, longitude, stationary, ongoing
+		FROM vehicle_fuel_events
+		WHERE start_time < $2
+		  AND end_time >= $1
+	`
+	args := []any{q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	if q.VehicleID != "" {
+		args = append(args, q.VehicleID)
+		query += fmt.Sprintf(" AND vehicle_id = $%d", len(args))
+	}
+	if q.Type != "" {
+		args = append(args, q.Type)
+		query += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+	query += fmt.Sprintf(" ORDER BY start_time ASC, id ASC LIMIT $%d;", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fuel events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*entity.FuelEvent
+	for rows.Next() {
+		var e entity.FuelEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.OwnerID,
+			&e.VehicleID,
+			&e.Type,
+			&e.StartTime,
+			&e.EndTime,
+			&e.LevelBefore,
+			&e.LevelAfter,
+			&e.VolumeLiters,
+			&e.Latitude,
+			&e.Longitude,
+			&e.Stationary,
+			&e.Ongoing,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan fuel event: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"fmt"
+)
+
+// AggregateGrid counts the points of the range per grid cell and returns the
+// busiest q.Limit cells together with the total number of non-empty cells.
+func (r *TimescaleTrackerRepo) AggregateGrid(ctx context.Context, q entity.GridQuery) ([]entity.GridCell, int64, error) {
+	args := []any{q.LatStep, q.LonStep, q.From, q.To}
+	where := "WHERE timestamp >= $3 AND timestamp < $4"
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		where += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT lat_index, lon_index, COUNT(*), COUNT(DISTINCT vehicle_id), COALESCE(AVG(speed), 0),
+		       COUNT(*) OVER ()
+		FROM (
+			SELECT floor((latitude + 90) / $1)::bigint AS lat_index,
+			       floor((longitude + 180) / $2)::bigint AS lon_index,
+			       vehicle_id, speed
+			FROM vehicle_locations
+			%s
+		) cells
+		GROUP BY lat_index, lon_index
+		ORDER BY COUNT(*) DESC, lat_index, lon_index
+		LIMIT $%d;
+	`, where, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to aggregate locations: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		cells []entity.GridCell
+		total int64
+	)
+	for rows.Next() {
+		var c entity.GridCell
+		if err := rows.Scan(&c.LatIndex, &c.LonIndex, &c.Points, &c.Vehicles, &c.AvgSpeed, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan grid cell: %w", err)
+		}
+		cells = append(cells, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+	return cells, total, nil
+}
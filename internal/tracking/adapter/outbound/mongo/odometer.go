@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dateLayout formats day values for DATE columns, so the session time zone
+// cannot shift them.
+const dateLayout = "2006-01-02"
+
+func (r *TimescaleTrackerRepo) GetOdometer(ctx context.Context, vehicleID string) (entity.Odometer, bool, error) {
+	const query = `
+		SELECT vehicle_id, owner_id, total_km, last_latitude, last_longitude, last_timestamp, updated_at
+		FROM vehicle_odometers
+		WHERE vehicle_id = $1;
+	`
+
+	var o entity.Odometer
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(
+		&o.VehicleID,
+		&o.OwnerID,
+		&o.TotalKm,
+		&o.LastLatitude,
+		&o.LastLongitude,
+		&o.LastTimestamp,
+		&o.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Odometer{}, false, nil
+	}
+	if err != nil {
+		return entity.Odometer{}, false, fmt.Errorf("failed to get odometer: %w", err)
+	}
+	return o, true, nil
+}
+
+// AdvanceOdometer stores the next odometer state and adds the travelled
+// distance to the daily aggregate in one transaction. The write only applies
+// while the stored state still ends at previous (nil when no state exists
+// yet); it returns false when another writer got there first.
+func (r *TimescaleTrackerRepo) AdvanceOdometer(ctx context.Context, next entity.Odometer, previous *time.Time, distance entity.DailyDistance) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin odometer transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var query string
+	args := []any{next.VehicleID, next.OwnerID, next.TotalKm, next.LastLatitude, next.LastLongitude, next.LastTimestamp, next.UpdatedAt}
+	if previous == nil {
+		query = `
+			INSERT INTO vehicle_odometers (vehicle_id, owner_id, total_km, last_latitude, last_longitude, last_timestamp, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (vehicle_id) DO NOTHING;
+		`
+	} else {
+		query = `
+			UPDATE vehicle_odometers
+			SET owner_id = $2, total_km = $3, last_latitude = $4, last_longitude = $5, last_timestamp = $6, updated_at = $7
+			WHERE vehicle_id = $1 AND last_timestamp = $8;
+		`
+		args = append(args, *previous)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to save odometer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if distance.DistanceKm > 0 {
+		const daily = `
+			INSERT INTO vehicle_daily_distance (vehicle_id, day, owner_id, distance_km)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (vehicle_id, day) DO UPDATE SET
+				owner_id = EXCLUDED.owner_id,
+				distance_km = vehicle_daily_distance.distance_km + EXCLUDED.distance_km;
+		`
+		if _, err := tx.Exec(ctx, daily, distance.VehicleID, distance.Day.Format(dateLayout), distance.OwnerID, distance.DistanceKm); err != nil {
+			return false, fmt.Errorf("failed to add daily distance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit odometer: %w", err)
+	}
+	return true, nil
+}
+
+// GetDailyDistance sums the daily distance rows of a vehicle per bucket.
+// Buckets without movement are not returned.
+func (r *TimescaleTrackerRepo) GetDailyDistance(ctx context.Context, q entity.DistanceQuery) ([]entity.DistanceBucket, error) {
+	query := `
+		SELECT date_trunc($1, day::timestamp) AS bucket, SUM(distance_km)
+		FROM vehicle_daily_distance
+		WHERE vehicle_id = $2
+		  AND day >= $3::date
+		  AND day < $4::date
+	`
+	args := []any{q.Bucket, q.VehicleID, q.From.Format(dateLayout), q.To.Format(dateLayout)}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	query += " GROUP BY bucket ORDER BY bucket ASC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily distance: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []entity.DistanceBucket
+	for rows.Next() {
+		var b entity.DistanceBucket
+		if err := rows.Scan(&b.Start, &b.DistanceKm); err != nil {
+			return nil, fmt.Errorf("failed to scan daily distance: %w", err)
+		}
+		b.Start = b.Start.UTC()
+		buckets = append(buckets, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return buckets, nil
+}
@@ -0,0 +1,165 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuarantineLocations bulk-loads rejected locations with COPY.
+func (r *TimescaleTrackerRepo) QuarantineLocations(ctx context.Context, locations []entity.QuarantinedLocation) (int64, error) {
+	return copyQuarantined(ctx, r.db, locations)
+}
+
+// copier is what COPY needs of a pool or a transaction.
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+func copyQuarantined(ctx context.Context, db copier, locations []entity.QuarantinedLocation) (int64, error) {
+	rows := make([][]any, len(locations))
+	for i, location := range locations {
+		rows[i] = append([]any{
+			location.OwnerID,
+			location.VehicleID,
+			location.Latitude,
+			location.Longitude,
+			location.Speed,
+			location.Timestamp,
+			string(location.Reason),
+			location.Detail,
+			location.ReceivedAt,
+		}, quarantineTelemetryValues(location)...)
+	}
+
+	columns := append([]string{"owner_id", "vehicle_id", "latitude", "longitude", "speed", "timestamp", "reason", "detail", "received_at"}, telemetryColumns...)
+	inserted, err := db.CopyFrom(ctx,
+		pgx.Identifier{"vehicle_location_quarantine"},
+		columns,
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to quarantine locations: %w", err)
+	}
+	return inserted, nil
+}
+
+// quarantineTelemetryValues and quarantineTelemetryDest are telemetryValues
+// and telemetryDest for quarantine rows, in the order of telemetryColumnList.
+func quarantineTelemetryValues(location entity.QuarantinedLocation) []any {
+	return []any{
+		location.Heading,
+		location.Altitude,
+		location.HDOP,
+		location.Accuracy,
+		location.Satellites,
+		location.Ignition,
+		location.ExternalVoltage,
+		location.BatteryVoltage,
+		location.FuelLevel,
+		location.Attributes,
+	}
+}
+
+func quarantineTelemetryDest(location *entity.QuarantinedLocation) []any {
+	return []any{
+		&location.Heading,
+		&location.Altitude,
+		&location.HDOP,
+		&location.Accuracy,
+		&location.Satellites,
+		&location.Ignition,
+		&location.ExternalVoltage,
+		&location.BatteryVoltage,
+		&location.FuelLevel,
+		&location.Attributes,
+	}
+}
+
+// GetQuarantinedLocations returns the newest quarantined locations first.
+func (r *TimescaleTrackerRepo) GetQuarantinedLocations(ctx context.Context, q entity.QuarantineQuery) ([]*entity.QuarantinedLocation, error) {
+	where, args := quarantineFilter(q)
+	args = append(args, q.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, owner_id, vehicle_id, latitude, longitude, speed, timestamp, reason, detail, received_at, `+telemetryColumnList+`
+		FROM vehicle_location_quarantine
+		%s
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $%d;
+	`, where, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantined locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*entity.QuarantinedLocation
+	for rows.Next() {
+		var loc entity.QuarantinedLocation
+		if err := rows.Scan(append([]any{
+			&loc.ID,
+			&loc.OwnerID,
+			&loc.VehicleID,
+			&loc.Latitude,
+			&loc.Longitude,
+			&loc.Speed,
+			&loc.Timestamp,
+			&loc.Reason,
+			&loc.Detail,
+			&loc.ReceivedAt,
+		}, quarantineTelemetryDest(&loc)...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined location: %w", err)
+		}
+		locations = append(locations, &loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return locations, nil
+}
+
+// CountQuarantinedLocations counts the quarantined locations per reason.
+func (r *TimescaleTrackerRepo) CountQuarantinedLocations(ctx context.Context, q entity.QuarantineQuery) (map[entity.QuarantineReason]int64, error) {
+	where, args := quarantineFilter(q)
+	query := fmt.Sprintf(`
+		SELECT reason, COUNT(*)
+		FROM vehicle_location_quarantine
+		%s
+		GROUP BY reason;
+	`, where)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count quarantined locations: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.QuarantineReason]int64)
+	for rows.Next() {
+		var reason entity.QuarantineReason
+		var n int64
+		if err := rows.Scan(&reason, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantine count: %w", err)
+		}
+		counts[reason] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return counts, nil
+}
+
+func quarantineFilter(q entity.QuarantineQuery) (string, []any) {
+	where := "WHERE vehicle_id = $1 AND timestamp >= $2 AND timestamp < $3"
+	args := []any{q.VehicleID, q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		where += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	return where, args
+}
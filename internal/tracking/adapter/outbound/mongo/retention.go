@@ -0,0 +1,289 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"fmt"
+	"time"
+)
+
+// retentionLockKey is the advisory lock that keeps retention runs of
+// several instances from overlapping.
+const retentionLockKey = 0x464D5453_0001
+
+// AcquireRetentionLock takes a session-level advisory lock on a dedicated
+// connection. acquired is false when another run holds it.
+func (r *TimescaleTrackerRepo) AcquireRetentionLock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1);`, retentionLockKey).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to take retention lock: %w", err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	release := func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1);`, retentionLockKey)
+		conn.Release()
+	}
+	return release, true, nil
+}
+
+// OldestLocation returns the timestamp of the oldest raw point.
+func (r *TimescaleTrackerRepo) OldestLocation(ctx context.Context) (time.Time, bool, error) {
+	var oldest *time.Time
+	if err := r.db.QueryRow(ctx, `SELECT MIN(timestamp) FROM vehicle_locations;`).Scan(&oldest); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get oldest location: %w", err)
+	}
+	if oldest == nil {
+		return time.Time{}, false, nil
+	}
+	return *oldest, true, nil
+}
+
+// SummarisedUntil returns the end of the newest per-minute summary.
+func (r *TimescaleTrackerRepo) SummarisedUntil(ctx context.Context) (time.Time, bool, error) {
+	var last *time.Time
+	if err := r.db.QueryRow(ctx, `SELECT MAX(bucket) FROM vehicle_location_minutes;`).Scan(&last); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get summary watermark: %w", err)
+	}
+	if last == nil {
+		return time.Time{}, false, nil
+	}
+	return last.Add(time.Minute), true, nil
+}
+
+// DownsampleLocations writes one summary row per vehicle and minute for the
+// raw points in [from, to), keeping the last position of every minute. Both
+// bounds must fall on whole minutes so no minute is split across calls.
+func (r *TimescaleTrackerRepo) DownsampleLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, int64, error) {
+	var query string
+	if dryRun {
+		query = `
+			SELECT COUNT(*), COALESCE(SUM(points), 0)
+			FROM (
+				SELECT COUNT(*) AS points
+				FROM vehicle_locations
+				WHERE timestamp >= $1 AND timestamp < $2
+				GROUP BY vehicle_id, date_trunc('minute', timestamp)
+			) buckets;
+		`
+	} else {
+		query = `
+			WITH written AS (
+				INSERT INTO vehicle_location_minutes
+					(vehicle_id, bucket, owner_id, latitude, longitude, avg_speed, max_speed, point_count)
+				SELECT vehicle_id,
+				       date_trunc('minute', timestamp),
+				       (array_agg(owner_id ORDER BY timestamp DESC))[1],
+				       (array_agg(latitude ORDER BY timestamp DESC))[1],
+				       (array_agg(longitude ORDER BY timestamp DESC))[1],
+				       AVG(speed),
+				       MAX(speed),
+				       COUNT(*)
+				FROM vehicle_locations
+				WHERE timestamp >= $1 AND timestamp < $2
+				GROUP BY vehicle_id, date_trunc('minute', timestamp)
+				ON CONFLICT (vehicle_id, bucket) DO UPDATE SET
+					owner_id = EXCLUDED.owner_id,
+					latitude = EXCLUDED.latitude,
+					longitude = EXCLUDED.longitude,
+					avg_speed = EXCLUDED.avg_speed,
+					max_speed = EXCLUDED.max_speed,
+					point_count = EXCLUDED.point_count
+				RETURNING point_count
+			)
+			SELECT COUNT(*), COALESCE(SUM(point_count), 0) FROM written;
+		`
+	}
+
+	var summaries, points int64
+	if err := r.db.QueryRow(ctx, query, from, to).Scan(&summaries, &points); err != nil {
+		return 0, 0, fmt.Errorf("failed to downsample locations: %w", err)
+	}
+	return summaries, points, nil
+}
+
+// lateMinutes summarises the minutes of [$1, $2) whose raw points are not
+// all counted in their summary row, or which have none: points that arrived
+// after the downsample pass had moved past their minute.
+const lateMinutes = `
+	WITH minutes AS (
+		SELECT vehicle_id,
+		       date_trunc('minute', timestamp) AS bucket,
+		       (array_agg(owner_id ORDER BY timestamp DESC))[1] AS owner_id,
+		       (array_agg(latitude ORDER BY timestamp DESC))[1] AS latitude,
+		       (array_agg(longitude ORDER BY timestamp DESC))[1] AS longitude,
+		       AVG(speed) AS avg_speed,
+		       MAX(speed) AS max_speed,
+		       COUNT(*) AS point_count
+		FROM vehicle_locations
+		WHERE timestamp >= $1 AND timestamp < $2
+		GROUP BY vehicle_id, date_trunc('minute', timestamp)
+	), late AS (
+		SELECT minutes.*
+		FROM minutes
+		LEFT JOIN vehicle_location_minutes m
+		  ON m.vehicle_id = minutes.vehicle_id AND m.bucket = minutes.bucket
+		WHERE m.vehicle_id IS NULL OR minutes.point_count > m.point_count
+	)
+`
+
+// SummariseLateLocations summarises again the minutes of [from, to) that
+// gained raw points after they were summarised. A minute whose raw points
+// were already deleted keeps its summary; points arriving for it later are
+// not added to it.
+func (r *TimescaleTrackerRepo) SummariseLateLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, int64, error) {
+	query := lateMinutes + `
+		SELECT COUNT(*), COALESCE(SUM(point_count), 0) FROM late;
+	`
+	if !dryRun {
+		query = lateMinutes + `
+			, written AS (
+				INSERT INTO vehicle_location_minutes
+					(vehicle_id, bucket, owner_id, latitude, longitude, avg_speed, max_speed, point_count)
+				SELECT vehicle_id, bucket, owner_id, latitude, longitude, avg_speed, max_speed, point_count
+				FROM late
+				ON CONFLICT (vehicle_id, bucket) DO UPDATE SET
+					owner_id = EXCLUDED.owner_id,
+					latitude = EXCLUDED.latitude,
+					longitude = EXCLUDED.longitude,
+					avg_speed = EXCLUDED.avg_speed,
+					max_speed = EXCLUDED.max_speed,
+					point_count = EXCLUDED.point_count
+				RETURNING point_count
+			)
+			SELECT COUNT(*), COALESCE(SUM(point_count), 0) FROM written;
+		`
+	}
+
+	var summaries, points int64
+	if err := r.db.QueryRow(ctx, query, from, to).Scan(&summaries, &points); err != nil {
+		return 0, 0, fmt.Errorf("failed to summarise late locations: %w", err)
+	}
+	return summaries, points, nil
+}
+
+// DeleteLocations removes (or, in a dry run, counts) the raw points in
+// [from, to).
+func (r *TimescaleTrackerRepo) DeleteLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error) {
+	if dryRun {
+		var n int64
+		err := r.db.QueryRow(ctx, `
+			SELECT COUNT(*) FROM vehicle_locations
+			WHERE timestamp >= $1 AND timestamp < $2;
+		`, from, to).Scan(&n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count expired locations: %w", err)
+		}
+		return n, nil
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM vehicle_locations
+		WHERE timestamp >= $1 AND timestamp < $2;
+	`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired locations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteSummarisedLocations removes the raw points in [from, to) whose
+// minute has a summary row. A dry run counts every point of the range, since
+// a real run summarises the late ones first.
+func (r *TimescaleTrackerRepo) DeleteSummarisedLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error) {
+	if dryRun {
+		return r.DeleteLocations(ctx, from, to, true)
+	}
+
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM vehicle_locations l
+		WHERE l.timestamp >= $1 AND l.timestamp < $2
+		  AND EXISTS (
+			SELECT 1 FROM vehicle_location_minutes m
+			WHERE m.vehicle_id = l.vehicle_id
+			  AND m.bucket = date_trunc('minute', l.timestamp)
+		  );
+	`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired locations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *TimescaleTrackerRepo) SaveRetentionReport(ctx context.Context, report *entity.RetentionReport) error {
+	const query = `
+		INSERT INTO location_retention_runs
+			(started_at, finished_at, dry_run, downsample_before, delete_before,
+			 summaries_written, points_summarised, points_deleted, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`
+	err := r.db.QueryRow(ctx, query,
+		report.StartedAt,
+		report.FinishedAt,
+		report.DryRun,
+		report.DownsampleBefore,
+		report.DeleteBefore,
+		report.SummariesWritten,
+		report.PointsSummarised,
+		report.PointsDeleted,
+		report.Error,
+	).Scan(&report.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save retention report: %w", err)
+	}
+	return nil
+}
+
+// GetRetentionReports returns the most recent runs first.
+func (r *TimescaleTrackerRepo) GetRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error) {
+	const query = `
+		SELECT id, started_at, finished_at, dry_run, downsample_before, delete_before,
+		       summaries_written, points_summarised, points_deleted, error
+		FROM location_retention_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1;
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*entity.RetentionReport
+	for rows.Next() {
+		var report entity.RetentionReport
+		if err := rows.Scan(
+			&report.ID,
+			&report.StartedAt,
+			&report.FinishedAt,
+			&report.DryRun,
+			&report.DownsampleBefore,
+			&report.DeleteBefore,
+			&report.SummariesWritten,
+			&report.PointsSummarised,
+			&report.PointsDeleted,
+			&report.Error,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan retention report: %w", err)
+		}
+		reports = append(reports, &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return reports, nil
+}
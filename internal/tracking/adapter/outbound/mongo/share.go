@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const shareLinkColumns = `
+	l.id, l.vehicle_id, l.owner_id, l.created_by, l.label, l.token_hash,
+	l.created_at, l.expires_at, l.window_from, l.window_to, l.revoked_at,
+	(SELECT count(*) FROM vehicle_share_accesses a WHERE a.share_id = l.id),
+	(SELECT max(a.accessed_at) FROM vehicle_share_accesses a WHERE a.share_id = l.id)
+`
+
+func scanShareLink(row pgx.Row) (entity.ShareLink, error) {
+	var l entity.ShareLink
+	err := row.Scan(
+		&l.ID,
+		&l.VehicleID,
+		&l.OwnerID,
+		&l.CreatedBy,
+		&l.Label,
+		&l.TokenHash,
+		&l.CreatedAt,
+		&l.ExpiresAt,
+		&l.WindowFrom,
+		&l.WindowTo,
+		&l.RevokedAt,
+		&l.AccessCount,
+		&l.LastAccess,
+	)
+	return l, err
+}
+
+func (r *TimescaleTrackerRepo) SaveShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error) {
+	const query = `
+		INSERT INTO vehicle_share_links (
+			vehicle_id, owner_id, created_by, label, token_hash,
+			created_at, expires_at, window_from, window_to
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`
+	err := r.db.QueryRow(ctx, query,
+		link.VehicleID,
+		link.OwnerID,
+		link.CreatedBy,
+		link.Label,
+		link.TokenHash,
+		link.CreatedAt,
+		link.ExpiresAt,
+		link.WindowFrom,
+		link.WindowTo,
+	).Scan(&link.ID)
+	if err != nil {
+		return entity.ShareLink{}, fmt.Errorf("failed to save share link: %w", err)
+	}
+	return link, nil
+}
+
+func (r *TimescaleTrackerRepo) GetShareLink(ctx context.Context, id int64) (entity.ShareLink, bool, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM vehicle_share_links l WHERE l.id = $1;`
+	link, err := scanShareLink(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ShareLink{}, false, nil
+	}
+	if err != nil {
+		return entity.ShareLink{}, false, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return link, true, nil
+}
+
+func (r *TimescaleTrackerRepo) GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (entity.ShareLink, bool, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM vehicle_share_links l WHERE l.token_hash = $1;`
+	link, err := scanShareLink(r.db.QueryRow(ctx, query, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ShareLink{}, false, nil
+	}
+	if err != nil {
+		return entity.ShareLink{}, false, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return link, true, nil
+}
+
+// GetShareLinks returns the share links of a vehicle, newest first.
+func (r *TimescaleTrackerRepo) GetShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM vehicle_share_links l WHERE l.vehicle_id = $1`
+	args := []any{vehicleID}
+	if ownerID != "" {
+		args = append(args, ownerID)
+		query += fmt.Sprintf(" AND l.owner_id = $%d", len(args))
+	}
+	query += " ORDER BY l.created_at DESC, l.id DESC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*entity.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, &link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeShareLink revokes a link that is not revoked yet. It returns false
+// when there is no such link.
+func (r *TimescaleTrackerRepo) RevokeShareLink(ctx context.Context, id int64, at time.Time) (bool, error) {
+	const query = `
+		UPDATE vehicle_share_links
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL;
+	`
+	tag, err := r.db.Exec(ctx, query, id, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *TimescaleTrackerRepo) SaveShareAccess(ctx context.Context, access entity.ShareAccess) error {
+	const query = `
+		INSERT INTO vehicle_share_accesses (share_id, vehicle_id, accessed_at, remote_addr, user_agent, outcome)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`
+	if _, err := r.db.Exec(ctx, query,
+		access.ShareID,
+		access.VehicleID,
+		access.AccessedAt,
+		access.RemoteAddr,
+		access.UserAgent,
+		string(access.Outcome),
+	); err != nil {
+		return fmt.Errorf("failed to record share access: %w", err)
+	}
+	return nil
+}
+
+// GetShareAccesses returns the most recent accesses of a link first.
+func (r *TimescaleTrackerRepo) GetShareAccesses(ctx context.Context, shareID int64, limit int) ([]*entity.ShareAccess, error) {
+	const query = `
+		SELECT id, share_id, vehicle_id, accessed_at, remote_addr, user_agent, outcome
+		FROM vehicle_share_accesses
+		WHERE share_id = $1
+		ORDER BY accessed_at DESC, id DESC
+		LIMIT $2;
+	`
+
+	rows, err := r.db.Query(ctx, query, shareID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share accesses: %w", err)
+	}
+	defer rows.Close()
+
+	var accesses []*entity.ShareAccess
+	for rows.Next() {
+		var a entity.ShareAccess
+		if err := rows.Scan(&a.ID, &a.ShareID, &a.VehicleID, &a.AccessedAt, &a.RemoteAddr, &a.UserAgent, &a.Outcome); err != nil {
+			return nil, fmt.Errorf("failed to scan share access: %w", err)
+		}
+		accesses = append(accesses, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return accesses, nil
+}
@@ -0,0 +1,230 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *TimescaleTrackerRepo) GetStopState(ctx context.Context, vehicleID string) (entity.StopState, bool, error) {
+	const query = `
+		SELECT vehicle_id, owner_id, anchor_latitude, anchor_longitude, latitude, longitude,
+			arrival_time, last_seen, point_count
+		FROM vehicle_stop_state
+		WHERE vehicle_id = $1;
+	`
+
+	var s entity.StopState
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(
+		&s.VehicleID,
+		&s.OwnerID,
+		&s.AnchorLatitude,
+		&s.AnchorLongitude,
+		&s.Latitude,
+		&s.Longitude,
+		&s.ArrivalTime,
+		&s.LastSeen,
+		&s.PointCount,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.StopState{}, false, nil
+	}
+	if err != nil {
+		return entity.StopState{}, false, fmt.Errorf("failed to get stop state: %w", err)
+	}
+	return s, true, nil
+}
+
+// AdvanceStop stores the next stop state and, when stop is set, saves the
+// stop in one transaction. Like AdvanceOdometer the write only applies while
+// the stored state was last seen at previous (nil when no state exists yet)
+// and returns false when another writer got there first. The geofences of a
+// stop are only written when it is first saved.
+func (r *TimescaleTrackerRepo) AdvanceStop(ctx context.Context, next entity.StopState, previous *time.Time, stop *entity.Stop) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin stop transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var query string
+	args := []any{
+		next.VehicleID, next.OwnerID, next.AnchorLatitude, next.AnchorLongitude,
+		next.Latitude, next.Longitude, next.ArrivalTime, next.LastSeen, next.PointCount,
+	}
+	if previous == nil {
+		query = `
+			INSERT INTO vehicle_stop_state (vehicle_id, owner_id, anchor_latitude, anchor_longitude,
+				latitude, longitude, arrival_time, last_seen, point_count)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (vehicle_id) DO NOTHING;
+		`
+	} else {
+		query = `
+			UPDATE vehicle_stop_state
+			SET owner_id = $2, anchor_latitude = $3, anchor_longitude = $4, latitude = $5, longitude = $6,
+				arrival_time = $7, last_seen = $8, point_count = $9
+			WHERE vehicle_id = $1 AND last_seen = $10;
+		`
+		args = append(args, *previous)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to save stop state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if stop != nil {
+		ids := make([]string, len(stop.Geofences))
+		names := make([]string, len(stop.Geofences))
+		for i, g := range stop.Geofences {
+			ids[i] = g.ID
+			names[i] = g.Name
+		}
+		const save = `
+			INSERT INTO vehicle_stops (
+				owner_id, vehicle_id, latitude, longitude, arrival_time, departure_time,
+				duration_seconds, point_count, ongoing, geofence_ids, geofence_names
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (vehicle_id, arrival_time) DO UPDATE SET
+				latitude = EXCLUDED.latitude,
+				longitude = EXCLUDED.longitude,
+				departure_time = EXCLUDED.departure_time,
+				duration_seconds = EXCLUDED.duration_seconds,
+				point_count = EXCLUDED.point_count,
+				ongoing = EXCLUDED.ongoing;
+		`
+		if _, err := tx.Exec(ctx, save,
+			stop.OwnerID,
+			stop.VehicleID,
+			stop.Latitude,
+			stop.Longitude,
+			stop.ArrivalTime,
+			stop.DepartureTime,
+			stop.DurationSeconds,
+			stop.PointCount,
+			stop.Ongoing,
+			ids,
+			names,
+		); err != nil {
+			return false, fmt.Errorf("failed to save stop: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit stop: %w", err)
+	}
+	return true, nil
+}
+
+func (r *TimescaleTrackerRepo) GetStops(ctx context.Context, q entity.StopQuery) ([]*entity.Stop, error) {
+	query := `
+		SELECT id, owner_id, vehicle_id, latitude, longitude, arrival_time, departure_time,
+			duration_seconds, point_count, ongoing, geofence_ids, geofence_names
+		FROM vehicle_stops
+		WHERE vehicle_id = $1
+		  AND arrival_time < $3
+		  AND departure_time > $2
+	`
+	args := []any{q.VehicleID, q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	query += " ORDER BY arrival_time ASC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stops: %w", err)
+	}
+	defer rows.Close()
+
+	var stops []*entity.Stop
+	for rows.Next() {
+		var s entity.Stop
+		var ids, names []string
+		if err := rows.Scan(
+			&s.ID,
+			&s.OwnerID,
+			&s.VehicleID,
+			&s.Latitude,
+			&s.Longitude,
+			&s.ArrivalTime,
+			&s.DepartureTime,
+			&s.DurationSeconds,
+			&s.PointCount,
+			&s.Ongoing,
+			&ids,
+			&names,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stop: %w", err)
+		}
+		s.Geofences = make([]entity.GeofenceRef, len(ids))
+		for i, id := range ids {
+			s.Geofences[i].ID = id
+			if i < len(names) {
+				s.Geofences[i].Name = names[i]
+			}
+		}
+		stops = append(stops, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return stops, nil
+}
+
+// GetGeofenceVisits aggregates the stops overlapping the range per geofence
+// they lay in, longest total dwell first.
+func (r *TimescaleTrackerRepo) GetGeofenceVisits(ctx context.Context, q entity.GeofenceVisitQuery) ([]entity.GeofenceVisits, error) {
+	query := `
+		SELECT g.id, max(g.name), count(*), count(DISTINCT s.vehicle_id),
+			sum(s.duration_seconds), max(s.duration_seconds)
+		FROM vehicle_stops s
+		CROSS JOIN LATERAL unnest(s.geofence_ids, s.geofence_names) AS g(id, name)
+		WHERE s.arrival_time < $2
+		  AND s.departure_time > $1
+	`
+	args := []any{q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND s.owner_id = $%d", len(args))
+	}
+	if q.VehicleID != "" {
+		args = append(args, q.VehicleID)
+		query += fmt.Sprintf(" AND s.vehicle_id = $%d", len(args))
+	}
+	query += " GROUP BY g.id ORDER BY sum(s.duration_seconds) DESC, g.id;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query geofence visits: %w", err)
+	}
+	defer rows.Close()
+
+	var visits []entity.GeofenceVisits
+	for rows.Next() {
+		var v entity.GeofenceVisits
+		if err := rows.Scan(&v.GeofenceID, &v.GeofenceName, &v.Visits, &v.Vehicles, &v.TotalDwellSeconds, &v.MaxDwellSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan geofence visits: %w", err)
+		}
+		if v.Visits > 0 {
+			v.AvgDwellSeconds = v.TotalDwellSeconds / int64(v.Visits)
+		}
+		visits = append(visits, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return visits, nil
+}
@@ -5,6 +5,9 @@ import (
 	// port "FMTS/internal/tracking/port/outbound"
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,21 +19,59 @@ func NewTimescaleTrackerRepo(db *pgxpool.Pool) *TimescaleTrackerRepo {
 	return &TimescaleTrackerRepo{db: db}
 }
 
+// telemetryColumnList names the optional telemetry columns of
+// vehicle_locations and vehicle_location_quarantine, in the order of telemetryValues and telemetryDest.
+const telemetryColumnList = "heading, altitude, hdop, accuracy, satellites, ignition, external_voltage, battery_voltage, fuel_level, attributes"
+
+var telemetryColumns = strings.Split(telemetryColumnList, ", ")
+
+func telemetryValues(location entity.VehicleLocation) []any {
+	return []any{
+		location.Heading,
+		location.Altitude,
+		location.HDOP,
+		location.Accuracy,
+		location.Satellites,
+		location.Ignition,
+		location.ExternalVoltage,
+		location.BatteryVoltage,
+		location.FuelLevel,
+		location.Attributes,
+	}
+}
+
+func telemetryDest(location *entity.VehicleLocation) []any {
+	return []any{
+		&location.Heading,
+		&location.Altitude,
+		&location.HDOP,
+		&location.Accuracy,
+		&location.Satellites,
+		&location.Ignition,
+		&location.ExternalVoltage,
+		&location.BatteryVoltage,
+		&location.FuelLevel,
+		&location.Attributes,
+	}
+}
+
 func (r *TimescaleTrackerRepo) UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
 	query := `
-		INSERT INTO vehicle_locations (owner_id, vehicle_id, latitude, longitude, speed, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO vehicle_locations (owner_id, vehicle_id, latitude, longitude, speed, timestamp, out_of_order, ` + telemetryColumnList + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id;
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	args := append([]any{
 		location.OwnerID,
 		location.VehicleID,
 		location.Latitude,
 		location.Longitude,
 		location.Speed,
 		location.Timestamp,
-	)
+		location.OutOfOrder,
+	}, telemetryValues(location)...)
+	_, err := r.db.Exec(ctx, query, args...)
 
 	if err != nil {
 		return entity.VehicleLocation{}, fmt.Errorf("failed to insert location: %w", err)
@@ -39,10 +80,57 @@ func (r *TimescaleTrackerRepo) UpdateLocation(ctx context.Context, location enti
 	return location, nil
 }
 
+// InsertLocations bulk-loads a batch of locations and the batch's
+// quarantined locations with COPY in one transaction, so a failed write
+// stores neither.
+func (r *TimescaleTrackerRepo) InsertLocations(ctx context.Context, locations []entity.VehicleLocation, quarantined []entity.QuarantinedLocation) (int64, error) {
+	rows := make([][]any, len(locations))
+	for i, location := range locations {
+		rows[i] = append([]any{
+			location.OwnerID,
+			location.VehicleID,
+			location.Latitude,
+			location.Longitude,
+			location.Speed,
+			location.Timestamp,
+			location.OutOfOrder,
+		}, telemetryValues(location)...)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var inserted int64
+	if len(rows) > 0 {
+		columns := append([]string{"owner_id", "vehicle_id", "latitude", "longitude", "speed", "timestamp", "out_of_order"}, telemetryColumns...)
+		inserted, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"vehicle_locations"},
+			columns,
+			pgx.CopyFromRows(rows),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to bulk insert locations: %w", err)
+		}
+	}
+	if len(quarantined) > 0 {
+		if _, err := copyQuarantined(ctx, tx, quarantined); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return inserted, nil
+}
+
 func (r *TimescaleTrackerRepo) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
 	fmt.Printf("vehile ID form repo : %v", vehicleID)
 	const query = `
-        SELECT owner_id, vehicle_id, latitude, longitude, speed, timestamp
+        SELECT owner_id, vehicle_id, latitude, longitude, speed, timestamp, ` + telemetryColumnList + `
         FROM vehicle_locations
         WHERE vehicle_id = $1
         ORDER BY timestamp DESC
@@ -51,14 +139,14 @@ func (r *TimescaleTrackerRepo) GetLatestVehicleLocationByID(ctx context.Context,
 
 	var loc entity.VehicleLocation
 	fmt.Printf("vehicleID : %v", vehicleID)
-	err := r.db.QueryRow(ctx, query, vehicleID).Scan(
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(append([]any{
 		&loc.OwnerID,
 		&loc.VehicleID,
 		&loc.Latitude,
 		&loc.Longitude,
 		&loc.Speed,
 		&loc.Timestamp,
-	)
+	}, telemetryDest(&loc)...)...)
 	if err != nil {
 		return entity.VehicleLocation{}, fmt.Errorf("failed to get latest location: %w", err)
 	}
@@ -67,7 +155,7 @@ func (r *TimescaleTrackerRepo) GetLatestVehicleLocationByID(ctx context.Context,
 }
 func (r *TimescaleTrackerRepo) GetLatestVehicleLocationsByUserID(ctx context.Context, userID string) ([]*entity.VehicleLocation, error) {
 	const query = `
-		SELECT DISTINCT ON (vehicle_id) owner_id, vehicle_id, latitude, longitude, speed, timestamp
+		SELECT DISTINCT ON (vehicle_id) owner_id, vehicle_id, latitude, longitude, speed, timestamp, ` + telemetryColumnList + `
 		FROM vehicle_locations
 		WHERE owner_id = $1
 		ORDER BY vehicle_id, timestamp DESC;
@@ -82,14 +170,52 @@ func (r *TimescaleTrackerRepo) GetLatestVehicleLocationsByUserID(ctx context.Con
 	var locations []*entity.VehicleLocation
 	for rows.Next() {
 		var loc entity.VehicleLocation
-		if err := rows.Scan(
+		if err := rows.Scan(append([]any{
+			&loc.OwnerID,
+			&loc.VehicleID,
+			&loc.Latitude,
+			&loc.Longitude,
+			&loc.Speed,
+			&loc.Timestamp,
+		}, telemetryDest(&loc)...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, &loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return locations, nil
+}
+
+// GetLatestVehicleLocations returns the latest location of every vehicle.
+// It scans the whole table and is only meant for warming caches at startup.
+func (r *TimescaleTrackerRepo) GetLatestVehicleLocations(ctx context.Context) ([]*entity.VehicleLocation, error) {
+	const query = `
+		SELECT DISTINCT ON (vehicle_id) owner_id, vehicle_id, latitude, longitude, speed, timestamp, ` + telemetryColumnList + `
+		FROM vehicle_locations
+		ORDER BY vehicle_id, timestamp DESC;
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*entity.VehicleLocation
+	for rows.Next() {
+		var loc entity.VehicleLocation
+		if err := rows.Scan(append([]any{
 			&loc.OwnerID,
 			&loc.VehicleID,
 			&loc.Latitude,
 			&loc.Longitude,
 			&loc.Speed,
 			&loc.Timestamp,
-		); err != nil {
+		}, telemetryDest(&loc)...)...); err != nil {
 			return nil, fmt.Errorf("failed to scan location: %w", err)
 		}
 		locations = append(locations, &loc)
@@ -101,3 +227,106 @@ func (r *TimescaleTrackerRepo) GetLatestVehicleLocationsByUserID(ctx context.Con
 
 	return locations, nil
 }
+
+func (r *TimescaleTrackerRepo) GetVehicleLocationHistory(ctx context.Context, q entity.LocationHistoryQuery) ([]*entity.VehicleLocation, error) {
+	query := `
+		SELECT id, owner_id, vehicle_id, latitude, longitude, speed, timestamp, out_of_order, ` + telemetryColumnList + `
+		FROM vehicle_locations
+		WHERE vehicle_id = $1
+		  AND timestamp >= $2
+		  AND timestamp < $3
+	`
+	args := []any{q.VehicleID, q.From, q.To}
+
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	if q.After != nil {
+		args = append(args, q.After.Timestamp, q.After.ID)
+		query += fmt.Sprintf(" AND (timestamp, id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, q.MaxPoints)
+	query += fmt.Sprintf(" ORDER BY timestamp ASC, id ASC LIMIT $%d;", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query location history: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*entity.VehicleLocation
+	for rows.Next() {
+		var loc entity.VehicleLocation
+		if err := rows.Scan(append([]any{
+			&loc.ID,
+			&loc.OwnerID,
+			&loc.VehicleID,
+			&loc.Latitude,
+			&loc.Longitude,
+			&loc.Speed,
+			&loc.Timestamp,
+			&loc.OutOfOrder,
+		}, telemetryDest(&loc)...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, &loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return locations, nil
+}
+
+// StreamVehicleLocations calls fn for every point of the range in time order
+// while reading the result set, so large ranges are never held in memory. An
+// error returned by fn stops the stream and is returned as is.
+func (r *TimescaleTrackerRepo) StreamVehicleLocations(ctx context.Context, q entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error {
+	query := `
+		SELECT id, owner_id, vehicle_id, latitude, longitude, speed, timestamp, out_of_order, ` + telemetryColumnList + `
+		FROM vehicle_locations
+		WHERE vehicle_id = $1
+		  AND timestamp >= $2
+		  AND timestamp < $3
+	`
+	args := []any{q.VehicleID, q.From, q.To}
+
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	query += " ORDER BY timestamp ASC, id ASC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query location range: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loc entity.VehicleLocation
+		if err := rows.Scan(append([]any{
+			&loc.ID,
+			&loc.OwnerID,
+			&loc.VehicleID,
+			&loc.Latitude,
+			&loc.Longitude,
+			&loc.Speed,
+			&loc.Timestamp,
+			&loc.OutOfOrder,
+		}, telemetryDest(&loc)...)...); err != nil {
+			return fmt.Errorf("failed to scan location: %w", err)
+		}
+		if err := fn(loc); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+	return nil
+}
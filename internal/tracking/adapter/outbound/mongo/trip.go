@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *TimescaleTrackerRepo) SaveTrips(ctx context.Context, trips []*entity.Trip) error {
+	const query = `
+		INSERT INTO vehicle_trips (
+			owner_id, vehicle_id, start_time, end_time,
+			start_latitude, start_longitude, end_latitude, end_longitude,
+			distance_km, max_speed, avg_speed, duration_seconds, point_count
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (vehicle_id, start_time) DO UPDATE SET
+			end_time = EXCLUDED.end_time,
+			end_latitude = EXCLUDED.end_latitude,
+			end_longitude = EXCLUDED.end_longitude,
+			distance_km = EXCLUDED.distance_km,
+			max_speed = EXCLUDED.max_speed,
+			avg_speed = EXCLUDED.avg_speed,
+			duration_seconds = EXCLUDED.duration_seconds,
+			point_count = EXCLUDED.point_count;
+	`
+
+	batch := &pgx.Batch{}
+	for _, t := range trips {
+		batch.Queue(query,
+			t.OwnerID,
+			t.VehicleID,
+			t.StartTime,
+			t.EndTime,
+			t.StartLatitude,
+			t.StartLongitude,
+			t.EndLatitude,
+			t.EndLongitude,
+			t.DistanceKm,
+			t.MaxSpeed,
+			t.AvgSpeed,
+			t.DurationSeconds,
+			t.PointCount,
+		)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save trips: %w", err)
+	}
+	return nil
+}
+
+func (r *TimescaleTrackerRepo) GetTrips(ctx context.Context, q entity.TripQuery) ([]*entity.Trip, error) {
+	query := `
+		SELECT id, owner_id, vehicle_id, start_time, end_time,
+			start_latitude, start_longitude, end_latitude, end_longitude,
+			distance_km, max_speed, avg_speed, duration_seconds, point_count
+		FROM vehicle_trips
+		WHERE vehicle_id = $1
+		  AND start_time < $3
+		  AND end_time > $2
+	`
+	args := []any{q.VehicleID, q.From, q.To}
+	if q.OwnerID != "" {
+		args = append(args, q.OwnerID)
+		query += fmt.Sprintf(" AND owner_id = $%d", len(args))
+	}
+	query += " ORDER BY start_time ASC;"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trips: %w", err)
+	}
+	defer rows.Close()
+
+	var trips []*entity.Trip
+	for rows.Next() {
+		var t entity.Trip
+		if err := rows.Scan(
+			&t.ID,
+			&t.OwnerID,
+			&t.VehicleID,
+			&t.StartTime,
+			&t.EndTime,
+			&t.StartLatitude,
+			&t.StartLongitude,
+			&t.EndLatitude,
+			&t.EndLongitude,
+			&t.DistanceKm,
+			&t.MaxSpeed,
+			&t.AvgSpeed,
+			&t.DurationSeconds,
+			&t.PointCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trip: %w", err)
+		}
+		trips = append(trips, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return trips, nil
+}
+
+func (r *TimescaleTrackerRepo) GetTripWatermark(ctx context.Context, vehicleID string) (entity.TripWatermark, bool, error) {
+	const query = `
+		SELECT vehicle_id, processed_from, processed_until
+		FROM vehicle_trip_watermarks
+		WHERE vehicle_id = $1;
+	`
+
+	var wm entity.TripWatermark
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(&wm.VehicleID, &wm.ProcessedFrom, &wm.ProcessedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.TripWatermark{}, false, nil
+	}
+	if err != nil {
+		return entity.TripWatermark{}, false, fmt.Errorf("failed to get trip watermark: %w", err)
+	}
+	return wm, true, nil
+}
+
+func (r *TimescaleTrackerRepo) SaveTripWatermark(ctx context.Context, wm entity.TripWatermark) error {
+	const query = `
+		INSERT INTO vehicle_trip_watermarks (vehicle_id, processed_from, processed_until)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (vehicle_id) DO UPDATE SET
+			processed_from = EXCLUDED.processed_from,
+			processed_until = EXCLUDED.processed_until;
+	`
+
+	if _, err := r.db.Exec(ctx, query, wm.VehicleID, wm.ProcessedFrom, wm.ProcessedUntil); err != nil {
+		return fmt.Errorf("failed to save trip watermark: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	domain "FMTS/internal/tracking/domain/service"
+
+	"context"
+	"errors"
+	"time"
+)
+
+func (s *TrackerApplicaionService) CreateShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error) {
+	created, err := s.TrackerDomain.CreateShareLink(ctx, link)
+	if err != nil {
+		s.Logger.Errorf("[CreateShareLink] failed: %v", err)
+		return entity.ShareLink{}, err
+	}
+	s.Logger.Infof("[CreateShareLink] share %d of vehicle %s valid until %s", created.ID, created.VehicleID, created.ExpiresAt.Format(time.RFC3339))
+	return created, nil
+}
+
+func (s *TrackerApplicaionService) ListShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error) {
+	links, err := s.TrackerDomain.ListShareLinks(ctx, vehicleID, ownerID)
+	if err != nil {
+		s.Logger.Errorf("[ListShareLinks] failed: %v", err)
+		return nil, err
+	}
+	return links, nil
+}
+
+func (s *TrackerApplicaionService) RevokeShareLink(ctx context.Context, vehicleID, ownerID string, id int64) (entity.ShareLink, error) {
+	link, err := s.TrackerDomain.RevokeShareLink(ctx, vehicleID, ownerID, id)
+	if err != nil {
+		s.Logger.Errorf("[RevokeShareLink] failed: %v", err)
+		return entity.ShareLink{}, err
+	}
+	return link, nil
+}
+
+func (s *TrackerApplicaionService) GetShareAccesses(ctx context.Context, vehicleID, ownerID string, id int64, limit int) ([]*entity.ShareAccess, error) {
+	accesses, err := s.TrackerDomain.GetShareAccesses(ctx, vehicleID, ownerID, id, limit)
+	if err != nil {
+		s.Logger.Errorf("[GetShareAccesses] failed: %v", err)
+		return nil, err
+	}
+	return accesses, nil
+}
+
+// ViewShareLink resolves a share token and returns what the link shows. A
+// link without a window follows the vehicle live from its creation; with a
+// window it shows the track inside the window, and the latest position only
+// while the window is open. The latest position is never older than the
+// start of the track.
+func (s *TrackerApplicaionService) ViewShareLink(ctx context.Context, token string, access entity.ShareAccess) (entity.ShareView, error) {
+	link, err := s.TrackerDomain.ResolveShareLink(ctx, token, access)
+	if err != nil {
+		if !isShareRefusal(err) {
+			s.Logger.Errorf("[ViewShareLink] failed: %v", err)
+		}
+		return entity.ShareView{}, err
+	}
+
+	now := time.Now().UTC()
+	view := entity.ShareView{
+		VehicleID: link.VehicleID,
+		Label:     link.Label,
+		ExpiresAt: link.ExpiresAt,
+		From:      link.CreatedAt,
+		To:        now,
+		Track:     []entity.SharedPoint{},
+	}
+	if link.WindowFrom != nil {
+		view.From = *link.WindowFrom
+	}
+	live := link.WindowTo == nil || link.WindowTo.After(now)
+	if !live {
+		view.To = *link.WindowTo
+	}
+
+	if view.From.Before(view.To) {
+		page, err := s.GetVehicleLocationHistory(ctx, entity.LocationHistoryQuery{
+			VehicleID: link.VehicleID,
+			From:      view.From,
+			To:        view.To,
+			Simplify:  &entity.SimplifyOptions{TargetPoints: s.TrackerDomain.ShareTrackPoints()},
+		})
+		if err != nil {
+			return entity.ShareView{}, err
+		}
+		for _, point := range page.Points {
+			view.Track = append(view.Track, entity.SharedPointOf(*point))
+		}
+	}
+
+	if live {
+		latest, err := s.TrackerDomain.GetLatestVehicleLocationByID(ctx, link.VehicleID)
+		if err == nil && !latest.Timestamp.Before(view.From) {
+			point := entity.SharedPointOf(latest)
+			view.Latest = &point
+		}
+	} else if len(view.Track) > 0 {
+		view.Latest = &view.Track[len(view.Track)-1]
+	}
+	return view, nil
+}
+
+func isShareRefusal(err error) bool {
+	return errors.Is(err, domain.ErrShareNotFound) || errors.Is(err, domain.ErrShareExpired) ||
+		errors.Is(err, domain.ErrShareRevoked) || errors.Is(err, domain.ErrShareNotStarted)
+}
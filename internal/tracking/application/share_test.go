@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	domain "FMTS/internal/tracking/domain/service"
+	"FMTS/utils"
+)
+
+// shareStore keeps share links and the points of one vehicle in memory.
+type shareStore struct {
+	repo.DomainTracker
+
+	links  map[string]*entity.ShareLink
+	points []entity.VehicleLocation
+}
+
+func (s *shareStore) SaveShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error) {
+	link.ID = int64(len(s.links) + 1)
+	s.links[link.TokenHash] = &link
+	return link, nil
+}
+
+func (s *shareStore) GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (entity.ShareLink, bool, error) {
+	link, ok := s.links[tokenHash]
+	if !ok {
+		return entity.ShareLink{}, false, nil
+	}
+	return *link, true, nil
+}
+
+func (s *shareStore) SaveShareAccess(ctx context.Context, access entity.ShareAccess) error {
+	return nil
+}
+
+func (s *shareStore) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
+	if len(s.points) == 0 {
+		return entity.VehicleLocation{}, errors.New("no location")
+	}
+	return s.points[len(s.points)-1], nil
+}
+
+func (s *shareStore) StreamVehicleLocations(ctx context.Context, q entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error {
+	for _, p := range s.points {
+		if !p.Timestamp.Before(q.From) && p.Timestamp.Before(q.To) {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestViewShareLink(t *testing.T) {
+	now := time.Now().UTC()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	cases := []struct {
+		name       string
+		points     []time.Duration // ages of the vehicle's fixes, oldest first
+		edit       func(link *entity.ShareLink)
+		wantErr    error
+		wantTrack  []time.Duration
+		wantLatest time.Duration // 0 when no latest position is shown
+	}{
+		{
+			name:       "live from creation",
+			points:     []time.Duration{2 * time.Hour, 30 * time.Minute, 10 * time.Minute},
+			wantTrack:  []time.Duration{30 * time.Minute, 10 * time.Minute},
+			wantLatest: 10 * time.Minute,
+		},
+		{
+			name:   "live but silent since before creation",
+			points: []time.Duration{2 * time.Hour},
+		},
+		{
+			name:   "open window",
+			points: []time.Duration{2 * time.Hour, 30 * time.Minute, 10 * time.Minute},
+			edit: func(l *entity.ShareLink) {
+				l.WindowFrom, l.WindowTo = ptr(ago(45*time.Minute)), ptr(now.Add(time.Hour))
+			},
+			wantTrack:  []time.Duration{30 * time.Minute, 10 * time.Minute},
+			wantLatest: 10 * time.Minute,
+		},
+		{
+			name:   "closed window cuts the track off",
+			points: []time.Duration{3 * time.Hour, 2 * time.Hour, 100 * time.Minute, 30 * time.Minute},
+			edit: func(l *entity.ShareLink) {
+				l.WindowFrom, l.WindowTo = ptr(ago(150*time.Minute)), ptr(ago(90*time.Minute))
+			},
+			wantTrack:  []time.Duration{2 * time.Hour, 100 * time.Minute},
+			wantLatest: 100 * time.Minute,
+		},
+		{
+			name:    "revoked",
+			points:  []time.Duration{10 * time.Minute},
+			edit:    func(l *entity.ShareLink) { l.RevokedAt = ptr(ago(time.Minute)) },
+			wantErr: domain.ErrShareRevoked,
+		},
+		{
+			name:    "expired",
+			points:  []time.Duration{10 * time.Minute},
+			edit:    func(l *entity.ShareLink) { l.ExpiresAt = ago(time.Minute) },
+			wantErr: domain.ErrShareExpired,
+		},
+		{
+			name:    "not started",
+			points:  []time.Duration{10 * time.Minute},
+			edit:    func(l *entity.ShareLink) { l.WindowFrom = ptr(now.Add(time.Hour)) },
+			wantErr: domain.ErrShareNotStarted,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &shareStore{links: make(map[string]*entity.ShareLink)}
+			for _, age := range tc.points {
+				store.points = append(store.points, entity.VehicleLocation{OwnerID: "owner1", VehicleID: "v1", Timestamp: ago(age)})
+			}
+			trackerDomain := domain.InitDomaintrakerservice(utils.NewLogger(), store, entity.TrackerConfig{})
+			app := NewTrackerApplicationService(trackerDomain, utils.NewLogger())
+			ctx := context.Background()
+
+			created, err := trackerDomain.CreateShareLink(ctx, entity.ShareLink{VehicleID: "v1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, link := range store.links {
+				link.CreatedAt = ago(time.Hour)
+				if tc.edit != nil {
+					tc.edit(link)
+				}
+			}
+
+			view, err := app.ViewShareLink(ctx, created.Token, entity.ShareAccess{})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(view.Track) != len(tc.wantTrack) {
+				t.Fatalf("track has %d points, want %d", len(view.Track), len(tc.wantTrack))
+			}
+			for i, age := range tc.wantTrack {
+				if !view.Track[i].Timestamp.Equal(ago(age)) {
+					t.Errorf("track point %d at %v, want %v", i, view.Track[i].Timestamp, ago(age))
+				}
+			}
+			switch {
+			case tc.wantLatest == 0 && view.Latest != nil:
+				t.Errorf("latest at %v, want none", view.Latest.Timestamp)
+			case tc.wantLatest != 0 && (view.Latest == nil || !view.Latest.Timestamp.Equal(ago(tc.wantLatest))):
+				t.Errorf("latest = %+v, want the fix at %v", view.Latest, ago(tc.wantLatest))
+			case view.Latest != nil && view.Latest.Timestamp.Before(view.From):
+				t.Errorf("latest at %v predates the track start %v", view.Latest.Timestamp, view.From)
+			}
+		})
+	}
+}
@@ -6,12 +6,39 @@ import (
 
 	"FMTS/pkg/utils"
 	"context"
+	"errors"
 )
 
 type TrackerApplication interface {
+	AuthorizeLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error)
+	CheckVehicleAccess(ctx context.Context, vehicleID, ownerID string) error
 	UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error)
+	UpdateLocations(ctx context.Context, locations []entity.VehicleLocation) (entity.BatchIngestResult, error)
 	GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error)
 	GetLatestVehicleLocationsByUserID(ctx context.Context, userID string) ([]*entity.VehicleLocation, error)
+	GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) (entity.LocationHistoryPage, error)
+	GetVehicleTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error)
+	GetVehicleOdometer(ctx context.Context, vehicleID string) (entity.Odometer, error)
+	StreamVehicleTrack(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error
+	GetVehicleDistance(ctx context.Context, query entity.DistanceQuery) (entity.DistanceReport, error)
+	GetQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) (entity.QuarantineReport, error)
+	FindNearbyVehicles(ctx context.Context, query entity.NearbyQuery) (entity.NearbyResult, error)
+	GetHeatmap(ctx context.Context, query entity.HeatmapQuery) (entity.Heatmap, error)
+	RunRetention(ctx context.Context, dryRun bool) (entity.RetentionReport, error)
+	ListRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error)
+	GetVehicleConnectivity(ctx context.Context, ownerID string) ([]entity.VehicleConnectivity, error)
+	GetConnectivityTransitions(ctx context.Context, query entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error)
+	GetConnectivityThresholds(ctx context.Context, ownerID string) (entity.ConnectivityThresholds, error)
+	SetConnectivityThresholds(ctx context.Context, thresholds entity.ConnectivityThresholds) (entity.ConnectivityThresholds, error)
+	GetVehicleStops(ctx context.Context, query entity.StopQuery) ([]*entity.Stop, error)
+	GetGeofenceVisits(ctx context.Context, query entity.GeofenceVisitQuery) (entity.GeofenceVisitReport, error)
+	GetFuelReport(ctx context.Context, query entity.FuelReportQuery) (entity.FuelReport, error)
+	GetFuelEvents(ctx context.Context, query entity.FuelEventQuery) ([]*entity.FuelEvent, error)
+	CreateShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error)
+	ListShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error)
+	RevokeShareLink(ctx context.Context, vehicleID, ownerID string, id int64) (entity.ShareLink, error)
+	GetShareAccesses(ctx context.Context, vehicleID, ownerID string, id int64, limit int) ([]*entity.ShareAccess, error)
+	ViewShareLink(ctx context.Context, token string, access entity.ShareAccess) (entity.ShareView, error)
 }
 type TrackerApplicaionService struct {
 	TrackerDomain domain.DomainTracker
@@ -24,8 +51,31 @@ func NewTrackerApplicationService(trackerDomain domain.DomainTracker, logger uti
 		Logger:        logger,
 	}
 }
+func (s *TrackerApplicaionService) AuthorizeLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
+	authorized, err := s.TrackerDomain.AuthorizeLocation(ctx, location)
+	if errors.Is(err, entity.ErrIngestDenied) {
+		return entity.VehicleLocation{}, err
+	}
+	if err != nil {
+		s.Logger.Errorf("[AuthorizeLocation] failed: %v", err)
+		return entity.VehicleLocation{}, err
+	}
+	return authorized, nil
+}
+
+func (s *TrackerApplicaionService) CheckVehicleAccess(ctx context.Context, vehicleID, ownerID string) error {
+	err := s.TrackerDomain.CheckVehicleAccess(ctx, vehicleID, ownerID)
+	if err != nil && !errors.Is(err, domain.ErrVehicleNotFound) && !errors.Is(err, domain.ErrVehicleForbidden) {
+		s.Logger.Errorf("[CheckVehicleAccess] failed: %v", err)
+	}
+	return err
+}
+
 func (s *TrackerApplicaionService) UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
 	updatedLocation, err := s.TrackerDomain.UpdateLocation(ctx, location)
+	if errors.Is(err, entity.ErrQuarantined) || errors.Is(err, entity.ErrIngestDenied) {
+		return entity.VehicleLocation{}, err
+	}
 	if err != nil {
 		s.Logger.Errorf("[UpdateLocation] failed: %v", err)
 		return entity.VehicleLocation{}, err
@@ -33,6 +83,15 @@ func (s *TrackerApplicaionService) UpdateLocation(ctx context.Context, location
 	return updatedLocation, nil
 }
 
+func (s *TrackerApplicaionService) UpdateLocations(ctx context.Context, locations []entity.VehicleLocation) (entity.BatchIngestResult, error) {
+	result, err := s.TrackerDomain.UpdateLocations(ctx, locations)
+	if err != nil {
+		s.Logger.Errorf("[UpdateLocations] failed: %v", err)
+		return entity.BatchIngestResult{}, err
+	}
+	return result, nil
+}
+
 func (s *TrackerApplicaionService) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
 	location, err := s.TrackerDomain.GetLatestVehicleLocationByID(ctx, vehicleID)
 	if err != nil {
@@ -50,3 +109,167 @@ func (s *TrackerApplicaionService) GetLatestVehicleLocationsByUserID(ctx context
 	}
 	return locations, nil
 }
+
+func (s *TrackerApplicaionService) GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) (entity.LocationHistoryPage, error) {
+	page, err := s.TrackerDomain.GetVehicleLocationHistory(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleLocationHistory] failed: %v", err)
+		return entity.LocationHistoryPage{}, err
+	}
+	return page, nil
+}
+
+func (s *TrackerApplicaionService) GetVehicleTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error) {
+	trips, err := s.TrackerDomain.GetVehicleTrips(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleTrips] failed: %v", err)
+		return nil, err
+	}
+	return trips, nil
+}
+
+func (s *TrackerApplicaionService) GetVehicleOdometer(ctx context.Context, vehicleID string) (entity.Odometer, error) {
+	odometer, err := s.TrackerDomain.GetVehicleOdometer(ctx, vehicleID)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleOdometer] failed: %v", err)
+		return entity.Odometer{}, err
+	}
+	return odometer, nil
+}
+
+func (s *TrackerApplicaionService) GetVehicleDistance(ctx context.Context, query entity.DistanceQuery) (entity.DistanceReport, error) {
+	report, err := s.TrackerDomain.GetVehicleDistance(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleDistance] failed: %v", err)
+		return entity.DistanceReport{}, err
+	}
+	return report, nil
+}
+
+func (s *TrackerApplicaionService) StreamVehicleTrack(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error {
+	if err := s.TrackerDomain.StreamVehicleTrack(ctx, query, fn); err != nil {
+		s.Logger.Errorf("[StreamVehicleTrack] failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *TrackerApplicaionService) GetQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) (entity.QuarantineReport, error) {
+	report, err := s.TrackerDomain.GetQuarantinedLocations(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetQuarantinedLocations] failed: %v", err)
+		return entity.QuarantineReport{}, err
+	}
+	return report, nil
+}
+
+func (s *TrackerApplicaionService) FindNearbyVehicles(ctx context.Context, query entity.NearbyQuery) (entity.NearbyResult, error) {
+	result, err := s.TrackerDomain.FindNearbyVehicles(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[FindNearbyVehicles] failed: %v", err)
+		return entity.NearbyResult{}, err
+	}
+	return result, nil
+}
+
+func (s *TrackerApplicaionService) GetHeatmap(ctx context.Context, query entity.HeatmapQuery) (entity.Heatmap, error) {
+	heatmap, err := s.TrackerDomain.GetHeatmap(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetHeatmap] failed: %v", err)
+		return entity.Heatmap{}, err
+	}
+	return heatmap, nil
+}
+
+func (s *TrackerApplicaionService) RunRetention(ctx context.Context, dryRun bool) (entity.RetentionReport, error) {
+	report, err := s.TrackerDomain.RunRetention(ctx, dryRun)
+	if errors.Is(err, domain.ErrRetentionRunning) {
+		return entity.RetentionReport{}, err
+	}
+	if err != nil {
+		s.Logger.Errorf("[RunRetention] failed: %v", err)
+		return report, err
+	}
+	return report, nil
+}
+
+func (s *TrackerApplicaionService) ListRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error) {
+	reports, err := s.TrackerDomain.ListRetentionReports(ctx, limit)
+	if err != nil {
+		s.Logger.Errorf("[ListRetentionReports] failed: %v", err)
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (s *TrackerApplicaionService) GetVehicleConnectivity(ctx context.Context, ownerID string) ([]entity.VehicleConnectivity, error) {
+	statuses, err := s.TrackerDomain.GetVehicleConnectivity(ctx, ownerID)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleConnectivity] failed: %v", err)
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (s *TrackerApplicaionService) GetConnectivityTransitions(ctx context.Context, query entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error) {
+	transitions, err := s.TrackerDomain.GetConnectivityTransitions(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetConnectivityTransitions] failed: %v", err)
+		return nil, err
+	}
+	return transitions, nil
+}
+
+func (s *TrackerApplicaionService) GetConnectivityThresholds(ctx context.Context, ownerID string) (entity.ConnectivityThresholds, error) {
+	thresholds, err := s.TrackerDomain.GetConnectivityThresholds(ctx, ownerID)
+	if err != nil {
+		s.Logger.Errorf("[GetConnectivityThresholds] failed: %v", err)
+		return entity.ConnectivityThresholds{}, err
+	}
+	return thresholds, nil
+}
+
+func (s *TrackerApplicaionService) SetConnectivityThresholds(ctx context.Context, thresholds entity.ConnectivityThresholds) (entity.ConnectivityThresholds, error) {
+	saved, err := s.TrackerDomain.SetConnectivityThresholds(ctx, thresholds)
+	if err != nil {
+		s.Logger.Errorf("[SetConnectivityThresholds] failed: %v", err)
+		return entity.ConnectivityThresholds{}, err
+	}
+	return saved, nil
+}
+
+func (s *TrackerApplicaionService) GetVehicleStops(ctx context.Context, query entity.StopQuery) ([]*entity.Stop, error) {
+	stops, err := s.TrackerDomain.GetVehicleStops(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetVehicleStops] failed: %v", err)
+		return nil, err
+	}
+	return stops, nil
+}
+
+func (s *TrackerApplicaionService) GetGeofenceVisits(ctx context.Context, query entity.GeofenceVisitQuery) (entity.GeofenceVisitReport, error) {
+	report, err := s.TrackerDomain.GetGeofenceVisits(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetGeofenceVisits] failed: %v", err)
+		return entity.GeofenceVisitReport{}, err
+	}
+	return report, nil
+}
+
+func (s *TrackerApplicaionService) GetFuelReport(ctx context.Context, query entity.FuelReportQuery) (entity.FuelReport, error) {
+	report, err := s.TrackerDomain.GetFuelReport(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetFuelReport] failed: %v", err)
+		return entity.FuelReport{}, err
+	}
+	return report, nil
+}
+
+func (s *TrackerApplicaionService) GetFuelEvents(ctx context.Context, query entity.FuelEventQuery) ([]*entity.FuelEvent, error) {
+	events, err := s.TrackerDomain.GetFuelEvents(ctx, query)
+	if err != nil {
+		s.Logger.Errorf("[GetFuelEvents] failed: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IngestDenial is the reason a location was refused for its vehicle.
+type IngestDenial string
+
+const (
+	DenialUnknownVehicle  IngestDenial = "unknown_vehicle"
+	DenialOwnerMismatch   IngestDenial = "owner_mismatch"
+	DenialVehicleDeleted  IngestDenial = "vehicle_deleted"
+	DenialVehicleDisabled IngestDenial = "vehicle_disabled"
+)
+
+// ErrIngestDenied matches every IngestDeniedError with errors.Is.
+var ErrIngestDenied = errors.New("location denied")
+
+// IngestDeniedError reports that a location was refused because its vehicle
+// is not registered, belongs to another owner or is deleted or disabled.
+// Nothing is stored and retrying the same location is refused again.
+type IngestDeniedError struct {
+	VehicleID string
+	Reason    IngestDenial
+}
+
+func (e *IngestDeniedError) Error() string {
+	return fmt.Sprintf("location denied for vehicle %s: %s", e.VehicleID, e.Reason)
+}
+
+func (e *IngestDeniedError) Is(target error) bool {
+	return target == ErrIngestDenied
+}
@@ -0,0 +1,13 @@
+package models
+
+// TrackerConfig groups the tunables of the tracking domain.
+type TrackerConfig struct {
+	Trips        TripDetectionConfig
+	Odometer     OdometerConfig
+	Ingest       IngestFilterConfig
+	Retention    RetentionConfig
+	Connectivity ConnectivityConfig
+	Stops        StopDetectionConfig
+	Shares       ShareConfig
+	Fuel         FuelDetectionConfig
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// ConnectivityStatus tells how recently a vehicle's tracker reported.
+type ConnectivityStatus string
+
+const (
+	// StatusOnline is a vehicle whose last fix is younger than the idle
+	// threshold of its fleet.
+	StatusOnline ConnectivityStatus = "online"
+	// StatusIdle is a vehicle that has been quiet for longer than the idle
+	// threshold but not yet for the offline threshold.
+	StatusIdle ConnectivityStatus = "idle"
+	// StatusOffline is a vehicle whose tracker has gone dark.
+	StatusOffline ConnectivityStatus = "offline"
+)
+
+// ConnectivityConfig configures the background connectivity monitor.
+type ConnectivityConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// Default applies to fleets without thresholds of their own.
+	Default ConnectivityThresholds
+}
+
+// ConnectivityThresholds are the silences after which the vehicles of a
+// fleet count as idle and offline. OwnerID is empty for the defaults.
+type ConnectivityThresholds struct {
+	OwnerID             string    `json:"owner_id,omitempty"`
+	IdleAfterSeconds    int64     `json:"idle_after_seconds"`
+	OfflineAfterSeconds int64     `json:"offline_after_seconds"`
+	UpdatedAt           time.Time `json:"updated_at,omitempty"`
+}
+
+func (t ConnectivityThresholds) IdleAfter() time.Duration {
+	return time.Duration(t.IdleAfterSeconds) * time.Second
+}
+
+func (t ConnectivityThresholds) OfflineAfter() time.Duration {
+	return time.Duration(t.OfflineAfterSeconds) * time.Second
+}
+
+// Classify returns the status of a vehicle whose last fix is silence old.
+func (t ConnectivityThresholds) Classify(silence time.Duration) ConnectivityStatus {
+	switch {
+	case silence >= t.OfflineAfter():
+		return StatusOffline
+	case silence >= t.IdleAfter():
+		return StatusIdle
+	default:
+		return StatusOnline
+	}
+}
+
+// VehicleConnectivity is the current status of a vehicle. Since is when the
+// status began, when the monitor has seen it begin.
+type VehicleConnectivity struct {
+	VehicleID string             `json:"vehicle_id"`
+	OwnerID   string             `json:"owner_id"`
+	Status    ConnectivityStatus `json:"status"`
+	Since     *time.Time         `json:"since,omitempty"`
+	LastFixAt time.Time          `json:"last_fix_at"`
+}
+
+// ConnectivityTransition records a vehicle changing status. From is empty
+// for the first status recorded for a vehicle.
+type ConnectivityTransition struct {
+	ID        int64              `json:"id,omitempty"`
+	VehicleID string             `json:"vehicle_id"`
+	OwnerID   string             `json:"owner_id"`
+	From      ConnectivityStatus `json:"from,omitempty"`
+	To        ConnectivityStatus `json:"to"`
+	At        time.Time          `json:"at"`
+	LastFixAt time.Time          `json:"last_fix_at"`
+}
+
+// ConnectivityQuery selects the transitions of a vehicle in [From, To).
+// OwnerID is empty for admins.
+type ConnectivityQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
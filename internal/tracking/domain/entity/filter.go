@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// QuarantineReason is the reason code stored with a rejected location.
+type QuarantineReason string
+
+const (
+	// QuarantineDuplicate is an exact repeat (same timestamp and coordinates)
+	// of a recently received fix.
+	QuarantineDuplicate QuarantineReason = "duplicate"
+	// QuarantineTeleport is a jump from the last accepted fix that implies a
+	// speed no vehicle can reach.
+	QuarantineTeleport QuarantineReason = "teleport"
+)
+
+// ErrQuarantined matches every QuarantineError with errors.Is.
+var ErrQuarantined = errors.New("location quarantined")
+
+// QuarantineError reports that a location was rejected by the ingest filter
+// and stored in quarantine instead of the track. It is final: retrying the
+// same location is rejected again.
+type QuarantineError struct {
+	Reason QuarantineReason
+	Detail string
+}
+
+func (e *QuarantineError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("location quarantined: %s", e.Reason)
+	}
+	return fmt.Sprintf("location quarantined: %s (%s)", e.Reason, e.Detail)
+}
+
+func (e *QuarantineError) Is(target error) bool {
+	return target == ErrQuarantined
+}
+
+// QuarantinedLocation is a location rejected by the ingest filter, kept so
+// device quality can be audited.
+type QuarantinedLocation struct {
+	ID         int64            `json:"id,omitempty"`
+	OwnerID    string           `json:"owner_id"`
+	VehicleID  string           `json:"vehicle_id"`
+	Latitude   float64          `json:"latitude"`
+	Longitude  float64          `json:"longitude"`
+	Speed      float64          `json:"speed,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Reason     QuarantineReason `json:"reason"`
+	Detail     string           `json:"detail,omitempty"`
+	ReceivedAt time.Time        `json:"received_at"`
+
+	// Telemetry reported with the fix, as on VehicleLocation.
+	Heading         *float64       `json:"heading,omitempty"`
+	Altitude        *float64       `json:"altitude,omitempty"`
+	HDOP            *float64       `json:"hdop,omitempty"`
+	Accuracy        *float64       `json:"accuracy,omitempty"`
+	Satellites      *int           `json:"satellites,omitempty"`
+	Ignition        *bool          `json:"ignition,omitempty"`
+	ExternalVoltage *float64       `json:"external_voltage,omitempty"`
+	BatteryVoltage  *float64       `json:"battery_voltage,omitempty"`
+	FuelLevel       *float64       `json:"fuel_level,omitempty"`
+	Attributes      map[string]any `json:"attributes,omitempty"`
+}
+
+// QuarantineQuery selects the quarantined locations of a vehicle whose
+// timestamp lies in [From, To). OwnerID is empty for admins.
+type QuarantineQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// QuarantineReport lists the most recent quarantined locations of a vehicle
+// together with the number of rejections per reason in the whole range.
+type QuarantineReport struct {
+	VehicleID string                     `json:"vehicle_id"`
+	From      time.Time                  `json:"from"`
+	To        time.Time                  `json:"to"`
+	Total     int64                      `json:"total"`
+	Counts    map[QuarantineReason]int64 `json:"counts"`
+	Points    []*QuarantinedLocation     `json:"points"`
+}
+
+// BatchIngestResult is the outcome of storing a batch of locations.
+// Quarantined maps the index of a rejected location in the submitted batch
+// to the reason it was rejected; Denied does the same for locations refused
+// for their vehicle and Invalid for locations that failed validation.
+type BatchIngestResult struct {
+	Inserted    int64
+	Quarantined map[int]QuarantineReason
+	Denied      map[int]IngestDenial
+	Invalid     map[int]string
+}
+
+// IngestFilterConfig tunes the filter every location passes before it is
+// stored.
+type IngestFilterConfig struct {
+	// MaxSpeedKmh quarantines fixes whose distance from the last accepted
+	// fix implies a higher speed. Zero disables the teleport check.
+	MaxSpeedKmh float64
+	// TeleportMinMeters never treats shorter jumps as teleports, so fixes a
+	// second apart with ordinary jitter are not rejected.
+	TeleportMinMeters float64
+	// TeleportResetAfter accepts the next fix as a new anchor after this many
+	// consecutive teleport rejections, in case the anchor itself was bad.
+	TeleportResetAfter int
+	// Kalman enables smoothing of accepted in-order coordinates.
+	Kalman KalmanConfig
+	// VehicleCacheTTL is how long a vehicle looked up for ingest
+	// authorization is reused, so disabling a vehicle takes up to this long
+	// to take effect.
+	VehicleCacheTTL time.Duration
+}
+
+// KalmanConfig tunes the constant-position Kalman filter used to smooth
+// coordinates.
+type KalmanConfig struct {
+	Enabled bool
+	// ProcessNoise is how fast, in m/s, the true position is expected to
+	// drift away from the estimate. Higher values follow the raw fixes
+	// more closely.
+	ProcessNoise float64
+	// MeasurementNoise is the expected GPS error of a single fix in metres.
+	MeasurementNoise float64
+	// ResetGap restarts the filter from the raw fix after a reporting gap.
+	ResetGap time.Duration
+}
@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// FuelDetectionConfig holds the thresholds used to smooth fuel readings and
+// to find refuels and drains in them.
+type FuelDetectionConfig struct {
+	Enabled bool
+	// SmoothingSamples is how many raw readings the level is the median of,
+	// which removes slosh on bumps and in turns.
+	SmoothingSamples int
+	// RefuelMinLiters and DrainMinLiters are the smallest rise and drop of
+	// the smoothed level within EventWindow that count as an event. Drains
+	// must be well above what the vehicle can burn within the window.
+	RefuelMinLiters float64
+	DrainMinLiters  float64
+	EventWindow     time.Duration
+	// SettleTime is how long the level has to stop moving for an event to
+	// be finished.
+	SettleTime time.Duration
+	// StationarySpeedKmh is the speed below which a vehicle counts as parked
+	// during a drain.
+	StationarySpeedKmh float64
+}
+
+// Fuel event types.
+const (
+	FuelEventRefuel = "refuel"
+	FuelEventDrain  = "drain"
+)
+
+// FuelEvent is a refuel or a sudden drop of the smoothed fuel level. The
+// position is that of the fix the event was detected at. Ongoing events are
+// still moving; their end and volume are those of the latest reading.
+// Stationary drains happened while the vehicle was parked, the usual sign of
+// siphoning.
+type FuelEvent struct {
+	ID           int64     `json:"id,omitempty"`
+	OwnerID      string    `json:"owner_id"`
+	VehicleID    string    `json:"vehicle_id"`
+	Type         string    `json:"type"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	LevelBefore  float64   `json:"level_before"`
+	LevelAfter   float64   `json:"level_after"`
+	VolumeLiters float64   `json:"volume_liters"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Stationary   bool      `json:"stationary"`
+	Ongoing      bool      `json:"ongoing"`
+}
+
+// FuelState is what fuel detection keeps per vehicle between readings.
+// Recent holds the raw readings the level is the median of. Low and High
+// are the lowest and highest smoothed levels within the event window, which
+// rises and drops are measured from. Open is the event in progress.
+type FuelState struct {
+	VehicleID string
+	OwnerID   string
+	Recent    []float64
+	Level     float64
+	LastSeen  time.Time
+	LowLevel  float64
+	LowAt     time.Time
+	HighLevel float64
+	HighAt    time.Time
+	Open      *FuelEvent
+}
+
+// FuelDay is the smoothed fuel level of a vehicle at the start and end of
+// one UTC day. The start is the last level before the day, when known.
+type FuelDay struct {
+	VehicleID  string
+	OwnerID    string
+	Day        time.Time
+	StartLevel float64
+	EndLevel   float64
+}
+
+// FuelReportQuery selects the fuel report of a vehicle. From and To are
+// widened to whole UTC days. OwnerID is empty for admins.
+type FuelReportQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+}
+
+// FuelEventQuery selects the fuel events overlapping [From, To). Empty
+// OwnerID, VehicleID and Type are not filtered on.
+type FuelEventQuery struct {
+	OwnerID   string
+	VehicleID string
+	Type      string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// FuelDailyUsage is the fuel balance of one day. Consumed is the drop of the
+// level that is not explained by drains, plus what was refuelled.
+// LitersPer100Km is left out when the vehicle hardly moved.
+type FuelDailyUsage struct {
+	Day            time.Time `json:"day"`
+	StartLevel     float64   `json:"start_level"`
+	EndLevel       float64   `json:"end_level"`
+	ConsumedLiters float64   `json:"consumed_liters"`
+	RefueledLiters float64   `json:"refueled_liters"`
+	DrainedLiters  float64   `json:"drained_liters"`
+	DistanceKm     float64   `json:"distance_km"`
+	LitersPer100Km *float64  `json:"liters_per_100km,omitempty"`
+}
+
+// FuelReport is the per-day fuel usage of a vehicle with the events of the
+// range.
+type FuelReport struct {
+	VehicleID      string           `json:"vehicle_id"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	ConsumedLiters float64          `json:"consumed_liters"`
+	RefueledLiters float64          `json:"refueled_liters"`
+	DrainedLiters  float64          `json:"drained_liters"`
+	DistanceKm     float64          `json:"distance_km"`
+	LitersPer100Km *float64         `json:"liters_per_100km,omitempty"`
+	Days           []FuelDailyUsage `json:"days"`
+	Events         []*FuelEvent     `json:"events"`
+}
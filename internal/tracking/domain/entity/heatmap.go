@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// HeatmapQuery aggregates the points of a fleet in [From, To) into geohash
+// cells. Precision wins over Zoom when both are set. OwnerID is empty for
+// admins.
+type HeatmapQuery struct {
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+	Zoom      int
+	Precision int
+	MaxCells  int
+}
+
+// GridQuery groups points into a regular latitude/longitude grid whose cells
+// are LatStep by LonStep degrees, keeping the Limit busiest cells.
+type GridQuery struct {
+	OwnerID string
+	From    time.Time
+	To      time.Time
+	LatStep float64
+	LonStep float64
+	Limit   int
+}
+
+// GridCell is one cell of a GridQuery, indexed from (-90, -180).
+type GridCell struct {
+	LatIndex int64
+	LonIndex int64
+	Points   int64
+	Vehicles int64
+	AvgSpeed float64
+}
+
+// HeatmapCell is a geohash cell with the activity recorded in it.
+type HeatmapCell struct {
+	Geohash   string  `json:"geohash"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Points    int64   `json:"points"`
+	Vehicles  int64   `json:"vehicles"`
+	AvgSpeed  float64 `json:"avg_speed"`
+}
+
+// Heatmap lists the busiest cells first. Truncated is set when TotalCells
+// exceeds the number of cells returned.
+type Heatmap struct {
+	From       time.Time     `json:"from"`
+	To         time.Time     `json:"to"`
+	Precision  int           `json:"precision"`
+	TotalCells int64         `json:"total_cells"`
+	Truncated  bool          `json:"truncated"`
+	Cells      []HeatmapCell `json:"cells"`
+}
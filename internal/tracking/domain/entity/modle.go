@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -8,12 +11,32 @@ import (
 )
 
 type VehicleLocation struct {
+	ID        int64     `json:"id,omitempty" bson:"-"`
 	OwnerID   string    `json:"owner_id" bson:"owner_id"`
 	VehicleID string    `json:"vehicle_id" bson:"vehicle_id"`
 	Latitude  float64   `json:"latitude" bson:"latitude"`
 	Longitude float64   `json:"longitude" bson:"longitude"`
 	Speed     float64   `json:"speed,omitempty" bson:"speed,omitempty"`
 	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+
+	// Optional telemetry. Pointers tell "not reported" apart from zero, which
+	// is a valid heading, altitude or ignition state.
+	Heading         *float64       `json:"heading,omitempty" bson:"heading,omitempty"`   // degrees clockwise from north
+	Altitude        *float64       `json:"altitude,omitempty" bson:"altitude,omitempty"` // meters above sea level
+	HDOP            *float64       `json:"hdop,omitempty" bson:"hdop,omitempty"`
+	Accuracy        *float64       `json:"accuracy,omitempty" bson:"accuracy,omitempty"` // meters
+	Satellites      *int           `json:"satellites,omitempty" bson:"satellites,omitempty"`
+	Ignition        *bool          `json:"ignition,omitempty" bson:"ignition,omitempty"`
+	ExternalVoltage *float64       `json:"external_voltage,omitempty" bson:"external_voltage,omitempty"` // volts
+	BatteryVoltage  *float64       `json:"battery_voltage,omitempty" bson:"battery_voltage,omitempty"`   // volts
+	FuelLevel       *float64       `json:"fuel_level,omitempty" bson:"fuel_level,omitempty"`             // liters in the tank
+	Attributes      map[string]any `json:"attributes,omitempty" bson:"attributes,omitempty"`
+
+	// OutOfOrder is set by the ingest filter on fixes older than the last
+	// accepted fix of the vehicle.
+	OutOfOrder bool `json:"out_of_order,omitempty" bson:"-"`
+	// Connectivity is attached to fleet listings of latest positions.
+	Connectivity *VehicleConnectivity `json:"connectivity,omitempty" bson:"-"`
 }
 
 // Ozzo validation for VehicleLocation
@@ -43,9 +66,136 @@ func (v VehicleLocation) Validate() error {
 		validation.Field(&v.Timestamp,
 			validation.Required,
 		),
+		validation.Field(&v.Heading,
+			validation.Min(0.0),
+			validation.Max(360.0),
+		),
+		validation.Field(&v.Altitude,
+			validation.Min(-1000.0),
+			validation.Max(20000.0),
+		),
+		validation.Field(&v.HDOP,
+			validation.Min(0.0),
+		),
+		validation.Field(&v.Accuracy,
+			validation.Min(0.0),
+		),
+		validation.Field(&v.Satellites,
+			validation.Min(0),
+			validation.Max(MaxSatellites),
+		),
+		validation.Field(&v.ExternalVoltage,
+			validation.Min(0.0),
+		),
+		validation.Field(&v.BatteryVoltage,
+			validation.Min(0.0),
+		),
+		validation.Field(&v.FuelLevel,
+			validation.Min(0.0),
+		),
+		validation.Field(&v.Attributes,
+			validation.By(validateAttributes),
+		),
 	)
 }
 
+// Limits on the free-form attributes of a location, which are stored with
+// every fix.
+const (
+	MaxSatellites         = 128
+	MaxAttributes         = 32
+	MaxAttributeKeyLength = 64
+	MaxAttributesBytes    = 2048
+)
+
+var errAttributesTooLarge = fmt.Errorf("must encode to at most %d bytes", MaxAttributesBytes)
+
+func validateAttributes(value any) error {
+	attributes, _ := value.(map[string]any)
+	if len(attributes) == 0 {
+		return nil
+	}
+	if len(attributes) > MaxAttributes {
+		return fmt.Errorf("must have at most %d entries", MaxAttributes)
+	}
+	for key := range attributes {
+		if key == "" || len(key) > MaxAttributeKeyLength {
+			return fmt.Errorf("keys must be 1 to %d characters long", MaxAttributeKeyLength)
+		}
+	}
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return errors.New("must be JSON encodable")
+	}
+	if len(encoded) > MaxAttributesBytes {
+		return errAttributesTooLarge
+	}
+	return nil
+}
+
+// LocationHistoryQuery describes a time-bounded read of one vehicle's track.
+// OwnerID is empty for admins; for everyone else it restricts the track to
+// points reported under their own account.
+type LocationHistoryQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+	Cursor    string
+	MaxPoints int
+	After     *HistoryCursor
+	Simplify  *SimplifyOptions
+}
+
+// SimplifyOptions asks for a Douglas-Peucker reduction of the whole range
+// instead of a page of raw points. At least one of ToleranceMeters and
+// TargetPoints must be set.
+type SimplifyOptions struct {
+	ToleranceMeters float64
+	TargetPoints    int
+}
+
+// SimplificationInfo describes how a simplified track was produced.
+// MaxErrorMeters is the largest distance between a dropped point and the
+// simplified line.
+type SimplificationInfo struct {
+	InputPoints     int     `json:"input_points"`
+	OutputPoints    int     `json:"output_points"`
+	MandatoryPoints int     `json:"mandatory_points"`
+	ToleranceMeters float64 `json:"tolerance_meters,omitempty"`
+	TargetPoints    int     `json:"target_points,omitempty"`
+	MaxErrorMeters  float64 `json:"max_error_meters"`
+	// Truncated is set when the output hit MaxHistoryPoints before the
+	// requested tolerance was met, so MaxErrorMeters exceeds it.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// LocationRangeQuery selects every point of a vehicle between From
+// (inclusive) and To (exclusive), for streaming consumers such as exports.
+type LocationRangeQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+}
+
+// HistoryCursor is the keyset position of the last point of a page.
+type HistoryCursor struct {
+	Timestamp time.Time
+	ID        int64
+}
+
+// LocationHistoryPage is one page of an ordered vehicle track.
+type LocationHistoryPage struct {
+	VehicleID  string             `json:"vehicle_id"`
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	Points     []*VehicleLocation `json:"points"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+
+	Simplification *SimplificationInfo `json:"simplification,omitempty"`
+}
+
 type VehicleID struct {
 	VehicleID string `json:"vehicle_id" bson:"vehicle_id"`
 }
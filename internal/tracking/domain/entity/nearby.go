@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// VehicleInfo is what the tracking domain knows about a vehicle registered
+// in the vehicle module.
+type VehicleInfo struct {
+	ID          string
+	OwnerID     string
+	PlateNumber string
+	VehicleType string
+	Deleted     bool
+	Disabled    bool
+	Tracked     bool
+}
+
+// NearbyQuery asks for the vehicles whose latest position lies within
+// RadiusMeters of a point. OwnerID is empty for admins.
+type NearbyQuery struct {
+	OwnerID      string
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	VehicleType  string
+	Limit        int
+}
+
+// NearbyVehicle is one match of a NearbyQuery.
+type NearbyVehicle struct {
+	VehicleID      string    `json:"vehicle_id"`
+	PlateNumber    string    `json:"plate_number,omitempty"`
+	VehicleType    string    `json:"vehicle_type,omitempty"`
+	DistanceMeters float64   `json:"distance_meters"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Speed          float64   `json:"speed,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+	AgeSeconds     int64     `json:"age_seconds"`
+}
+
+// NearbyResult lists matches ordered by distance, nearest first.
+type NearbyResult struct {
+	Latitude     float64         `json:"latitude"`
+	Longitude    float64         `json:"longitude"`
+	RadiusMeters float64         `json:"radius_meters"`
+	VehicleType  string          `json:"vehicle_type,omitempty"`
+	Vehicles     []NearbyVehicle `json:"vehicles"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Odometer is the running distance of a vehicle together with the last fix
+// that was counted, which the next fix is measured from.
+type Odometer struct {
+	VehicleID     string    `json:"vehicle_id"`
+	OwnerID       string    `json:"owner_id"`
+	TotalKm       float64   `json:"total_km"`
+	LastLatitude  float64   `json:"last_latitude"`
+	LastLongitude float64   `json:"last_longitude"`
+	LastTimestamp time.Time `json:"last_timestamp"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DailyDistance is the distance a vehicle covered on one UTC day.
+type DailyDistance struct {
+	VehicleID  string    `json:"vehicle_id"`
+	OwnerID    string    `json:"owner_id"`
+	Day        time.Time `json:"day"`
+	DistanceKm float64   `json:"distance_km"`
+}
+
+// Distance buckets supported by GetVehicleDistance.
+const (
+	DistanceBucketDay   = "day"
+	DistanceBucketWeek  = "week"
+	DistanceBucketMonth = "month"
+)
+
+// DistanceQuery selects the daily distance rows of a vehicle. From and To
+// are widened to whole UTC days.
+type DistanceQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+	Bucket    string
+}
+
+type DistanceBucket struct {
+	Start      time.Time `json:"start"`
+	DistanceKm float64   `json:"distance_km"`
+}
+
+type DistanceReport struct {
+	VehicleID string           `json:"vehicle_id"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Bucket    string           `json:"bucket"`
+	TotalKm   float64          `json:"total_km"`
+	Buckets   []DistanceBucket `json:"buckets"`
+}
+
+// OdometerConfig tunes the jitter filter applied before distance is counted.
+type OdometerConfig struct {
+	// MinMoveMeters ignores movements shorter than this from the last
+	// counted fix, so a parked vehicle's GPS drift does not add up.
+	MinMoveMeters float64
+	// MaxSpeedKmh rejects jumps that would require a higher speed.
+	MaxSpeedKmh float64
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// RetentionConfig controls how long raw location points are kept.
+type RetentionConfig struct {
+	// Enabled schedules the job in-process every Interval.
+	Enabled  bool
+	Interval time.Duration
+	// DryRun makes scheduled runs only count what they would change.
+	DryRun bool
+	// DownsampleAfter summarises raw points older than this into one row per
+	// vehicle and minute. Zero disables downsampling. The summaries are kept
+	// as an archive; location history only reads raw points.
+	DownsampleAfter time.Duration
+	// DeleteAfter deletes raw points older than this. Zero disables deletion.
+	// Points that were not summarised yet are summarised before they are
+	// deleted.
+	DeleteAfter time.Duration
+	// Chunk is the time span handled per statement, which bounds the size
+	// of every transaction.
+	Chunk time.Duration
+}
+
+// RetentionReport records what one retention run changed, or would have
+// changed in a dry run.
+type RetentionReport struct {
+	ID               int64      `json:"id,omitempty"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       time.Time  `json:"finished_at"`
+	DryRun           bool       `json:"dry_run"`
+	DownsampleBefore *time.Time `json:"downsample_before,omitempty"`
+	DeleteBefore     *time.Time `json:"delete_before,omitempty"`
+	SummariesWritten int64      `json:"summaries_written"`
+	PointsSummarised int64      `json:"points_summarised"`
+	PointsDeleted    int64      `json:"points_deleted"`
+	Error            string     `json:"error,omitempty"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// ShareConfig bounds the lifetime of public share links.
+type ShareConfig struct {
+	// DefaultTTL applies when a link is created without an expiry.
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+	// TrackPoints caps the simplified track a link shows.
+	TrackPoints int
+}
+
+// ShareLink lets anyone holding its token follow one vehicle without an
+// account until it expires or is revoked. Only the SHA-256 of the token is
+// stored; Token is set once, in the response to its creation. With a window
+// the link only shows the vehicle between WindowFrom and WindowTo.
+type ShareLink struct {
+	ID          int64      `json:"id"`
+	VehicleID   string     `json:"vehicle_id"`
+	OwnerID     string     `json:"owner_id"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	Label       string     `json:"label,omitempty"`
+	Token       string     `json:"token,omitempty"`
+	TokenHash   string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	WindowFrom  *time.Time `json:"window_from,omitempty"`
+	WindowTo    *time.Time `json:"window_to,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	AccessCount int64      `json:"access_count"`
+	LastAccess  *time.Time `json:"last_access_at,omitempty"`
+}
+
+// ShareAccessOutcome tells whether an access to a share link was served.
+type ShareAccessOutcome string
+
+const (
+	ShareAccessGranted    ShareAccessOutcome = "granted"
+	ShareAccessExpired    ShareAccessOutcome = "expired"
+	ShareAccessRevoked    ShareAccessOutcome = "revoked"
+	ShareAccessNotStarted ShareAccessOutcome = "not_started"
+)
+
+// ShareAccess is the audit record of one request made with a share link.
+type ShareAccess struct {
+	ID         int64              `json:"id"`
+	ShareID    int64              `json:"share_id"`
+	VehicleID  string             `json:"vehicle_id"`
+	AccessedAt time.Time          `json:"accessed_at"`
+	RemoteAddr string             `json:"remote_addr,omitempty"`
+	UserAgent  string             `json:"user_agent,omitempty"`
+	Outcome    ShareAccessOutcome `json:"outcome"`
+}
+
+// ShareView is what a share link shows: the vehicle's latest position and
+// its simplified track over the shared range. Points carry no owner or row
+// identifiers since the view is public.
+type ShareView struct {
+	VehicleID string        `json:"vehicle_id"`
+	Label     string        `json:"label,omitempty"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Latest    *SharedPoint  `json:"latest,omitempty"`
+	Track     []SharedPoint `json:"track"`
+}
+
+// SharedPoint is a position shown through a share link.
+type SharedPoint struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Speed     float64   `json:"speed,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func SharedPointOf(location VehicleLocation) SharedPoint {
+	return SharedPoint{
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Speed:     location.Speed,
+		Timestamp: location.Timestamp,
+	}
+}
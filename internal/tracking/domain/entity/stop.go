@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// StopDetectionConfig holds the thresholds used to find stops in the
+// location stream.
+type StopDetectionConfig struct {
+	Enabled bool
+	// RadiusMeters is how far a vehicle may drift from where it arrived and
+	// still count as stopped.
+	RadiusMeters float64
+	// MinDuration is how long a vehicle has to stay within the radius for
+	// the stay to count as a stop.
+	MinDuration time.Duration
+}
+
+// Stop is a stay of a vehicle within a small radius that lasted at least the
+// configured minimum. The position is the mean of the fixes of the stay.
+// Ongoing stops have not been left yet; their departure is the latest fix.
+type Stop struct {
+	ID              int64         `json:"id,omitempty"`
+	OwnerID         string        `json:"owner_id"`
+	VehicleID       string        `json:"vehicle_id"`
+	Latitude        float64       `json:"latitude"`
+	Longitude       float64       `json:"longitude"`
+	ArrivalTime     time.Time     `json:"arrival_time"`
+	DepartureTime   time.Time     `json:"departure_time"`
+	DurationSeconds int64         `json:"duration_seconds"`
+	PointCount      int           `json:"point_count"`
+	Ongoing         bool          `json:"ongoing"`
+	Geofences       []GeofenceRef `json:"geofences"`
+}
+
+// GeofenceRef names a geofence a stop lay in.
+type GeofenceRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// StopState is the stay a vehicle is currently in, which becomes a stop once
+// it lasts long enough. Anchor is the first fix of the stay and the centre
+// of the radius.
+type StopState struct {
+	VehicleID       string
+	OwnerID         string
+	AnchorLatitude  float64
+	AnchorLongitude float64
+	Latitude        float64
+	Longitude       float64
+	ArrivalTime     time.Time
+	LastSeen        time.Time
+	PointCount      int
+}
+
+// Dwell is how long the vehicle has stayed so far.
+func (s StopState) Dwell() time.Duration {
+	return s.LastSeen.Sub(s.ArrivalTime)
+}
+
+// StopQuery selects the stops of a vehicle that overlap [From, To).
+// OwnerID is empty for admins.
+type StopQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+}
+
+// GeofenceVisitQuery selects the stops overlapping [From, To) that lay in a
+// geofence. Empty OwnerID and VehicleID are not filtered on.
+type GeofenceVisitQuery struct {
+	OwnerID   string
+	VehicleID string
+	From      time.Time
+	To        time.Time
+}
+
+// GeofenceVisits summarises the stops made in one geofence.
+type GeofenceVisits struct {
+	GeofenceID        string `json:"geofence_id"`
+	GeofenceName      string `json:"geofence_name"`
+	Visits            int    `json:"visits"`
+	Vehicles          int    `json:"vehicles"`
+	TotalDwellSeconds int64  `json:"total_dwell_seconds"`
+	AvgDwellSeconds   int64  `json:"avg_dwell_seconds"`
+	MaxDwellSeconds   int64  `json:"max_dwell_seconds"`
+}
+
+// GeofenceVisitReport is the per-geofence visit summary for a range. Dwell
+// times are those of whole stops, including parts outside the range.
+type GeofenceVisitReport struct {
+	OwnerID   string           `json:"owner_id,omitempty"`
+	VehicleID string           `json:"vehicle_id,omitempty"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Geofences []GeofenceVisits `json:"geofences"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Trip is a continuous stretch of movement of a single vehicle, bounded by
+// stops that lasted at least the configured dwell time.
+type Trip struct {
+	ID              int64     `json:"id,omitempty"`
+	OwnerID         string    `json:"owner_id"`
+	VehicleID       string    `json:"vehicle_id"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	StartLatitude   float64   `json:"start_latitude"`
+	StartLongitude  float64   `json:"start_longitude"`
+	EndLatitude     float64   `json:"end_latitude"`
+	EndLongitude    float64   `json:"end_longitude"`
+	DistanceKm      float64   `json:"distance_km"`
+	MaxSpeed        float64   `json:"max_speed"`
+	AvgSpeed        float64   `json:"avg_speed"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	PointCount      int       `json:"point_count"`
+}
+
+// TripQuery selects the trips of a vehicle that overlap [From, To).
+// OwnerID is empty for admins.
+type TripQuery struct {
+	VehicleID string
+	OwnerID   string
+	From      time.Time
+	To        time.Time
+}
+
+// TripWatermark records which part of a vehicle's raw track has already been
+// segmented into persisted trips.
+type TripWatermark struct {
+	VehicleID      string
+	ProcessedFrom  time.Time
+	ProcessedUntil time.Time
+}
+
+// TripDetectionConfig holds the thresholds used to split a track into trips.
+type TripDetectionConfig struct {
+	// StopSpeed is the speed in km/h at or below which a vehicle counts as stationary.
+	StopSpeed float64
+	// MinDwell is how long a vehicle has to stay stationary (or silent) to end a trip.
+	MinDwell time.Duration
+	// MinDistanceKm drops trips shorter than this, which are usually GPS drift.
+	MinDistanceKm float64
+	// SegmentInterval is how often new points are segmented into trips.
+	SegmentInterval time.Duration
+	// Backfill is how far back a vehicle without a watermark is segmented.
+	Backfill time.Duration
+}
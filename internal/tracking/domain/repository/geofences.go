@@ -0,0 +1,12 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+)
+
+// GeofenceLocator finds the geofences of an owner that contain a point, from
+// the geofence module.
+type GeofenceLocator interface {
+	GeofencesAt(ctx context.Context, ownerID string, latitude, longitude float64) ([]entity.GeofenceRef, error)
+}
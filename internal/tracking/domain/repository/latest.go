@@ -0,0 +1,23 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+)
+
+// LatestPositionStore keeps the most recent location of every vehicle so
+// latest-position reads do not scan vehicle_locations. The in-process
+// implementation only sees writes of its own instance; multi-instance
+// deployments should plug in a shared store.
+type LatestPositionStore interface {
+	// Get returns the latest location of a vehicle; ok is false when the
+	// store has none.
+	Get(ctx context.Context, vehicleID string) (location entity.VehicleLocation, ok bool, err error)
+	// ListByOwner returns the latest location of every vehicle whose latest
+	// location was reported under ownerID, ordered by vehicle ID. An empty
+	// ownerID lists every vehicle.
+	ListByOwner(ctx context.Context, ownerID string) ([]*entity.VehicleLocation, error)
+	// Put records location unless the store already holds a newer one for
+	// the vehicle.
+	Put(ctx context.Context, location entity.VehicleLocation) error
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+)
+
+// LocationListener is notified after a location has been persisted. Other
+// modules (geofences, alerts, ...) hook into ingestion through it.
+type LocationListener interface {
+	OnLocation(ctx context.Context, location entity.VehicleLocation)
+}
+
+// LocationListenerFunc adapts a plain function to a LocationListener.
+type LocationListenerFunc func(ctx context.Context, location entity.VehicleLocation)
+
+func (f LocationListenerFunc) OnLocation(ctx context.Context, location entity.VehicleLocation) {
+	f(ctx, location)
+}
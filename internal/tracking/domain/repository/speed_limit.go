@@ -0,0 +1,16 @@
+package service
+
+import "context"
+
+// SpeedLimitProvider looks up the speed limit (km/h) of a vehicle. ok is
+// false when the vehicle has no known limit.
+type SpeedLimitProvider interface {
+	SpeedLimit(ctx context.Context, vehicleID string) (limit float64, ok bool)
+}
+
+// SpeedLimitProviderFunc adapts a plain function to a SpeedLimitProvider.
+type SpeedLimitProviderFunc func(ctx context.Context, vehicleID string) (float64, bool)
+
+func (f SpeedLimitProviderFunc) SpeedLimit(ctx context.Context, vehicleID string) (float64, bool) {
+	return f(ctx, vehicleID)
+}
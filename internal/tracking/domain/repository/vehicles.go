@@ -0,0 +1,23 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+)
+
+// VehicleDirectory lists registered vehicles from the vehicle module.
+type VehicleDirectory interface {
+	// ListVehicles returns the vehicles of ownerID (every vehicle when it is
+	// empty), restricted to vehicleType when it is not empty.
+	ListVehicles(ctx context.Context, ownerID, vehicleType string) ([]entity.VehicleInfo, error)
+}
+
+// VehicleRegistry resolves the vehicle a location is reported for, so ingest
+// can check who owns it and whether it may be tracked.
+type VehicleRegistry interface {
+	// LookupVehicle returns the vehicle, deleted ones included; found is
+	// false when it was never registered.
+	LookupVehicle(ctx context.Context, vehicleID string) (vehicle entity.VehicleInfo, found bool, err error)
+	// MarkTracked flags the vehicle as currently tracked.
+	MarkTracked(ctx context.Context, vehicleID string) error
+}
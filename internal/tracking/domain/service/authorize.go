@@ -0,0 +1,143 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const DefaultVehicleCacheTTL = 30 * time.Second
+
+var (
+	ErrVehicleNotFound  = errors.New("vehicle not found")
+	ErrVehicleForbidden = errors.New("vehicle belongs to another owner")
+)
+
+// SetVehicleRegistry enables ingest authorization. Without a registry every
+// location is accepted for the owner it names.
+func (s *DomainTrackerService) SetVehicleRegistry(registry repo.VehicleRegistry) {
+	ttl := s.config.Ingest.VehicleCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultVehicleCacheTTL
+	}
+	s.registry = registry
+	s.registered = &vehicleCache{ttl: ttl, entries: make(map[string]cachedVehicle)}
+}
+
+// AuthorizeLocation checks that the vehicle of a location is registered,
+// belongs to the location's owner and is neither deleted nor disabled. An
+// empty OwnerID is filled in from the vehicle. Refusals are reported with
+// an *IngestDeniedError.
+func (s *DomainTrackerService) AuthorizeLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
+	location, _, err := s.authorize(ctx, location)
+	return location, err
+}
+
+func (s *DomainTrackerService) authorize(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, entity.VehicleInfo, error) {
+	if s.registry == nil {
+		return location, entity.VehicleInfo{ID: location.VehicleID, OwnerID: location.OwnerID, Tracked: true}, nil
+	}
+
+	vehicle, found, err := s.registeredVehicle(ctx, location.VehicleID)
+	if err != nil {
+		return entity.VehicleLocation{}, entity.VehicleInfo{}, err
+	}
+
+	var reason entity.IngestDenial
+	switch {
+	case !found:
+		reason = entity.DenialUnknownVehicle
+	case vehicle.Deleted:
+		reason = entity.DenialVehicleDeleted
+	case vehicle.Disabled:
+		reason = entity.DenialVehicleDisabled
+	case location.OwnerID != "" && location.OwnerID != vehicle.OwnerID:
+		reason = entity.DenialOwnerMismatch
+	}
+	if reason != "" {
+		return entity.VehicleLocation{}, entity.VehicleInfo{}, &entity.IngestDeniedError{VehicleID: location.VehicleID, Reason: reason}
+	}
+
+	location.OwnerID = vehicle.OwnerID
+	return location, vehicle, nil
+}
+
+// CheckVehicleAccess checks that a vehicle is registered and belongs to
+// ownerID, which is empty for admins. Unknown and deleted vehicles are
+// reported with ErrVehicleNotFound, vehicles of other owners with
+// ErrVehicleForbidden.
+func (s *DomainTrackerService) CheckVehicleAccess(ctx context.Context, vehicleID, ownerID string) error {
+	owner, err := s.vehicleOwner(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+	if ownerID != "" && owner != ownerID {
+		return ErrVehicleForbidden
+	}
+	return nil
+}
+
+// markTracked flags a vehicle as tracked after its first stored fix.
+// Failures are logged; the fix itself is already stored.
+func (s *DomainTrackerService) markTracked(ctx context.Context, vehicle entity.VehicleInfo) {
+	if s.registry == nil || vehicle.Tracked {
+		return
+	}
+	if err := s.registry.MarkTracked(ctx, vehicle.ID); err != nil {
+		s.logger.Errorf("[MarkTracked] vehicle %s: %v", vehicle.ID, err)
+		return
+	}
+	vehicle.Tracked = true
+	s.registered.put(vehicle)
+	s.logger.Infof("[MarkTracked] vehicle %s reported its first location", vehicle.ID)
+}
+
+func (s *DomainTrackerService) registeredVehicle(ctx context.Context, vehicleID string) (entity.VehicleInfo, bool, error) {
+	if vehicle, ok := s.registered.get(vehicleID); ok {
+		return vehicle, true, nil
+	}
+	vehicle, found, err := s.registry.LookupVehicle(ctx, vehicleID)
+	if err != nil || !found {
+		// Misses are not cached so a vehicle can report as soon as it is
+		// registered.
+		return entity.VehicleInfo{}, false, err
+	}
+	s.registered.put(vehicle)
+	return vehicle, true, nil
+}
+
+// vehicleCache keeps looked-up vehicles for a short time so a stream of
+// fixes does not query the vehicle store for every point.
+type vehicleCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedVehicle
+}
+
+type cachedVehicle struct {
+	vehicle entity.VehicleInfo
+	expires time.Time
+}
+
+func (c *vehicleCache) get(vehicleID string) (entity.VehicleInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[vehicleID]
+	if !ok {
+		return entity.VehicleInfo{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, vehicleID)
+		return entity.VehicleInfo{}, false
+	}
+	return entry.vehicle, true
+}
+
+func (c *vehicleCache) put(vehicle entity.VehicleInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[vehicle.ID] = cachedVehicle{vehicle: vehicle, expires: time.Now().Add(c.ttl)}
+}
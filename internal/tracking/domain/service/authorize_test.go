@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+// memoryRegistry holds registered vehicles and counts the lookups.
+type memoryRegistry struct {
+	vehicles map[string]entity.VehicleInfo
+	lookups  int
+}
+
+func (r *memoryRegistry) LookupVehicle(ctx context.Context, vehicleID string) (entity.VehicleInfo, bool, error) {
+	r.lookups++
+	vehicle, ok := r.vehicles[vehicleID]
+	return vehicle, ok, nil
+}
+
+func (r *memoryRegistry) MarkTracked(ctx context.Context, vehicleID string) error {
+	return nil
+}
+
+func TestAuthorizeLocation(t *testing.T) {
+	registry := &memoryRegistry{vehicles: map[string]entity.VehicleInfo{
+		"v1":       {ID: "v1", OwnerID: "owner1"},
+		"deleted":  {ID: "deleted", OwnerID: "owner1", Deleted: true},
+		"disabled": {ID: "disabled", OwnerID: "owner1", Disabled: true},
+		// Deleted wins over another owner, so a deleted vehicle of another
+		// owner does not reveal who owns it.
+		"foreign-deleted": {ID: "foreign-deleted", OwnerID: "owner2", Deleted: true},
+	}}
+
+	cases := []struct {
+		name       string
+		location   entity.VehicleLocation
+		wantReason entity.IngestDenial
+		wantOwner  string
+	}{
+		{name: "owner matches", location: entity.VehicleLocation{VehicleID: "v1", OwnerID: "owner1"}, wantOwner: "owner1"},
+		{name: "owner from the vehicle", location: entity.VehicleLocation{VehicleID: "v1"}, wantOwner: "owner1"},
+		{name: "owner mismatch", location: entity.VehicleLocation{VehicleID: "v1", OwnerID: "owner2"}, wantReason: entity.DenialOwnerMismatch},
+		{name: "unknown vehicle", location: entity.VehicleLocation{VehicleID: "v9", OwnerID: "owner1"}, wantReason: entity.DenialUnknownVehicle},
+		{name: "deleted vehicle", location: entity.VehicleLocation{VehicleID: "deleted", OwnerID: "owner1"}, wantReason: entity.DenialVehicleDeleted},
+		{name: "disabled vehicle", location: entity.VehicleLocation{VehicleID: "disabled", OwnerID: "owner1"}, wantReason: entity.DenialVehicleDisabled},
+		{name: "deleted vehicle of another owner", location: entity.VehicleLocation{VehicleID: "foreign-deleted", OwnerID: "owner1"}, wantReason: entity.DenialVehicleDeleted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestTracker(&fakeTrackerRepo{}, entity.TrackerConfig{})
+			s.SetVehicleRegistry(registry)
+
+			got, err := s.AuthorizeLocation(context.Background(), tc.location)
+			if tc.wantReason == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want none", err)
+				}
+				if got.OwnerID != tc.wantOwner {
+					t.Errorf("owner = %q, want %q", got.OwnerID, tc.wantOwner)
+				}
+				return
+			}
+
+			var denied *entity.IngestDeniedError
+			if !errors.As(err, &denied) || denied.Reason != tc.wantReason {
+				t.Fatalf("err = %v, want denial %q", err, tc.wantReason)
+			}
+			if !errors.Is(err, entity.ErrIngestDenied) {
+				t.Errorf("err = %v does not match ErrIngestDenied", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeLocationCachesRegisteredVehicles(t *testing.T) {
+	registry := &memoryRegistry{vehicles: map[string]entity.VehicleInfo{"v1": {ID: "v1", OwnerID: "owner1"}}}
+	s := newTestTracker(&fakeTrackerRepo{}, entity.TrackerConfig{})
+	s.SetVehicleRegistry(registry)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.AuthorizeLocation(ctx, entity.VehicleLocation{VehicleID: "v1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if registry.lookups != 1 {
+		t.Errorf("registered vehicle looked up %d times, want 1", registry.lookups)
+	}
+
+	// A vehicle registered after a refused fix is accepted right away.
+	if _, err := s.AuthorizeLocation(ctx, entity.VehicleLocation{VehicleID: "v2"}); !errors.Is(err, entity.ErrIngestDenied) {
+		t.Fatalf("unregistered vehicle: err = %v, want a denial", err)
+	}
+	registry.vehicles["v2"] = entity.VehicleInfo{ID: "v2", OwnerID: "owner1"}
+	if _, err := s.AuthorizeLocation(ctx, entity.VehicleLocation{VehicleID: "v2"}); err != nil {
+		t.Fatalf("newly registered vehicle: %v", err)
+	}
+}
+
+func TestAuthorizeLocationWithoutRegistry(t *testing.T) {
+	s := newTestTracker(&fakeTrackerRepo{}, entity.TrackerConfig{})
+	location := entity.VehicleLocation{VehicleID: "v9", OwnerID: "owner1"}
+
+	got, err := s.AuthorizeLocation(context.Background(), location)
+	if err != nil || got.OwnerID != "owner1" || got.VehicleID != "v9" {
+		t.Fatalf("got %+v, %v; want the location accepted as named", got, err)
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultIdleAfter               = 5 * time.Minute
+	DefaultOfflineAfter            = 30 * time.Minute
+	DefaultConnectivityTransitions = 100
+	MaxConnectivityTransitions     = 1000
+)
+
+var ErrInvalidThresholds = errors.New("invalid thresholds: idle_after_seconds must be positive and below offline_after_seconds")
+
+// connectivityMonitor holds the last status recorded per vehicle and the
+// per-fleet thresholds.
+type connectivityMonitor struct {
+	mu         sync.Mutex
+	states     map[string]entity.VehicleConnectivity
+	thresholds map[string]entity.ConnectivityThresholds
+}
+
+func (s *DomainTrackerService) connectivityThresholds(ownerID string) entity.ConnectivityThresholds {
+	s.connectivity.mu.Lock()
+	defer s.connectivity.mu.Unlock()
+	if t, ok := s.connectivity.thresholds[ownerID]; ok {
+		return t
+	}
+	t := s.config.Connectivity.Default
+	if t.IdleAfterSeconds <= 0 {
+		t.IdleAfterSeconds = int64(DefaultIdleAfter / time.Second)
+	}
+	if t.OfflineAfterSeconds <= t.IdleAfterSeconds {
+		t.OfflineAfterSeconds = max(int64(DefaultOfflineAfter/time.Second), t.IdleAfterSeconds+1)
+	}
+	t.OwnerID = ownerID
+	return t
+}
+
+// LoadConnectivity reads the recorded statuses and the fleet thresholds.
+func (s *DomainTrackerService) LoadConnectivity(ctx context.Context) error {
+	states, err := s.trackerRepo.GetConnectivityStates(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.reloadThresholds(ctx); err != nil {
+		return err
+	}
+
+	s.connectivity.mu.Lock()
+	defer s.connectivity.mu.Unlock()
+	for _, state := range states {
+		s.connectivity.states[state.VehicleID] = state
+	}
+	return nil
+}
+
+func (s *DomainTrackerService) reloadThresholds(ctx context.Context) error {
+	thresholds, err := s.trackerRepo.GetConnectivityThresholds(ctx)
+	if err != nil {
+		return err
+	}
+	byOwner := make(map[string]entity.ConnectivityThresholds, len(thresholds))
+	for _, t := range thresholds {
+		byOwner[t.OwnerID] = t
+	}
+
+	s.connectivity.mu.Lock()
+	defer s.connectivity.mu.Unlock()
+	s.connectivity.thresholds = byOwner
+	return nil
+}
+
+// CheckConnectivity classifies every vehicle by the age of its latest fix
+// and records the vehicles whose status changed. It returns the number of
+// transitions recorded.
+func (s *DomainTrackerService) CheckConnectivity(ctx context.Context) (int, error) {
+	// Thresholds may have been changed through another instance.
+	if err := s.reloadThresholds(ctx); err != nil {
+		return 0, err
+	}
+	locations, err := s.latestPositions(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	recorded := 0
+	for _, location := range locations {
+		changed, err := s.observeConnectivity(ctx, *location, now)
+		if err != nil {
+			return recorded, err
+		}
+		if changed {
+			recorded++
+		}
+	}
+	return recorded, nil
+}
+
+// ConnectivityListener brings a vehicle back online as soon as it reports
+// instead of at the next check.
+func (s *DomainTrackerService) ConnectivityListener() repo.LocationListener {
+	return repo.LocationListenerFunc(func(ctx context.Context, location entity.VehicleLocation) {
+		if _, err := s.observeConnectivity(ctx, location, time.Now().UTC()); err != nil {
+			s.logger.Errorf("[Connectivity] vehicle %s: %v", location.VehicleID, err)
+		}
+	})
+}
+
+// observeConnectivity records a transition when the status implied by the
+// vehicle's latest fix differs from its recorded status. A transition is
+// dated when it actually happened: at the fix for online, and when the
+// idle or offline threshold passed otherwise.
+func (s *DomainTrackerService) observeConnectivity(ctx context.Context, location entity.VehicleLocation, now time.Time) (bool, error) {
+	thresholds := s.connectivityThresholds(location.OwnerID)
+	status := thresholds.Classify(now.Sub(location.Timestamp))
+
+	s.connectivity.mu.Lock()
+	previous, known := s.connectivity.states[location.VehicleID]
+	if known && location.Timestamp.Before(previous.LastFixAt) {
+		// An older fix than the one the status is based on.
+		s.connectivity.mu.Unlock()
+		return false, nil
+	}
+	if known && previous.Status == status {
+		previous.LastFixAt = location.Timestamp
+		s.connectivity.states[location.VehicleID] = previous
+		s.connectivity.mu.Unlock()
+		return false, nil
+	}
+	s.connectivity.mu.Unlock()
+
+	at := location.Timestamp
+	switch status {
+	case entity.StatusIdle:
+		at = location.Timestamp.Add(thresholds.IdleAfter())
+	case entity.StatusOffline:
+		at = location.Timestamp.Add(thresholds.OfflineAfter())
+	}
+	if at.After(now) {
+		at = now
+	}
+
+	transition := entity.ConnectivityTransition{
+		VehicleID: location.VehicleID,
+		OwnerID:   location.OwnerID,
+		From:      previous.Status,
+		To:        status,
+		At:        at,
+		LastFixAt: location.Timestamp,
+	}
+	recorded, err := s.trackerRepo.SaveConnectivityTransition(ctx, transition)
+	if err != nil {
+		return false, err
+	}
+
+	s.connectivity.mu.Lock()
+	s.connectivity.states[location.VehicleID] = entity.VehicleConnectivity{
+		VehicleID: location.VehicleID,
+		OwnerID:   location.OwnerID,
+		Status:    status,
+		Since:     &at,
+		LastFixAt: location.Timestamp,
+	}
+	s.connectivity.mu.Unlock()
+
+	if recorded {
+		s.logger.Infof("[Connectivity] vehicle %s is %s (last fix %s)", location.VehicleID, status, location.Timestamp.Format(time.RFC3339))
+	}
+	return recorded, nil
+}
+
+// connectivityOf returns the current status of a vehicle from its latest
+// fix. Since is only known once the monitor has recorded the status.
+func (s *DomainTrackerService) connectivityOf(location entity.VehicleLocation, now time.Time) *entity.VehicleConnectivity {
+	status := s.connectivityThresholds(location.OwnerID).Classify(now.Sub(location.Timestamp))
+	current := &entity.VehicleConnectivity{
+		VehicleID: location.VehicleID,
+		OwnerID:   location.OwnerID,
+		Status:    status,
+		LastFixAt: location.Timestamp,
+	}
+
+	s.connectivity.mu.Lock()
+	defer s.connectivity.mu.Unlock()
+	if state, ok := s.connectivity.states[location.VehicleID]; ok && state.Status == status {
+		current.Since = state.Since
+	}
+	return current
+}
+
+// GetVehicleConnectivity returns the current status of every vehicle of
+// ownerID that has reported, or of every vehicle when ownerID is empty.
+func (s *DomainTrackerService) GetVehicleConnectivity(ctx context.Context, ownerID string) ([]entity.VehicleConnectivity, error) {
+	locations, err := s.latestPositions(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	statuses := make([]entity.VehicleConnectivity, 0, len(locations))
+	for _, location := range locations {
+		statuses = append(statuses, *s.connectivityOf(*location, now))
+	}
+	return statuses, nil
+}
+
+// GetConnectivityTransitions returns the most recent status changes of a
+// vehicle in the range.
+func (s *DomainTrackerService) GetConnectivityTransitions(ctx context.Context, query entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error) {
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultConnectivityTransitions
+	}
+	if query.Limit > MaxConnectivityTransitions {
+		query.Limit = MaxConnectivityTransitions
+	}
+	transitions, err := s.trackerRepo.GetConnectivityTransitions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if transitions == nil {
+		transitions = []*entity.ConnectivityTransition{}
+	}
+	return transitions, nil
+}
+
+// GetConnectivityThresholds returns the thresholds in effect for a fleet.
+func (s *DomainTrackerService) GetConnectivityThresholds(ctx context.Context, ownerID string) (entity.ConnectivityThresholds, error) {
+	if err := s.reloadThresholds(ctx); err != nil {
+		return entity.ConnectivityThresholds{}, err
+	}
+	return s.connectivityThresholds(ownerID), nil
+}
+
+// SetConnectivityThresholds stores thresholds of a fleet's own.
+func (s *DomainTrackerService) SetConnectivityThresholds(ctx context.Context, thresholds entity.ConnectivityThresholds) (entity.ConnectivityThresholds, error) {
+	if thresholds.IdleAfterSeconds <= 0 || thresholds.OfflineAfterSeconds <= thresholds.IdleAfterSeconds {
+		return entity.ConnectivityThresholds{}, ErrInvalidThresholds
+	}
+	thresholds.UpdatedAt = time.Now().UTC()
+	if err := s.trackerRepo.SaveConnectivityThresholds(ctx, thresholds); err != nil {
+		return entity.ConnectivityThresholds{}, err
+	}
+
+	s.connectivity.mu.Lock()
+	s.connectivity.thresholds[thresholds.OwnerID] = thresholds
+	s.connectivity.mu.Unlock()
+	return thresholds, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentFixes is how many raw fixes per vehicle are remembered to catch
+// retransmitted duplicates that arrive out of order.
+const recentFixes = 64
+
+// IngestFilter screens incoming locations per vehicle before they are stored.
+// It keeps the last accepted fix of every vehicle in memory, seeded from the
+// latest stored location the first time a vehicle is seen. Locations are
+// screened within a FilterTx, so what the filter remembers about them only
+// changes once they were stored.
+type IngestFilter struct {
+	config entity.IngestFilterConfig
+	latest func(ctx context.Context, vehicleID string) (entity.VehicleLocation, error)
+
+	mu       sync.Mutex
+	vehicles map[string]*vehicleFilter
+}
+
+// FilterDecision is the outcome of running one location through the filter.
+// Reason is empty when the location is accepted; Location then holds the
+// (possibly smoothed and flagged) fix to store.
+type FilterDecision struct {
+	Location entity.VehicleLocation
+	Reason   entity.QuarantineReason
+	Detail   string
+}
+
+type rawFix struct {
+	timestamp time.Time
+	latitude  float64
+	longitude float64
+}
+
+type vehicleFilter struct {
+	mu        sync.Mutex
+	seeded    bool
+	last      *rawFix
+	recent    []rawFix
+	next      int
+	teleports int
+	kalman    kalmanState
+}
+
+func NewIngestFilter(config entity.IngestFilterConfig, latest func(ctx context.Context, vehicleID string) (entity.VehicleLocation, error)) *IngestFilter {
+	return &IngestFilter{
+		config:   config,
+		latest:   latest,
+		vehicles: make(map[string]*vehicleFilter),
+	}
+}
+
+// FilterTx screens locations against a working copy of the state of their
+// vehicles. Commit makes the changes visible once the outcome is stored;
+// Rollback drops them, so a location whose write failed is screened afresh
+// when it is retried instead of being taken for a duplicate. The vehicles of
+// a transaction stay locked until it ends, so concurrent writes of one
+// vehicle are screened one after the other.
+type FilterTx struct {
+	filter *IngestFilter
+	locked map[string]*vehicleFilter
+	work   map[string]*vehicleFilter
+	done   bool
+}
+
+// Begin starts a transaction over the given vehicles, locking them in a
+// fixed order so that concurrent transactions cannot deadlock. Vehicles not
+// named here are locked when their first location is screened.
+func (f *IngestFilter) Begin(vehicleIDs ...string) *FilterTx {
+	ids := append([]string(nil), vehicleIDs...)
+	sort.Strings(ids)
+	tx := &FilterTx{
+		filter: f,
+		locked: make(map[string]*vehicleFilter, len(ids)),
+		work:   make(map[string]*vehicleFilter, len(ids)),
+	}
+	for _, id := range ids {
+		tx.state(id)
+	}
+	return tx
+}
+
+// state returns the working copy of a vehicle's state, locking the vehicle
+// on first use.
+func (tx *FilterTx) state(vehicleID string) *vehicleFilter {
+	if work, ok := tx.work[vehicleID]; ok {
+		return work
+	}
+	state := tx.filter.vehicle(vehicleID)
+	state.mu.Lock()
+	tx.locked[vehicleID] = state
+	work := state.clone()
+	tx.work[vehicleID] = work
+	return work
+}
+
+// Commit makes the state changes of the transaction visible and releases
+// its vehicles.
+func (tx *FilterTx) Commit() {
+	if tx.done {
+		return
+	}
+	for id, state := range tx.locked {
+		state.restore(tx.work[id])
+	}
+	tx.release()
+}
+
+// Rollback drops the state changes of the transaction and releases its
+// vehicles. It does nothing after Commit, so it can be deferred.
+func (tx *FilterTx) Rollback() {
+	if tx.done {
+		return
+	}
+	tx.release()
+}
+
+func (tx *FilterTx) release() {
+	tx.done = true
+	for _, state := range tx.locked {
+		state.mu.Unlock()
+	}
+}
+
+// Apply runs location through the filter. Exact duplicates of recent fixes
+// and teleport jumps are rejected; fixes older than the last accepted one are
+// accepted but flagged OutOfOrder and are neither used as the new anchor nor
+// smoothed. Later locations of the transaction are screened against the
+// earlier ones.
+func (tx *FilterTx) Apply(ctx context.Context, location entity.VehicleLocation) FilterDecision {
+	f := tx.filter
+	state := tx.state(location.VehicleID)
+
+	if !state.seeded {
+		state.seeded = true
+		if f.latest != nil {
+			if stored, err := f.latest(ctx, location.VehicleID); err == nil && !stored.Timestamp.IsZero() {
+				state.accept(rawFix{stored.Timestamp, stored.Latitude, stored.Longitude})
+			}
+		}
+	}
+
+	fix := rawFix{location.Timestamp, location.Latitude, location.Longitude}
+	location.OutOfOrder = false
+
+	if state.seen(fix) {
+		return FilterDecision{Location: location, Reason: entity.QuarantineDuplicate, Detail: "repeat of a recent fix"}
+	}
+
+	if state.last != nil && !fix.timestamp.After(state.last.timestamp) {
+		state.remember(fix)
+		location.OutOfOrder = true
+		return FilterDecision{Location: location}
+	}
+
+	if state.last != nil && f.config.MaxSpeedKmh > 0 {
+		meters := geo.HaversineMeters(state.last.latitude, state.last.longitude, fix.latitude, fix.longitude)
+		hours := fix.timestamp.Sub(state.last.timestamp).Hours()
+		if speed := meters / 1000 / hours; meters > f.config.TeleportMinMeters && speed > f.config.MaxSpeedKmh {
+			state.teleports++
+			if state.teleports <= f.config.TeleportResetAfter {
+				state.remember(fix)
+				return FilterDecision{
+					Location: location,
+					Reason:   entity.QuarantineTeleport,
+					Detail:   fmt.Sprintf("%.0f m in %s implies %.0f km/h", meters, fix.timestamp.Sub(state.last.timestamp), speed),
+				}
+			}
+			// The device keeps disagreeing with the anchor; trust it again.
+			state.kalman = kalmanState{}
+		}
+	}
+
+	state.accept(fix)
+	if f.config.Kalman.Enabled {
+		location.Latitude, location.Longitude = state.kalman.update(fix, f.config.Kalman)
+	}
+	return FilterDecision{Location: location}
+}
+
+func (f *IngestFilter) vehicle(vehicleID string) *vehicleFilter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.vehicles[vehicleID]
+	if !ok {
+		state = &vehicleFilter{recent: make([]rawFix, 0, recentFixes)}
+		f.vehicles[vehicleID] = state
+	}
+	return state
+}
+
+// clone copies the state without its lock.
+func (v *vehicleFilter) clone() *vehicleFilter {
+	c := &vehicleFilter{
+		seeded:    v.seeded,
+		recent:    append(make([]rawFix, 0, recentFixes), v.recent...),
+		next:      v.next,
+		teleports: v.teleports,
+		kalman:    v.kalman,
+	}
+	if v.last != nil {
+		last := *v.last
+		c.last = &last
+	}
+	return c
+}
+
+// restore takes over the state of a working copy; v must be locked.
+func (v *vehicleFilter) restore(work *vehicleFilter) {
+	v.seeded = work.seeded
+	v.last = work.last
+	v.recent = work.recent
+	v.next = work.next
+	v.teleports = work.teleports
+	v.kalman = work.kalman
+}
+
+func (v *vehicleFilter) accept(fix rawFix) {
+	v.last = &fix
+	v.teleports = 0
+	v.remember(fix)
+}
+
+func (v *vehicleFilter) remember(fix rawFix) {
+	if len(v.recent) < recentFixes {
+		v.recent = append(v.recent, fix)
+		return
+	}
+	v.recent[v.next] = fix
+	v.next = (v.next + 1) % recentFixes
+}
+
+func (v *vehicleFilter) seen(fix rawFix) bool {
+	for _, r := range v.recent {
+		if r.timestamp.Equal(fix.timestamp) && r.latitude == fix.latitude && r.longitude == fix.longitude {
+			return true
+		}
+	}
+	return false
+}
+
+// kalmanState is a constant-position Kalman filter over latitude and
+// longitude with a shared variance in square metres.
+type kalmanState struct {
+	initialized bool
+	latitude    float64
+	longitude   float64
+	variance    float64
+	timestamp   time.Time
+}
+
+func (k *kalmanState) update(fix rawFix, cfg entity.KalmanConfig) (float64, float64) {
+	if cfg.MeasurementNoise <= 0 {
+		return fix.latitude, fix.longitude
+	}
+	measurement := cfg.MeasurementNoise * cfg.MeasurementNoise
+	gap := fix.timestamp.Sub(k.timestamp)
+	if !k.initialized || (cfg.ResetGap > 0 && gap > cfg.ResetGap) {
+		*k = kalmanState{
+			initialized: true,
+			latitude:    fix.latitude,
+			longitude:   fix.longitude,
+			variance:    measurement,
+			timestamp:   fix.timestamp,
+		}
+		return fix.latitude, fix.longitude
+	}
+
+	k.variance += gap.Seconds() * cfg.ProcessNoise * cfg.ProcessNoise
+	gain := k.variance / (k.variance + measurement)
+	k.latitude += gain * (fix.latitude - k.latitude)
+	k.longitude += gain * (fix.longitude - k.longitude)
+	k.variance *= 1 - gain
+	k.timestamp = fix.timestamp
+	return k.latitude, k.longitude
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"FMTS/pkg/geo"
+	"FMTS/utils"
+)
+
+var (
+	testOrigin = geo.Point{Latitude: 9.0108, Longitude: 38.7613}
+	testStart  = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+)
+
+// fixAt returns a fix of vehicle v1 the given metres north and east of the
+// test origin, seconds after the test start.
+func fixAt(seconds int, north, east float64) entity.VehicleLocation {
+	p := geo.Offset(testOrigin, north, east)
+	return entity.VehicleLocation{
+		OwnerID:   "owner1",
+		VehicleID: "v1",
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Timestamp: testStart.Add(time.Duration(seconds) * time.Second),
+	}
+}
+
+// applyOne screens a single location in its own committed transaction.
+func applyOne(f *IngestFilter, location entity.VehicleLocation) FilterDecision {
+	tx := f.Begin(location.VehicleID)
+	defer tx.Commit()
+	return tx.Apply(context.Background(), location)
+}
+
+func TestFilterRejectsDuplicates(t *testing.T) {
+	f := NewIngestFilter(entity.IngestFilterConfig{}, nil)
+	fix := fixAt(0, 0, 0)
+
+	if d := applyOne(f, fix); d.Reason != "" {
+		t.Fatalf("first fix rejected: %s", d.Reason)
+	}
+	if d := applyOne(f, fix); d.Reason != entity.QuarantineDuplicate {
+		t.Fatalf("repeat: reason = %q, want %q", d.Reason, entity.QuarantineDuplicate)
+	}
+
+	// A duplicate within one transaction is caught as well.
+	tx := f.Begin("v1")
+	defer tx.Rollback()
+	if d := tx.Apply(context.Background(), fixAt(10, 5, 5)); d.Reason != "" {
+		t.Fatalf("new fix rejected: %s", d.Reason)
+	}
+	if d := tx.Apply(context.Background(), fixAt(10, 5, 5)); d.Reason != entity.QuarantineDuplicate {
+		t.Fatalf("repeat in transaction: reason = %q, want %q", d.Reason, entity.QuarantineDuplicate)
+	}
+}
+
+func TestFilterFlagsOutOfOrder(t *testing.T) {
+	f := NewIngestFilter(entity.IngestFilterConfig{}, nil)
+	applyOne(f, fixAt(60, 0, 0))
+
+	d := applyOne(f, fixAt(30, 10, 0))
+	if d.Reason != "" || !d.Location.OutOfOrder {
+		t.Fatalf("late fix: reason = %q, out_of_order = %v; want accepted and flagged", d.Reason, d.Location.OutOfOrder)
+	}
+	// The late fix is not the new anchor: a fix after the first is in order.
+	if d := applyOne(f, fixAt(90, 20, 0)); d.Location.OutOfOrder {
+		t.Fatal("fix after the anchor flagged out of order")
+	}
+	if d := applyOne(f, fixAt(30, 10, 0)); d.Reason != entity.QuarantineDuplicate {
+		t.Fatalf("repeated late fix: reason = %q, want duplicate", d.Reason)
+	}
+}
+
+func TestFilterQuarantinesTeleports(t *testing.T) {
+	f := NewIngestFilter(entity.IngestFilterConfig{
+		MaxSpeedKmh:        200,
+		TeleportMinMeters:  100,
+		TeleportResetAfter: 2,
+	}, nil)
+	applyOne(f, fixAt(0, 0, 0))
+
+	// 50 km in a minute.
+	for i := 1; i <= 2; i++ {
+		d := applyOne(f, fixAt(i*60, 50000, 0))
+		if d.Reason != entity.QuarantineTeleport {
+			t.Fatalf("jump %d: reason = %q, want %q", i, d.Reason, entity.QuarantineTeleport)
+		}
+	}
+	// The device keeps insisting; after TeleportResetAfter rejections it is
+	// trusted again.
+	if d := applyOne(f, fixAt(180, 50100, 0)); d.Reason != "" {
+		t.Fatalf("jump after reset: reason = %q, want accepted", d.Reason)
+	}
+	// Short jitter is never a teleport, however fast it looks.
+	if d := applyOne(f, fixAt(181, 50150, 0)); d.Reason != "" {
+		t.Fatalf("jitter: reason = %q, want accepted", d.Reason)
+	}
+}
+
+func TestFilterKalmanSmoothsInOrderFixes(t *testing.T) {
+	f := NewIngestFilter(entity.IngestFilterConfig{
+		Kalman: entity.KalmanConfig{
+			Enabled:          true,
+			ProcessNoise:     1,
+			MeasurementNoise: 10,
+			ResetGap:         time.Minute,
+		},
+	}, nil)
+
+	// A parked vehicle whose fixes jump 20 m either side of its position.
+	var rawErr, smoothErr float64
+	for i := 0; i < 20; i++ {
+		east := 20.0
+		if i%2 == 1 {
+			east = -20
+		}
+		fix := fixAt(i*5, 0, east)
+		d := applyOne(f, fix)
+		if i == 0 {
+			if d.Location.Latitude != fix.Latitude || d.Location.Longitude != fix.Longitude {
+				t.Fatal("first fix was moved")
+			}
+			continue
+		}
+		rawErr += math.Abs(east)
+		smoothErr += geo.HaversineMeters(testOrigin.Latitude, testOrigin.Longitude, d.Location.Latitude, d.Location.Longitude)
+	}
+	if smoothErr >= rawErr/2 {
+		t.Fatalf("smoothed error %.0f m, raw %.0f m; want less than half", smoothErr, rawErr)
+	}
+
+	// After a reporting gap the filter restarts from the raw fix.
+	fix := fixAt(600, 0, 20)
+	if d := applyOne(f, fix); d.Location.Longitude != fix.Longitude {
+		t.Fatal("fix after a gap was smoothed")
+	}
+}
+
+func TestFilterRollbackForgetsFixes(t *testing.T) {
+	f := NewIngestFilter(entity.IngestFilterConfig{MaxSpeedKmh: 200, TeleportMinMeters: 100}, nil)
+	applyOne(f, fixAt(0, 0, 0))
+
+	tx := f.Begin("v1")
+	tx.Apply(context.Background(), fixAt(60, 500, 0))
+	tx.Rollback()
+
+	// Neither a duplicate nor measured against the rolled-back fix.
+	if d := applyOne(f, fixAt(60, 500, 0)); d.Reason != "" || d.Location.OutOfOrder {
+		t.Fatalf("retried fix: reason = %q, out_of_order = %v; want accepted in order", d.Reason, d.Location.OutOfOrder)
+	}
+}
+
+// fakeTrackerRepo stores locations in memory and fails the next writes when
+// told to. Methods a test does not use panic through the nil embedded
+// interface.
+type fakeTrackerRepo struct {
+	repo.DomainTracker
+
+	failWrites  int
+	stored      []entity.VehicleLocation
+	quarantined []entity.QuarantinedLocation
+}
+
+var errStoreDown = errors.New("store unavailable")
+
+func (r *fakeTrackerRepo) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
+	for i := len(r.stored) - 1; i >= 0; i-- {
+		if r.stored[i].VehicleID == vehicleID {
+			return r.stored[i], nil
+		}
+	}
+	return entity.VehicleLocation{}, errors.New("no rows")
+}
+
+func (r *fakeTrackerRepo) UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
+	if r.failWrites > 0 {
+		r.failWrites--
+		return entity.VehicleLocation{}, errStoreDown
+	}
+	r.stored = append(r.stored, location)
+	return location, nil
+}
+
+func (r *fakeTrackerRepo) InsertLocations(ctx context.Context, locations []entity.VehicleLocation, quarantined []entity.QuarantinedLocation) (int64, error) {
+	if r.failWrites > 0 {
+		r.failWrites--
+		return 0, errStoreDown
+	}
+	r.stored = append(r.stored, locations...)
+	r.quarantined = append(r.quarantined, quarantined...)
+	return int64(len(locations)), nil
+}
+
+func (r *fakeTrackerRepo) QuarantineLocations(ctx context.Context, locations []entity.QuarantinedLocation) (int64, error) {
+	r.quarantined = append(r.quarantined, locations...)
+	return int64(len(locations)), nil
+}
+
+func newTestTracker(trackerRepo repo.DomainTracker, config entity.TrackerConfig) *DomainTrackerService {
+	return InitDomaintrakerservice(utils.NewLogger(), trackerRepo, config)
+}
+
+func TestUpdateLocationRetryAfterStoreError(t *testing.T) {
+	store := &fakeTrackerRepo{failWrites: 1}
+	s := newTestTracker(store, entity.TrackerConfig{})
+	fix := fixAt(0, 0, 0)
+
+	if _, err := s.UpdateLocation(context.Background(), fix); !errors.Is(err, errStoreDown) {
+		t.Fatalf("first attempt: err = %v, want %v", err, errStoreDown)
+	}
+	if _, err := s.UpdateLocation(context.Background(), fix); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(store.stored) != 1 || len(store.quarantined) != 0 {
+		t.Fatalf("stored %d, quarantined %d; want 1 stored, none quarantined", len(store.stored), len(store.quarantined))
+	}
+
+	// Once stored, a resend is a duplicate.
+	if _, err := s.UpdateLocation(context.Background(), fix); !errors.Is(err, entity.ErrQuarantined) {
+		t.Fatalf("resend: err = %v, want quarantined", err)
+	}
+}
+
+func TestUpdateLocationsRetryAfterStoreError(t *testing.T) {
+	store := &fakeTrackerRepo{failWrites: 1}
+	s := newTestTracker(store, entity.TrackerConfig{})
+	batch := []entity.VehicleLocation{fixAt(0, 0, 0), fixAt(10, 50, 0), fixAt(20, 100, 0)}
+
+	if _, err := s.UpdateLocations(context.Background(), batch); !errors.Is(err, errStoreDown) {
+		t.Fatalf("first attempt: err = %v, want %v", err, errStoreDown)
+	}
+	result, err := s.UpdateLocations(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if result.Inserted != 3 || len(result.Quarantined) != 0 {
+		t.Fatalf("retry inserted %d, quarantined %v; want all 3 inserted", result.Inserted, result.Quarantined)
+	}
+}
+
+func TestUpdateLocationsStoresNothingWhenWriteFails(t *testing.T) {
+	store := &fakeTrackerRepo{failWrites: 1}
+	s := newTestTracker(store, entity.TrackerConfig{})
+	// The repeated fix is quarantined as a duplicate.
+	batch := []entity.VehicleLocation{fixAt(0, 0, 0), fixAt(0, 0, 0), fixAt(10, 50, 0)}
+
+	if _, err := s.UpdateLocations(context.Background(), batch); !errors.Is(err, errStoreDown) {
+		t.Fatalf("first attempt: err = %v, want %v", err, errStoreDown)
+	}
+	if len(store.stored) != 0 || len(store.quarantined) != 0 {
+		t.Fatalf("failed write left %d stored and %d quarantined", len(store.stored), len(store.quarantined))
+	}
+
+	result, err := s.UpdateLocations(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if result.Inserted != 2 || len(store.quarantined) != 1 {
+		t.Fatalf("retry inserted %d, quarantined %d; want 2 and 1", result.Inserted, len(store.quarantined))
+	}
+}
+
+func TestUpdateLocationsReportsInvalidItems(t *testing.T) {
+	store := &fakeTrackerRepo{}
+	s := newTestTracker(store, entity.TrackerConfig{})
+	bad := fixAt(10, 50, 0)
+	bad.Latitude = 95
+	batch := []entity.VehicleLocation{fixAt(0, 0, 0), bad, fixAt(20, 100, 0)}
+
+	result, err := s.UpdateLocations(context.Background(), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Inserted != 2 || len(result.Invalid) != 1 || result.Invalid[1] == "" {
+		t.Fatalf("inserted %d, invalid %v; want 2 inserted and item 1 invalid", result.Inserted, result.Invalid)
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultFuelSmoothingSamples   = 5
+	DefaultFuelRefuelMinLiters    = 10
+	DefaultFuelDrainMinLiters     = 8
+	DefaultFuelEventWindow        = 15 * time.Minute
+	DefaultFuelSettleTime         = 5 * time.Minute
+	DefaultFuelStationarySpeedKmh = 5
+
+	DefaultFuelEvents = 100
+	MaxFuelEvents     = 1000
+
+	// MinFuelDistanceKm is the distance below which no consumption per
+	// 100 km is given; a few hundred meters would blow it up.
+	MinFuelDistanceKm = 1
+)
+
+var ErrInvalidFuelEventType = errors.New("invalid fuel event type: use refuel or drain")
+
+// fuelRetries bounds how often a conflicting fuel state write is retried.
+const fuelRetries = 3
+
+// FuelListener returns the listener that follows the fuel level of every
+// persisted location to detect refuels and drains.
+func (s *DomainTrackerService) FuelListener() repo.LocationListener {
+	return repo.LocationListenerFunc(func(ctx context.Context, location entity.VehicleLocation) {
+		if location.FuelLevel == nil {
+			return
+		}
+		if err := s.advanceFuel(ctx, location); err != nil {
+			s.logger.Errorf("[Fuel] vehicle %s: %v", location.VehicleID, err)
+		}
+	})
+}
+
+func (s *DomainTrackerService) advanceFuel(ctx context.Context, location entity.VehicleLocation) error {
+	cfg := s.fuelConfig()
+	for attempt := 0; attempt < fuelRetries; attempt++ {
+		current, found, err := s.trackerRepo.GetFuelState(ctx, location.VehicleID)
+		if err != nil {
+			return err
+		}
+		next, event, ok := NextFuel(current, found, location, cfg)
+		if !ok {
+			return nil
+		}
+
+		day := entity.FuelDay{
+			VehicleID:  next.VehicleID,
+			OwnerID:    next.OwnerID,
+			Day:        startOfDay(next.LastSeen),
+			StartLevel: next.Level,
+			EndLevel:   next.Level,
+		}
+		var previous *time.Time
+		if found {
+			previous = &current.LastSeen
+			day.StartLevel = current.Level
+		}
+		applied, err := s.trackerRepo.AdvanceFuel(ctx, next, previous, day, event)
+		if err != nil {
+			return err
+		}
+		if applied {
+			if event != nil && !event.Ongoing {
+				s.logger.Infof("[Fuel] vehicle %s: %s of %.1f l", event.VehicleID, event.Type, event.VolumeLiters)
+			}
+			return nil
+		}
+		// Another writer advanced the state concurrently; re-read and retry.
+	}
+	return fmt.Errorf("fuel update still conflicting after %d attempts", fuelRetries)
+}
+
+func (s *DomainTrackerService) fuelConfig() entity.FuelDetectionConfig {
+	cfg := s.config.Fuel
+	if cfg.SmoothingSamples <= 0 {
+		cfg.SmoothingSamples = DefaultFuelSmoothingSamples
+	}
+	if cfg.RefuelMinLiters <= 0 {
+		cfg.RefuelMinLiters = DefaultFuelRefuelMinLiters
+	}
+	if cfg.DrainMinLiters <= 0 {
+		cfg.DrainMinLiters = DefaultFuelDrainMinLiters
+	}
+	if cfg.EventWindow <= 0 {
+		cfg.EventWindow = DefaultFuelEventWindow
+	}
+	if cfg.SettleTime <= 0 {
+		cfg.SettleTime = DefaultFuelSettleTime
+	}
+	if cfg.StationarySpeedKmh <= 0 {
+		cfg.StationarySpeedKmh = DefaultFuelStationarySpeedKmh
+	}
+	return cfg
+}
+
+// NextFuel feeds the fuel reading of a fix into a vehicle's fuel state. It
+// returns the next state and the event to save: an ongoing one while a
+// refuel or drain is still moving the level, a finished one once the level
+// settled. ok is false when the fix has no fuel reading or is not newer
+// than the state.
+func NextFuel(current entity.FuelState, found bool, location entity.VehicleLocation, cfg entity.FuelDetectionConfig) (next entity.FuelState, event *entity.FuelEvent, ok bool) {
+	if location.FuelLevel == nil {
+		return current, nil, false
+	}
+	if found && !location.Timestamp.After(current.LastSeen) {
+		return current, nil, false
+	}
+	at := location.Timestamp
+	if !found {
+		next = entity.FuelState{
+			VehicleID: location.VehicleID,
+			OwnerID:   location.OwnerID,
+			Recent:    []float64{*location.FuelLevel},
+			Level:     *location.FuelLevel,
+			LastSeen:  at,
+		}
+		next.LowLevel, next.LowAt = next.Level, at
+		next.HighLevel, next.HighAt = next.Level, at
+		return next, nil, true
+	}
+
+	next = current
+	next.OwnerID = location.OwnerID
+	next.Recent = append(append([]float64(nil), current.Recent...), *location.FuelLevel)
+	if len(next.Recent) > cfg.SmoothingSamples {
+		next.Recent = next.Recent[len(next.Recent)-cfg.SmoothingSamples:]
+	}
+	next.Level = median(next.Recent)
+	next.LastSeen = at
+
+	if current.Open != nil {
+		open := *current.Open
+		switch {
+		case stillMoving(open, next.Level, at, cfg):
+			open.LevelAfter = next.Level
+			open.EndTime = at
+			open.VolumeLiters = math.Abs(open.LevelAfter - open.LevelBefore)
+			if open.Type == entity.FuelEventDrain && !parked(location, cfg) {
+				open.Stationary = false
+			}
+		case at.Sub(open.EndTime) >= cfg.SettleTime:
+			open.Ongoing = false
+			next.Open = nil
+			next.LowLevel, next.LowAt = next.Level, at
+			next.HighLevel, next.HighAt = next.Level, at
+			return next, &open, true
+		default:
+			// The level holds; the event is saved once it settles.
+			next.Open = &open
+			return next, nil, true
+		}
+		next.Open = &open
+		saved := open
+		return next, &saved, true
+	}
+
+	// Rises and drops are measured within the event window. An extreme that
+	// aged out is replaced by the previous level, so a change across a gap
+	// in reporting is still measured.
+	if at.Sub(next.LowAt) > cfg.EventWindow {
+		next.LowLevel, next.LowAt = current.Level, current.LastSeen
+	}
+	if at.Sub(next.HighAt) > cfg.EventWindow {
+		next.HighLevel, next.HighAt = current.Level, current.LastSeen
+	}
+
+	switch {
+	case next.Level-next.LowLevel >= cfg.RefuelMinLiters:
+		event = fuelEventOf(entity.FuelEventRefuel, next.LowLevel, next.LowAt, location, next.Level)
+	case next.HighLevel-next.Level >= cfg.DrainMinLiters:
+		event = fuelEventOf(entity.FuelEventDrain, next.HighLevel, next.HighAt, location, next.Level)
+		event.Stationary = parked(location, cfg)
+	default:
+		if next.Level < next.LowLevel {
+			next.LowLevel, next.LowAt = next.Level, at
+		}
+		if next.Level > next.HighLevel {
+			next.HighLevel, next.HighAt = next.Level, at
+		}
+		return next, nil, true
+	}
+	open := *event
+	next.Open = &open
+	return next, event, true
+}
+
+// stillMoving reports whether the level keeps changing in the direction of
+// an open event at least as fast as it had to for the event to be detected.
+// Slower changes, such as burning fuel while driving, do not extend a drain.
+func stillMoving(open entity.FuelEvent, level float64, at time.Time, cfg entity.FuelDetectionConfig) bool {
+	change := level - open.LevelAfter
+	minLiters := cfg.RefuelMinLiters
+	if open.Type == entity.FuelEventDrain {
+		change = -change
+		minLiters = cfg.DrainMinLiters
+	}
+	if change <= 0 {
+		return false
+	}
+	elapsed := at.Sub(open.EndTime)
+	return change*cfg.EventWindow.Seconds() >= minLiters*elapsed.Seconds()
+}
+
+func fuelEventOf(eventType string, before float64, start time.Time, location entity.VehicleLocation, level float64) *entity.FuelEvent {
+	return &entity.FuelEvent{
+		OwnerID:      location.OwnerID,
+		VehicleID:    location.VehicleID,
+		Type:         eventType,
+		StartTime:    start,
+		EndTime:      location.Timestamp,
+		LevelBefore:  before,
+		LevelAfter:   level,
+		VolumeLiters: math.Abs(level - before),
+		Latitude:     location.Latitude,
+		Longitude:    location.Longitude,
+		Ongoing:      true,
+	}
+}
+
+// parked reports whether a fix was taken standing still or with the
+// ignition off.
+func parked(location entity.VehicleLocation, cfg entity.FuelDetectionConfig) bool {
+	if location.Ignition != nil && !*location.Ignition {
+		return true
+	}
+	return location.Speed <= cfg.StationarySpeedKmh
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// GetFuelReport returns the daily fuel balance of a vehicle with the
+// distance it covered and the refuels and drains of the range. The range is
+// widened to whole UTC days since levels and distance are kept daily.
+func (s *DomainTrackerService) GetFuelReport(ctx context.Context, query entity.FuelReportQuery) (entity.FuelReport, error) {
+	if !query.From.Before(query.To) {
+		return entity.FuelReport{}, ErrInvalidTimeRange
+	}
+	query.From, query.To = wholeDays(query.From, query.To)
+
+	days, err := s.trackerRepo.GetFuelDays(ctx, query)
+	if err != nil {
+		return entity.FuelReport{}, err
+	}
+	events, err := s.trackerRepo.GetFuelEvents(ctx, entity.FuelEventQuery{
+		OwnerID:   query.OwnerID,
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Limit:     MaxFuelEvents,
+	})
+	if err != nil {
+		return entity.FuelReport{}, err
+	}
+	distances, err := s.trackerRepo.GetDailyDistance(ctx, entity.DistanceQuery{
+		VehicleID: query.VehicleID,
+		OwnerID:   query.OwnerID,
+		From:      query.From,
+		To:        query.To,
+		Bucket:    entity.DistanceBucketDay,
+	})
+	if err != nil {
+		return entity.FuelReport{}, err
+	}
+
+	distanceOn := make(map[time.Time]float64, len(distances))
+	for _, d := range distances {
+		distanceOn[d.Start] = d.DistanceKm
+	}
+	refueledOn := make(map[time.Time]float64)
+	drainedOn := make(map[time.Time]float64)
+	for _, e := range events {
+		day := startOfDay(e.StartTime)
+		if e.Type == entity.FuelEventRefuel {
+			refueledOn[day] += e.VolumeLiters
+		} else {
+			drainedOn[day] += e.VolumeLiters
+		}
+	}
+
+	report := entity.FuelReport{
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Days:      make([]entity.FuelDailyUsage, 0, len(days)),
+		Events:    events,
+	}
+	for _, d := range days {
+		usage := entity.FuelDailyUsage{
+			Day:            d.Day,
+			StartLevel:     d.StartLevel,
+			EndLevel:       d.EndLevel,
+			RefueledLiters: refueledOn[d.Day],
+			DrainedLiters:  drainedOn[d.Day],
+			DistanceKm:     distanceOn[d.Day],
+		}
+		usage.ConsumedLiters = max(0, usage.StartLevel-usage.EndLevel+usage.RefueledLiters-usage.DrainedLiters)
+		usage.LitersPer100Km = litersPer100Km(usage.ConsumedLiters, usage.DistanceKm)
+		report.Days = append(report.Days, usage)
+
+		report.ConsumedLiters += usage.ConsumedLiters
+		report.RefueledLiters += usage.RefueledLiters
+		report.DrainedLiters += usage.DrainedLiters
+		report.DistanceKm += usage.DistanceKm
+	}
+	report.LitersPer100Km = litersPer100Km(report.ConsumedLiters, report.DistanceKm)
+	if report.Events == nil {
+		report.Events = []*entity.FuelEvent{}
+	}
+	return report, nil
+}
+
+func litersPer100Km(liters, km float64) *float64 {
+	if km < MinFuelDistanceKm {
+		return nil
+	}
+	v := liters / km * 100
+	return &v
+}
+
+// GetFuelEvents returns the refuels and drains overlapping the range, in
+// start order.
+func (s *DomainTrackerService) GetFuelEvents(ctx context.Context, query entity.FuelEventQuery) ([]*entity.FuelEvent, error) {
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+	switch query.Type {
+	case "", entity.FuelEventRefuel, entity.FuelEventDrain:
+	default:
+		return nil, ErrInvalidFuelEventType
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultFuelEvents
+	}
+	if query.Limit > MaxFuelEvents {
+		query.Limit = MaxFuelEvents
+	}
+	events, err := s.trackerRepo.GetFuelEvents(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*entity.FuelEvent{}
+	}
+	return events, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+var testFuelConfig = entity.FuelDetectionConfig{
+	SmoothingSamples:   3,
+	RefuelMinLiters:    10,
+	DrainMinLiters:     8,
+	EventWindow:        15 * time.Minute,
+	SettleTime:         5 * time.Minute,
+	StationarySpeedKmh: 5,
+}
+
+// reading is a fuel level reported a number of minutes after the test
+// start, at a speed.
+type reading struct {
+	minute int
+	level  float64
+	speed  float64
+}
+
+// steady reports the same level once a minute from one minute to another.
+func steady(from, to int, level, speed float64) []reading {
+	var readings []reading
+	for m := from; m <= to; m++ {
+		readings = append(readings, reading{m, level, speed})
+	}
+	return readings
+}
+
+// ramp changes the level by step once a minute from one minute to another.
+func ramp(from, to int, level, step, speed float64) []reading {
+	var readings []reading
+	for m := from; m <= to; m++ {
+		readings = append(readings, reading{m, level, speed})
+		level += step
+	}
+	return readings
+}
+
+func readings(parts ...[]reading) []reading {
+	var all []reading
+	for _, p := range parts {
+		all = append(all, p...)
+	}
+	return all
+}
+
+func TestNextFuel(t *testing.T) {
+	type event struct {
+		kind       string
+		volume     float64
+		stationary bool
+	}
+
+	// Readings that slosh a few litres either side, with a single spike.
+	noisy := steady(0, 60, 50, 40)
+	for i := range noisy {
+		noisy[i].level += float64(i%3-1) * 3
+	}
+	noisy[30].level = 80
+
+	cases := []struct {
+		name  string
+		input []reading
+		want  []event
+	}{
+		{
+			name:  "refuel",
+			input: readings(steady(0, 10, 20, 0), ramp(11, 16, 30, 10, 0), steady(17, 30, 80, 0)),
+			want:  []event{{entity.FuelEventRefuel, 60, false}},
+		},
+		{
+			name:  "drain while parked",
+			input: readings(steady(0, 10, 60, 0), ramp(11, 15, 55, -5, 0), steady(16, 30, 35, 0)),
+			want:  []event{{entity.FuelEventDrain, 25, true}},
+		},
+		{
+			name:  "drain while driving",
+			input: readings(steady(0, 10, 60, 50), ramp(11, 15, 55, -5, 50), steady(16, 30, 35, 50)),
+			want:  []event{{entity.FuelEventDrain, 25, false}},
+		},
+		{
+			name:  "noise below threshold",
+			input: noisy,
+		},
+		{
+			name:  "burning fuel while driving",
+			input: ramp(0, 120, 60, -0.2, 60),
+		},
+		{
+			name:  "refuel across a reporting gap",
+			input: readings(steady(0, 5, 20, 0), steady(60, 75, 70, 0)),
+			want:  []event{{entity.FuelEventRefuel, 50, false}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var state entity.FuelState
+			var found bool
+			var got []event
+			for _, r := range tc.input {
+				fix := fixAt(r.minute*60, 0, 0)
+				fix.FuelLevel = &r.level
+				fix.Speed = r.speed
+				next, e, ok := NextFuel(state, found, fix, testFuelConfig)
+				if !ok {
+					t.Fatalf("reading at minute %d not taken", r.minute)
+				}
+				state, found = next, true
+				if e != nil && !e.Ongoing {
+					got = append(got, event{e.Type, e.VolumeLiters, e.Stationary})
+				}
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("events %v, want %v", got, tc.want)
+			}
+			for i, want := range tc.want {
+				if got[i].kind != want.kind || got[i].stationary != want.stationary || math.Abs(got[i].volume-want.volume) > 1 {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextFuelIgnoresStaleReadings(t *testing.T) {
+	level := 40.0
+	fix := fixAt(60, 0, 0)
+	fix.FuelLevel = &level
+	state, _, _ := NextFuel(entity.FuelState{}, false, fix, testFuelConfig)
+
+	if _, _, ok := NextFuel(state, true, fix, testFuelConfig); ok {
+		t.Error("repeated reading taken")
+	}
+	fix.Timestamp = fix.Timestamp.Add(time.Minute)
+	fix.FuelLevel = nil
+	if _, _, ok := NextFuel(state, true, fix, testFuelConfig); ok {
+		t.Error("fix without a fuel level taken")
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	DefaultHeatmapPrecision = 5
+	MaxHeatmapPrecision     = 9
+	MaxHeatmapZoom          = 22
+	DefaultHeatmapCells     = 2000
+	MaxHeatmapCells         = 10000
+	MaxHeatmapRange         = 93 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidPrecision     = errors.New("invalid precision: must be between 1 and 9")
+	ErrInvalidZoom          = errors.New("invalid zoom: must be between 0 and 22")
+	ErrHeatmapRangeTooLarge = errors.New("invalid time range: heatmaps cover at most 93 days")
+)
+
+// HeatmapPrecisionForZoom picks the geohash precision whose cells are a few
+// screen pixels wide at a web map zoom level.
+func HeatmapPrecisionForZoom(zoom int) int {
+	switch {
+	case zoom <= 2:
+		return 1
+	case zoom <= 4:
+		return 2
+	case zoom <= 6:
+		return 3
+	case zoom <= 8:
+		return 4
+	case zoom <= 11:
+		return 5
+	case zoom <= 13:
+		return 6
+	case zoom <= 16:
+		return 7
+	case zoom <= 18:
+		return 8
+	}
+	return 9
+}
+
+// GetHeatmap counts the points of a fleet per geohash cell, busiest first.
+func (s *DomainTrackerService) GetHeatmap(ctx context.Context, query entity.HeatmapQuery) (entity.Heatmap, error) {
+	if !query.From.Before(query.To) {
+		return entity.Heatmap{}, ErrInvalidTimeRange
+	}
+	if query.To.Sub(query.From) > MaxHeatmapRange {
+		return entity.Heatmap{}, ErrHeatmapRangeTooLarge
+	}
+
+	precision := query.Precision
+	switch {
+	case precision < 0 || precision > MaxHeatmapPrecision:
+		return entity.Heatmap{}, ErrInvalidPrecision
+	case precision == 0 && (query.Zoom < 0 || query.Zoom > MaxHeatmapZoom):
+		return entity.Heatmap{}, ErrInvalidZoom
+	case precision == 0 && query.Zoom > 0:
+		precision = HeatmapPrecisionForZoom(query.Zoom)
+	case precision == 0:
+		precision = DefaultHeatmapPrecision
+	}
+
+	if query.MaxCells <= 0 {
+		query.MaxCells = DefaultHeatmapCells
+	}
+	if query.MaxCells > MaxHeatmapCells {
+		query.MaxCells = MaxHeatmapCells
+	}
+
+	latStep, lonStep := geo.GeohashCellSize(precision)
+	cells, total, err := s.trackerRepo.AggregateGrid(ctx, entity.GridQuery{
+		OwnerID: query.OwnerID,
+		From:    query.From,
+		To:      query.To,
+		LatStep: latStep,
+		LonStep: lonStep,
+		Limit:   query.MaxCells,
+	})
+	if err != nil {
+		return entity.Heatmap{}, err
+	}
+
+	heatmap := entity.Heatmap{
+		From:       query.From,
+		To:         query.To,
+		Precision:  precision,
+		TotalCells: total,
+		Truncated:  total > int64(len(cells)),
+		Cells:      make([]entity.HeatmapCell, 0, len(cells)),
+	}
+	for _, c := range cells {
+		center := geo.GeohashCellCenter(c.LatIndex, c.LonIndex, precision)
+		heatmap.Cells = append(heatmap.Cells, entity.HeatmapCell{
+			Geohash:   geo.GeohashFromCell(c.LatIndex, c.LonIndex, precision),
+			Latitude:  center.Latitude,
+			Longitude: center.Longitude,
+			Points:    c.Points,
+			Vehicles:  c.Vehicles,
+			AvgSpeed:  math.Round(c.AvgSpeed*10) / 10,
+		})
+	}
+	return heatmap, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"context"
+	"time"
+)
+
+// SetLatestPositionStore serves latest-position reads from store. Until
+// WarmLatestPositions succeeds only per-vehicle reads use it; owner listings
+// keep reading Postgres because the store may be missing vehicles.
+func (s *DomainTrackerService) SetLatestPositionStore(store repo.LatestPositionStore) {
+	s.latest = store
+	s.latestWarm.Store(false)
+}
+
+// WarmLatestPositions loads the latest location of every vehicle into the
+// latest-position store.
+func (s *DomainTrackerService) WarmLatestPositions(ctx context.Context) (int, error) {
+	if s.latest == nil {
+		return 0, nil
+	}
+	locations, err := s.trackerRepo.GetLatestVehicleLocations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, location := range locations {
+		if err := s.latest.Put(ctx, *location); err != nil {
+			return 0, err
+		}
+	}
+	s.latestWarm.Store(true)
+	return len(locations), nil
+}
+
+// rememberLatest writes a stored location through to the latest-position
+// store. The store keeps the newest fix, so late points are ignored there.
+func (s *DomainTrackerService) rememberLatest(ctx context.Context, location entity.VehicleLocation) {
+	if s.latest == nil {
+		return
+	}
+	if err := s.latest.Put(ctx, location); err != nil {
+		s.logger.Errorf("[LatestPositions] vehicle %s: %v", location.VehicleID, err)
+	}
+}
+
+func (s *DomainTrackerService) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
+	if s.latest != nil {
+		location, ok, err := s.latest.Get(ctx, vehicleID)
+		if err != nil {
+			s.logger.Errorf("[LatestPositions] vehicle %s: %v", vehicleID, err)
+		} else if ok {
+			return location, nil
+		}
+	}
+
+	location, err := s.trackerRepo.GetLatestVehicleLocationByID(ctx, vehicleID)
+	if err != nil {
+		return entity.VehicleLocation{}, err
+	}
+	s.rememberLatest(ctx, location)
+	return location, nil
+}
+
+// latestPositions lists the latest location of every vehicle of ownerID, or
+// of every vehicle when ownerID is empty.
+func (s *DomainTrackerService) latestPositions(ctx context.Context, ownerID string) ([]*entity.VehicleLocation, error) {
+	if ownerID != "" {
+		return s.ownerLatestPositions(ctx, ownerID)
+	}
+	if s.latest != nil && s.latestWarm.Load() {
+		locations, err := s.latest.ListByOwner(ctx, "")
+		if err == nil {
+			return locations, nil
+		}
+		s.logger.Errorf("[LatestPositions] all vehicles: %v", err)
+	}
+	return s.trackerRepo.GetLatestVehicleLocations(ctx)
+}
+
+// GetLatestVehicleLocationsByUserID lists the latest location of every
+// vehicle of a fleet together with its connectivity status.
+func (s *DomainTrackerService) GetLatestVehicleLocationsByUserID(ctx context.Context, userID string) ([]*entity.VehicleLocation, error) {
+	locations, err := s.ownerLatestPositions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, location := range locations {
+		location.Connectivity = s.connectivityOf(*location, now)
+	}
+	return locations, nil
+}
+
+func (s *DomainTrackerService) ownerLatestPositions(ctx context.Context, ownerID string) ([]*entity.VehicleLocation, error) {
+	if s.latest != nil && s.latestWarm.Load() {
+		locations, err := s.latest.ListByOwner(ctx, ownerID)
+		if err == nil {
+			return locations, nil
+		}
+		s.logger.Errorf("[LatestPositions] owner %s: %v", ownerID, err)
+	}
+	return s.trackerRepo.GetLatestVehicleLocationsByUserID(ctx, ownerID)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"FMTS/pkg/geo"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultNearbyRadiusMeters = 5000
+	MaxNearbyRadiusMeters     = 100000
+	DefaultNearbyLimit        = 50
+	MaxNearbyLimit            = 500
+)
+
+var (
+	ErrInvalidCoordinates = errors.New("invalid coordinates: lat must be within [-90, 90] and lng within [-180, 180]")
+	ErrInvalidRadius      = errors.New("invalid radius: must be positive and at most 100000 meters")
+)
+
+// SetVehicleDirectory lets nearby searches restrict results to registered
+// vehicles and filter them by type.
+func (s *DomainTrackerService) SetVehicleDirectory(directory repo.VehicleDirectory) {
+	s.vehicles = directory
+}
+
+// FindNearbyVehicles returns the vehicles whose latest position is within
+// query.RadiusMeters of the query point, nearest first. Only vehicles
+// registered to the owner (all vehicles for admins) are considered.
+func (s *DomainTrackerService) FindNearbyVehicles(ctx context.Context, query entity.NearbyQuery) (entity.NearbyResult, error) {
+	if query.Latitude < -90 || query.Latitude > 90 || query.Longitude < -180 || query.Longitude > 180 {
+		return entity.NearbyResult{}, ErrInvalidCoordinates
+	}
+	if query.RadiusMeters == 0 {
+		query.RadiusMeters = DefaultNearbyRadiusMeters
+	}
+	if query.RadiusMeters < 0 || query.RadiusMeters > MaxNearbyRadiusMeters {
+		return entity.NearbyResult{}, ErrInvalidRadius
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultNearbyLimit
+	}
+	if query.Limit > MaxNearbyLimit {
+		query.Limit = MaxNearbyLimit
+	}
+	query.VehicleType = strings.ToLower(query.VehicleType)
+
+	var registered map[string]entity.VehicleInfo
+	if s.vehicles != nil {
+		vehicles, err := s.vehicles.ListVehicles(ctx, query.OwnerID, query.VehicleType)
+		if err != nil {
+			return entity.NearbyResult{}, err
+		}
+		registered = make(map[string]entity.VehicleInfo, len(vehicles))
+		for _, v := range vehicles {
+			registered[v.ID] = v
+		}
+	} else if query.VehicleType != "" {
+		return entity.NearbyResult{}, errors.New("vehicle type filter is not available")
+	}
+
+	positions, err := s.latestPositions(ctx, query.OwnerID)
+	if err != nil {
+		return entity.NearbyResult{}, err
+	}
+
+	now := time.Now()
+	result := entity.NearbyResult{
+		Latitude:     query.Latitude,
+		Longitude:    query.Longitude,
+		RadiusMeters: query.RadiusMeters,
+		VehicleType:  query.VehicleType,
+		Vehicles:     []entity.NearbyVehicle{},
+	}
+	for _, p := range positions {
+		var info entity.VehicleInfo
+		if registered != nil {
+			var ok bool
+			if info, ok = registered[p.VehicleID]; !ok {
+				continue
+			}
+		}
+		distance := geo.HaversineMeters(query.Latitude, query.Longitude, p.Latitude, p.Longitude)
+		if distance > query.RadiusMeters {
+			continue
+		}
+		result.Vehicles = append(result.Vehicles, entity.NearbyVehicle{
+			VehicleID:      p.VehicleID,
+			PlateNumber:    info.PlateNumber,
+			VehicleType:    info.VehicleType,
+			DistanceMeters: distance,
+			Latitude:       p.Latitude,
+			Longitude:      p.Longitude,
+			Speed:          p.Speed,
+			Timestamp:      p.Timestamp,
+			AgeSeconds:     int64(now.Sub(p.Timestamp).Seconds()),
+		})
+	}
+
+	sort.Slice(result.Vehicles, func(i, j int) bool {
+		return result.Vehicles[i].DistanceMeters < result.Vehicles[j].DistanceMeters
+	})
+	if len(result.Vehicles) > query.Limit {
+		result.Vehicles = result.Vehicles[:query.Limit]
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"FMTS/pkg/geo"
+
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// odometerRetries bounds how often a conflicting odometer write is retried.
+const odometerRetries = 3
+
+var ErrInvalidBucket = errors.New("invalid bucket: must be day, week or month")
+
+// OdometerListener returns the listener that advances the odometer and the
+// daily distance of a vehicle for every persisted location.
+func (s *DomainTrackerService) OdometerListener() repo.LocationListener {
+	return repo.LocationListenerFunc(func(ctx context.Context, location entity.VehicleLocation) {
+		if err := s.advanceOdometer(ctx, location); err != nil {
+			s.logger.Errorf("[Odometer] vehicle %s: %v", location.VehicleID, err)
+		}
+	})
+}
+
+func (s *DomainTrackerService) advanceOdometer(ctx context.Context, location entity.VehicleLocation) error {
+	for attempt := 0; attempt < odometerRetries; attempt++ {
+		current, found, err := s.trackerRepo.GetOdometer(ctx, location.VehicleID)
+		if err != nil {
+			return err
+		}
+		next, km, ok := NextOdometer(current, found, location, s.config.Odometer)
+		if !ok {
+			return nil
+		}
+
+		var previous *time.Time
+		if found {
+			previous = &current.LastTimestamp
+		}
+		applied, err := s.trackerRepo.AdvanceOdometer(ctx, next, previous, entity.DailyDistance{
+			VehicleID:  location.VehicleID,
+			OwnerID:    location.OwnerID,
+			Day:        startOfDay(location.Timestamp),
+			DistanceKm: km,
+		})
+		if err != nil {
+			return err
+		}
+		if applied {
+			return nil
+		}
+		// Another writer advanced the odometer concurrently; re-read and retry.
+	}
+	return fmt.Errorf("odometer update still conflicting after %d attempts", odometerRetries)
+}
+
+// NextOdometer measures a fix against the last counted one and returns the
+// new odometer state and the kilometres added. ok is false when the fix must
+// not change the odometer: it is older than the last counted fix, within the
+// jitter radius, or implies an impossible speed.
+func NextOdometer(current entity.Odometer, found bool, location entity.VehicleLocation, cfg entity.OdometerConfig) (next entity.Odometer, km float64, ok bool) {
+	next = current
+	next.VehicleID = location.VehicleID
+	next.OwnerID = location.OwnerID
+	next.UpdatedAt = time.Now()
+
+	if found {
+		if !location.Timestamp.After(current.LastTimestamp) {
+			return current, 0, false
+		}
+
+		meters := geo.HaversineMeters(current.LastLatitude, current.LastLongitude, location.Latitude, location.Longitude)
+		if meters < cfg.MinMoveMeters {
+			// Keep measuring from the old anchor so slow movement still adds up.
+			return current, 0, false
+		}
+		hours := location.Timestamp.Sub(current.LastTimestamp).Hours()
+		if cfg.MaxSpeedKmh > 0 && meters/1000/hours > cfg.MaxSpeedKmh {
+			return current, 0, false
+		}
+		km = meters / 1000
+		next.TotalKm += km
+	}
+
+	next.LastLatitude = location.Latitude
+	next.LastLongitude = location.Longitude
+	next.LastTimestamp = location.Timestamp
+	return next, km, true
+}
+
+// GetVehicleOdometer returns the running odometer of a vehicle. Vehicles
+// that never reported a fix have an odometer of zero.
+func (s *DomainTrackerService) GetVehicleOdometer(ctx context.Context, vehicleID string) (entity.Odometer, error) {
+	odometer, found, err := s.trackerRepo.GetOdometer(ctx, vehicleID)
+	if err != nil {
+		return entity.Odometer{}, err
+	}
+	if !found {
+		return entity.Odometer{VehicleID: vehicleID}, nil
+	}
+	return odometer, nil
+}
+
+// GetVehicleDistance sums the daily distance of a vehicle per bucket. The
+// range is widened to whole UTC days since distance is aggregated daily.
+func (s *DomainTrackerService) GetVehicleDistance(ctx context.Context, query entity.DistanceQuery) (entity.DistanceReport, error) {
+	if !query.From.Before(query.To) {
+		return entity.DistanceReport{}, ErrInvalidTimeRange
+	}
+	query.Bucket = strings.ToLower(query.Bucket)
+	switch query.Bucket {
+	case "":
+		query.Bucket = entity.DistanceBucketDay
+	case entity.DistanceBucketDay, entity.DistanceBucketWeek, entity.DistanceBucketMonth:
+	default:
+		return entity.DistanceReport{}, ErrInvalidBucket
+	}
+
+	query.From, query.To = wholeDays(query.From, query.To)
+
+	buckets, err := s.trackerRepo.GetDailyDistance(ctx, query)
+	if err != nil {
+		return entity.DistanceReport{}, err
+	}
+
+	report := entity.DistanceReport{
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Bucket:    query.Bucket,
+		Buckets:   buckets,
+	}
+	for _, b := range buckets {
+		report.TotalKm += b.DistanceKm
+	}
+	if report.Buckets == nil {
+		report.Buckets = []entity.DistanceBucket{}
+	}
+	return report, nil
+}
+
+// wholeDays widens [from, to) to whole UTC days.
+func wholeDays(from, to time.Time) (time.Time, time.Time) {
+	end := startOfDay(to)
+	if !end.Equal(to.UTC()) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return startOfDay(from), end
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
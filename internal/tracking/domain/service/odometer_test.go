@@ -0,0 +1,103 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+var testOdometerConfig = entity.OdometerConfig{
+	MinMoveMeters: 15,
+	MaxSpeedKmh:   250,
+}
+
+func TestNextOdometer(t *testing.T) {
+	type fix struct {
+		seconds int
+		north   float64
+	}
+
+	cases := []struct {
+		name       string
+		fixes      []fix
+		wantTaken  []bool
+		wantKm     float64
+		wantAnchor int // seconds of the last counted fix
+	}{
+		{
+			name:       "first fix anchors",
+			fixes:      []fix{{0, 0}},
+			wantTaken:  []bool{true},
+			wantKm:     0,
+			wantAnchor: 0,
+		},
+		{
+			name:       "drive",
+			fixes:      []fix{{0, 0}, {60, 1000}, {120, 2000}},
+			wantTaken:  []bool{true, true, true},
+			wantKm:     2,
+			wantAnchor: 120,
+		},
+		{
+			name:       "jitter within radius",
+			fixes:      []fix{{0, 0}, {30, 5}, {60, -6}, {90, 10}, {120, -4}},
+			wantTaken:  []bool{true, false, false, false, false},
+			wantKm:     0,
+			wantAnchor: 0,
+		},
+		{
+			name:       "slow creep adds up from the anchor",
+			fixes:      []fix{{0, 0}, {30, 8}, {60, 16}, {90, 24}, {120, 32}},
+			wantTaken:  []bool{true, false, true, false, true},
+			wantKm:     0.032,
+			wantAnchor: 120,
+		},
+		{
+			name:       "impossible speed",
+			fixes:      []fix{{0, 0}, {10, 5000}, {60, 1000}},
+			wantTaken:  []bool{true, false, true},
+			wantKm:     1,
+			wantAnchor: 60,
+		},
+		{
+			name:       "late fix",
+			fixes:      []fix{{0, 0}, {60, 1000}, {30, 500}, {60, 2000}},
+			wantTaken:  []bool{true, true, false, false},
+			wantKm:     1,
+			wantAnchor: 60,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var current entity.Odometer
+			var found bool
+			var added float64
+			for i, f := range tc.fixes {
+				next, km, ok := NextOdometer(current, found, fixAt(f.seconds, f.north, 0), testOdometerConfig)
+				if ok != tc.wantTaken[i] {
+					t.Fatalf("fix %d taken = %v, want %v", i, ok, tc.wantTaken[i])
+				}
+				if !ok {
+					if next != current {
+						t.Fatalf("fix %d not taken but changed the odometer", i)
+					}
+					continue
+				}
+				current, found = next, true
+				added += km
+			}
+
+			if math.Abs(current.TotalKm-tc.wantKm) > 0.001 {
+				t.Errorf("total = %.4f km, want %.4f km", current.TotalKm, tc.wantKm)
+			}
+			if math.Abs(added-current.TotalKm) > 1e-9 {
+				t.Errorf("kilometres added = %.4f, total %.4f", added, current.TotalKm)
+			}
+			if want := at(tc.wantAnchor); !current.LastTimestamp.Equal(want) {
+				t.Errorf("anchor at %v, want %v", current.LastTimestamp, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"time"
+)
+
+const (
+	DefaultQuarantinePoints = 100
+	MaxQuarantinePoints     = 1000
+)
+
+// quarantine stores the locations the ingest filter rejected.
+func (s *DomainTrackerService) quarantine(ctx context.Context, decisions []FilterDecision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+	_, err := s.trackerRepo.QuarantineLocations(ctx, s.quarantined(decisions))
+	return err
+}
+
+// quarantined logs the locations the ingest filter rejected and returns
+// them as quarantine rows.
+func (s *DomainTrackerService) quarantined(decisions []FilterDecision) []entity.QuarantinedLocation {
+	now := time.Now().UTC()
+	rejected := make([]entity.QuarantinedLocation, len(decisions))
+	for i, d := range decisions {
+		rejected[i] = entity.QuarantinedLocation{
+			OwnerID:    d.Location.OwnerID,
+			VehicleID:  d.Location.VehicleID,
+			Latitude:   d.Location.Latitude,
+			Longitude:  d.Location.Longitude,
+			Speed:      d.Location.Speed,
+			Timestamp:  d.Location.Timestamp,
+			Reason:     d.Reason,
+			Detail:     d.Detail,
+			ReceivedAt: now,
+
+			Heading:         d.Location.Heading,
+			Altitude:        d.Location.Altitude,
+			HDOP:            d.Location.HDOP,
+			Accuracy:        d.Location.Accuracy,
+			Satellites:      d.Location.Satellites,
+			Ignition:        d.Location.Ignition,
+			ExternalVoltage: d.Location.ExternalVoltage,
+			BatteryVoltage:  d.Location.BatteryVoltage,
+			FuelLevel:       d.Location.FuelLevel,
+			Attributes:      d.Location.Attributes,
+		}
+		s.logger.Warnf("[IngestFilter] vehicle %s: quarantined fix at %s: %s %s", d.Location.VehicleID, d.Location.Timestamp.Format(time.RFC3339), d.Reason, d.Detail)
+	}
+	return rejected
+}
+
+// GetQuarantinedLocations returns the most recent rejected locations of a
+// vehicle in the range together with per-reason counts.
+func (s *DomainTrackerService) GetQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) (entity.QuarantineReport, error) {
+	if !query.From.Before(query.To) {
+		return entity.QuarantineReport{}, ErrInvalidTimeRange
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultQuarantinePoints
+	}
+	if query.Limit > MaxQuarantinePoints {
+		query.Limit = MaxQuarantinePoints
+	}
+
+	counts, err := s.trackerRepo.CountQuarantinedLocations(ctx, query)
+	if err != nil {
+		return entity.QuarantineReport{}, err
+	}
+	points, err := s.trackerRepo.GetQuarantinedLocations(ctx, query)
+	if err != nil {
+		return entity.QuarantineReport{}, err
+	}
+
+	report := entity.QuarantineReport{
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Counts:    counts,
+		Points:    points,
+	}
+	for _, n := range counts {
+		report.Total += n
+	}
+	if report.Counts == nil {
+		report.Counts = map[entity.QuarantineReason]int64{}
+	}
+	if report.Points == nil {
+		report.Points = []*entity.QuarantinedLocation{}
+	}
+	return report, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultRetentionChunk   = 24 * time.Hour
+	DefaultRetentionReports = 20
+	MaxRetentionReports     = 200
+)
+
+var (
+	ErrRetentionRunning  = errors.New("a retention run is already in progress")
+	ErrRetentionDisabled = errors.New("retention has no downsample or delete age configured")
+	ErrInvalidRetention  = errors.New("retention delete age must not be shorter than the downsample age")
+)
+
+// RunRetention summarises raw points older than the downsample age into
+// per-minute rows and deletes raw points older than the delete age. While
+// downsampling is enabled, deletion never passes the summarised range, and
+// points that arrived after their minute was summarised (batch uploads of
+// old fixes) are summarised again before their minute is deleted. The report
+// is stored even when the run fails part way.
+//
+// The per-minute rows are an archive: location reads only return raw points.
+func (s *DomainTrackerService) RunRetention(ctx context.Context, dryRun bool) (entity.RetentionReport, error) {
+	cfg := s.config.Retention
+	if cfg.DownsampleAfter <= 0 && cfg.DeleteAfter <= 0 {
+		return entity.RetentionReport{}, ErrRetentionDisabled
+	}
+	if cfg.DownsampleAfter > 0 && cfg.DeleteAfter > 0 && cfg.DeleteAfter < cfg.DownsampleAfter {
+		return entity.RetentionReport{}, ErrInvalidRetention
+	}
+
+	release, acquired, err := s.trackerRepo.AcquireRetentionLock(ctx)
+	if err != nil {
+		return entity.RetentionReport{}, err
+	}
+	if !acquired {
+		return entity.RetentionReport{}, ErrRetentionRunning
+	}
+	defer release()
+
+	report := entity.RetentionReport{
+		StartedAt: time.Now().UTC(),
+		DryRun:    dryRun,
+	}
+	runErr := s.applyRetention(ctx, &report)
+	report.FinishedAt = time.Now().UTC()
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	// The report is written even if the run was cancelled.
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.trackerRepo.SaveRetentionReport(saveCtx, &report); err != nil {
+		s.logger.Errorf("[RunRetention] failed to save report: %v", err)
+	}
+	return report, runErr
+}
+
+func (s *DomainTrackerService) applyRetention(ctx context.Context, report *entity.RetentionReport) error {
+	cfg := s.config.Retention
+	chunk := cfg.Chunk
+	if chunk <= 0 {
+		chunk = DefaultRetentionChunk
+	}
+	// Windows stay on whole minutes so no minute is split between two
+	// summary statements.
+	chunk = max(chunk.Truncate(time.Minute), time.Minute)
+	now := report.StartedAt
+
+	var summarisedUntil time.Time
+	if cfg.DownsampleAfter > 0 {
+		cutoff := now.Add(-cfg.DownsampleAfter).Truncate(time.Minute)
+		report.DownsampleBefore = &cutoff
+
+		from, ok, err := s.trackerRepo.SummarisedUntil(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if from, ok, err = s.trackerRepo.OldestLocation(ctx); err != nil {
+				return err
+			}
+			from = from.Truncate(time.Minute)
+		}
+		if ok {
+			for from.Before(cutoff) {
+				to := minTime(from.Add(chunk), cutoff)
+				summaries, points, err := s.trackerRepo.DownsampleLocations(ctx, from, to, report.DryRun)
+				if err != nil {
+					return fmt.Errorf("downsample %s to %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+				}
+				report.SummariesWritten += summaries
+				report.PointsSummarised += points
+				from = to
+			}
+		}
+		summarisedUntil = cutoff
+	}
+
+	if cfg.DeleteAfter > 0 {
+		cutoff := now.Add(-cfg.DeleteAfter).Truncate(time.Minute)
+		if cfg.DownsampleAfter > 0 {
+			cutoff = minTime(cutoff, summarisedUntil)
+		}
+		report.DeleteBefore = &cutoff
+
+		from, ok, err := s.trackerRepo.OldestLocation(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		for from.Before(cutoff) {
+			to := minTime(from.Add(chunk), cutoff)
+			deleted, err := s.expireLocations(ctx, report, from, to)
+			if err != nil {
+				return err
+			}
+			report.PointsDeleted += deleted
+			from = to
+		}
+	}
+	return nil
+}
+
+// expireLocations deletes the raw points of [from, to). With downsampling
+// enabled, minutes that gained points since they were summarised are
+// summarised again first, and only summarised minutes are deleted.
+func (s *DomainTrackerService) expireLocations(ctx context.Context, report *entity.RetentionReport, from, to time.Time) (int64, error) {
+	window := from.Format(time.RFC3339) + " to " + to.Format(time.RFC3339)
+	if s.config.Retention.DownsampleAfter <= 0 {
+		deleted, err := s.trackerRepo.DeleteLocations(ctx, from, to, report.DryRun)
+		if err != nil {
+			return 0, fmt.Errorf("delete %s: %w", window, err)
+		}
+		return deleted, nil
+	}
+
+	summaries, points, err := s.trackerRepo.SummariseLateLocations(ctx, from, to, report.DryRun)
+	if err != nil {
+		return 0, fmt.Errorf("summarise late points %s: %w", window, err)
+	}
+	report.SummariesWritten += summaries
+	report.PointsSummarised += points
+
+	deleted, err := s.trackerRepo.DeleteSummarisedLocations(ctx, from, to, report.DryRun)
+	if err != nil {
+		return 0, fmt.Errorf("delete %s: %w", window, err)
+	}
+	return deleted, nil
+}
+
+// ListRetentionReports returns the most recent retention runs first.
+func (s *DomainTrackerService) ListRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error) {
+	if limit <= 0 {
+		limit = DefaultRetentionReports
+	}
+	if limit > MaxRetentionReports {
+		limit = MaxRetentionReports
+	}
+	reports, err := s.trackerRepo.GetRetentionReports(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []*entity.RetentionReport{}
+	}
+	return reports, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
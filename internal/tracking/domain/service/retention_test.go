@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+// minuteKey identifies the summary row of one vehicle and minute.
+type minuteKey struct {
+	vehicleID string
+	bucket    time.Time
+}
+
+// retentionRepo keeps raw points and per-minute summaries in memory.
+type retentionRepo struct {
+	fakeTrackerRepo
+
+	raw     []entity.VehicleLocation
+	minutes map[minuteKey]int64
+}
+
+func newRetentionRepo() *retentionRepo {
+	return &retentionRepo{minutes: make(map[minuteKey]int64)}
+}
+
+func (r *retentionRepo) add(timestamp time.Time) {
+	r.raw = append(r.raw, entity.VehicleLocation{VehicleID: "v1", Timestamp: timestamp})
+}
+
+func (r *retentionRepo) has(timestamp time.Time) bool {
+	for _, p := range r.raw {
+		if p.Timestamp.Equal(timestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *retentionRepo) AcquireRetentionLock(ctx context.Context) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+func (r *retentionRepo) OldestLocation(ctx context.Context) (time.Time, bool, error) {
+	var oldest time.Time
+	for _, p := range r.raw {
+		if oldest.IsZero() || p.Timestamp.Before(oldest) {
+			oldest = p.Timestamp
+		}
+	}
+	return oldest, !oldest.IsZero(), nil
+}
+
+func (r *retentionRepo) SummarisedUntil(ctx context.Context) (time.Time, bool, error) {
+	var last time.Time
+	for k := range r.minutes {
+		if k.bucket.After(last) {
+			last = k.bucket
+		}
+	}
+	if last.IsZero() {
+		return time.Time{}, false, nil
+	}
+	return last.Add(time.Minute), true, nil
+}
+
+// counts returns the raw points per minute of [from, to).
+func (r *retentionRepo) counts(from, to time.Time) map[minuteKey]int64 {
+	counts := make(map[minuteKey]int64)
+	for _, p := range r.raw {
+		if !p.Timestamp.Before(from) && p.Timestamp.Before(to) {
+			counts[minuteKey{p.VehicleID, p.Timestamp.Truncate(time.Minute)}]++
+		}
+	}
+	return counts
+}
+
+func (r *retentionRepo) DownsampleLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, int64, error) {
+	var summaries, points int64
+	for k, n := range r.counts(from, to) {
+		summaries++
+		points += n
+		r.minutes[k] = n
+	}
+	return summaries, points, nil
+}
+
+func (r *retentionRepo) SummariseLateLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, int64, error) {
+	var summaries, points int64
+	for k, n := range r.counts(from, to) {
+		if n > r.minutes[k] {
+			summaries++
+			points += n
+			r.minutes[k] = n
+		}
+	}
+	return summaries, points, nil
+}
+
+func (r *retentionRepo) DeleteLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error) {
+	return r.delete(from, to, false), nil
+}
+
+func (r *retentionRepo) DeleteSummarisedLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error) {
+	return r.delete(from, to, true), nil
+}
+
+func (r *retentionRepo) delete(from, to time.Time, summarisedOnly bool) int64 {
+	var kept []entity.VehicleLocation
+	for _, p := range r.raw {
+		_, summarised := r.minutes[minuteKey{p.VehicleID, p.Timestamp.Truncate(time.Minute)}]
+		if p.Timestamp.Before(from) || !p.Timestamp.Before(to) || (summarisedOnly && !summarised) {
+			kept = append(kept, p)
+		}
+	}
+	deleted := int64(len(r.raw) - len(kept))
+	r.raw = kept
+	return deleted
+}
+
+func (r *retentionRepo) SaveRetentionReport(ctx context.Context, report *entity.RetentionReport) error {
+	return nil
+}
+
+func TestRunRetentionSummarisesLatePointsBeforeDeleting(t *testing.T) {
+	day := 24 * time.Hour
+	store := newRetentionRepo()
+	s := newTestTracker(store, entity.TrackerConfig{Retention: entity.RetentionConfig{
+		DownsampleAfter: 30 * day,
+		DeleteAfter:     90 * day,
+		Chunk:           day,
+	}})
+	now := time.Now().Truncate(time.Minute)
+
+	store.add(now.Add(-50 * day))
+	if _, err := s.RunRetention(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run moved the summary watermark past these points, which
+	// arrive afterwards from a batch upload.
+	expiring := now.Add(-95 * day)
+	kept := now.Add(-60 * day)
+	store.add(expiring)
+	store.add(kept)
+
+	report, err := s.RunRetention(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if store.has(expiring) {
+		t.Error("late point past the delete age was kept")
+	}
+	if _, ok := store.minutes[minuteKey{"v1", expiring.Truncate(time.Minute)}]; !ok {
+		t.Error("late point past the delete age was deleted without a summary")
+	}
+	if !store.has(kept) {
+		t.Error("late point before the delete age was deleted")
+	}
+	if report.PointsDeleted != 1 {
+		t.Errorf("deleted %d points, want 1", report.PointsDeleted)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultShareTTL         = 24 * time.Hour
+	MaxShareTTL             = 7 * 24 * time.Hour
+	DefaultShareTrackPoints = 500
+	DefaultShareAccesses    = 100
+	MaxShareAccesses        = 1000
+	MaxShareLabelLength     = 100
+)
+
+// shareTokenBytes is the entropy of a share token.
+const shareTokenBytes = 32
+
+var (
+	ErrShareNotFound        = errors.New("share link not found")
+	ErrShareExpired         = errors.New("share link has expired")
+	ErrShareRevoked         = errors.New("share link has been revoked")
+	ErrShareNotStarted      = errors.New("share link is not active yet")
+	ErrInvalidShare         = errors.New("invalid share link")
+	ErrShareVehicleNotFound = ErrVehicleNotFound
+	ErrShareForbidden       = ErrVehicleForbidden
+)
+
+// CreateShareLink creates a share link for a vehicle of link.OwnerID (any
+// owner's when it is empty) and returns it with its token. A zero ExpiresAt
+// takes the default lifetime.
+func (s *DomainTrackerService) CreateShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error) {
+	cfg := s.shareConfig()
+	now := time.Now().UTC()
+	if link.ExpiresAt.IsZero() {
+		link.ExpiresAt = now.Add(cfg.DefaultTTL)
+	}
+	switch {
+	case !link.ExpiresAt.After(now):
+		return entity.ShareLink{}, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidShare)
+	case link.ExpiresAt.Sub(now) > cfg.MaxTTL:
+		return entity.ShareLink{}, fmt.Errorf("%w: a link may be valid for at most %s", ErrInvalidShare, cfg.MaxTTL)
+	case link.WindowFrom != nil && link.WindowTo != nil && !link.WindowFrom.Before(*link.WindowTo):
+		return entity.ShareLink{}, fmt.Errorf("%w: window_from must be before window_to", ErrInvalidShare)
+	case len(link.Label) > MaxShareLabelLength:
+		return entity.ShareLink{}, fmt.Errorf("%w: label is longer than %d characters", ErrInvalidShare, MaxShareLabelLength)
+	}
+
+	ownerID, err := s.vehicleOwner(ctx, link.VehicleID)
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	if link.OwnerID != "" && link.OwnerID != ownerID {
+		return entity.ShareLink{}, ErrShareForbidden
+	}
+	link.OwnerID = ownerID
+
+	token, err := newShareToken()
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	link.TokenHash = hashShareToken(token)
+	link.CreatedAt = now
+
+	saved, err := s.trackerRepo.SaveShareLink(ctx, link)
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	saved.Token = token
+	return saved, nil
+}
+
+// vehicleOwner returns the owner of a live vehicle: from the vehicle
+// registry when there is one, otherwise from its latest location.
+func (s *DomainTrackerService) vehicleOwner(ctx context.Context, vehicleID string) (string, error) {
+	if s.registry == nil {
+		location, err := s.GetLatestVehicleLocationByID(ctx, vehicleID)
+		if err != nil {
+			return "", ErrShareVehicleNotFound
+		}
+		return location.OwnerID, nil
+	}
+	vehicle, found, err := s.registeredVehicle(ctx, vehicleID)
+	if err != nil {
+		return "", err
+	}
+	if !found || vehicle.Deleted {
+		return "", ErrShareVehicleNotFound
+	}
+	return vehicle.OwnerID, nil
+}
+
+// ListShareLinks returns the share links of a vehicle, newest first,
+// without their tokens.
+func (s *DomainTrackerService) ListShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error) {
+	links, err := s.trackerRepo.GetShareLinks(ctx, vehicleID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []*entity.ShareLink{}
+	}
+	return links, nil
+}
+
+// RevokeShareLink revokes a share link of a vehicle. Revoking a revoked
+// link again leaves it as it is.
+func (s *DomainTrackerService) RevokeShareLink(ctx context.Context, vehicleID, ownerID string, id int64) (entity.ShareLink, error) {
+	link, err := s.ownedShareLink(ctx, vehicleID, ownerID, id)
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	if link.RevokedAt != nil {
+		return link, nil
+	}
+	now := time.Now().UTC()
+	if _, err := s.trackerRepo.RevokeShareLink(ctx, id, now); err != nil {
+		return entity.ShareLink{}, err
+	}
+	link.RevokedAt = &now
+	return link, nil
+}
+
+// GetShareAccesses returns the most recent accesses of a share link first.
+func (s *DomainTrackerService) GetShareAccesses(ctx context.Context, vehicleID, ownerID string, id int64, limit int) ([]*entity.ShareAccess, error) {
+	if _, err := s.ownedShareLink(ctx, vehicleID, ownerID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultShareAccesses
+	}
+	if limit > MaxShareAccesses {
+		limit = MaxShareAccesses
+	}
+	accesses, err := s.trackerRepo.GetShareAccesses(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if accesses == nil {
+		accesses = []*entity.ShareAccess{}
+	}
+	return accesses, nil
+}
+
+func (s *DomainTrackerService) ownedShareLink(ctx context.Context, vehicleID, ownerID string, id int64) (entity.ShareLink, error) {
+	link, found, err := s.trackerRepo.GetShareLink(ctx, id)
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	if !found || link.VehicleID != vehicleID || (ownerID != "" && link.OwnerID != ownerID) {
+		return entity.ShareLink{}, ErrShareNotFound
+	}
+	return link, nil
+}
+
+// ResolveShareLink returns the link a token belongs to if it may be used
+// now. Every use of an existing link is audited, refused ones included; a
+// link is not served when its access cannot be recorded.
+func (s *DomainTrackerService) ResolveShareLink(ctx context.Context, token string, access entity.ShareAccess) (entity.ShareLink, error) {
+	if token == "" {
+		return entity.ShareLink{}, ErrShareNotFound
+	}
+	link, found, err := s.trackerRepo.GetShareLinkByTokenHash(ctx, hashShareToken(token))
+	if err != nil {
+		return entity.ShareLink{}, err
+	}
+	if !found {
+		return entity.ShareLink{}, ErrShareNotFound
+	}
+
+	now := time.Now().UTC()
+	var refused error
+	access.Outcome = entity.ShareAccessGranted
+	switch {
+	case link.RevokedAt != nil:
+		access.Outcome, refused = entity.ShareAccessRevoked, ErrShareRevoked
+	case !now.Before(link.ExpiresAt):
+		access.Outcome, refused = entity.ShareAccessExpired, ErrShareExpired
+	case link.WindowFrom != nil && now.Before(*link.WindowFrom):
+		access.Outcome, refused = entity.ShareAccessNotStarted, ErrShareNotStarted
+	}
+
+	access.ShareID = link.ID
+	access.VehicleID = link.VehicleID
+	access.AccessedAt = now
+	if err := s.trackerRepo.SaveShareAccess(ctx, access); err != nil {
+		return entity.ShareLink{}, err
+	}
+	if refused != nil {
+		return entity.ShareLink{}, refused
+	}
+	return link, nil
+}
+
+func (s *DomainTrackerService) shareConfig() entity.ShareConfig {
+	cfg := s.config.Shares
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = DefaultShareTTL
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = MaxShareTTL
+	}
+	if cfg.DefaultTTL > cfg.MaxTTL {
+		cfg.DefaultTTL = cfg.MaxTTL
+	}
+	if cfg.TrackPoints < 2 {
+		cfg.TrackPoints = DefaultShareTrackPoints
+	}
+	return cfg
+}
+
+// ShareTrackPoints is how many points the track of a share link shows.
+func (s *DomainTrackerService) ShareTrackPoints() int {
+	return s.shareConfig().TrackPoints
+}
+
+func newShareToken() (string, error) {
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
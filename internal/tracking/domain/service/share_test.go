@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+// shareRepo keeps share links by token hash and records their accesses.
+type shareRepo struct {
+	fakeTrackerRepo
+
+	links    map[string]entity.ShareLink
+	accesses []entity.ShareAccess
+}
+
+func (r *shareRepo) GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (entity.ShareLink, bool, error) {
+	link, ok := r.links[tokenHash]
+	return link, ok, nil
+}
+
+func (r *shareRepo) SaveShareAccess(ctx context.Context, access entity.ShareAccess) error {
+	r.accesses = append(r.accesses, access)
+	return nil
+}
+
+func TestResolveShareLink(t *testing.T) {
+	now := time.Now().UTC()
+	hour := func(n int) *time.Time {
+		h := now.Add(time.Duration(n) * time.Hour)
+		return &h
+	}
+	active := entity.ShareLink{ID: 1, VehicleID: "v1", OwnerID: "owner1", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+
+	cases := []struct {
+		name        string
+		edit        func(link *entity.ShareLink)
+		token       string
+		wantErr     error
+		wantOutcome entity.ShareAccessOutcome // "" when no access is recorded
+	}{
+		{name: "active", token: "secret", wantOutcome: entity.ShareAccessGranted},
+		{name: "window open", token: "secret", edit: func(l *entity.ShareLink) { l.WindowFrom, l.WindowTo = hour(-2), hour(2) }, wantOutcome: entity.ShareAccessGranted},
+		{name: "window closed", token: "secret", edit: func(l *entity.ShareLink) { l.WindowFrom, l.WindowTo = hour(-3), hour(-2) }, wantOutcome: entity.ShareAccessGranted},
+		{name: "revoked", token: "secret", edit: func(l *entity.ShareLink) { l.RevokedAt = hour(0) }, wantErr: ErrShareRevoked, wantOutcome: entity.ShareAccessRevoked},
+		{name: "expired", token: "secret", edit: func(l *entity.ShareLink) { l.ExpiresAt = *hour(-1) }, wantErr: ErrShareExpired, wantOutcome: entity.ShareAccessExpired},
+		{name: "revoked and expired", token: "secret", edit: func(l *entity.ShareLink) { l.RevokedAt, l.ExpiresAt = hour(-2), *hour(-1) }, wantErr: ErrShareRevoked, wantOutcome: entity.ShareAccessRevoked},
+		{name: "not started", token: "secret", edit: func(l *entity.ShareLink) { l.WindowFrom = hour(1) }, wantErr: ErrShareNotStarted, wantOutcome: entity.ShareAccessNotStarted},
+		{name: "unknown token", token: "other", wantErr: ErrShareNotFound},
+		{name: "empty token", token: "", wantErr: ErrShareNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			link := active
+			if tc.edit != nil {
+				tc.edit(&link)
+			}
+			store := &shareRepo{links: map[string]entity.ShareLink{hashShareToken("secret"): link}}
+			s := newTestTracker(store, entity.TrackerConfig{})
+
+			got, err := s.ResolveShareLink(context.Background(), tc.token, entity.ShareAccess{RemoteAddr: "198.51.100.7"})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && got.ID != link.ID {
+				t.Errorf("resolved link %d, want %d", got.ID, link.ID)
+			}
+
+			if tc.wantOutcome == "" {
+				if len(store.accesses) != 0 {
+					t.Fatalf("recorded %d accesses of an unknown link", len(store.accesses))
+				}
+				return
+			}
+			if len(store.accesses) != 1 {
+				t.Fatalf("recorded %d accesses, want 1", len(store.accesses))
+			}
+			access := store.accesses[0]
+			if access.Outcome != tc.wantOutcome || access.ShareID != link.ID || access.RemoteAddr != "198.51.100.7" {
+				t.Errorf("access = %+v, want outcome %q for share %d", access, tc.wantOutcome, link.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"FMTS/pkg/geo"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxSimplifyInputPoints bounds how many raw points are loaded into memory
+// for one simplified history request.
+const MaxSimplifyInputPoints = 200000
+
+var (
+	ErrSimplifyWithCursor      = errors.New("cursor cannot be combined with simplification")
+	ErrInvalidSimplify         = errors.New("simplification needs a positive tolerance or target_points of at least 2")
+	ErrTooManyPointsToSimplify = fmt.Errorf("range has more than %d points to simplify: narrow the time range", MaxSimplifyInputPoints)
+)
+
+// SetSpeedLimitProvider lets simplification keep speed-limit violations.
+// Without a provider only stops are mandatory vertices.
+func (s *DomainTrackerService) SetSpeedLimitProvider(provider repo.SpeedLimitProvider) {
+	s.speedLimits = provider
+}
+
+// simplifiedHistory reads the whole range and reduces it with
+// Douglas-Peucker. The edges of stops and the start, peak and end of every
+// run above the vehicle's speed limit are always kept. When only a
+// tolerance is given the output is still capped at MaxHistoryPoints, and
+// the response is marked truncated if the cap stopped it short of the
+// tolerance.
+func (s *DomainTrackerService) simplifiedHistory(ctx context.Context, query entity.LocationHistoryQuery) (entity.LocationHistoryPage, error) {
+	opts := *query.Simplify
+	if query.Cursor != "" || query.After != nil {
+		return entity.LocationHistoryPage{}, ErrSimplifyWithCursor
+	}
+	if opts.ToleranceMeters < 0 || opts.TargetPoints < 0 || (opts.ToleranceMeters == 0 && opts.TargetPoints < 2) {
+		return entity.LocationHistoryPage{}, ErrInvalidSimplify
+	}
+	target := opts.TargetPoints
+	capped := target == 0 || target > MaxHistoryPoints
+	if capped {
+		target = MaxHistoryPoints
+	}
+
+	var points []*entity.VehicleLocation
+	err := s.trackerRepo.StreamVehicleLocations(ctx, entity.LocationRangeQuery{
+		VehicleID: query.VehicleID,
+		OwnerID:   query.OwnerID,
+		From:      query.From,
+		To:        query.To,
+	}, func(loc entity.VehicleLocation) error {
+		if len(points) >= MaxSimplifyInputPoints {
+			return ErrTooManyPointsToSimplify
+		}
+		points = append(points, &loc)
+		return nil
+	})
+	if err != nil {
+		return entity.LocationHistoryPage{}, err
+	}
+
+	var speedLimit float64
+	if s.speedLimits != nil {
+		if limit, ok := s.speedLimits.SpeedLimit(ctx, query.VehicleID); ok {
+			speedLimit = limit
+		}
+	}
+	mandatory := MandatoryVertices(points, s.config.Trips, speedLimit)
+
+	coords := make([]geo.Point, len(points))
+	for i, p := range points {
+		coords[i] = geo.Point{Latitude: p.Latitude, Longitude: p.Longitude}
+	}
+	keep := geo.Simplify(coords, opts.ToleranceMeters, target, func(i int) bool { return mandatory[i] })
+
+	page := entity.LocationHistoryPage{
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Points:    make([]*entity.VehicleLocation, 0, len(keep)),
+		Simplification: &entity.SimplificationInfo{
+			InputPoints:     len(points),
+			OutputPoints:    len(keep),
+			ToleranceMeters: opts.ToleranceMeters,
+			TargetPoints:    opts.TargetPoints,
+			MaxErrorMeters:  maxSimplificationError(coords, keep),
+		},
+	}
+	info := page.Simplification
+	info.Truncated = capped && len(keep) >= MaxHistoryPoints && info.MaxErrorMeters > opts.ToleranceMeters
+	for _, i := range keep {
+		page.Points = append(page.Points, points[i])
+	}
+	for _, m := range mandatory {
+		if m {
+			page.Simplification.MandatoryPoints++
+		}
+	}
+	return page, nil
+}
+
+// MandatoryVertices marks the points a simplified track must keep: the first
+// and last point of every stop lasting at least cfg.MinDwell (including
+// reporting gaps that long), and the start, peak and end of every run above
+// speedLimit. A speedLimit of zero disables the overspeed rule.
+func MandatoryVertices(points []*entity.VehicleLocation, cfg entity.TripDetectionConfig, speedLimit float64) []bool {
+	mandatory := make([]bool, len(points))
+	stopStart, overStart, peak := -1, -1, -1
+
+	closeStop := func(end int) {
+		if stopStart >= 0 && points[end].Timestamp.Sub(points[stopStart].Timestamp) >= cfg.MinDwell {
+			mandatory[stopStart], mandatory[end] = true, true
+		}
+		stopStart = -1
+	}
+	closeOverspeed := func(end int) {
+		if overStart >= 0 {
+			mandatory[overStart], mandatory[peak], mandatory[end] = true, true, true
+		}
+		overStart, peak = -1, -1
+	}
+
+	var peakSpeed float64
+	for i, p := range points {
+		var prev *entity.VehicleLocation
+		if i > 0 {
+			prev = points[i-1]
+			if p.Timestamp.Sub(prev.Timestamp) >= cfg.MinDwell {
+				mandatory[i-1], mandatory[i] = true, true
+			}
+		}
+
+		speed := pointSpeed(prev, p)
+		if speed <= cfg.StopSpeed {
+			if stopStart < 0 {
+				stopStart = i
+			}
+		} else if stopStart >= 0 {
+			closeStop(i - 1)
+		}
+
+		if speedLimit > 0 && speed > speedLimit {
+			if overStart < 0 || speed > peakSpeed {
+				if overStart < 0 {
+					overStart = i
+				}
+				peak, peakSpeed = i, speed
+			}
+		} else if overStart >= 0 {
+			closeOverspeed(i - 1)
+		}
+	}
+	if n := len(points); n > 0 {
+		closeStop(n - 1)
+		closeOverspeed(n - 1)
+	}
+	return mandatory
+}
+
+// maxSimplificationError returns the largest distance from a dropped point
+// to the kept segment spanning it.
+func maxSimplificationError(points []geo.Point, keep []int) float64 {
+	worst := 0.0
+	for k := 1; k < len(keep); k++ {
+		a, b := keep[k-1], keep[k]
+		for i := a + 1; i < b; i++ {
+			worst = math.Max(worst, geo.SegmentDistanceMeters(points[i], points[a], points[b]))
+		}
+	}
+	return math.Round(worst*100) / 100
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+func (r *fakeTrackerRepo) StreamVehicleLocations(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error {
+	for _, loc := range r.stored {
+		if loc.Timestamp.Before(query.From) || !loc.Timestamp.Before(query.To) {
+			continue
+		}
+		if err := fn(loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func simplify(t *testing.T, s *DomainTrackerService, opts entity.SimplifyOptions) entity.LocationHistoryPage {
+	t.Helper()
+	page, err := s.simplifiedHistory(context.Background(), entity.LocationHistoryQuery{
+		VehicleID: "v1",
+		From:      testStart,
+		To:        testStart.AddDate(0, 0, 1),
+		Simplify:  &opts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestSimplifiedHistoryKeepsStopsAtLargeTolerance(t *testing.T) {
+	store := &tripRepo{}
+	s := newTestTracker(store, entity.TrackerConfig{Trips: testTripConfig})
+	track := newTrack().drive(10).park(6).drive(10)
+	store.store(track.points)
+
+	page := simplify(t, s, entity.SimplifyOptions{ToleranceMeters: 100000})
+
+	// Only the endpoints and the edges of the stop are left.
+	mandatory := MandatoryVertices(track.points, testTripConfig, 0)
+	want := map[int64]bool{track.points[0].ID: true, track.points[len(track.points)-1].ID: true}
+	for i, m := range mandatory {
+		if m {
+			want[track.points[i].ID] = true
+		}
+	}
+	if len(want) != 4 {
+		t.Fatalf("%d mandatory points and endpoints, want 4", len(want))
+	}
+	if len(page.Points) != len(want) {
+		t.Fatalf("kept %d points, want %d", len(page.Points), len(want))
+	}
+	for _, p := range page.Points {
+		if !want[p.ID] {
+			t.Errorf("kept point %d that is not mandatory", p.ID)
+		}
+	}
+	if page.Simplification.Truncated {
+		t.Error("output below the cap marked truncated")
+	}
+}
+
+func TestSimplifiedHistoryFlagsTruncation(t *testing.T) {
+	store := &fakeTrackerRepo{}
+	s := newTestTracker(store, entity.TrackerConfig{Trips: testTripConfig})
+	// A track zigzagging 50 m either side every fix, with more corners than
+	// the cap allows.
+	for i := 0; i < MaxHistoryPoints+500; i++ {
+		east := 50.0
+		if i%2 == 1 {
+			east = -50
+		}
+		store.stored = append(store.stored, fixAt(i, float64(i)*10, east))
+	}
+
+	page := simplify(t, s, entity.SimplifyOptions{ToleranceMeters: 1})
+	info := page.Simplification
+	if info.OutputPoints != MaxHistoryPoints || !info.Truncated {
+		t.Fatalf("output %d points, truncated = %v; want %d and truncated", info.OutputPoints, info.Truncated, MaxHistoryPoints)
+	}
+	if info.MaxErrorMeters <= info.ToleranceMeters {
+		t.Fatalf("max error %.1fm within the tolerance of a truncated track", info.MaxErrorMeters)
+	}
+
+	// A tolerance the cap can meet is not truncated.
+	if page := simplify(t, s, entity.SimplifyOptions{ToleranceMeters: 200}); page.Simplification.Truncated {
+		t.Fatal("tolerance met under the cap marked truncated")
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpeedLimit returns the speed limit of a vehicle from the speed limit
+// provider. Limits are kept for the vehicle cache TTL, so looking one up for
+// every fix does not query the vehicle store.
+func (s *DomainTrackerService) SpeedLimit(ctx context.Context, vehicleID string) (float64, bool) {
+	if s.speedLimits == nil {
+		return 0, false
+	}
+	if limit, ok := s.limits.get(vehicleID); ok {
+		return limit, true
+	}
+	limit, ok := s.speedLimits.SpeedLimit(ctx, vehicleID)
+	if !ok {
+		// Misses are not cached so a vehicle is checked as soon as it is
+		// registered.
+		return 0, false
+	}
+	ttl := s.config.Ingest.VehicleCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultVehicleCacheTTL
+	}
+	s.limits.put(vehicleID, limit, ttl)
+	return limit, true
+}
+
+// speedLimitCache keeps looked-up speed limits per vehicle. The zero value
+// is ready to use.
+type speedLimitCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSpeedLimit
+}
+
+type cachedSpeedLimit struct {
+	limit   float64
+	expires time.Time
+}
+
+func (c *speedLimitCache) get(vehicleID string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[vehicleID]
+	if !ok {
+		return 0, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, vehicleID)
+		return 0, false
+	}
+	return entry.limit, true
+}
+
+func (c *speedLimitCache) put(vehicleID string, limit float64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedSpeedLimit)
+	}
+	c.entries[vehicleID] = cachedSpeedLimit{limit: limit, expires: time.Now().Add(ttl)}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	"FMTS/pkg/geo"
+
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultStopRadiusMeters = 50
+	DefaultStopMinDuration  = 5 * time.Minute
+)
+
+// stopRetries bounds how often a conflicting stop state write is retried.
+const stopRetries = 3
+
+// SetGeofenceLocator lets detected stops be matched to geofences.
+func (s *DomainTrackerService) SetGeofenceLocator(locator repo.GeofenceLocator) {
+	s.geofences = locator
+}
+
+// StopListener returns the listener that follows every persisted location
+// to detect when a vehicle arrives at and leaves a stop.
+func (s *DomainTrackerService) StopListener() repo.LocationListener {
+	return repo.LocationListenerFunc(func(ctx context.Context, location entity.VehicleLocation) {
+		if err := s.advanceStop(ctx, location); err != nil {
+			s.logger.Errorf("[Stops] vehicle %s: %v", location.VehicleID, err)
+		}
+	})
+}
+
+func (s *DomainTrackerService) advanceStop(ctx context.Context, location entity.VehicleLocation) error {
+	cfg := s.stopConfig()
+	for attempt := 0; attempt < stopRetries; attempt++ {
+		current, found, err := s.trackerRepo.GetStopState(ctx, location.VehicleID)
+		if err != nil {
+			return err
+		}
+		next, stop, ok := NextStop(current, found, location, cfg)
+		if !ok {
+			return nil
+		}
+
+		var previous *time.Time
+		if found {
+			previous = &current.LastSeen
+		}
+		if stop != nil && stop.Ongoing && current.Dwell() < cfg.MinDuration {
+			// The stay just became a stop; it is saved with its geofences.
+			stop.Geofences = s.geofencesAt(ctx, *stop)
+		}
+		applied, err := s.trackerRepo.AdvanceStop(ctx, next, previous, stop)
+		if err != nil {
+			return err
+		}
+		if applied {
+			if stop != nil && !stop.Ongoing {
+				s.logger.Infof("[Stops] vehicle %s left a stop of %s", stop.VehicleID, time.Duration(stop.DurationSeconds)*time.Second)
+			}
+			return nil
+		}
+		// Another writer advanced the state concurrently; re-read and retry.
+	}
+	return fmt.Errorf("stop update still conflicting after %d attempts", stopRetries)
+}
+
+// geofencesAt returns the geofences a stop lies in. Failures are logged and
+// the stop is kept without geofences.
+func (s *DomainTrackerService) geofencesAt(ctx context.Context, stop entity.Stop) []entity.GeofenceRef {
+	if s.geofences == nil {
+		return nil
+	}
+	geofences, err := s.geofences.GeofencesAt(ctx, stop.OwnerID, stop.Latitude, stop.Longitude)
+	if err != nil {
+		s.logger.Errorf("[Stops] geofences of vehicle %s: %v", stop.VehicleID, err)
+		return nil
+	}
+	return geofences
+}
+
+func (s *DomainTrackerService) stopConfig() entity.StopDetectionConfig {
+	cfg := s.config.Stops
+	if cfg.RadiusMeters <= 0 {
+		cfg.RadiusMeters = DefaultStopRadiusMeters
+	}
+	if cfg.MinDuration <= 0 {
+		cfg.MinDuration = DefaultStopMinDuration
+	}
+	return cfg
+}
+
+// NextStop measures a fix against the stay a vehicle is in and returns the
+// next stay and, once the stay lasts cfg.MinDuration, the stop to save: an
+// ongoing one while the vehicle stays within the radius, a finished one when
+// it leaves. ok is false when the fix is not newer than the stay.
+func NextStop(current entity.StopState, found bool, location entity.VehicleLocation, cfg entity.StopDetectionConfig) (next entity.StopState, stop *entity.Stop, ok bool) {
+	if found && !location.Timestamp.After(current.LastSeen) {
+		return current, nil, false
+	}
+	if !found {
+		return newStopState(location), nil, true
+	}
+
+	meters := geo.HaversineMeters(current.AnchorLatitude, current.AnchorLongitude, location.Latitude, location.Longitude)
+	if meters > cfg.RadiusMeters {
+		// The vehicle left; the stop ended with the last fix inside the radius.
+		if current.Dwell() >= cfg.MinDuration {
+			stop = stopOf(current, false)
+		}
+		return newStopState(location), stop, true
+	}
+
+	next = current
+	next.OwnerID = location.OwnerID
+	next.PointCount++
+	next.Latitude += (location.Latitude - next.Latitude) / float64(next.PointCount)
+	next.Longitude += (location.Longitude - next.Longitude) / float64(next.PointCount)
+	next.LastSeen = location.Timestamp
+	if next.Dwell() >= cfg.MinDuration {
+		stop = stopOf(next, true)
+	}
+	return next, stop, true
+}
+
+func newStopState(location entity.VehicleLocation) entity.StopState {
+	return entity.StopState{
+		VehicleID:       location.VehicleID,
+		OwnerID:         location.OwnerID,
+		AnchorLatitude:  location.Latitude,
+		AnchorLongitude: location.Longitude,
+		Latitude:        location.Latitude,
+		Longitude:       location.Longitude,
+		ArrivalTime:     location.Timestamp,
+		LastSeen:        location.Timestamp,
+		PointCount:      1,
+	}
+}
+
+func stopOf(state entity.StopState, ongoing bool) *entity.Stop {
+	return &entity.Stop{
+		OwnerID:         state.OwnerID,
+		VehicleID:       state.VehicleID,
+		Latitude:        state.Latitude,
+		Longitude:       state.Longitude,
+		ArrivalTime:     state.ArrivalTime,
+		DepartureTime:   state.LastSeen,
+		DurationSeconds: int64(state.Dwell().Seconds()),
+		PointCount:      state.PointCount,
+		Ongoing:         ongoing,
+	}
+}
+
+// GetVehicleStops returns the stops of a vehicle overlapping the range, in
+// arrival order.
+func (s *DomainTrackerService) GetVehicleStops(ctx context.Context, query entity.StopQuery) ([]*entity.Stop, error) {
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+	stops, err := s.trackerRepo.GetStops(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stops == nil {
+		stops = []*entity.Stop{}
+	}
+	return stops, nil
+}
+
+// GetGeofenceVisits summarises per geofence the stops overlapping the range.
+func (s *DomainTrackerService) GetGeofenceVisits(ctx context.Context, query entity.GeofenceVisitQuery) (entity.GeofenceVisitReport, error) {
+	if !query.From.Before(query.To) {
+		return entity.GeofenceVisitReport{}, ErrInvalidTimeRange
+	}
+	visits, err := s.trackerRepo.GetGeofenceVisits(ctx, query)
+	if err != nil {
+		return entity.GeofenceVisitReport{}, err
+	}
+	if visits == nil {
+		visits = []entity.GeofenceVisits{}
+	}
+	return entity.GeofenceVisitReport{
+		OwnerID:   query.OwnerID,
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Geofences: visits,
+	}, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+)
+
+var testStopConfig = entity.StopDetectionConfig{
+	RadiusMeters: 50,
+	MinDuration:  5 * time.Minute,
+}
+
+// stay returns fixes every 30 seconds from one second to another, the given
+// metres north of the origin.
+func stay(from, to int, north float64) []entity.VehicleLocation {
+	var fixes []entity.VehicleLocation
+	for s := from; s <= to; s += 30 {
+		fixes = append(fixes, fixAt(s, north, 0))
+	}
+	return fixes
+}
+
+func concat(parts ...[]entity.VehicleLocation) []entity.VehicleLocation {
+	var all []entity.VehicleLocation
+	for _, p := range parts {
+		all = append(all, p...)
+	}
+	return all
+}
+
+func TestNextStop(t *testing.T) {
+	type span struct{ arrival, departure int }
+	jitter := stay(0, 420, 0)
+	for i := range jitter {
+		if i%2 == 1 {
+			jitter[i] = fixAt(i*30, 30, 20)
+		}
+	}
+	crawl := stay(0, 600, 0)
+	for i := range crawl {
+		crawl[i] = fixAt(i*30, float64(i)*20, 0)
+	}
+
+	cases := []struct {
+		name        string
+		fixes       []entity.VehicleLocation
+		wantOngoing int // seconds of the first ongoing stop, -1 for none
+		want        []span
+	}{
+		{
+			name:        "short stay",
+			fixes:       concat(stay(0, 240, 0), stay(270, 270, 500)),
+			wantOngoing: -1,
+		},
+		{
+			name:        "stop then leave",
+			fixes:       concat(stay(0, 420, 0), stay(450, 450, 500)),
+			wantOngoing: 300,
+			want:        []span{{0, 420}},
+		},
+		{
+			name:        "jitter within radius",
+			fixes:       concat(jitter, stay(450, 450, 500)),
+			wantOngoing: 300,
+			want:        []span{{0, 420}},
+		},
+		{
+			name:        "crawl beyond radius",
+			fixes:       crawl,
+			wantOngoing: -1,
+		},
+		{
+			name:        "two stops",
+			fixes:       concat(stay(0, 360, 0), stay(390, 900, 1000), stay(930, 930, 2000)),
+			wantOngoing: 300,
+			want:        []span{{0, 360}, {390, 900}},
+		},
+		{
+			name:        "late fix ignored",
+			fixes:       concat(stay(0, 420, 0), stay(100, 100, 500), stay(450, 450, 500)),
+			wantOngoing: 300,
+			want:        []span{{0, 420}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var state entity.StopState
+			var found bool
+			firstOngoing := -1
+			var got []span
+			for _, fix := range tc.fixes {
+				next, stop, ok := NextStop(state, found, fix, testStopConfig)
+				if !ok {
+					continue
+				}
+				state, found = next, true
+				if stop == nil {
+					continue
+				}
+				if stop.Ongoing {
+					if firstOngoing < 0 {
+						firstOngoing = int(fix.Timestamp.Sub(testStart).Seconds())
+					}
+					continue
+				}
+				got = append(got, span{
+					arrival:   int(stop.ArrivalTime.Sub(testStart).Seconds()),
+					departure: int(stop.DepartureTime.Sub(testStart).Seconds()),
+				})
+			}
+			if firstOngoing != tc.wantOngoing {
+				t.Errorf("first ongoing stop at %ds, want %ds", firstOngoing, tc.wantOngoing)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("stops %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("stop %d = %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+// stopRepo keeps the stop state and the saved stops in memory.
+type stopRepo struct {
+	fakeTrackerRepo
+
+	state *entity.StopState
+	saved []entity.Stop
+}
+
+func (r *stopRepo) GetStopState(ctx context.Context, vehicleID string) (entity.StopState, bool, error) {
+	if r.state == nil {
+		return entity.StopState{}, false, nil
+	}
+	return *r.state, true, nil
+}
+
+func (r *stopRepo) AdvanceStop(ctx context.Context, next entity.StopState, previous *time.Time, stop *entity.Stop) (bool, error) {
+	if (previous == nil) != (r.state == nil) || (previous != nil && !previous.Equal(r.state.LastSeen)) {
+		return false, nil
+	}
+	r.state = &next
+	if stop != nil {
+		r.saved = append(r.saved, *stop)
+	}
+	return true, nil
+}
+
+// depotLocator has a single geofence of radius 100 m around a point.
+type depotLocator struct {
+	centre geo.Point
+	calls  int
+}
+
+func (l *depotLocator) GeofencesAt(ctx context.Context, ownerID string, latitude, longitude float64) ([]entity.GeofenceRef, error) {
+	l.calls++
+	if geo.HaversineMeters(l.centre.Latitude, l.centre.Longitude, latitude, longitude) > 100 {
+		return nil, nil
+	}
+	return []entity.GeofenceRef{{ID: "g1", Name: "Depot"}}, nil
+}
+
+func TestAdvanceStopMatchesGeofences(t *testing.T) {
+	cases := []struct {
+		name      string
+		north     float64
+		until     int
+		wantFence bool
+		wantCalls int
+	}{
+		{name: "stop inside the depot", north: 20, until: 420, wantFence: true, wantCalls: 1},
+		{name: "stop outside the depot", north: 2000, until: 420, wantFence: false, wantCalls: 1},
+		{name: "short stay in the depot", north: 20, until: 240, wantCalls: 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &stopRepo{}
+			locator := &depotLocator{centre: testOrigin}
+			s := newTestTracker(store, entity.TrackerConfig{Stops: testStopConfig})
+			s.SetGeofenceLocator(locator)
+
+			for _, fix := range concat(stay(0, tc.until, tc.north), stay(tc.until+30, tc.until+30, tc.north+5000)) {
+				if err := s.advanceStop(context.Background(), fix); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The geofences are looked up once, when the stay becomes a stop.
+			if locator.calls != tc.wantCalls {
+				t.Errorf("geofence lookups = %d, want %d", locator.calls, tc.wantCalls)
+			}
+			if tc.wantCalls == 0 {
+				if len(store.saved) != 0 {
+					t.Fatalf("saved %d stops for a short stay", len(store.saved))
+				}
+				return
+			}
+
+			first, last := store.saved[0], store.saved[len(store.saved)-1]
+			if !first.Ongoing || last.Ongoing {
+				t.Fatalf("first saved ongoing = %v, last ongoing = %v; want an ongoing stop closed when the vehicle left", first.Ongoing, last.Ongoing)
+			}
+			if got := len(first.Geofences) == 1 && first.Geofences[0].ID == "g1"; got != tc.wantFence {
+				t.Errorf("geofences of the stop = %v, want depot %v", first.Geofences, tc.wantFence)
+			}
+			if want := at(tc.until); !last.DepartureTime.Equal(want) {
+				t.Errorf("departure = %v, want %v", last.DepartureTime, want)
+			}
+		})
+	}
+}
@@ -6,34 +6,308 @@ import (
 
 	"FMTS/utils"
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultHistoryPoints = 1000
+	MaxHistoryPoints     = 10000
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidTimeRange = errors.New("invalid time range: from must be before to")
 )
 
 type DomainTracker interface {
+	AuthorizeLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error)
+	CheckVehicleAccess(ctx context.Context, vehicleID, ownerID string) error
 	UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error)
+	UpdateLocations(ctx context.Context, locations []entity.VehicleLocation) (entity.BatchIngestResult, error)
 	GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error)
 	GetLatestVehicleLocationsByUserID(ctx context.Context, UserID string) ([]*entity.VehicleLocation, error)
+	GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) (entity.LocationHistoryPage, error)
+	GetVehicleTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error)
+	SegmentTrips(ctx context.Context) (int, error)
+	GetVehicleOdometer(ctx context.Context, vehicleID string) (entity.Odometer, error)
+	StreamVehicleTrack(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error
+	GetVehicleDistance(ctx context.Context, query entity.DistanceQuery) (entity.DistanceReport, error)
+	GetQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) (entity.QuarantineReport, error)
+	FindNearbyVehicles(ctx context.Context, query entity.NearbyQuery) (entity.NearbyResult, error)
+	GetHeatmap(ctx context.Context, query entity.HeatmapQuery) (entity.Heatmap, error)
+	RunRetention(ctx context.Context, dryRun bool) (entity.RetentionReport, error)
+	ListRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error)
+	LoadConnectivity(ctx context.Context) error
+	CheckConnectivity(ctx context.Context) (int, error)
+	GetVehicleConnectivity(ctx context.Context, ownerID string) ([]entity.VehicleConnectivity, error)
+	GetConnectivityTransitions(ctx context.Context, query entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error)
+	GetConnectivityThresholds(ctx context.Context, ownerID string) (entity.ConnectivityThresholds, error)
+	SetConnectivityThresholds(ctx context.Context, thresholds entity.ConnectivityThresholds) (entity.ConnectivityThresholds, error)
+	GetVehicleStops(ctx context.Context, query entity.StopQuery) ([]*entity.Stop, error)
+	GetGeofenceVisits(ctx context.Context, query entity.GeofenceVisitQuery) (entity.GeofenceVisitReport, error)
+	GetFuelReport(ctx context.Context, query entity.FuelReportQuery) (entity.FuelReport, error)
+	GetFuelEvents(ctx context.Context, query entity.FuelEventQuery) ([]*entity.FuelEvent, error)
+	CreateShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error)
+	ListShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error)
+	RevokeShareLink(ctx context.Context, vehicleID, ownerID string, id int64) (entity.ShareLink, error)
+	GetShareAccesses(ctx context.Context, vehicleID, ownerID string, id int64, limit int) ([]*entity.ShareAccess, error)
+	ResolveShareLink(ctx context.Context, token string, access entity.ShareAccess) (entity.ShareLink, error)
+	ShareTrackPoints() int
 }
 
 type DomainTrackerService struct {
-	logger      utils.Logger
-	trackerRepo repo.DomainTracker
+	logger       utils.Logger
+	trackerRepo  repo.DomainTracker
+	config       entity.TrackerConfig
+	listeners    []repo.LocationListener
+	speedLimits  repo.SpeedLimitProvider
+	limits       speedLimitCache
+	filter       *IngestFilter
+	latest       repo.LatestPositionStore
+	latestWarm   atomic.Bool
+	vehicles     repo.VehicleDirectory
+	registry     repo.VehicleRegistry
+	registered   *vehicleCache
+	connectivity *connectivityMonitor
+	geofences    repo.GeofenceLocator
+	tripMarks    tripMarks
 }
 
-func InitDomaintrakerservice(logger utils.Logger, trackerRepo repo.DomainTracker) *DomainTrackerService {
-	return &DomainTrackerService{
+func InitDomaintrakerservice(logger utils.Logger, trackerRepo repo.DomainTracker, config entity.TrackerConfig) *DomainTrackerService {
+	s := &DomainTrackerService{
 		logger:      logger,
 		trackerRepo: trackerRepo,
+		config:      config,
+		connectivity: &connectivityMonitor{
+			states:     make(map[string]entity.VehicleConnectivity),
+			thresholds: make(map[string]entity.ConnectivityThresholds),
+		},
 	}
+	s.filter = NewIngestFilter(config.Ingest, s.GetLatestVehicleLocationByID)
+	return s
 }
 
+// AddLocationListener registers a listener that is called for every location
+// successfully persisted through UpdateLocation.
+func (s *DomainTrackerService) AddLocationListener(listener repo.LocationListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// UpdateLocation authorizes a location for its vehicle, runs it through the
+// ingest filter and stores it. Refused locations are reported with an
+// *IngestDeniedError and not stored; rejected locations are quarantined and
+// reported with a *QuarantineError.
+// Out-of-order locations are stored flagged but not passed to listeners,
+// which all expect fixes in time order.
 func (s *DomainTrackerService) UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error) {
-	return s.trackerRepo.UpdateLocation(ctx, location)
+	location, vehicle, err := s.authorize(ctx, location)
+	if err != nil {
+		return entity.VehicleLocation{}, err
+	}
+
+	// The filter only remembers the fix once its outcome is stored, so a
+	// retry after a failed write is not taken for a duplicate.
+	tx := s.filter.Begin(location.VehicleID)
+	defer tx.Rollback()
+
+	decision := tx.Apply(ctx, location)
+	if decision.Reason != "" {
+		if err := s.quarantine(ctx, []FilterDecision{decision}); err != nil {
+			return entity.VehicleLocation{}, err
+		}
+		tx.Commit()
+		return entity.VehicleLocation{}, &entity.QuarantineError{Reason: decision.Reason, Detail: decision.Detail}
+	}
+
+	saved, err := s.trackerRepo.UpdateLocation(ctx, decision.Location)
+	if err != nil {
+		return entity.VehicleLocation{}, err
+	}
+	tx.Commit()
+	s.markTracked(ctx, vehicle)
+	s.rememberLatest(ctx, saved)
+	if !saved.OutOfOrder {
+		s.notifyListeners(ctx, saved)
+	}
+	return saved, nil
 }
 
-func (s *DomainTrackerService) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error) {
-	return s.trackerRepo.GetLatestVehicleLocationByID(ctx, vehicleID)
+// UpdateLocations authorizes, validates and filters a batch in timestamp order,
+// persists the accepted locations with a single bulk write and then notifies
+// listeners in the same order, so geofence transitions and live maps see a
+// buffered batch the way it was driven.
+func (s *DomainTrackerService) UpdateLocations(ctx context.Context, locations []entity.VehicleLocation) (entity.BatchIngestResult, error) {
+	result := entity.BatchIngestResult{
+		Quarantined: map[int]entity.QuarantineReason{},
+		Denied:      map[int]entity.IngestDenial{},
+		Invalid:     map[int]string{},
+	}
+	if len(locations) == 0 {
+		return result, nil
+	}
+
+	order := make([]int, len(locations))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return locations[order[i]].Timestamp.Before(locations[order[j]].Timestamp)
+	})
+
+	vehicleIDs := make([]string, len(locations))
+	for i, location := range locations {
+		vehicleIDs[i] = location.VehicleID
+	}
+	tx := s.filter.Begin(vehicleIDs...)
+	defer tx.Rollback()
+
+	accepted := make([]entity.VehicleLocation, 0, len(locations))
+	var rejected []FilterDecision
+	firstFix := make(map[string]entity.VehicleInfo)
+	for _, i := range order {
+		location, vehicle, err := s.authorize(ctx, locations[i])
+		var denied *entity.IngestDeniedError
+		if errors.As(err, &denied) {
+			result.Denied[i] = denied.Reason
+			continue
+		}
+		if err != nil {
+			return entity.BatchIngestResult{}, err
+		}
+		// Validated after authorization, which fills in a missing owner.
+		if err := location.Validate(); err != nil {
+			result.Invalid[i] = err.Error()
+			continue
+		}
+
+		decision := tx.Apply(ctx, location)
+		if decision.Reason != "" {
+			result.Quarantined[i] = decision.Reason
+			rejected = append(rejected, decision)
+			continue
+		}
+		accepted = append(accepted, decision.Location)
+		if !vehicle.Tracked {
+			firstFix[vehicle.ID] = vehicle
+		}
+	}
+
+	// Accepted and quarantined locations are written together, so a failed
+	// write leaves neither behind.
+	if len(accepted) > 0 || len(rejected) > 0 {
+		inserted, err := s.trackerRepo.InsertLocations(ctx, accepted, s.quarantined(rejected))
+		if err != nil {
+			return entity.BatchIngestResult{}, err
+		}
+		result.Inserted = inserted
+	}
+	tx.Commit()
+
+	for _, vehicle := range firstFix {
+		s.markTracked(ctx, vehicle)
+	}
+
+	for _, location := range accepted {
+		s.rememberLatest(ctx, location)
+		if !location.OutOfOrder {
+			s.notifyListeners(ctx, location)
+		}
+	}
+	return result, nil
+}
+
+func (s *DomainTrackerService) notifyListeners(ctx context.Context, location entity.VehicleLocation) {
+	for _, listener := range s.listeners {
+		listener.OnLocation(ctx, location)
+	}
+}
+
+// GetVehicleLocationHistory returns one page of the ordered track of a vehicle
+// between query.From (inclusive) and query.To (exclusive). With
+// query.Simplify set it returns the whole range simplified, unpaged.
+func (s *DomainTrackerService) GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) (entity.LocationHistoryPage, error) {
+	if !query.From.Before(query.To) {
+		return entity.LocationHistoryPage{}, ErrInvalidTimeRange
+	}
+	if query.Simplify != nil {
+		return s.simplifiedHistory(ctx, query)
+	}
+	if query.MaxPoints <= 0 {
+		query.MaxPoints = DefaultHistoryPoints
+	}
+	if query.MaxPoints > MaxHistoryPoints {
+		query.MaxPoints = MaxHistoryPoints
+	}
+	if query.Cursor != "" {
+		after, err := decodeHistoryCursor(query.Cursor)
+		if err != nil {
+			return entity.LocationHistoryPage{}, err
+		}
+		query.After = &after
+	}
+
+	// Ask for one extra point so we know whether another page exists.
+	pageSize := query.MaxPoints
+	query.MaxPoints++
+	points, err := s.trackerRepo.GetVehicleLocationHistory(ctx, query)
+	if err != nil {
+		return entity.LocationHistoryPage{}, err
+	}
+
+	page := entity.LocationHistoryPage{
+		VehicleID: query.VehicleID,
+		From:      query.From,
+		To:        query.To,
+		Points:    points,
+	}
+	if len(points) > pageSize {
+		page.Points = points[:pageSize]
+		last := page.Points[pageSize-1]
+		page.NextCursor = encodeHistoryCursor(entity.HistoryCursor{Timestamp: last.Timestamp, ID: last.ID})
+	}
+	if page.Points == nil {
+		page.Points = []*entity.VehicleLocation{}
+	}
+	return page, nil
+}
+
+// StreamVehicleTrack passes every point of a vehicle's track in the range to
+// fn, in time order, without paging.
+func (s *DomainTrackerService) StreamVehicleTrack(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error {
+	if !query.From.Before(query.To) {
+		return ErrInvalidTimeRange
+	}
+	return s.trackerRepo.StreamVehicleLocations(ctx, query, fn)
 }
 
-func (s *DomainTrackerService) GetLatestVehicleLocationsByUserID(ctx context.Context, userID string) ([]*entity.VehicleLocation, error) {
-	return s.trackerRepo.GetLatestVehicleLocationsByUserID(ctx, userID)
+func encodeHistoryCursor(c entity.HistoryCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.Timestamp.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string) (entity.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return entity.HistoryCursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return entity.HistoryCursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return entity.HistoryCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return entity.HistoryCursor{}, ErrInvalidCursor
+	}
+	return entity.HistoryCursor{Timestamp: time.Unix(0, nanos).UTC(), ID: id}, nil
 }
@@ -0,0 +1,313 @@
+package service
+
+import (
+	entity "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/geo"
+
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	tripScanPageSize = 5000
+
+	DefaultTripBackfill = 7 * 24 * time.Hour
+)
+
+// GetVehicleTrips returns the persisted trips of a vehicle overlapping the
+// requested window. Trips are written by SegmentTrips, so the most recent
+// one shows up once it has ended and the next segmentation run has seen it.
+func (s *DomainTrackerService) GetVehicleTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error) {
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+	trips, err := s.trackerRepo.GetTrips(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if trips == nil {
+		trips = []*entity.Trip{}
+	}
+	return trips, nil
+}
+
+// tripMarks caches the trip watermark of every vehicle seen by SegmentTrips,
+// so vehicles without new points cost no query.
+type tripMarks struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// SegmentTrips segments the points every vehicle reported since its trip
+// watermark into persisted trips. Vehicles without a watermark are
+// segmented from cfg.Backfill back. It returns the number of trips saved;
+// failures of single vehicles are logged and do not stop the run.
+func (s *DomainTrackerService) SegmentTrips(ctx context.Context) (int, error) {
+	locations, err := s.latestPositions(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	saved := 0
+	for _, location := range locations {
+		if err := ctx.Err(); err != nil {
+			return saved, err
+		}
+		if until, ok := s.tripMarks.get(location.VehicleID); ok && !location.Timestamp.After(until) {
+			continue
+		}
+		n, err := s.segmentTrips(ctx, location.VehicleID, now)
+		if err != nil {
+			s.logger.Errorf("[SegmentTrips] vehicle %s: %v", location.VehicleID, err)
+			continue
+		}
+		saved += n
+	}
+	return saved, nil
+}
+
+// segmentTrips advances the trip watermark of a vehicle up to the settled
+// part of its track and returns the number of trips saved.
+func (s *DomainTrackerService) segmentTrips(ctx context.Context, vehicleID string, now time.Time) (int, error) {
+	// Points arriving shortly after a stop can still extend the last trip, so
+	// never settle the most recent dwell window.
+	to := now.Add(-s.config.Trips.MinDwell)
+
+	wm, found, err := s.trackerRepo.GetTripWatermark(ctx, vehicleID)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		backfill := s.config.Trips.Backfill
+		if backfill <= 0 {
+			backfill = DefaultTripBackfill
+		}
+		wm = entity.TripWatermark{
+			VehicleID:      vehicleID,
+			ProcessedFrom:  to.Add(-backfill),
+			ProcessedUntil: to.Add(-backfill),
+		}
+	}
+	if !wm.ProcessedUntil.Before(to) {
+		s.tripMarks.put(vehicleID, wm.ProcessedUntil)
+		return 0, nil
+	}
+
+	resume, saved, err := s.scanTrips(ctx, vehicleID, wm.ProcessedUntil, to)
+	if err != nil {
+		return 0, err
+	}
+	wm.ProcessedUntil = resume
+	if err := s.trackerRepo.SaveTripWatermark(ctx, wm); err != nil {
+		return 0, err
+	}
+	s.tripMarks.put(vehicleID, resume)
+	return saved, nil
+}
+
+// scanTrips segments the raw points in [from, to), persists the completed
+// trips and returns the timestamp the next scan has to resume from.
+func (s *DomainTrackerService) scanTrips(ctx context.Context, vehicleID string, from, to time.Time) (time.Time, int, error) {
+	var points []*entity.VehicleLocation
+	query := entity.LocationHistoryQuery{
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		MaxPoints: tripScanPageSize,
+	}
+	for {
+		page, err := s.trackerRepo.GetVehicleLocationHistory(ctx, query)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		points = append(points, page...)
+		if len(page) < tripScanPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		query.After = &entity.HistoryCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+
+	trips, resume := DetectTrips(points, s.config.Trips, to)
+	if resume.IsZero() {
+		resume = to
+	}
+	if len(trips) > 0 {
+		if err := s.trackerRepo.SaveTrips(ctx, trips); err != nil {
+			return time.Time{}, 0, err
+		}
+	}
+	s.logger.Debugf("[scanTrips] vehicle %s: %d points, %d trips between %s and %s", vehicleID, len(points), len(trips), from.Format(time.RFC3339), to.Format(time.RFC3339))
+	return resume, len(trips), nil
+}
+
+func (m *tripMarks) get(vehicleID string) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.until[vehicleID]
+	return until, ok
+}
+
+func (m *tripMarks) put(vehicleID string, until time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.until == nil {
+		m.until = make(map[string]time.Time)
+	}
+	m.until[vehicleID] = until
+}
+
+// DetectTrips splits an ascending track into trips. A trip starts at the last
+// stationary point before movement and ends at the first point of a stop (or
+// before a reporting gap) lasting at least cfg.MinDwell. until is where the
+// track ends: a trip whose last point is at least cfg.MinDwell before it
+// ended with the device going silent. A zero until leaves such trips open.
+// The second return value is where an unfinished trip starts, or the last
+// point when the vehicle is parked, so segmentation can resume from there.
+func DetectTrips(points []*entity.VehicleLocation, cfg entity.TripDetectionConfig, until time.Time) ([]*entity.Trip, time.Time) {
+	var (
+		trips     []*entity.Trip
+		current   *tripBuilder
+		lastStill *entity.VehicleLocation
+		stopStart *entity.VehicleLocation
+	)
+
+	closeTrip := func(end *entity.VehicleLocation) {
+		if trip := current.finish(end); trip.DistanceKm >= cfg.MinDistanceKm {
+			trips = append(trips, trip)
+		}
+		current = nil
+	}
+
+	for i, p := range points {
+		var prev *entity.VehicleLocation
+		if i > 0 {
+			prev = points[i-1]
+		}
+
+		if current != nil && prev != nil && p.Timestamp.Sub(prev.Timestamp) >= cfg.MinDwell {
+			// The device went silent long enough to count as a stop.
+			closeTrip(prev)
+			stopStart = nil
+		}
+
+		moving := pointSpeed(prev, p) > cfg.StopSpeed
+		switch {
+		case current == nil && moving:
+			start := p
+			if lastStill != nil && prev == lastStill {
+				start = lastStill
+			}
+			current = newTripBuilder(start)
+			if start != p {
+				current.add(p)
+			}
+			stopStart = nil
+		case current != nil && moving:
+			current.add(p)
+			stopStart = nil
+		case current != nil && !moving:
+			current.add(p)
+			if stopStart == nil {
+				stopStart = p
+			}
+			if p.Timestamp.Sub(stopStart.Timestamp) >= cfg.MinDwell {
+				current.truncate(stopStart)
+				closeTrip(stopStart)
+				stopStart = nil
+			}
+		}
+		if !moving {
+			lastStill = p
+		}
+	}
+
+	if current != nil {
+		last := points[len(points)-1]
+		if until.IsZero() || until.Sub(last.Timestamp) < cfg.MinDwell {
+			return trips, current.start.Timestamp
+		}
+		if stopStart != nil {
+			current.truncate(stopStart)
+			closeTrip(stopStart)
+		} else {
+			closeTrip(last)
+		}
+	}
+	if len(points) > 0 {
+		return trips, points[len(points)-1].Timestamp
+	}
+	return trips, time.Time{}
+}
+
+// pointSpeed prefers the device-reported speed and falls back to the speed
+// implied by the distance from the previous fix.
+func pointSpeed(prev, p *entity.VehicleLocation) float64 {
+	if p.Speed > 0 || prev == nil {
+		return p.Speed
+	}
+	elapsed := p.Timestamp.Sub(prev.Timestamp).Hours()
+	if elapsed <= 0 {
+		return 0
+	}
+	return geo.HaversineMeters(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude) / 1000 / elapsed
+}
+
+type tripBuilder struct {
+	start  *entity.VehicleLocation
+	points []*entity.VehicleLocation
+}
+
+func newTripBuilder(start *entity.VehicleLocation) *tripBuilder {
+	return &tripBuilder{start: start, points: []*entity.VehicleLocation{start}}
+}
+
+func (b *tripBuilder) add(p *entity.VehicleLocation) {
+	b.points = append(b.points, p)
+}
+
+// truncate drops the points recorded after end, i.e. the tail of a stop.
+func (b *tripBuilder) truncate(end *entity.VehicleLocation) {
+	for i, p := range b.points {
+		if p == end {
+			b.points = b.points[:i+1]
+			return
+		}
+	}
+}
+
+func (b *tripBuilder) finish(end *entity.VehicleLocation) *entity.Trip {
+	trip := &entity.Trip{
+		OwnerID:        b.start.OwnerID,
+		VehicleID:      b.start.VehicleID,
+		StartTime:      b.start.Timestamp,
+		EndTime:        end.Timestamp,
+		StartLatitude:  b.start.Latitude,
+		StartLongitude: b.start.Longitude,
+		EndLatitude:    end.Latitude,
+		EndLongitude:   end.Longitude,
+		PointCount:     len(b.points),
+	}
+
+	var meters float64
+	for i, p := range b.points {
+		var prev *entity.VehicleLocation
+		if i > 0 {
+			prev = b.points[i-1]
+			meters += geo.HaversineMeters(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude)
+		}
+		if speed := pointSpeed(prev, p); speed > trip.MaxSpeed {
+			trip.MaxSpeed = speed
+		}
+	}
+	trip.DistanceKm = meters / 1000
+
+	duration := trip.EndTime.Sub(trip.StartTime)
+	trip.DurationSeconds = int64(duration.Seconds())
+	if duration > 0 {
+		trip.AvgSpeed = trip.DistanceKm / duration.Hours()
+	}
+	return trip
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	entity "FMTS/internal/tracking/domain/entity"
+)
+
+var testTripConfig = entity.TripDetectionConfig{
+	StopSpeed:     5,
+	MinDwell:      5 * time.Minute,
+	MinDistanceKm: 0.2,
+	Backfill:      24 * time.Hour,
+}
+
+// trackBuilder records a track of vehicle v1 with a fix every 30 seconds.
+type trackBuilder struct {
+	seconds int
+	north   float64
+	points  []*entity.VehicleLocation
+}
+
+func newTrack() *trackBuilder {
+	b := &trackBuilder{}
+	b.fix()
+	return b
+}
+
+func (b *trackBuilder) fix() {
+	loc := fixAt(b.seconds, b.north, 0)
+	loc.ID = int64(len(b.points) + 1)
+	b.points = append(b.points, &loc)
+}
+
+// drive moves 300 m north per fix (36 km/h).
+func (b *trackBuilder) drive(minutes int) *trackBuilder {
+	for i := 0; i < minutes*2; i++ {
+		b.seconds += 30
+		b.north += 300
+		b.fix()
+	}
+	return b
+}
+
+func (b *trackBuilder) park(minutes int) *trackBuilder {
+	for i := 0; i < minutes*2; i++ {
+		b.seconds += 30
+		b.fix()
+	}
+	return b
+}
+
+func (b *trackBuilder) silent(minutes int) *trackBuilder {
+	b.seconds += minutes * 60
+	return b
+}
+
+func (b *trackBuilder) end() time.Time {
+	return b.points[len(b.points)-1].Timestamp
+}
+
+func at(seconds int) time.Time {
+	return testStart.Add(time.Duration(seconds) * time.Second)
+}
+
+func TestDetectTrips(t *testing.T) {
+	type span struct {
+		start, end int
+		km         float64
+	}
+	tests := []struct {
+		name   string
+		track  *trackBuilder
+		closed bool // the track ended long enough ago to close the last trip
+		want   []span
+		resume int
+	}{
+		{
+			name:   "stop longer than the dwell time splits",
+			track:  newTrack().drive(10).park(6).drive(10).park(6),
+			want:   []span{{0, 630, 6}, {960, 1590, 6}},
+			resume: 1920,
+		},
+		{
+			name:   "stop shorter than the dwell time does not split",
+			track:  newTrack().drive(10).park(3).drive(10).park(6),
+			want:   []span{{0, 1410, 12}},
+			resume: 1740,
+		},
+		{
+			name:   "reporting gap splits",
+			track:  newTrack().drive(10).silent(10).drive(10).park(6),
+			want:   []span{{0, 600, 6}, {1230, 1830, 5.7}},
+			resume: 2160,
+		},
+		{
+			name:   "trip below the minimum distance is dropped",
+			track:  newTrack().drive(10).park(6).drive(0).park(1).drive(0),
+			want:   []span{{0, 630, 6}},
+			resume: 1020,
+		},
+		{
+			name:   "unfinished trip resumes at its start",
+			track:  newTrack().drive(10).park(6).drive(5),
+			want:   []span{{0, 630, 6}},
+			resume: 960,
+		},
+		{
+			name:   "silence after the track closes the last trip",
+			track:  newTrack().drive(10).park(6).drive(5),
+			closed: true,
+			want:   []span{{0, 630, 6}, {960, 1260, 3}},
+			resume: 1260,
+		},
+		{
+			name:   "silence keeps a short stop at the end out of the trip",
+			track:  newTrack().drive(10).park(2),
+			closed: true,
+			want:   []span{{0, 630, 6}},
+			resume: 720,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var until time.Time
+			if tt.closed {
+				until = tt.track.end().Add(testTripConfig.MinDwell)
+			}
+			trips, resume := DetectTrips(tt.track.points, testTripConfig, until)
+			if len(trips) != len(tt.want) {
+				t.Fatalf("got %d trips, want %d", len(trips), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := trips[i]
+				if !got.StartTime.Equal(at(want.start)) || !got.EndTime.Equal(at(want.end)) {
+					t.Errorf("trip %d: %s - %s, want %s - %s", i, got.StartTime, got.EndTime, at(want.start), at(want.end))
+				}
+				if math.Abs(got.DistanceKm-want.km) > 0.05 {
+					t.Errorf("trip %d: %.2f km, want %.1f", i, got.DistanceKm, want.km)
+				}
+			}
+			if !resume.Equal(at(tt.resume)) {
+				t.Errorf("resume = %s, want %s", resume, at(tt.resume))
+			}
+		})
+	}
+}
+
+// tripRepo serves a stored track and keeps trips and the watermark in
+// memory.
+type tripRepo struct {
+	fakeTrackerRepo
+
+	watermark      *entity.TripWatermark
+	watermarkReads int
+	trips          map[time.Time]*entity.Trip
+	scans          []entity.LocationHistoryQuery
+}
+
+func (r *tripRepo) GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) ([]*entity.VehicleLocation, error) {
+	if query.After == nil {
+		r.scans = append(r.scans, query)
+	}
+	var page []*entity.VehicleLocation
+	for i := range r.stored {
+		p := &r.stored[i]
+		if p.Timestamp.Before(query.From) || !p.Timestamp.Before(query.To) {
+			continue
+		}
+		if query.After != nil && !p.Timestamp.After(query.After.Timestamp) {
+			continue
+		}
+		page = append(page, p)
+		if len(page) == query.MaxPoints {
+			break
+		}
+	}
+	return page, nil
+}
+
+func (r *tripRepo) GetLatestVehicleLocations(ctx context.Context) ([]*entity.VehicleLocation, error) {
+	latest := r.stored[len(r.stored)-1]
+	return []*entity.VehicleLocation{&latest}, nil
+}
+
+func (r *tripRepo) GetTripWatermark(ctx context.Context, vehicleID string) (entity.TripWatermark, bool, error) {
+	r.watermarkReads++
+	if r.watermark == nil {
+		return entity.TripWatermark{}, false, nil
+	}
+	return *r.watermark, true, nil
+}
+
+func (r *tripRepo) SaveTripWatermark(ctx context.Context, wm entity.TripWatermark) error {
+	r.watermark = &wm
+	return nil
+}
+
+func (r *tripRepo) SaveTrips(ctx context.Context, trips []*entity.Trip) error {
+	for _, trip := range trips {
+		r.trips[trip.StartTime] = trip
+	}
+	return nil
+}
+
+func (r *tripRepo) GetTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error) {
+	var trips []*entity.Trip
+	for _, trip := range r.trips {
+		trips = append(trips, trip)
+	}
+	return trips, nil
+}
+
+func (r *tripRepo) store(points []*entity.VehicleLocation) {
+	r.stored = r.stored[:0]
+	for _, p := range points {
+		r.stored = append(r.stored, *p)
+	}
+}
+
+func TestSegmentTripsResumesFromWatermark(t *testing.T) {
+	store := &tripRepo{trips: make(map[time.Time]*entity.Trip)}
+	s := newTestTracker(store, entity.TrackerConfig{Trips: testTripConfig})
+	ctx := context.Background()
+
+	// The vehicle is on its second trip when the first run settles the
+	// track up to five minutes ago.
+	track := newTrack().drive(10).park(6).drive(5)
+	store.store(track.points)
+	saved, err := s.segmentTrips(ctx, "v1", track.end().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 1 {
+		t.Fatalf("first run saved %d trips, want 1", saved)
+	}
+	if !store.scans[0].From.Equal(track.end().Add(time.Minute - testTripConfig.MinDwell - testTripConfig.Backfill)) {
+		t.Fatalf("first scan from %s, want the backfill start", store.scans[0].From)
+	}
+	if !store.watermark.ProcessedUntil.Equal(at(960)) {
+		t.Fatalf("watermark at %s, want the start of the open trip %s", store.watermark.ProcessedUntil, at(960))
+	}
+
+	// The trip ends; the next run only reads from the open trip on.
+	track.drive(5).park(10)
+	store.store(track.points)
+	saved, err = s.segmentTrips(ctx, "v1", track.end().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 1 || len(store.trips) != 2 {
+		t.Fatalf("second run saved %d trips, %d stored; want 1 and 2", saved, len(store.trips))
+	}
+	if !store.scans[1].From.Equal(at(960)) {
+		t.Fatalf("second scan from %s, want %s", store.scans[1].From, at(960))
+	}
+	if trip := store.trips[at(960)]; trip == nil || !trip.EndTime.Equal(at(1590)) {
+		t.Fatalf("second trip = %+v, want it to end at %s", trip, at(1590))
+	}
+
+	// Without new points a run does not even read the watermark.
+	reads := store.watermarkReads
+	if _, err := s.SegmentTrips(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.watermarkReads != reads || len(store.scans) != 2 {
+		t.Fatalf("idle run read the watermark %d times and scanned %d times, want neither", store.watermarkReads-reads, len(store.scans)-2)
+	}
+
+	// Reading trips never segments.
+	if _, err := s.GetVehicleTrips(ctx, entity.TripQuery{VehicleID: "v1", From: at(0), To: track.end()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.scans) != 2 {
+		t.Fatal("reading trips scanned the track")
+	}
+}
@@ -4,7 +4,30 @@ import "net/http"
 
 type TrackerPortHandler interface {
 	UpdateLocation(w http.ResponseWriter, r *http.Request)
+	UpdateLocationsBatch(w http.ResponseWriter, r *http.Request)
 	GetLetestViecleByViecleID(w http.ResponseWriter, r *http.Request)
 	GetLetestLocationsOfViecleByUserID(w http.ResponseWriter, r *http.Request)
+	GetVehicleLocationHistory(w http.ResponseWriter, r *http.Request)
+	GetVehicleTrips(w http.ResponseWriter, r *http.Request)
+	GetVehicleDistance(w http.ResponseWriter, r *http.Request)
+	ExportVehicleTrack(w http.ResponseWriter, r *http.Request)
+	GetQuarantinedLocations(w http.ResponseWriter, r *http.Request)
+	FindNearbyVehicles(w http.ResponseWriter, r *http.Request)
+	GetHeatmap(w http.ResponseWriter, r *http.Request)
+	RunRetention(w http.ResponseWriter, r *http.Request)
+	ListRetentionReports(w http.ResponseWriter, r *http.Request)
+	GetVehicleConnectivityTransitions(w http.ResponseWriter, r *http.Request)
+	GetConnectivityThresholds(w http.ResponseWriter, r *http.Request)
+	SetConnectivityThresholds(w http.ResponseWriter, r *http.Request)
+	GetVehicleStops(w http.ResponseWriter, r *http.Request)
+	GetGeofenceVisits(w http.ResponseWriter, r *http.Request)
+	GetFuelReport(w http.ResponseWriter, r *http.Request)
+	GetFuelEvents(w http.ResponseWriter, r *http.Request)
+	CreateShareLink(w http.ResponseWriter, r *http.Request)
+	ListShareLinks(w http.ResponseWriter, r *http.Request)
+	RevokeShareLink(w http.ResponseWriter, r *http.Request)
+	GetShareAccesses(w http.ResponseWriter, r *http.Request)
+	ViewSharedLocation(w http.ResponseWriter, r *http.Request)
+	StreamLocations(w http.ResponseWriter, r *http.Request)
 	// GetLetestLocationsOfViecleByUserIDFromParam(w http.ResponseWriter, r *http.Request)
 }
@@ -4,10 +4,55 @@ import (
 	entity "FMTS/internal/tracking/domain/entity"
 	// "FMTS/utils"
 	"context"
+	"time"
 )
 
 type TimescaleTrackerRepo interface {
 	UpdateLocation(ctx context.Context, location entity.VehicleLocation) (entity.VehicleLocation, error)
+	InsertLocations(ctx context.Context, locations []entity.VehicleLocation, quarantined []entity.QuarantinedLocation) (int64, error)
 	GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (entity.VehicleLocation, error)
 	GetLatestVehicleLocationsByUserID(ctx context.Context, userID string) ([]*entity.VehicleLocation, error)
+	GetLatestVehicleLocations(ctx context.Context) ([]*entity.VehicleLocation, error)
+	GetVehicleLocationHistory(ctx context.Context, query entity.LocationHistoryQuery) ([]*entity.VehicleLocation, error)
+	StreamVehicleLocations(ctx context.Context, query entity.LocationRangeQuery, fn func(entity.VehicleLocation) error) error
+	AggregateGrid(ctx context.Context, query entity.GridQuery) ([]entity.GridCell, int64, error)
+	SaveTrips(ctx context.Context, trips []*entity.Trip) error
+	GetTrips(ctx context.Context, query entity.TripQuery) ([]*entity.Trip, error)
+	GetTripWatermark(ctx context.Context, vehicleID string) (entity.TripWatermark, bool, error)
+	SaveTripWatermark(ctx context.Context, watermark entity.TripWatermark) error
+	GetOdometer(ctx context.Context, vehicleID string) (entity.Odometer, bool, error)
+	AdvanceOdometer(ctx context.Context, next entity.Odometer, previous *time.Time, distance entity.DailyDistance) (bool, error)
+	GetDailyDistance(ctx context.Context, query entity.DistanceQuery) ([]entity.DistanceBucket, error)
+	QuarantineLocations(ctx context.Context, locations []entity.QuarantinedLocation) (int64, error)
+	GetQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) ([]*entity.QuarantinedLocation, error)
+	CountQuarantinedLocations(ctx context.Context, query entity.QuarantineQuery) (map[entity.QuarantineReason]int64, error)
+	AcquireRetentionLock(ctx context.Context) (release func(), acquired bool, err error)
+	OldestLocation(ctx context.Context) (time.Time, bool, error)
+	SummarisedUntil(ctx context.Context) (time.Time, bool, error)
+	DownsampleLocations(ctx context.Context, from, to time.Time, dryRun bool) (summaries int64, points int64, err error)
+	SummariseLateLocations(ctx context.Context, from, to time.Time, dryRun bool) (summaries int64, points int64, err error)
+	DeleteLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error)
+	DeleteSummarisedLocations(ctx context.Context, from, to time.Time, dryRun bool) (int64, error)
+	SaveRetentionReport(ctx context.Context, report *entity.RetentionReport) error
+	GetRetentionReports(ctx context.Context, limit int) ([]*entity.RetentionReport, error)
+	GetConnectivityStates(ctx context.Context) ([]entity.VehicleConnectivity, error)
+	SaveConnectivityTransition(ctx context.Context, transition entity.ConnectivityTransition) (bool, error)
+	GetConnectivityTransitions(ctx context.Context, q entity.ConnectivityQuery) ([]*entity.ConnectivityTransition, error)
+	GetConnectivityThresholds(ctx context.Context) ([]entity.ConnectivityThresholds, error)
+	SaveConnectivityThresholds(ctx context.Context, thresholds entity.ConnectivityThresholds) error
+	GetStopState(ctx context.Context, vehicleID string) (entity.StopState, bool, error)
+	AdvanceStop(ctx context.Context, next entity.StopState, previous *time.Time, stop *entity.Stop) (bool, error)
+	GetStops(ctx context.Context, q entity.StopQuery) ([]*entity.Stop, error)
+	GetGeofenceVisits(ctx context.Context, q entity.GeofenceVisitQuery) ([]entity.GeofenceVisits, error)
+	GetFuelState(ctx context.Context, vehicleID string) (entity.FuelState, bool, error)
+	AdvanceFuel(ctx context.Context, next entity.FuelState, previous *time.Time, day entity.FuelDay, event *entity.FuelEvent) (bool, error)
+	GetFuelDays(ctx context.Context, q entity.FuelReportQuery) ([]entity.FuelDay, error)
+	GetFuelEvents(ctx context.Context, q entity.FuelEventQuery) ([]*entity.FuelEvent, error)
+	SaveShareLink(ctx context.Context, link entity.ShareLink) (entity.ShareLink, error)
+	GetShareLink(ctx context.Context, id int64) (entity.ShareLink, bool, error)
+	GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (entity.ShareLink, bool, error)
+	GetShareLinks(ctx context.Context, vehicleID, ownerID string) ([]*entity.ShareLink, error)
+	RevokeShareLink(ctx context.Context, id int64, at time.Time) (bool, error)
+	SaveShareAccess(ctx context.Context, access entity.ShareAccess) error
+	GetShareAccesses(ctx context.Context, shareID int64, limit int) ([]*entity.ShareAccess, error)
 }
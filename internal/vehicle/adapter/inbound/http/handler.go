@@ -31,6 +31,15 @@ func InitVehicleRoutes(router chi.Router, vehicleHandler inbound.VehiclePortInte
 				// 	// authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
 				// },
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/{id}/odometer",
+				Handler: vehicleHandler.GetVehicleOdometer,
+				Middlewares: []func(http.Handler) http.Handler{
+					authMiddleware.AuthenticateToken,
+					authMiddleware.AccessControl([]string{"ADMIN", "FLEET_MANAGER", "USER"}),
+				},
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/",
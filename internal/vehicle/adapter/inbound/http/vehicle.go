@@ -2,8 +2,10 @@ package vehicle_handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
@@ -135,3 +137,24 @@ func (h *VehicleHandler) DeleteVehicle(w http.ResponseWriter, r *http.Request) {
 	}
 	utility.WriteSuccessResponse(w, fmt.Sprintf("Vehicle ID: %s deleted successfully", id), "Vehicle deleted successfully")
 }
+
+func (h *VehicleHandler) GetVehicleOdometer(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	userInfo := contexts.ExtractUserContext(r)
+	ownerID := userInfo.UserID
+	if strings.EqualFold(userInfo.UserRole, "ADMIN") {
+		ownerID = ""
+	}
+
+	odometer, err := h.vehicleService.GetVehicleOdometer(id, ownerID)
+	if err != nil {
+		h.logger.Errorf("[GetVehicleOdometer] error: %v", err)
+		status := http.StatusNotFound
+		if errors.Is(err, dto.ErrVehicleForbidden) {
+			status = http.StatusForbidden
+		}
+		utility.SendErrorResponse(w, err.Error(), status, nil)
+		return
+	}
+	utility.WriteSuccessResponse(w, odometer, "Vehicle odometer fetched successfully")
+}
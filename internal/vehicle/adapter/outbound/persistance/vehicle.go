@@ -49,6 +49,22 @@ func (v *VehiclePersistence) FindByPlateNumber(plate string) (*model.Vehicle, er
 	return vehicle, nil
 }
 
+func (v *VehiclePersistence) FindByDeviceIMEI(imei string) (*model.Vehicle, error) {
+	filter := bson.M{"device_imei": imei, "is_deleted": false}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vehicle, err := v.vehicleDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		v.logger.Errorf("[FindByDeviceIMEI] DB error: %v", err)
+		return nil, err
+	}
+	return vehicle, nil
+}
+
 func (v *VehiclePersistence) CreateVehicle(vehicle model.Vehicle) (*model.Vehicle, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -69,6 +85,41 @@ func (v *VehiclePersistence) FindByID(id string) (*model.Vehicle, error) {
 	return v.vehicleDal.FindOne(ctx, filter, nil)
 }
 
+// FindByIDIncludingDeleted also returns soft-deleted vehicles. It returns
+// nil when no vehicle has the id.
+func (v *VehiclePersistence) FindByIDIncludingDeleted(id string) (*model.Vehicle, error) {
+	filter := bson.M{"_id": id}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	vehicle, err := v.vehicleDal.FindOne(ctx, filter, nil)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		v.logger.Errorf("[FindByIDIncludingDeleted] DB error: %v", err)
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// MarkTracked flags a vehicle as currently tracked. Vehicles that are
+// already tracked are left untouched.
+func (v *VehiclePersistence) MarkTracked(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "is_deleted": false, "currently_tracked": false}
+	update := bson.M{"currently_tracked": true, "not_tracked_reason": "", "updated_at": time.Now()}
+
+	_, err := v.vehicleDal.UpdateOne(ctx, filter, update)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		v.logger.Errorf("[MarkTracked] update error: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (v *VehiclePersistence) FindAllVehicles(User_ID string) ([]*model.Vehicle, error) {
 	filter := bson.M{"is_deleted": false}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -163,6 +214,11 @@ func (v *VehiclePersistence) UpdateVehicle(vehicle model.Vehicle) (model.Vehicle
 	if vehicle.ImageURL != "" {
 		update["image_url"] = vehicle.ImageURL
 	}
+	if vehicle.DeviceIMEI != "" {
+		update["device_imei"] = vehicle.DeviceIMEI
+	}
+	// 0 is meaningful here: it resets the vehicle to its type's default.
+	update["speed_limit"] = vehicle.SpeedLimit
 
 	// ✅ always update UpdatedAt
 	update["updated_at"] = vehicle.UpdatedAt
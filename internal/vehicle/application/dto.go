@@ -18,6 +18,8 @@ type CreateVehicleRequest struct {
 	DriverName   string      `json:"driver_name,omitempty"`
 	DriverPhone  string      `json:"driver_phone,omitempty"`
 	ImageURL     string      `json:"image_url,omitempty"`
+	DeviceIMEI   string      `json:"device_imei,omitempty"`
+	SpeedLimit   float64     `json:"speed_limit,omitempty"`
 }
 
 // Assume you have OwnerType and VehicleType as string aliases or custom types,
@@ -38,6 +40,8 @@ func (r CreateVehicleRequest) Validate() error {
 		validation.Field(&r.DriverName, validation.NilOrNotEmpty, validation.Length(0, 100)),
 		validation.Field(&r.DriverPhone, validation.NilOrNotEmpty, is.E164),
 		validation.Field(&r.ImageURL, validation.NilOrNotEmpty, is.URL),
+		validation.Field(&r.DeviceIMEI, validation.NilOrNotEmpty, is.Digit, validation.Length(15, 15)),
+		validation.Field(&r.SpeedLimit, validation.Min(0.0), validation.Max(300.0)),
 	)
 }
 
@@ -73,6 +77,8 @@ type UpdateVehicleRequest struct {
 	DriverName   *string      `json:"driver_name,omitempty"`
 	DriverPhone  *string      `json:"driver_phone,omitempty"`
 	ImageURL     *string      `json:"image_url,omitempty"`
+	DeviceIMEI   *string      `json:"device_imei,omitempty"`
+	SpeedLimit   *float64     `json:"speed_limit,omitempty"`
 	// CurrentlyTracked *bool        `json:"currently_tracked,omitempty"`
 	// IsDisabled       *bool        `json:"is_disabled,omitempty"`
 	// DisabledReason   *string      `json:"disabled_reason,omitempty"`
@@ -106,6 +112,8 @@ func (r UpdateVehicleRequest) Validate() error {
 		validation.Field(&r.DriverName, validation.When(r.DriverName != nil, validation.Length(1, 50))),
 		validation.Field(&r.DriverPhone, validation.When(r.DriverPhone != nil, is.E164)),
 		validation.Field(&r.ImageURL, validation.When(r.ImageURL != nil, is.URL)),
+		validation.Field(&r.DeviceIMEI, validation.When(r.DeviceIMEI != nil, is.Digit, validation.Length(15, 15))),
+		validation.Field(&r.SpeedLimit, validation.When(r.SpeedLimit != nil, validation.Min(0.0), validation.Max(300.0))),
 
 	// validation.Field(&r.DisabledReason,
 	// 	validation.When(r.IsDisabled != nil && *r.IsDisabled, validation.Required.Error("disabled_reason is required when vehicle is disabled")),
@@ -6,6 +6,7 @@ import (
 
 	model "FMTS/internal/vehicle/domain/entity"
 	domain "FMTS/internal/vehicle/domain/service"
+	outbound "FMTS/internal/vehicle/port/outbound"
 	"FMTS/pkg/utils"
 )
 
@@ -16,18 +17,31 @@ type VehicleService interface {
 	ListVehicles(user_id string) ([]*model.Vehicle, error)
 	UpdateVehicle(id string, req UpdateVehicleRequest) (*model.Vehicle, error)
 	DeleteVehicle(id string) (model.Vehicle, error)
+	GetVehicleOdometer(id, ownerID string) (*model.Odometer, error)
 }
 
+var (
+	ErrVehicleForbidden = errors.New("vehicle belongs to another owner")
+	ErrDeviceInstalled  = errors.New("device is already installed in another vehicle")
+	ErrDeviceRegistered = errors.New("a registered device has this IMEI; bind the device to the vehicle instead")
+)
+
 type vehicleServiceImpl struct {
-	domain domain.VehicleService
-	logger utils.Logger
+	domain       domain.VehicleService
+	odometers    outbound.OdometerReader
+	connectivity outbound.ConnectivityReader
+	devices      outbound.DeviceLookup
+	logger       utils.Logger
 }
 
 // Constructor
-func NewVehicleService(domain domain.VehicleService, logger utils.Logger) VehicleService {
+func NewVehicleService(domain domain.VehicleService, odometers outbound.OdometerReader, connectivity outbound.ConnectivityReader, devices outbound.DeviceLookup, logger utils.Logger) VehicleService {
 	return &vehicleServiceImpl{
-		domain: domain,
-		logger: logger,
+		domain:       domain,
+		odometers:    odometers,
+		connectivity: connectivity,
+		devices:      devices,
+		logger:       logger,
 	}
 }
 
@@ -44,6 +58,11 @@ func (s *vehicleServiceImpl) RegisterVehicle(req CreateVehicleRequest, createdBy
 	if existing != nil {
 		return nil, errors.New("vehicle already registered with this plate number")
 	}
+	if req.DeviceIMEI != "" {
+		if err := s.checkIMEIFree(req.DeviceIMEI, ""); err != nil {
+			return nil, err
+		}
+	}
 
 	// 3. Map request to entity
 	vehicle := model.Vehicle{
@@ -58,6 +77,8 @@ func (s *vehicleServiceImpl) RegisterVehicle(req CreateVehicleRequest, createdBy
 		DriverName:       req.DriverName,
 		DriverPhone:      req.DriverPhone,
 		ImageURL:         req.ImageURL,
+		DeviceIMEI:       req.DeviceIMEI,
+		SpeedLimit:       req.SpeedLimit,
 		CurrentlyTracked: false,
 		IsDeleted:        false,
 		IsDisabled:       false,
@@ -86,15 +107,37 @@ func (s *vehicleServiceImpl) GetVehicleByID(id string) (*model.Vehicle, error) {
 }
 
 // ListVehicles returns all vehicles (pagination/filter can be added later)
+// with their connectivity status.
 func (s *vehicleServiceImpl) ListVehicles(user_ID string) ([]*model.Vehicle, error) {
 	vehicles, err := s.domain.FindAll(user_ID)
 	if err != nil {
 		s.logger.Errorf("[ListVehicles] error: %v", err)
 		return nil, err
 	}
+	s.attachConnectivity(user_ID, vehicles)
 	return vehicles, nil
 }
 
+// attachConnectivity fills in the connectivity status of each vehicle. The
+// list is still returned without it when the status cannot be read.
+func (s *vehicleServiceImpl) attachConnectivity(ownerID string, vehicles []*model.Vehicle) {
+	if s.connectivity == nil || len(vehicles) == 0 {
+		return
+	}
+	statuses, err := s.connectivity.GetConnectivity(ownerID)
+	if err != nil {
+		s.logger.Errorf("[ListVehicles] connectivity of owner %s: %v", ownerID, err)
+		return
+	}
+	for _, vehicle := range vehicles {
+		connectivity, ok := statuses[vehicle.ID]
+		if !ok {
+			connectivity = model.Connectivity{Status: model.ConnectivityUnknown}
+		}
+		vehicle.Connectivity = &connectivity
+	}
+}
+
 // UpdateVehicle updates allowed fields for a vehicle
 func (s *vehicleServiceImpl) UpdateVehicle(id string, req UpdateVehicleRequest) (*model.Vehicle, error) {
 	// if err := req.Validate(); err != nil {
@@ -140,6 +183,18 @@ func (s *vehicleServiceImpl) UpdateVehicle(id string, req UpdateVehicleRequest)
 	if req.ImageURL != nil {
 		vehicle.ImageURL = *req.ImageURL
 	}
+	if req.DeviceIMEI != nil && *req.DeviceIMEI != vehicle.DeviceIMEI {
+		if err := s.checkIMEIFree(*req.DeviceIMEI, vehicle.ID); err != nil {
+			return nil, err
+		}
+		vehicle.DeviceIMEI = *req.DeviceIMEI
+	}
+	if req.SpeedLimit != nil {
+		if *req.SpeedLimit < 0 || *req.SpeedLimit > 300 {
+			return nil, errors.New("speed_limit must be between 0 and 300 km/h")
+		}
+		vehicle.SpeedLimit = *req.SpeedLimit
+	}
 
 	vehicle.UpdatedAt = time.Now()
 
@@ -163,3 +218,51 @@ func (s *vehicleServiceImpl) DeleteVehicle(id string) (model.Vehicle, error) {
 
 	return s.domain.UpdateVehicle(*vehicle)
 }
+
+// GetVehicleOdometer returns the odometer of a vehicle the caller owns.
+// ownerID is empty for admins, who may read any vehicle.
+func (s *vehicleServiceImpl) GetVehicleOdometer(id, ownerID string) (*model.Odometer, error) {
+	vehicle, err := s.domain.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && vehicle.OwnerID != ownerID {
+		return nil, ErrVehicleForbidden
+	}
+
+	odometer, err := s.odometers.GetOdometer(vehicle.ID)
+	if err != nil {
+		s.logger.Errorf("[GetVehicleOdometer] failed: %v", err)
+		return nil, err
+	}
+	return &odometer, nil
+}
+
+// checkIMEIFree makes sure no vehicle other than vehicleID stores the IMEI
+// and the device registered with it, if any, is bound to vehicleID. vehicleID
+// is empty for a vehicle that is not saved yet.
+func (s *vehicleServiceImpl) checkIMEIFree(imei, vehicleID string) error {
+	installed, err := s.domain.FindByDeviceIMEI(imei)
+	if err != nil {
+		return err
+	}
+	if installed != nil && installed.ID != vehicleID {
+		return ErrDeviceInstalled
+	}
+
+	if s.devices == nil {
+		return nil
+	}
+	boundTo, found, err := s.devices.DeviceWithIMEI(imei)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !found, vehicleID != "" && boundTo == vehicleID:
+		return nil
+	case boundTo != "":
+		return ErrDeviceInstalled
+	default:
+		return ErrDeviceRegistered
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	// "errors"
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,8 @@ type Vehicle struct {
 	DriverName       string      `bson:"driver_name,omitempty" json:"driver_name,omitempty"`
 	DriverPhone      string      `bson:"driver_phone,omitempty" json:"driver_phone,omitempty"`
 	ImageURL         string      `bson:"image_url,omitempty" json:"image_url,omitempty"`
+	DeviceIMEI       string      `bson:"device_imei,omitempty" json:"device_imei,omitempty"`
+	SpeedLimit       float64     `bson:"speed_limit,omitempty" json:"speed_limit,omitempty"` // km/h, 0 uses the VehicleType default
 	CurrentlyTracked bool        `bson:"currently_tracked" json:"currently_tracked"`
 	IsDeleted        bool        `bson:"is_deleted" json:"is_deleted"`
 	IsDisabled       bool        `bson:"is_disabled" json:"is_disabled"`
@@ -25,6 +28,10 @@ type Vehicle struct {
 	NotTrackedReason string      `bson:"not_tracked_reason,omitempty" json:"not_tracked_reason,omitempty"`
 	CreatedAt        time.Time   `bson:"created_at" json:"created_at"`
 	UpdatedAt        time.Time   `bson:"updated_at" json:"updated_at"`
+
+	// Connectivity is filled in from the tracking module when vehicles are
+	// listed; it is not stored with the vehicle.
+	Connectivity *Connectivity `bson:"-" json:"connectivity,omitempty"`
 }
 
 // OwnerType custom string type with predefined values
@@ -64,3 +71,47 @@ const (
 // 	}
 // 	return errors.New("invalid VehicleType")
 // }
+
+// DefaultSpeedLimits are the limits in km/h applied to vehicles that have no
+// SpeedLimit of their own.
+var DefaultSpeedLimits = map[VehicleType]float64{
+	VehicleTypeSedan:      120,
+	VehicleTypeSUV:        120,
+	VehicleTypeVan:        100,
+	VehicleTypeMotorcycle: 100,
+	VehicleTypeTruck:      80,
+	VehicleTypeBus:        80,
+}
+
+// FallbackSpeedLimit is used for vehicle types without a default.
+const FallbackSpeedLimit = 100.0
+
+// EffectiveSpeedLimit returns the vehicle's own speed limit, or the default
+// for its type.
+func (v Vehicle) EffectiveSpeedLimit() float64 {
+	if v.SpeedLimit > 0 {
+		return v.SpeedLimit
+	}
+	if limit, ok := DefaultSpeedLimits[VehicleType(strings.ToLower(string(v.VehicleType)))]; ok {
+		return limit
+	}
+	return FallbackSpeedLimit
+}
+
+// Odometer is the distance a vehicle has covered since it was first tracked.
+type Odometer struct {
+	VehicleID string     `json:"vehicle_id"`
+	TotalKm   float64    `json:"total_km"`
+	LastFixAt *time.Time `json:"last_fix_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ConnectivityUnknown is the status of a vehicle that has never reported.
+const ConnectivityUnknown = "unknown"
+
+// Connectivity tells whether a vehicle's tracker is online, idle or offline.
+type Connectivity struct {
+	Status    string     `json:"status"`
+	Since     *time.Time `json:"since,omitempty"`
+	LastFixAt *time.Time `json:"last_fix_at,omitempty"`
+}
@@ -7,8 +7,11 @@ import (
 // VehicleRepo abstracts database operations for the Vehicle entity
 type VehicleRepo interface {
 	FindByPlateNumber(plate string) (*model.Vehicle, error)
+	FindByDeviceIMEI(imei string) (*model.Vehicle, error)
 	CreateVehicle(vehicle model.Vehicle) (*model.Vehicle, error)
 	FindByID(id string) (*model.Vehicle, error)
+	FindByIDIncludingDeleted(id string) (*model.Vehicle, error)
+	MarkTracked(id string) error
 	FindAllVehicles(User_ID string) ([]*model.Vehicle, error)
 	UpdateVehicle(vehicle model.Vehicle) (model.Vehicle, error)
 	UpdateSoftDelete(id string) error
@@ -25,8 +25,11 @@ func NewVehicleDomainService(repo repository.VehicleRepo, logger utils.Logger) V
 
 type VehicleService interface {
 	FindByPlateNumber(plate string) (*model.Vehicle, error)
+	FindByDeviceIMEI(imei string) (*model.Vehicle, error)
 	CreateVehicle(vehicle model.Vehicle) (*model.Vehicle, error)
 	FindByID(id string) (*model.Vehicle, error)
+	LookupVehicle(id string) (*model.Vehicle, error)
+	MarkTracked(id string) error
 	FindAll(User_ID string) ([]*model.Vehicle, error)
 	UpdateVehicle(vehicle model.Vehicle) (model.Vehicle, error)
 	UpdateSoftDelete(id string) error
@@ -42,6 +45,16 @@ func (v *VehicleDomain) FindByPlateNumber(plate string) (*model.Vehicle, error)
 	return existingVehicle, nil
 }
 
+// Find the vehicle a tracker device is installed in
+func (v *VehicleDomain) FindByDeviceIMEI(imei string) (*model.Vehicle, error) {
+	vehicle, err := v.vehicleRepo.FindByDeviceIMEI(imei)
+	if err != nil {
+		v.logger.Errorf("[FindByDeviceIMEI] DB error: %v", err)
+		return nil, err
+	}
+	return vehicle, nil
+}
+
 // Create a new vehicle
 func (v *VehicleDomain) CreateVehicle(vehicle model.Vehicle) (*model.Vehicle, error) {
 	vehicle.ID = primitive.NewObjectID().Hex()
@@ -72,6 +85,25 @@ func (v *VehicleDomain) FindByID(id string) (*model.Vehicle, error) {
 	return vehicle, nil
 }
 
+// Find vehicle by ID, deleted or not. Returns nil when it was never registered.
+func (v *VehicleDomain) LookupVehicle(id string) (*model.Vehicle, error) {
+	vehicle, err := v.vehicleRepo.FindByIDIncludingDeleted(id)
+	if err != nil {
+		v.logger.Errorf("[LookupVehicle] error: %v", err)
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// Mark a vehicle as tracked once it reports its first location
+func (v *VehicleDomain) MarkTracked(id string) error {
+	if err := v.vehicleRepo.MarkTracked(id); err != nil {
+		v.logger.Errorf("[MarkTracked] error: %v", err)
+		return err
+	}
+	return nil
+}
+
 // List all vehicles
 func (v *VehicleDomain) FindAll(User_ID string) ([]*model.Vehicle, error) {
 	vehicles, err := v.vehicleRepo.FindAllVehicles(User_ID)
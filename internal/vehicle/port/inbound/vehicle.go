@@ -8,4 +8,5 @@ type VehiclePortInterface interface {
 	ListVehicles(w http.ResponseWriter, r *http.Request)
 	UpdateVehicle(w http.ResponseWriter, r *http.Request)
 	DeleteVehicle(w http.ResponseWriter, r *http.Request)
+	GetVehicleOdometer(w http.ResponseWriter, r *http.Request)
 }
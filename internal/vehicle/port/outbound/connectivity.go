@@ -0,0 +1,11 @@
+package repository
+
+import (
+	model "FMTS/internal/vehicle/domain/entity"
+)
+
+// ConnectivityReader reads the connectivity status the tracking module
+// derives from reported locations, keyed by vehicle ID.
+type ConnectivityReader interface {
+	GetConnectivity(ownerID string) (map[string]model.Connectivity, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+// DeviceLookup reads the device registry of the device module, so a vehicle
+// never stores the IMEI of a device registered for another vehicle.
+type DeviceLookup interface {
+	// DeviceWithIMEI returns the vehicle the device registered with imei is
+	// bound to, empty when it is unbound; found is false when no device is
+	// registered with it.
+	DeviceWithIMEI(imei string) (vehicleID string, found bool, err error)
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	model "FMTS/internal/vehicle/domain/entity"
+)
+
+// OdometerReader reads the odometer the tracking module maintains from
+// reported locations.
+type OdometerReader interface {
+	GetOdometer(vehicleID string) (model.Odometer, error)
+}
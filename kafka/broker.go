@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageWriter writes messages to the topic it is bound to. *kafka.Writer
+// and the in-memory broker's writers implement it.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// MessageReader consumes a topic as a member of a consumer group without
+// committing automatically. *kafka.Reader and the in-memory broker's
+// readers implement it.
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewWriter returns a writer for a real Kafka cluster.
+func NewWriter(brokers []string, topic string) MessageWriter {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{}, // keep every vehicle on one partition so its points stay ordered
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+// NewReader returns a consumer-group reader for a real Kafka cluster.
+func NewReader(brokers []string, topic, groupID string) MessageReader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       topic,
+		GroupID:     groupID,
+		StartOffset: kafka.FirstOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6, // 10MB
+	})
+}
@@ -1,52 +1,177 @@
 package kafka
 
-// import (
-// 	model "FMTS/internal/tracking/domain/entity"
-// 	// "FMTS/internal/tracking/domain/entity"
-// 	// "FMTS/internal/tracking/infrastructure/persistence"
-// 	"context"
-// 	"encoding/json"
-// 	"log"
-
-// 	"github.com/segmentio/kafka-go"
-// )
-
-// type KafkaConsumer struct {
-// 	Reader     *kafka.Reader
-// 	Repository *persistence.VehicleLocationRepository
-// }
-
-// func NewKafkaConsumer(brokers []string, topic, groupID string, repo *persistence.VehicleLocationRepository) *KafkaConsumer {
-// 	reader := kafka.NewReader(kafka.ReaderConfig{
-// 		Brokers:     brokers,
-// 		Topic:       topic,
-// 		GroupID:     groupID,
-// 		StartOffset: kafka.LastOffset,
-// 		MinBytes:    10e3, // 10KB
-// 		MaxBytes:    10e6, // 10MB
-// 	})
-// 	return &KafkaConsumer{
-// 		Reader:     reader,
-// 		Repository: repo,
-// 	}
-// }
-
-// func (kc *KafkaConsumer) StartConsuming(ctx context.Context) {
-// 	for {
-// 		m, err := kc.Reader.ReadMessage(ctx)
-// 		if err != nil {
-// 			log.Printf("Error reading message: %v", err)
-// 			continue
-// 		}
-
-// 		var location model.VehicleLocation
-// 		if err := json.Unmarshal(m.Value, &location); err != nil {
-// 			log.Printf("Error unmarshalling message: %v", err)
-// 			continue
-// 		}
-
-// 		if err := kc.Repository.Save(ctx, &location); err != nil {
-// 			log.Printf("Error saving to DB: %v", err)
-// 		}
-// 	}
-// }
+import (
+	model "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// LocationStore persists a consumed location. The tracker application
+// satisfies it, so consumed points go through the same domain path (and
+// listeners) as directly ingested ones.
+type LocationStore interface {
+	UpdateLocation(ctx context.Context, location model.VehicleLocation) (model.VehicleLocation, error)
+}
+
+type ConsumerConfig struct {
+	// MaxRetries is how many times a failed write is retried before the
+	// message is sent to the dead-letter topic.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles per attempt.
+	RetryBackoff time.Duration
+}
+
+// KafkaConsumer reads vehicle locations from a consumer group and writes them
+// to the store. Offsets are committed only after a message has been stored or
+// parked on the dead-letter topic, so a crash never loses a location.
+type KafkaConsumer struct {
+	reader MessageReader
+	dlq    MessageWriter
+	store  LocationStore
+	config ConsumerConfig
+	logger utils.Logger
+}
+
+func NewKafkaConsumer(reader MessageReader, dlq MessageWriter, store LocationStore, config ConsumerConfig, logger utils.Logger) *KafkaConsumer {
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	return &KafkaConsumer{
+		reader: reader,
+		dlq:    dlq,
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// StartConsuming processes messages until ctx is cancelled or the reader fails.
+func (kc *KafkaConsumer) StartConsuming(ctx context.Context) error {
+	for {
+		msg, err := kc.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		if err := kc.handle(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				// Shutting down: leave the offset uncommitted so the message is redelivered.
+				return nil
+			}
+			return err
+		}
+
+		if err := kc.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to commit offset %d: %w", msg.Offset, err)
+		}
+	}
+}
+
+func (kc *KafkaConsumer) Close() error {
+	return kc.reader.Close()
+}
+
+// handle stores one message, retrying transient failures and dead-lettering
+// messages that can never be stored. It only returns an error when the
+// message could be neither stored nor dead-lettered.
+func (kc *KafkaConsumer) handle(ctx context.Context, msg kafka.Message) error {
+	var location model.VehicleLocation
+	if err := json.Unmarshal(msg.Value, &location); err != nil {
+		return kc.deadLetter(ctx, msg, fmt.Errorf("invalid payload: %w", err), 0)
+	}
+	if err := location.Validate(); err != nil {
+		return kc.deadLetter(ctx, msg, fmt.Errorf("invalid location: %w", err), 0)
+	}
+
+	backoff := kc.config.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= kc.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			kc.logger.Warnf("[KafkaConsumer] retrying offset %d (attempt %d/%d): %v", msg.Offset, attempt, kc.config.MaxRetries, lastErr)
+			if err := sleep(ctx, backoff); err != nil {
+				return err
+			}
+			backoff *= 2
+		}
+
+		_, lastErr = kc.store.UpdateLocation(ctx, location)
+		if lastErr == nil {
+			return nil
+		}
+		if errors.Is(lastErr, model.ErrQuarantined) || errors.Is(lastErr, model.ErrIngestDenied) {
+			// Quarantined or refused for its vehicle; retrying would be
+			// rejected again. The ingest filter only remembers a fix once
+			// it is stored, so a retry after a failed write is not taken
+			// for a duplicate of itself.
+			kc.logger.Warnf("[KafkaConsumer] offset %d for vehicle %s: %v", msg.Offset, location.VehicleID, lastErr)
+			return nil
+		}
+	}
+
+	return kc.deadLetter(ctx, msg, lastErr, kc.config.MaxRetries+1)
+}
+
+// deadLetter parks a message on the dead-letter topic. Writing to the DLQ is
+// itself retried until it succeeds or ctx is cancelled, because committing
+// without it would drop the location.
+func (kc *KafkaConsumer) deadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	kc.logger.Errorf("[KafkaConsumer] dead-lettering offset %d for vehicle %s: %v", msg.Offset, string(msg.Key), cause)
+	if kc.dlq == nil {
+		return fmt.Errorf("no dead-letter topic configured: %w", cause)
+	}
+
+	dead := kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Time:  time.Now(),
+		Headers: append(append([]kafka.Header(nil), msg.Headers...),
+			kafka.Header{Key: "x-error", Value: []byte(cause.Error())},
+			kafka.Header{Key: "x-attempts", Value: []byte(strconv.Itoa(attempts))},
+			kafka.Header{Key: "x-original-topic", Value: []byte(msg.Topic)},
+			kafka.Header{Key: "x-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: "x-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		),
+	}
+
+	backoff := kc.config.RetryBackoff
+	for {
+		err := kc.dlq.WriteMessages(ctx, dead)
+		if err == nil {
+			return nil
+		}
+		kc.logger.Errorf("[KafkaConsumer] failed to write dead letter for offset %d: %v", msg.Offset, err)
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,276 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	model "FMTS/internal/tracking/domain/entity"
+	repo "FMTS/internal/tracking/domain/repository"
+	domain "FMTS/internal/tracking/domain/service"
+	"FMTS/pkg/utils"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	testTopic = "locations"
+	testDLQ   = "locations-dlq"
+	testGroup = "writer"
+)
+
+var testTime = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+// scriptedStore returns the scripted errors in turn, then nil, and records
+// the group's committed offset at every call.
+type scriptedStore struct {
+	broker *MemoryBroker
+	errs   []error
+
+	mu        sync.Mutex
+	calls     int
+	stored    []model.VehicleLocation
+	committed []int64
+}
+
+func (s *scriptedStore) UpdateLocation(ctx context.Context, location model.VehicleLocation) (model.VehicleLocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.committed = append(s.committed, s.broker.Committed(testTopic, testGroup))
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return model.VehicleLocation{}, err
+		}
+	}
+	s.stored = append(s.stored, location)
+	return location, nil
+}
+
+var errStoreDown = errors.New("store unavailable")
+
+func testLocation(seconds int) model.VehicleLocation {
+	return model.VehicleLocation{
+		OwnerID:   "owner1",
+		VehicleID: "v1",
+		Latitude:  9.0108,
+		Longitude: 38.7613 + float64(seconds)*1e-4,
+		Timestamp: testTime.Add(time.Duration(seconds) * time.Second),
+	}
+}
+
+func publish(t *testing.T, broker *MemoryBroker, locations ...model.VehicleLocation) {
+	t.Helper()
+	producer := NewKafkaProducerWithWriter(broker.Writer(testTopic), utils.NewStandardLogger())
+	if err := producer.ProduceVehicleLocations(context.Background(), locations); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+}
+
+// consume runs a consumer on the broker until the group has committed
+// offset, then stops it.
+func consume(t *testing.T, broker *MemoryBroker, store LocationStore, config ConsumerConfig, offset int64) {
+	t.Helper()
+	consumer := NewKafkaConsumer(broker.Reader(testTopic, testGroup), broker.Writer(testDLQ), store, config, utils.NewStandardLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.StartConsuming(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for broker.Committed(testTopic, testGroup) < offset {
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatalf("committed offset %d, want %d", broker.Committed(testTopic, testGroup), offset)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+}
+
+func TestConsumerCommitsOnlyAfterWrite(t *testing.T) {
+	broker := NewMemoryBroker()
+	publish(t, broker, testLocation(0), testLocation(10))
+	store := &scriptedStore{broker: broker, errs: []error{errStoreDown, errStoreDown}}
+
+	consume(t, broker, store, ConsumerConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}, 2)
+
+	// Offset 0 is written on the third attempt, offset 1 on the first.
+	want := []int64{0, 0, 0, 1}
+	if len(store.committed) != len(want) {
+		t.Fatalf("store called %d times, want %d", len(store.committed), len(want))
+	}
+	for i, c := range want {
+		if store.committed[i] != c {
+			t.Fatalf("call %d saw committed offset %d, want %d", i, store.committed[i], c)
+		}
+	}
+	if len(store.stored) != 2 {
+		t.Fatalf("stored %d locations, want 2", len(store.stored))
+	}
+}
+
+func TestConsumerRedeliversAfterShutdownDuringRetry(t *testing.T) {
+	broker := NewMemoryBroker()
+	publish(t, broker, testLocation(0))
+	failing := &scriptedStore{broker: broker, errs: []error{errStoreDown}}
+
+	consumer := NewKafkaConsumer(broker.Reader(testTopic, testGroup), broker.Writer(testDLQ), failing,
+		ConsumerConfig{MaxRetries: 3, RetryBackoff: time.Hour}, utils.NewStandardLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.StartConsuming(ctx) }()
+	for {
+		failing.mu.Lock()
+		calls := failing.calls
+		failing.mu.Unlock()
+		if calls > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+	if c := broker.Committed(testTopic, testGroup); c != 0 {
+		t.Fatalf("committed offset %d after a failed write, want 0", c)
+	}
+
+	// A restarted consumer gets the location again.
+	store := &scriptedStore{broker: broker}
+	consume(t, broker, store, ConsumerConfig{}, 1)
+	if len(store.stored) != 1 {
+		t.Fatalf("stored %d locations after restart, want 1", len(store.stored))
+	}
+}
+
+func TestConsumerDeadLettersAfterRetries(t *testing.T) {
+	broker := NewMemoryBroker()
+	publish(t, broker, testLocation(0))
+	store := &scriptedStore{broker: broker, errs: []error{errStoreDown, errStoreDown, errStoreDown, errStoreDown}}
+
+	consume(t, broker, store, ConsumerConfig{MaxRetries: 2, RetryBackoff: time.Millisecond}, 1)
+
+	if store.calls != 3 {
+		t.Fatalf("store called %d times, want 3", store.calls)
+	}
+	dead := broker.Messages(testDLQ)
+	if len(dead) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(dead))
+	}
+	headers := make(map[string]string)
+	for _, h := range dead[0].Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	want := map[string]string{
+		"x-error":              errStoreDown.Error(),
+		"x-attempts":           "3",
+		"x-original-topic":     testTopic,
+		"x-original-partition": "0",
+		"x-original-offset":    "0",
+	}
+	for k, v := range want {
+		if headers[k] != v {
+			t.Fatalf("header %s = %q, want %q", k, headers[k], v)
+		}
+	}
+	if string(dead[0].Key) != "v1" {
+		t.Fatalf("dead letter key = %q, want v1", dead[0].Key)
+	}
+}
+
+func TestConsumerDeadLettersInvalidPayload(t *testing.T) {
+	broker := NewMemoryBroker()
+	if err := broker.Writer(testTopic).WriteMessages(context.Background(), kafka.Message{Key: []byte("v1"), Value: []byte("{")}); err != nil {
+		t.Fatal(err)
+	}
+	store := &scriptedStore{broker: broker}
+
+	consume(t, broker, store, ConsumerConfig{MaxRetries: 2, RetryBackoff: time.Millisecond}, 1)
+
+	if store.calls != 0 {
+		t.Fatalf("store called %d times, want 0", store.calls)
+	}
+	if dead := broker.Messages(testDLQ); len(dead) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(dead))
+	}
+}
+
+func TestConsumerSkipsRejectedLocations(t *testing.T) {
+	broker := NewMemoryBroker()
+	publish(t, broker, testLocation(0), testLocation(10))
+	store := &scriptedStore{broker: broker, errs: []error{
+		&model.QuarantineError{Reason: model.QuarantineTeleport},
+		&model.IngestDeniedError{VehicleID: "v1", Reason: model.DenialUnknownVehicle},
+	}}
+
+	consume(t, broker, store, ConsumerConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}, 2)
+
+	if store.calls != 2 {
+		t.Fatalf("store called %d times, want 2 (no retries)", store.calls)
+	}
+	if dead := broker.Messages(testDLQ); len(dead) != 0 {
+		t.Fatalf("dead letters = %d, want 0", len(dead))
+	}
+}
+
+// flakyTrackerRepo fails the first writes and keeps the rest in memory.
+// Methods the tracker does not call panic through the nil embedded
+// interface.
+type flakyTrackerRepo struct {
+	repo.DomainTracker
+
+	mu          sync.Mutex
+	failWrites  int
+	stored      []model.VehicleLocation
+	quarantined []model.QuarantinedLocation
+}
+
+func (r *flakyTrackerRepo) GetLatestVehicleLocationByID(ctx context.Context, vehicleID string) (model.VehicleLocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.stored) - 1; i >= 0; i-- {
+		if r.stored[i].VehicleID == vehicleID {
+			return r.stored[i], nil
+		}
+	}
+	return model.VehicleLocation{}, errors.New("no rows")
+}
+
+func (r *flakyTrackerRepo) UpdateLocation(ctx context.Context, location model.VehicleLocation) (model.VehicleLocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failWrites > 0 {
+		r.failWrites--
+		return model.VehicleLocation{}, errStoreDown
+	}
+	r.stored = append(r.stored, location)
+	return location, nil
+}
+
+func (r *flakyTrackerRepo) QuarantineLocations(ctx context.Context, locations []model.QuarantinedLocation) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quarantined = append(r.quarantined, locations...)
+	return int64(len(locations)), nil
+}
+
+func TestConsumerRetryIsNotQuarantined(t *testing.T) {
+	broker := NewMemoryBroker()
+	publish(t, broker, testLocation(0))
+	trackerRepo := &flakyTrackerRepo{failWrites: 2}
+	tracker := domain.InitDomaintrakerservice(utils.NewStandardLogger(), trackerRepo, model.TrackerConfig{})
+
+	consume(t, broker, tracker, ConsumerConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}, 1)
+
+	if len(trackerRepo.stored) != 1 || len(trackerRepo.quarantined) != 0 {
+		t.Fatalf("stored %d, quarantined %d; want 1 stored, none quarantined", len(trackerRepo.stored), len(trackerRepo.quarantined))
+	}
+}
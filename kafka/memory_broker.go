@@ -0,0 +1,216 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var ErrBrokerClosed = errors.New("memory broker: closed")
+
+// unconsumedRetention is how many messages a topic without consumer groups,
+// like the dead-letter topic, keeps before dropping the oldest.
+const unconsumedRetention = 10000
+
+// MemoryBroker is an in-process stand-in for Kafka with a single partition
+// per topic and committed offsets per consumer group. It lets the ingestion
+// pipeline run, and be tested, without a Kafka cluster.
+//
+// Messages are dropped once every group reading their topic has committed
+// them, so the log only holds the backlog.
+type MemoryBroker struct {
+	mu      sync.Mutex
+	topics  map[string]*memoryTopic
+	commits map[string]int64 // topic + "/" + group -> next offset to consume
+	notify  chan struct{}
+}
+
+// memoryTopic is the retained part of a topic's log. base is the offset of
+// msgs[0].
+type memoryTopic struct {
+	base   int64
+	msgs   []kafka.Message
+	groups map[string]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics:  make(map[string]*memoryTopic),
+		commits: make(map[string]int64),
+		notify:  make(chan struct{}),
+	}
+}
+
+// Writer returns a writer bound to topic.
+func (b *MemoryBroker) Writer(topic string) MessageWriter {
+	return &memoryWriter{broker: b, topic: topic}
+}
+
+// Reader returns a reader for topic that resumes from the group's last
+// committed offset, like a restarted consumer would.
+func (b *MemoryBroker) Reader(topic, groupID string) MessageReader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.topic(topic).groups[groupID] = struct{}{}
+	return &memoryReader{
+		broker: b,
+		topic:  topic,
+		group:  groupID,
+		next:   b.commits[commitKey(topic, groupID)],
+		closed: make(chan struct{}),
+	}
+}
+
+// Messages returns a copy of the messages topic still holds.
+func (b *MemoryBroker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]kafka.Message(nil), b.topic(topic).msgs...)
+}
+
+// Committed returns the next offset the group will consume from topic.
+func (b *MemoryBroker) Committed(topic, groupID string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.commits[commitKey(topic, groupID)]
+}
+
+// topic returns the log of a topic, creating it on first use. The caller
+// holds b.mu.
+func (b *MemoryBroker) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{groups: make(map[string]struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *MemoryBroker) append(topic string, msgs []kafka.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(topic)
+	for _, m := range msgs {
+		m.Topic = topic
+		m.Offset = t.base + int64(len(t.msgs))
+		if m.Time.IsZero() {
+			m.Time = time.Now()
+		}
+		t.msgs = append(t.msgs, m)
+	}
+	if len(t.groups) == 0 && len(t.msgs) > unconsumedRetention {
+		t.trim(t.base + int64(len(t.msgs)-unconsumedRetention))
+	}
+	// Wake up every waiting reader.
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// trimCommitted drops the messages of topic every group has committed. The
+// caller holds b.mu.
+func (b *MemoryBroker) trimCommitted(topic string) {
+	t := b.topic(topic)
+	first := true
+	var until int64
+	for group := range t.groups {
+		committed := b.commits[commitKey(topic, group)]
+		if first || committed < until {
+			until = committed
+			first = false
+		}
+	}
+	t.trim(until)
+}
+
+// trim drops the messages before offset.
+func (t *memoryTopic) trim(offset int64) {
+	n := offset - t.base
+	if n <= 0 {
+		return
+	}
+	if n > int64(len(t.msgs)) {
+		n = int64(len(t.msgs))
+	}
+	// Copy so the dropped messages do not stay reachable through the
+	// backing array.
+	t.msgs = append([]kafka.Message(nil), t.msgs[n:]...)
+	t.base += n
+}
+
+func commitKey(topic, group string) string {
+	return topic + "/" + group
+}
+
+type memoryWriter struct {
+	broker *MemoryBroker
+	topic  string
+}
+
+func (w *memoryWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.broker.append(w.topic, msgs)
+	return nil
+}
+
+func (w *memoryWriter) Close() error { return nil }
+
+type memoryReader struct {
+	broker *MemoryBroker
+	topic  string
+	group  string
+	next   int64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (r *memoryReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.broker.mu.Lock()
+		t := r.broker.topic(r.topic)
+		if r.next < t.base {
+			// Dropped before this reader got to it; resume at the oldest
+			// message still held.
+			r.next = t.base
+		}
+		if i := r.next - t.base; i < int64(len(t.msgs)) {
+			msg := t.msgs[i]
+			r.next++
+			r.broker.mu.Unlock()
+			return msg, nil
+		}
+		wait := r.broker.notify
+		r.broker.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-r.closed:
+			return kafka.Message{}, ErrBrokerClosed
+		}
+	}
+}
+
+func (r *memoryReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+	key := commitKey(r.topic, r.group)
+	for _, m := range msgs {
+		if m.Offset+1 > r.broker.commits[key] {
+			r.broker.commits[key] = m.Offset + 1
+		}
+	}
+	r.broker.trimCommitted(r.topic)
+	return nil
+}
+
+func (r *memoryReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestMemoryBrokerDropsCommittedMessages(t *testing.T) {
+	ctx := context.Background()
+	broker := NewMemoryBroker()
+	fast := broker.Reader(testTopic, "fast")
+	slow := broker.Reader(testTopic, "slow")
+	writer := broker.Writer(testTopic)
+	for i := 0; i < 3; i++ {
+		if err := writer.WriteMessages(ctx, kafka.Message{Value: []byte{byte(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		msg, err := fast.FetchMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fast.CommitMessages(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(broker.Messages(testTopic)); n != 3 {
+		t.Fatalf("held %d messages while a group lags, want 3", n)
+	}
+
+	first, _ := slow.FetchMessage(ctx)
+	second, _ := slow.FetchMessage(ctx)
+	if err := slow.CommitMessages(ctx, first, second); err != nil {
+		t.Fatal(err)
+	}
+	held := broker.Messages(testTopic)
+	if len(held) != 1 || held[0].Offset != 2 {
+		t.Fatalf("held %v, want only offset 2", held)
+	}
+
+	// Offsets keep counting after a trim.
+	if err := writer.WriteMessages(ctx, kafka.Message{Value: []byte{3}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slow.FetchMessage(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := slow.FetchMessage(ctx); msg.Offset != 3 {
+		t.Fatalf("next offset = %d, want 3", msg.Offset)
+	}
+}
+
+func TestMemoryBrokerBoundsUnconsumedTopics(t *testing.T) {
+	broker := NewMemoryBroker()
+	writer := broker.Writer(testDLQ)
+	for i := 0; i < unconsumedRetention+5; i++ {
+		if err := writer.WriteMessages(context.Background(), kafka.Message{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	held := broker.Messages(testDLQ)
+	if len(held) != unconsumedRetention || held[0].Offset != 5 {
+		t.Fatalf("held %d messages from offset %d, want %d from 5", len(held), held[0].Offset, unconsumedRetention)
+	}
+}
@@ -2,27 +2,27 @@ package kafka
 
 import (
 	model "FMTS/internal/tracking/domain/entity"
+	"FMTS/pkg/utils"
 	"context"
 	"encoding/json"
-	"log"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
 type KafkaProducer struct {
-	writer *kafka.Writer
+	writer MessageWriter
+	logger utils.Logger
 }
 
-func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
-	return &KafkaProducer{
-		writer: kafka.NewWriter(kafka.WriterConfig{
-			Brokers:  brokers,
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
-			// RequiredAcks: kafka.RequireAll,
-		}),
-	}
+func NewKafkaProducer(brokers []string, topic string, logger utils.Logger) *KafkaProducer {
+	return NewKafkaProducerWithWriter(NewWriter(brokers, topic), logger)
+}
+
+// NewKafkaProducerWithWriter builds a producer on any MessageWriter, e.g. a
+// MemoryBroker writer.
+func NewKafkaProducerWithWriter(writer MessageWriter, logger utils.Logger) *KafkaProducer {
+	return &KafkaProducer{writer: writer, logger: logger}
 }
 
 func (kp *KafkaProducer) ProduceVehicleLocation(ctx context.Context, location model.VehicleLocation) error {
@@ -40,13 +40,32 @@ func (kp *KafkaProducer) ProduceVehicleLocation(ctx context.Context, location mo
 	}
 
 	// Send message
-	err = kp.writer.WriteMessages(ctx, msg)
-	if err != nil {
-		log.Printf("Error producing message to Kafka: %v", err)
+	if err := kp.writer.WriteMessages(ctx, msg); err != nil {
+		kp.logger.Errorf("[KafkaProducer] failed to produce location for vehicle %s: %v", location.VehicleID, err)
 		return err
 	}
+	return nil
+}
+
+// ProduceVehicleLocations publishes a batch of locations in one write.
+func (kp *KafkaProducer) ProduceVehicleLocations(ctx context.Context, locations []model.VehicleLocation) error {
+	msgs := make([]kafka.Message, 0, len(locations))
+	for _, location := range locations {
+		value, err := json.Marshal(location)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(location.VehicleID),
+			Value: value,
+			Time:  time.Now(),
+		})
+	}
 
-	log.Printf("Produced location for vehicle %s at %v", location.VehicleID, location.Timestamp)
+	if err := kp.writer.WriteMessages(ctx, msgs...); err != nil {
+		kp.logger.Errorf("[KafkaProducer] failed to produce %d locations: %v", len(msgs), err)
+		return err
+	}
 	return nil
 }
 
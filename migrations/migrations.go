@@ -0,0 +1,279 @@
+// Package migrations versions the Postgres/Timescale schema. Migrations are
+// embedded SQL files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql; applied versions are recorded in
+// schema_migrations.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey serialises migrations run by several processes at once.
+const lockKey = 0x464D5453_0002
+
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports one known migration; AppliedAt is nil while it is pending.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Load reads the embedded migrations in version order.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		file := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql", file)
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+		}
+
+		body, err := files.ReadFile(path.Join("sql", file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+type Runner struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewRunner(db *pgxpool.Pool) (*Runner, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, migrations: migrations}, nil
+}
+
+// Status lists every known migration and when it was applied.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(r.migrations))
+	for i, m := range r.migrations {
+		statuses[i] = Status{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns ErrSchemaOutdated when any migration is not applied yet.
+func (r *Runner) Check(ctx context.Context) error {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up applies every pending migration in version order, each in its own
+// transaction, and returns the ones it applied.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	if err := r.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, m := range r.migrations {
+		applied, err := r.step(ctx, m, true)
+		if err != nil {
+			return done, err
+		}
+		if applied {
+			done = append(done, m)
+		}
+	}
+	return done, nil
+}
+
+// Down reverts the newest steps applied migrations and returns the ones it
+// reverted.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := r.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for i := len(r.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		reverted, err := r.step(ctx, r.migrations[i], false)
+		if err != nil {
+			return done, err
+		}
+		if reverted {
+			done = append(done, r.migrations[i])
+		}
+	}
+	return done, nil
+}
+
+// step applies (up) or reverts (down) one migration. It reports false when
+// the migration was already in the requested state, which happens when
+// another process got there first.
+func (r *Runner) step(ctx context.Context, m Migration, up bool) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, lockKey); err != nil {
+		return false, fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	var applied bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1);`, m.Version).Scan(&applied); err != nil {
+		return false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if applied == up {
+		return false, nil
+	}
+
+	if up {
+		if _, err := tx.Exec(ctx, m.Up); err != nil {
+			return false, fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3);`,
+			m.Version, m.Name, time.Now().UTC()); err != nil {
+			return false, fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+	} else {
+		if _, err := tx.Exec(ctx, m.Down); err != nil {
+			return false, fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1;`, m.Version); err != nil {
+			return false, fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return true, nil
+}
+
+func (r *Runner) ensureTable(ctx context.Context) error {
+	const query = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		);
+	`
+	if _, err := r.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// applied returns the recorded versions. A database without the
+// schema_migrations table has none.
+func (r *Runner) applied(ctx context.Context) (map[int64]time.Time, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL;`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	applied := make(map[int64]time.Time)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT version, applied_at FROM schema_migrations;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return applied, nil
+}
@@ -0,0 +1,33 @@
+-- Raw location points. IF NOT EXISTS lets the first migration adopt
+-- databases that were set up by hand before migrations existed.
+CREATE TABLE IF NOT EXISTS vehicle_locations (
+    id         BIGSERIAL,
+    owner_id   TEXT             NOT NULL,
+    vehicle_id TEXT             NOT NULL,
+    latitude   DOUBLE PRECISION NOT NULL,
+    longitude  DOUBLE PRECISION NOT NULL,
+    speed      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    timestamp  TIMESTAMPTZ      NOT NULL,
+    PRIMARY KEY (id, timestamp)
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_locations_vehicle_time_idx
+    ON vehicle_locations (vehicle_id, timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS vehicle_locations_owner_vehicle_time_idx
+    ON vehicle_locations (owner_id, vehicle_id, timestamp DESC);
+
+-- Partition by time when TimescaleDB is installed; plain Postgres keeps a
+-- regular table. A hand-made table whose keys leave out timestamp cannot be
+-- converted and is kept as it is.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        BEGIN
+            PERFORM create_hypertable('vehicle_locations', 'timestamp',
+                if_not_exists => TRUE, migrate_data => TRUE);
+        EXCEPTION WHEN others THEN
+            RAISE NOTICE 'vehicle_locations kept as a plain table: %', SQLERRM;
+        END;
+    END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS vehicle_daily_distance;
+DROP TABLE IF EXISTS vehicle_odometers;
+DROP TABLE IF EXISTS vehicle_trip_watermarks;
+DROP TABLE IF EXISTS vehicle_trips;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS vehicle_trips (
+    id               BIGSERIAL PRIMARY KEY,
+    owner_id         TEXT             NOT NULL,
+    vehicle_id       TEXT             NOT NULL,
+    start_time       TIMESTAMPTZ      NOT NULL,
+    end_time         TIMESTAMPTZ      NOT NULL,
+    start_latitude   DOUBLE PRECISION NOT NULL,
+    start_longitude  DOUBLE PRECISION NOT NULL,
+    end_latitude     DOUBLE PRECISION NOT NULL,
+    end_longitude    DOUBLE PRECISION NOT NULL,
+    distance_km      DOUBLE PRECISION NOT NULL,
+    max_speed        DOUBLE PRECISION NOT NULL,
+    avg_speed        DOUBLE PRECISION NOT NULL,
+    duration_seconds BIGINT           NOT NULL,
+    point_count      INTEGER          NOT NULL,
+    UNIQUE (vehicle_id, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_trips_vehicle_end_idx
+    ON vehicle_trips (vehicle_id, end_time);
+
+CREATE TABLE IF NOT EXISTS vehicle_trip_watermarks (
+    vehicle_id      TEXT PRIMARY KEY,
+    processed_from  TIMESTAMPTZ NOT NULL,
+    processed_until TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_odometers (
+    vehicle_id     TEXT PRIMARY KEY,
+    owner_id       TEXT             NOT NULL,
+    total_km       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_latitude  DOUBLE PRECISION NOT NULL,
+    last_longitude DOUBLE PRECISION NOT NULL,
+    last_timestamp TIMESTAMPTZ      NOT NULL,
+    updated_at     TIMESTAMPTZ      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_daily_distance (
+    vehicle_id  TEXT             NOT NULL,
+    day         DATE             NOT NULL,
+    owner_id    TEXT             NOT NULL,
+    distance_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (vehicle_id, day)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vehicle_location_quarantine;
+ALTER TABLE vehicle_locations DROP COLUMN IF EXISTS out_of_order;
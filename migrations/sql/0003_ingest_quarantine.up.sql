@@ -0,0 +1,18 @@
+ALTER TABLE vehicle_locations
+    ADD COLUMN IF NOT EXISTS out_of_order BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS vehicle_location_quarantine (
+    id          BIGSERIAL PRIMARY KEY,
+    owner_id    TEXT             NOT NULL,
+    vehicle_id  TEXT             NOT NULL,
+    latitude    DOUBLE PRECISION NOT NULL,
+    longitude   DOUBLE PRECISION NOT NULL,
+    speed       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    timestamp   TIMESTAMPTZ      NOT NULL,
+    reason      TEXT             NOT NULL,
+    detail      TEXT             NOT NULL DEFAULT '',
+    received_at TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_location_quarantine_vehicle_time_idx
+    ON vehicle_location_quarantine (vehicle_id, timestamp DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS location_retention_runs;
+DROP INDEX IF EXISTS vehicle_locations_time_idx;
+DROP TABLE IF EXISTS vehicle_location_minutes;
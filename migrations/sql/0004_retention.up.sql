@@ -0,0 +1,31 @@
+-- One row per vehicle and minute for points older than the downsample age.
+CREATE TABLE IF NOT EXISTS vehicle_location_minutes (
+    vehicle_id  TEXT             NOT NULL,
+    bucket      TIMESTAMPTZ      NOT NULL,
+    owner_id    TEXT             NOT NULL,
+    latitude    DOUBLE PRECISION NOT NULL,
+    longitude   DOUBLE PRECISION NOT NULL,
+    avg_speed   DOUBLE PRECISION NOT NULL,
+    max_speed   DOUBLE PRECISION NOT NULL,
+    point_count INTEGER          NOT NULL,
+    PRIMARY KEY (vehicle_id, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_location_minutes_bucket_idx
+    ON vehicle_location_minutes (bucket);
+
+CREATE INDEX IF NOT EXISTS vehicle_locations_time_idx
+    ON vehicle_locations (timestamp);
+
+CREATE TABLE IF NOT EXISTS location_retention_runs (
+    id                BIGSERIAL PRIMARY KEY,
+    started_at        TIMESTAMPTZ NOT NULL,
+    finished_at       TIMESTAMPTZ NOT NULL,
+    dry_run           BOOLEAN     NOT NULL,
+    downsample_before TIMESTAMPTZ,
+    delete_before     TIMESTAMPTZ,
+    summaries_written BIGINT      NOT NULL DEFAULT 0,
+    points_summarised BIGINT      NOT NULL DEFAULT 0,
+    points_deleted    BIGINT      NOT NULL DEFAULT 0,
+    error             TEXT        NOT NULL DEFAULT ''
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fleet_connectivity_thresholds;
+DROP TABLE IF EXISTS vehicle_connectivity_events;
+DROP TABLE IF EXISTS vehicle_connectivity;
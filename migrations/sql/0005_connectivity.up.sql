@@ -0,0 +1,29 @@
+-- Current connectivity status of every vehicle that has reported.
+CREATE TABLE IF NOT EXISTS vehicle_connectivity (
+    vehicle_id  TEXT PRIMARY KEY,
+    owner_id    TEXT        NOT NULL,
+    status      TEXT        NOT NULL,
+    since       TIMESTAMPTZ NOT NULL,
+    last_fix_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_connectivity_events (
+    id          BIGSERIAL PRIMARY KEY,
+    vehicle_id  TEXT        NOT NULL,
+    owner_id    TEXT        NOT NULL,
+    from_status TEXT        NOT NULL DEFAULT '',
+    to_status   TEXT        NOT NULL,
+    at          TIMESTAMPTZ NOT NULL,
+    last_fix_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_connectivity_events_vehicle_at_idx
+    ON vehicle_connectivity_events (vehicle_id, at DESC);
+
+-- Per-fleet overrides of the idle and offline thresholds.
+CREATE TABLE IF NOT EXISTS fleet_connectivity_thresholds (
+    owner_id              TEXT PRIMARY KEY,
+    idle_after_seconds    BIGINT      NOT NULL,
+    offline_after_seconds BIGINT      NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vehicle_stop_state;
+DROP TABLE IF EXISTS vehicle_stops;
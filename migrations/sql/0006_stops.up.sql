@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS vehicle_stops (
+    id               BIGSERIAL PRIMARY KEY,
+    owner_id         TEXT             NOT NULL,
+    vehicle_id       TEXT             NOT NULL,
+    latitude         DOUBLE PRECISION NOT NULL,
+    longitude        DOUBLE PRECISION NOT NULL,
+    arrival_time     TIMESTAMPTZ      NOT NULL,
+    departure_time   TIMESTAMPTZ      NOT NULL,
+    duration_seconds BIGINT           NOT NULL,
+    point_count      INTEGER          NOT NULL,
+    ongoing          BOOLEAN          NOT NULL DEFAULT FALSE,
+    -- Geofences the stop lay in when it was detected, as parallel arrays.
+    geofence_ids     TEXT[]           NOT NULL DEFAULT '{}',
+    geofence_names   TEXT[]           NOT NULL DEFAULT '{}',
+    UNIQUE (vehicle_id, arrival_time)
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_stops_vehicle_departure_idx
+    ON vehicle_stops (vehicle_id, departure_time);
+
+CREATE INDEX IF NOT EXISTS vehicle_stops_owner_departure_idx
+    ON vehicle_stops (owner_id, departure_time);
+
+-- The stay each vehicle is currently in, fed by the location stream.
+CREATE TABLE IF NOT EXISTS vehicle_stop_state (
+    vehicle_id       TEXT PRIMARY KEY,
+    owner_id         TEXT             NOT NULL,
+    anchor_latitude  DOUBLE PRECISION NOT NULL,
+    anchor_longitude DOUBLE PRECISION NOT NULL,
+    latitude         DOUBLE PRECISION NOT NULL,
+    longitude        DOUBLE PRECISION NOT NULL,
+    arrival_time     TIMESTAMPTZ      NOT NULL,
+    last_seen        TIMESTAMPTZ      NOT NULL,
+    point_count      INTEGER          NOT NULL
+);
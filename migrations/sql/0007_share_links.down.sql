@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vehicle_share_accesses;
+DROP TABLE IF EXISTS vehicle_share_links;
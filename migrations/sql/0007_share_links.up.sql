@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS vehicle_share_links (
+    id          BIGSERIAL PRIMARY KEY,
+    vehicle_id  TEXT        NOT NULL,
+    owner_id    TEXT        NOT NULL,
+    created_by  TEXT        NOT NULL DEFAULT '',
+    label       TEXT        NOT NULL DEFAULT '',
+    -- SHA-256 of the token, hex encoded; the token itself is never stored.
+    token_hash  TEXT        NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    window_from TIMESTAMPTZ,
+    window_to   TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_share_links_vehicle_idx
+    ON vehicle_share_links (vehicle_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS vehicle_share_accesses (
+    id          BIGSERIAL PRIMARY KEY,
+    share_id    BIGINT      NOT NULL REFERENCES vehicle_share_links (id) ON DELETE CASCADE,
+    vehicle_id  TEXT        NOT NULL,
+    accessed_at TIMESTAMPTZ NOT NULL,
+    remote_addr TEXT        NOT NULL DEFAULT '',
+    user_agent  TEXT        NOT NULL DEFAULT '',
+    outcome     TEXT        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_share_accesses_share_idx
+    ON vehicle_share_accesses (share_id, accessed_at DESC);
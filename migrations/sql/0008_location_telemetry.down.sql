@@ -0,0 +1,10 @@
+ALTER TABLE vehicle_locations
+    DROP COLUMN IF EXISTS attributes,
+    DROP COLUMN IF EXISTS battery_voltage,
+    DROP COLUMN IF EXISTS external_voltage,
+    DROP COLUMN IF EXISTS ignition,
+    DROP COLUMN IF EXISTS satellites,
+    DROP COLUMN IF EXISTS accuracy,
+    DROP COLUMN IF EXISTS hdop,
+    DROP COLUMN IF EXISTS altitude,
+    DROP COLUMN IF EXISTS heading;
@@ -0,0 +1,13 @@
+-- Optional telemetry reported with a fix. Every column is nullable so rows
+-- written before this migration, and clients that only send a position,
+-- read back as "not reported".
+ALTER TABLE vehicle_locations
+    ADD COLUMN IF NOT EXISTS heading          DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS altitude         DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS hdop             DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS accuracy         DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS satellites       SMALLINT,
+    ADD COLUMN IF NOT EXISTS ignition         BOOLEAN,
+    ADD COLUMN IF NOT EXISTS external_voltage DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS battery_voltage  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS attributes       JSONB;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS vehicle_fuel_events;
+DROP TABLE IF EXISTS vehicle_fuel_daily;
+DROP TABLE IF EXISTS vehicle_fuel_state;
+ALTER TABLE vehicle_locations DROP COLUMN IF EXISTS fuel_level;
@@ -0,0 +1,46 @@
+ALTER TABLE vehicle_locations
+    ADD COLUMN IF NOT EXISTS fuel_level DOUBLE PRECISION;
+
+-- Where fuel detection is for each vehicle, fed by the location stream.
+CREATE TABLE IF NOT EXISTS vehicle_fuel_state (
+    vehicle_id TEXT PRIMARY KEY,
+    owner_id   TEXT               NOT NULL,
+    recent     DOUBLE PRECISION[] NOT NULL,
+    level      DOUBLE PRECISION   NOT NULL,
+    last_seen  TIMESTAMPTZ        NOT NULL,
+    low_level  DOUBLE PRECISION   NOT NULL,
+    low_at     TIMESTAMPTZ        NOT NULL,
+    high_level DOUBLE PRECISION   NOT NULL,
+    high_at    TIMESTAMPTZ        NOT NULL,
+    -- The refuel or drain in progress, if any.
+    open_event JSONB
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_fuel_daily (
+    vehicle_id  TEXT             NOT NULL,
+    day         DATE             NOT NULL,
+    owner_id    TEXT             NOT NULL,
+    start_level DOUBLE PRECISION NOT NULL,
+    end_level   DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (vehicle_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS vehicle_fuel_events (
+    id            BIGSERIAL PRIMARY KEY,
+    owner_id      TEXT             NOT NULL,
+    vehicle_id    TEXT             NOT NULL,
+    type          TEXT             NOT NULL,
+    start_time    TIMESTAMPTZ      NOT NULL,
+    end_time      TIMESTAMPTZ      NOT NULL,
+    level_before  DOUBLE PRECISION NOT NULL,
+    level_after   DOUBLE PRECISION NOT NULL,
+    volume_liters DOUBLE PRECISION NOT NULL,
+    latitude      DOUBLE PRECISION NOT NULL,
+    longitude     DOUBLE PRECISION NOT NULL,
+    stationary    BOOLEAN          NOT NULL DEFAULT FALSE,
+    ongoing       BOOLEAN          NOT NULL DEFAULT FALSE,
+    UNIQUE (vehicle_id, start_time)
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_fuel_events_owner_start_idx
+    ON vehicle_fuel_events (owner_id, start_time);
@@ -0,0 +1,13 @@
+-- Quarantined fixes keep the telemetry they were reported with, so a
+-- rejected fix can be audited with everything the device sent.
+ALTER TABLE vehicle_location_quarantine
+    ADD COLUMN IF NOT EXISTS heading          DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS altitude         DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS hdop             DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS accuracy         DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS satellites       SMALLINT,
+    ADD COLUMN IF NOT EXISTS ignition         BOOLEAN,
+    ADD COLUMN IF NOT EXISTS external_voltage DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS battery_voltage  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS fuel_level       DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS attributes       JSONB;
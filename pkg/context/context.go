@@ -4,15 +4,24 @@ package contexts
 import (
 	constant "FMTS/utils"
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 )
 
+// ErrMissingUserID is returned when a non-admin request carries no user ID.
+var ErrMissingUserID = errors.New("user_id not found")
+
 type UserContext struct {
 	UserCode    string
 	UserID      string
 	FullName    string
 	PhoneNumber string
 	UserRole    string
+	// DeviceID and VehicleID are set for requests authenticated with a
+	// device credential.
+	DeviceID  string
+	VehicleID string
 }
 
 func ExtractUserContext(r *http.Request) UserContext {
@@ -28,6 +37,8 @@ func ExtractUserContext(r *http.Request) UserContext {
 		FullName:    get("full_name"),
 		PhoneNumber: get("phone_number"),
 		UserRole:    get("user_role"),
+		DeviceID:    get("device_id"),
+		VehicleID:   get("vehicle_id"),
 	}
 }
 
@@ -49,3 +60,22 @@ func ExtractContext(c context.Context) UserContext {
 func (u UserContext) IsIncomplete() bool {
 	return u.UserID == "" || u.FullName == "" || u.PhoneNumber == ""
 }
+
+// IsAdmin reports whether the user has the admin role.
+func (u UserContext) IsAdmin() bool {
+	return strings.EqualFold(u.UserRole, "ADMIN")
+}
+
+// OwnerScope returns the owner a request must be restricted to: the caller's
+// user ID, or "" for admins who may act on every owner's data. A non-admin
+// request without a user ID is refused, since "" would mean every owner.
+func OwnerScope(r *http.Request) (string, error) {
+	u := ExtractUserContext(r)
+	if u.IsAdmin() {
+		return "", nil
+	}
+	if u.UserID == "" {
+		return "", ErrMissingUserID
+	}
+	return u.UserID, nil
+}
@@ -0,0 +1,86 @@
+// Package geo contains small geodesic helpers shared by the tracking modules.
+package geo
+
+import "math"
+
+const EarthRadiusMeters = 6371008.8
+
+// HaversineMeters returns the great-circle distance between two coordinates.
+func HaversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// PointInPolygon reports whether p lies inside the polygon using ray casting
+// on the lat/lng plane, which is accurate enough for zones a few km across.
+// The ring may be open or closed. Points on an edge count as inside.
+// Longitudes are unwrapped around the first vertex, so rings crossing the
+// antimeridian work.
+func PointInPolygon(p Point, ring []Point) bool {
+	if len(ring) < 3 {
+		return false
+	}
+	ref := ring[0].Longitude
+	p.Longitude = unwrapLongitude(p.Longitude, ref)
+
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		a.Longitude = unwrapLongitude(a.Longitude, ref)
+		b.Longitude = unwrapLongitude(b.Longitude, ref)
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Latitude > p.Latitude) != (b.Latitude > p.Latitude) {
+			crossLng := (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude)/(b.Latitude-a.Latitude) + a.Longitude
+			if p.Longitude < crossLng {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// unwrapLongitude shifts lng by whole turns to within 180 degrees of ref.
+func unwrapLongitude(lng, ref float64) float64 {
+	for lng-ref > 180 {
+		lng -= 360
+	}
+	for lng-ref < -180 {
+		lng += 360
+	}
+	return lng
+}
+
+// onSegment reports whether p lies on the segment a-b, within about a
+// centimetre.
+func onSegment(p, a, b Point) bool {
+	const epsilon = 1e-7
+	cross := (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude) - (b.Latitude-a.Latitude)*(p.Longitude-a.Longitude)
+	length := math.Hypot(b.Longitude-a.Longitude, b.Latitude-a.Latitude)
+	if math.Abs(cross) > epsilon*length {
+		return false
+	}
+	return p.Longitude >= math.Min(a.Longitude, b.Longitude)-epsilon && p.Longitude <= math.Max(a.Longitude, b.Longitude)+epsilon &&
+		p.Latitude >= math.Min(a.Latitude, b.Latitude)-epsilon && p.Latitude <= math.Max(a.Latitude, b.Latitude)+epsilon
+}
+
+// Offset moves p by the given distances north and east, on a local flat
+// approximation that holds for a few kilometres.
+func Offset(p Point, northMeters, eastMeters float64) Point {
+	dLat := northMeters / EarthRadiusMeters * 180 / math.Pi
+	dLon := eastMeters / (EarthRadiusMeters * math.Cos(p.Latitude*math.Pi/180)) * 180 / math.Pi
+	return Point{Latitude: p.Latitude + dLat, Longitude: p.Longitude + dLon}
+}
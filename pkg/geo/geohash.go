@@ -0,0 +1,83 @@
+package geo
+
+import "math"
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash supported, about 37 mm wide.
+const MaxGeohashPrecision = 12
+
+// GeohashCellSize returns the height and width in degrees of a geohash cell
+// of the given precision. Geohash cells of one precision form a regular
+// latitude/longitude grid, so points can be bucketed with plain arithmetic.
+func GeohashCellSize(precision int) (latDegrees, lonDegrees float64) {
+	latBits, lonBits := geohashBits(precision)
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// GeohashCell returns the grid indices of the cell containing a coordinate,
+// counted from the south-west corner (-90, -180).
+func GeohashCell(latitude, longitude float64, precision int) (latIndex, lonIndex int64) {
+	latBits, lonBits := geohashBits(precision)
+	latSize, lonSize := GeohashCellSize(precision)
+	latIndex = clampIndex(int64(math.Floor((latitude+90)/latSize)), latBits)
+	lonIndex = clampIndex(int64(math.Floor((longitude+180)/lonSize)), lonBits)
+	return latIndex, lonIndex
+}
+
+// GeohashFromCell encodes the grid cell with the given indices. Indices
+// outside the grid are clamped to its edge.
+func GeohashFromCell(latIndex, lonIndex int64, precision int) string {
+	latBits, lonBits := geohashBits(precision)
+	latIndex = clampIndex(latIndex, latBits)
+	lonIndex = clampIndex(lonIndex, lonBits)
+
+	// Bits alternate starting with longitude, most significant first.
+	hash := make([]byte, precision)
+	latBit, lonBit := latBits-1, lonBits-1
+	for i := 0; i < precision; i++ {
+		var char int
+		for b := 0; b < 5; b++ {
+			bitIndex := i*5 + b
+			char <<= 1
+			if bitIndex%2 == 0 {
+				char |= int(lonIndex>>lonBit) & 1
+				lonBit--
+			} else {
+				char |= int(latIndex>>latBit) & 1
+				latBit--
+			}
+		}
+		hash[i] = geohashAlphabet[char]
+	}
+	return string(hash)
+}
+
+// GeohashEncode returns the geohash of a coordinate.
+func GeohashEncode(latitude, longitude float64, precision int) string {
+	precision = clampPrecision(precision)
+	latIndex, lonIndex := GeohashCell(latitude, longitude, precision)
+	return GeohashFromCell(latIndex, lonIndex, precision)
+}
+
+// GeohashCellCenter returns the centre of the grid cell with the given indices.
+func GeohashCellCenter(latIndex, lonIndex int64, precision int) Point {
+	latSize, lonSize := GeohashCellSize(precision)
+	return Point{
+		Latitude:  -90 + (float64(latIndex)+0.5)*latSize,
+		Longitude: -180 + (float64(lonIndex)+0.5)*lonSize,
+	}
+}
+
+func geohashBits(precision int) (latBits, lonBits int) {
+	total := clampPrecision(precision) * 5
+	return total / 2, total - total/2
+}
+
+func clampPrecision(precision int) int {
+	return max(1, min(precision, MaxGeohashPrecision))
+}
+
+func clampIndex(index int64, bits int) int64 {
+	return max(0, min(index, int64(1)<<bits-1))
+}
@@ -0,0 +1,39 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeohashEncode(t *testing.T) {
+	cases := []struct {
+		lat, lon  float64
+		precision int
+		want      string
+	}{
+		{42.6, -5.6, 5, "ezs42"},
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{-25.382708, -49.265506, 9, "6gkzwgjzn"},
+		{90, 180, 4, "zzzz"},
+		{-90, -180, 4, "0000"},
+	}
+	for _, tc := range cases {
+		if got := GeohashEncode(tc.lat, tc.lon, tc.precision); got != tc.want {
+			t.Errorf("GeohashEncode(%v, %v, %d) = %q, want %q", tc.lat, tc.lon, tc.precision, got, tc.want)
+		}
+	}
+}
+
+func TestGeohashCellCenter(t *testing.T) {
+	for precision := 1; precision <= 9; precision++ {
+		latSize, lonSize := GeohashCellSize(precision)
+		latIndex, lonIndex := GeohashCell(9.0108, 38.7613, precision)
+		center := GeohashCellCenter(latIndex, lonIndex, precision)
+		if math.Abs(center.Latitude-9.0108) > latSize/2 || math.Abs(center.Longitude-38.7613) > lonSize/2 {
+			t.Errorf("precision %d: centre %+v does not cover the point", precision, center)
+		}
+		if got, want := GeohashEncode(center.Latitude, center.Longitude, precision), GeohashFromCell(latIndex, lonIndex, precision); got != want {
+			t.Errorf("precision %d: centre encodes to %q, want %q", precision, got, want)
+		}
+	}
+}
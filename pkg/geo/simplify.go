@@ -0,0 +1,137 @@
+package geo
+
+import (
+	"container/heap"
+	"math"
+)
+
+// SegmentDistanceMeters returns the distance from p to the segment a-b. The
+// points are projected onto a local equirectangular plane around a, which is
+// accurate to well under a metre for segments a few tens of km long.
+func SegmentDistanceMeters(p, a, b Point) float64 {
+	cosLat := math.Cos(a.Latitude * math.Pi / 180)
+	project := func(q Point) (float64, float64) {
+		x := (q.Longitude - a.Longitude) * math.Pi / 180 * cosLat * EarthRadiusMeters
+		y := (q.Latitude - a.Latitude) * math.Pi / 180 * EarthRadiusMeters
+		return x, y
+	}
+	px, py := project(p)
+	bx, by := project(b)
+
+	lengthSq := bx*bx + by*by
+	if lengthSq == 0 {
+		return math.Hypot(px, py)
+	}
+	t := (px*bx + py*by) / lengthSq
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(px-t*bx, py-t*by)
+}
+
+// Simplify reduces a polyline with the Douglas-Peucker algorithm and returns
+// the indices of the points to keep, in order.
+//
+// With toleranceMeters > 0, every dropped point lies within toleranceMeters
+// of the kept segment spanning it. With targetPoints > 0, the points that
+// deviate most are added until targetPoints are kept. When both are given,
+// simplification stops at whichever limit is reached first. The first and
+// last points and every index for which mandatory returns true are always
+// kept, even if that exceeds targetPoints. mandatory may be nil.
+func Simplify(points []Point, toleranceMeters float64, targetPoints int, mandatory func(i int) bool) []int {
+	n := len(points)
+	if n <= 2 {
+		keep := make([]int, n)
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+
+	kept := make([]bool, n)
+	kept[0], kept[n-1] = true, true
+	count := 2
+	if mandatory != nil {
+		for i := 1; i < n-1; i++ {
+			if mandatory(i) {
+				kept[i] = true
+				count++
+			}
+		}
+	}
+
+	// Split at the kept points first, then refine the segment with the
+	// largest deviation until the limits are met.
+	queue := &segmentQueue{}
+	start := 0
+	for i := 1; i < n; i++ {
+		if kept[i] {
+			if seg, ok := farthestPoint(points, start, i); ok {
+				heap.Push(queue, seg)
+			}
+			start = i
+		}
+	}
+
+	for queue.Len() > 0 {
+		seg := heap.Pop(queue).(segment)
+		if toleranceMeters > 0 && seg.distance <= toleranceMeters {
+			break
+		}
+		if targetPoints > 0 && count >= targetPoints {
+			break
+		}
+		if toleranceMeters <= 0 && targetPoints <= 0 {
+			break
+		}
+		kept[seg.farthest] = true
+		count++
+		if left, ok := farthestPoint(points, seg.start, seg.farthest); ok {
+			heap.Push(queue, left)
+		}
+		if right, ok := farthestPoint(points, seg.farthest, seg.end); ok {
+			heap.Push(queue, right)
+		}
+	}
+
+	keep := make([]int, 0, count)
+	for i, k := range kept {
+		if k {
+			keep = append(keep, i)
+		}
+	}
+	return keep
+}
+
+type segment struct {
+	start, end int
+	farthest   int
+	distance   float64
+}
+
+// farthestPoint finds the interior point of start..end that deviates most
+// from the segment between them. ok is false when there is no interior point.
+func farthestPoint(points []Point, start, end int) (segment, bool) {
+	if end-start < 2 {
+		return segment{}, false
+	}
+	seg := segment{start: start, end: end, farthest: start + 1, distance: -1}
+	for i := start + 1; i < end; i++ {
+		if d := SegmentDistanceMeters(points[i], points[start], points[end]); d > seg.distance {
+			seg.farthest, seg.distance = i, d
+		}
+	}
+	return seg, true
+}
+
+// segmentQueue is a max-heap of segments by deviation.
+type segmentQueue []segment
+
+func (q segmentQueue) Len() int           { return len(q) }
+func (q segmentQueue) Less(i, j int) bool { return q[i].distance > q[j].distance }
+func (q segmentQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *segmentQueue) Push(x any)        { *q = append(*q, x.(segment)) }
+func (q *segmentQueue) Pop() any {
+	old := *q
+	seg := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return seg
+}
@@ -0,0 +1,265 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// offset returns the point east and north metres away from origin.
+func offset(origin Point, east, north float64) Point {
+	return Point{
+		Latitude:  origin.Latitude + north/EarthRadiusMeters*180/math.Pi,
+		Longitude: origin.Longitude + east/(EarthRadiusMeters*math.Cos(origin.Latitude*math.Pi/180))*180/math.Pi,
+	}
+}
+
+var origin = Point{Latitude: 9.0108, Longitude: 38.7613}
+
+func zigzagTrack() []Point {
+	points := make([]Point, 0, 400)
+	for i := 0; i < 400; i++ {
+		north := 0.0
+		if i%40 >= 20 {
+			north = 60
+		}
+		points = append(points, offset(origin, float64(i)*5, north+float64(i%20)*2))
+	}
+	return points
+}
+
+func noisyLineTrack() []Point {
+	rng := rand.New(rand.NewSource(7))
+	points := make([]Point, 0, 500)
+	for i := 0; i < 500; i++ {
+		points = append(points, offset(origin, float64(i)*4, float64(i)*1.5+rng.NormFloat64()*3))
+	}
+	return points
+}
+
+func circleTrack() []Point {
+	points := make([]Point, 0, 360)
+	for deg := 0; deg < 360; deg++ {
+		rad := float64(deg) * math.Pi / 180
+		points = append(points, offset(origin, 500*math.Cos(rad), 500*math.Sin(rad)))
+	}
+	return points
+}
+
+var syntheticTracks = map[string][]Point{
+	"zigzag": zigzagTrack(),
+	"noisy":  noisyLineTrack(),
+	"circle": circleTrack(),
+}
+
+// geodesicSegmentDistance samples the segment a-b every half metre and
+// returns the smallest great-circle distance to p. It is independent of the
+// projection used by SegmentDistanceMeters.
+func geodesicSegmentDistance(p, a, b Point) float64 {
+	length := HaversineMeters(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	steps := int(length/0.5) + 1
+	best := math.Inf(1)
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		lat := a.Latitude + t*(b.Latitude-a.Latitude)
+		lon := a.Longitude + t*(b.Longitude-a.Longitude)
+		best = math.Min(best, HaversineMeters(p.Latitude, p.Longitude, lat, lon))
+	}
+	return best
+}
+
+// maxDeviation returns the largest distance from a dropped point to the kept
+// segment spanning it.
+func maxDeviation(t *testing.T, points []Point, keep []int) float64 {
+	t.Helper()
+	worst := 0.0
+	for k := 1; k < len(keep); k++ {
+		a, b := keep[k-1], keep[k]
+		if b <= a {
+			t.Fatalf("indices not strictly increasing: %v", keep)
+		}
+		for i := a + 1; i < b; i++ {
+			worst = math.Max(worst, geodesicSegmentDistance(points[i], points[a], points[b]))
+		}
+	}
+	return worst
+}
+
+func assertEndpoints(t *testing.T, points []Point, keep []int) {
+	t.Helper()
+	if len(keep) < 2 || keep[0] != 0 || keep[len(keep)-1] != len(points)-1 {
+		t.Fatalf("endpoints not kept: first=%d last=%d of %d", keep[0], keep[len(keep)-1], len(points))
+	}
+}
+
+func TestSegmentDistanceMeters(t *testing.T) {
+	a := origin
+	b := offset(origin, 1000, 0)
+
+	cases := []struct {
+		name string
+		p    Point
+		want float64
+	}{
+		{"perpendicular", offset(origin, 500, 30), 30},
+		{"on segment", offset(origin, 250, 0), 0},
+		{"before start", offset(origin, -40, 30), 50},
+		{"past end", offset(origin, 1030, -40), 50},
+	}
+	for _, tc := range cases {
+		if got := SegmentDistanceMeters(tc.p, a, b); math.Abs(got-tc.want) > 0.05 {
+			t.Errorf("%s: distance = %.3f, want %.3f", tc.name, got, tc.want)
+		}
+	}
+
+	if got := SegmentDistanceMeters(offset(origin, 3, 4), a, a); math.Abs(got-5) > 0.01 {
+		t.Errorf("degenerate segment: distance = %.3f, want 5", got)
+	}
+}
+
+func TestSimplifyToleranceBound(t *testing.T) {
+	for name, points := range syntheticTracks {
+		for _, tolerance := range []float64{1, 5, 20, 100} {
+			keep := Simplify(points, tolerance, 0, nil)
+			assertEndpoints(t, points, keep)
+
+			// Allow for the half-metre sampling and the projection error.
+			if worst := maxDeviation(t, points, keep); worst > tolerance+0.3 {
+				t.Errorf("%s tolerance %.0fm: max deviation %.2fm", name, tolerance, worst)
+			}
+			if len(keep) >= len(points) && tolerance >= 5 {
+				t.Errorf("%s tolerance %.0fm: nothing was dropped", name, tolerance)
+			}
+		}
+	}
+}
+
+func TestSimplifyToleranceIsMonotonic(t *testing.T) {
+	for name, points := range syntheticTracks {
+		previous := len(points) + 1
+		for _, tolerance := range []float64{1, 5, 20, 100} {
+			n := len(Simplify(points, tolerance, 0, nil))
+			if n > previous {
+				t.Errorf("%s: tolerance %.0fm kept %d points, more than a smaller tolerance (%d)", name, tolerance, n, previous)
+			}
+			previous = n
+		}
+	}
+}
+
+func TestSimplifyStraightLine(t *testing.T) {
+	points := make([]Point, 100)
+	for i := range points {
+		points[i] = offset(origin, float64(i)*10, float64(i)*10)
+	}
+	keep := Simplify(points, 0.5, 0, nil)
+	if len(keep) != 2 {
+		t.Fatalf("straight line kept %d points, want 2", len(keep))
+	}
+}
+
+func TestSimplifyTargetPoints(t *testing.T) {
+	for name, points := range syntheticTracks {
+		for _, target := range []int{2, 10, 50, 200} {
+			keep := Simplify(points, 0, target, nil)
+			assertEndpoints(t, points, keep)
+			if len(keep) != target {
+				t.Errorf("%s target %d: kept %d points", name, target, len(keep))
+			}
+		}
+
+		// More points lower the error bound.
+		coarse := maxDeviation(t, points, Simplify(points, 0, 10, nil))
+		fine := maxDeviation(t, points, Simplify(points, 0, 100, nil))
+		if fine > coarse {
+			t.Errorf("%s: 100 points deviate %.2fm, more than 10 points (%.2fm)", name, fine, coarse)
+		}
+	}
+
+	points := circleTrack()
+	if keep := Simplify(points, 0, len(points)*2, nil); len(keep) != len(points) {
+		t.Errorf("target above input size kept %d of %d points", len(keep), len(points))
+	}
+}
+
+func TestSimplifyToleranceAndTarget(t *testing.T) {
+	points := noisyLineTrack()
+	// The tolerance is reached well before 400 points.
+	byTolerance := Simplify(points, 20, 0, nil)
+	both := Simplify(points, 20, 400, nil)
+	if len(both) != len(byTolerance) {
+		t.Errorf("tolerance should stop first: kept %d, want %d", len(both), len(byTolerance))
+	}
+	// A small target stops before the tolerance is met.
+	if keep := Simplify(points, 1, 5, nil); len(keep) != 5 {
+		t.Errorf("target should stop first: kept %d, want 5", len(keep))
+	}
+}
+
+func TestSimplifyKeepsMandatoryPoints(t *testing.T) {
+	points := make([]Point, 200)
+	for i := range points {
+		points[i] = offset(origin, float64(i)*10, 0)
+	}
+	mandatory := map[int]bool{17: true, 90: true, 91: true, 150: true}
+	isMandatory := func(i int) bool { return mandatory[i] }
+
+	for _, keep := range [][]int{
+		Simplify(points, 50, 0, isMandatory),
+		Simplify(points, 0, 3, isMandatory),
+	} {
+		assertEndpoints(t, points, keep)
+		seen := make(map[int]bool, len(keep))
+		for _, i := range keep {
+			seen[i] = true
+		}
+		for i := range mandatory {
+			if !seen[i] {
+				t.Errorf("mandatory point %d dropped: %v", i, keep)
+			}
+		}
+		// The track is straight, so nothing else is needed.
+		if len(keep) != len(mandatory)+2 {
+			t.Errorf("kept %d points, want %d", len(keep), len(mandatory)+2)
+		}
+	}
+
+	// The bound still holds around mandatory points on a curved track.
+	circle := circleTrack()
+	keep := Simplify(circle, 5, 0, func(i int) bool { return i%97 == 0 })
+	if worst := maxDeviation(t, circle, keep); worst > 5.3 {
+		t.Errorf("circle with mandatory points: max deviation %.2fm", worst)
+	}
+}
+
+func TestSimplifyKeepsMandatoryPointsAtLargeTolerance(t *testing.T) {
+	points := zigzagTrack()
+	mandatory := map[int]bool{5: true, 20: true, 39: true, 200: true, 398: true}
+
+	// A tolerance wider than the whole track reduces it to its endpoints,
+	// except for the points that must be kept.
+	keep := Simplify(points, 100000, 0, func(i int) bool { return mandatory[i] })
+	assertEndpoints(t, points, keep)
+	if len(keep) != len(mandatory)+2 {
+		t.Fatalf("kept %d points, want %d: %v", len(keep), len(mandatory)+2, keep)
+	}
+	for _, i := range keep[1 : len(keep)-1] {
+		if !mandatory[i] {
+			t.Errorf("kept point %d that is not mandatory", i)
+		}
+	}
+}
+
+func TestSimplifyShortInput(t *testing.T) {
+	if keep := Simplify(nil, 10, 0, nil); len(keep) != 0 {
+		t.Errorf("nil input kept %v", keep)
+	}
+	one := []Point{origin}
+	if keep := Simplify(one, 10, 0, nil); len(keep) != 1 || keep[0] != 0 {
+		t.Errorf("single point kept %v", keep)
+	}
+	two := []Point{origin, offset(origin, 10, 0)}
+	if keep := Simplify(two, 10, 1, nil); len(keep) != 2 {
+		t.Errorf("two points kept %v", keep)
+	}
+}
@@ -26,7 +26,7 @@ type Filter struct {
 }
 
 func (e ErrorDefinition) Error() string {
-	return fmt.Sprintf(`{"code": %d ,"message":"%s"}`, e.Code, e.Message)
+	return fmt.Sprintf(`{"code": "%s" ,"message":"%s"}`, e.Code, e.Message)
 }
 
 type SuccesResponse struct {
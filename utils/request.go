@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const DefaultTimeRangeWindow = 24 * time.Hour
+
+// ParseTimeRange reads the RFC3339 `from` and `to` query params. A missing
+// `to` defaults to now and a missing `from` to one day before `to`.
+func ParseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+
+	to := time.Now().UTC()
+	if raw := q.Get("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be an RFC3339 timestamp")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-DefaultTimeRangeWindow)
+	if raw := q.Get("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be an RFC3339 timestamp")
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
+
+// ParseOptionalInt reads a non-negative integer query param, returning 0 when absent.
+func ParseOptionalInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, errors.New(name + " must be a non-negative integer")
+	}
+	return n, nil
+}
+
+// ParseOptionalFloat reads a non-negative number query param, returning 0 when absent.
+func ParseOptionalFloat(r *http.Request, name string) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errors.New(name + " must be a non-negative number")
+	}
+	return f, nil
+}